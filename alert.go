@@ -1,12 +1,36 @@
 package easyalert
 
-import "time"
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// DefaultDedupWindow is used when an alert is given a dedup key but no window.
+const DefaultDedupWindow = time.Hour
 
 type AlertRepository interface {
 	FindAlert(query string, params ...interface{}) (Alert, error)
 	FindAlerts(query string, params ...interface{}) ([]Alert, error)
 	CreateAlert(alert Alert) (Alert, error)
+	CreateAlerts(alerts []Alert) ([]Alert, error)
+	// UpdateAlert stores the alert except its occurrences and last_seen_at,
+	// which are only changed by FoldAlert.
 	UpdateAlert(alert Alert) (Alert, error)
+	// FoldAlert records another occurrence of the alert found by the query,
+	// which is given like for FindAlert, and returns it. The database counts
+	// the occurrences, so concurrent folds are not lost.
+	// ErrRecordDoesNotExist is returned if no alert is found.
+	FoldAlert(query string, params ...interface{}) (Alert, error)
+	// UpdateAlertDelivery only stores the status and sent_at of the alert,
+	// so that recording the outcome of a delivery does not overwrite a
+	// concurrent fold.
+	UpdateAlertDelivery(alert Alert) (Alert, error)
+	// UpdateAlertStatus only stores the status and message of the alert and
+	// only if the alert still has the status from. Otherwise
+	// ErrRecordDoesNotExist is returned, e.g. if a scheduled alert was
+	// canceled meanwhile.
+	UpdateAlertStatus(alert Alert, from uint) (Alert, error)
 	DeleteAlert(alert Alert) error
 }
 
@@ -14,14 +38,90 @@ const (
 	AlertStatusPending = iota
 	AlertStatusSent
 	AlertStatusFailed
+	AlertStatusScheduled
+	AlertStatusCanceled
+)
+
+// ErrInvalidStatus is returned if a status can not be parsed
+var ErrInvalidStatus = errors.New("invalid status")
+
+// ParseStatus converts the human representation of a status into its numeric value.
+func ParseStatus(s string) (uint, error) {
+	switch s {
+	case "pending":
+		return AlertStatusPending, nil
+	case "sent":
+		return AlertStatusSent, nil
+	case "failed":
+		return AlertStatusFailed, nil
+	case "scheduled":
+		return AlertStatusScheduled, nil
+	case "canceled":
+		return AlertStatusCanceled, nil
+	default:
+		return 0, ErrInvalidStatus
+	}
+}
+
+// Severities are ordered by urgency so that they can be compared,
+// e.g. alert.Severity >= AlertSeverityWarning.
+const (
+	AlertSeverityInfo = iota
+	AlertSeverityWarning
+	AlertSeverityCritical
 )
 
+// ErrInvalidSeverity is returned if a severity can not be parsed
+var ErrInvalidSeverity = errors.New("invalid severity")
+
+// ParseSeverity converts the human representation of a severity into its
+// numeric value. An empty string results in AlertSeverityInfo.
+func ParseSeverity(s string) (uint, error) {
+	switch s {
+	case "", "info":
+		return AlertSeverityInfo, nil
+	case "warning":
+		return AlertSeverityWarning, nil
+	case "critical":
+		return AlertSeverityCritical, nil
+	default:
+		return 0, ErrInvalidSeverity
+	}
+}
+
+// SeverityFromName maps the severity names used by other tools, e.g. in a
+// severity label, onto a severity. Unknown names result in AlertSeverityInfo.
+func SeverityFromName(s string) uint {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "critical", "page", "error", "alerting":
+		return AlertSeverityCritical
+	case "warning", "warn":
+		return AlertSeverityWarning
+	default:
+		return AlertSeverityInfo
+	}
+}
+
 type Alert struct {
-	ID        uint
-	Subject   string
-	Status    uint
-	SentAt    *time.Time
-	UserID    uint
+	ID             uint
+	Subject        string
+	Status         uint
+	SentAt         *time.Time
+	UserID         uint
+	DedupKey       string
+	Occurrences    uint
+	LastSeenAt     time.Time
+	Severity       uint
+	Labels         map[string]string
+	SendAt         *time.Time
+	AttachmentSize int64
+
+	// Message and Format of the notification are only stored until a
+	// scheduled alert is released, since messages are never kept once
+	// they were sent.
+	Message string
+	Format  string
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
@@ -34,7 +134,54 @@ func (a *Alert) HumanStatus() string {
 		return "sent"
 	case AlertStatusFailed:
 		return "failed"
+	case AlertStatusScheduled:
+		return "scheduled"
+	case AlertStatusCanceled:
+		return "canceled"
 	default:
 		return "invalid status"
 	}
 }
+
+func (a *Alert) HumanSeverity() string {
+	switch a.Severity {
+	case AlertSeverityInfo:
+		return "info"
+	case AlertSeverityWarning:
+		return "warning"
+	case AlertSeverityCritical:
+		return "critical"
+	default:
+		return "invalid severity"
+	}
+}
+
+// CreateOrFoldAlert creates the alert unless an alert with the same dedup key
+// was created inside the window. In that case the alert is folded into the
+// existing alert, which is returned, and folded is true.
+func CreateOrFoldAlert(repo AlertRepository, alert Alert, window time.Duration) (Alert, bool, error) {
+	if alert.DedupKey != "" {
+		// alerts are folded into the first alert of the window, so a
+		// permanently failing job still alerts once per window
+		// canceled alerts are never sent, so nothing is folded into them
+		existing, err := repo.FoldAlert(`
+			WHERE user_id = $1 AND dedup_key = $2
+			AND created_at > NOW() - $3 * INTERVAL '1 second'
+			AND status <> $4
+			ORDER BY created_at DESC
+			LIMIT 1
+		`, alert.UserID, alert.DedupKey, int64(window.Seconds()), AlertStatusCanceled)
+
+		if err == nil {
+			return existing, true, nil
+		}
+
+		if err != ErrRecordDoesNotExist {
+			return Alert{}, false, err
+		}
+	}
+
+	alert, err := repo.CreateAlert(alert)
+
+	return alert, false, err
+}
@@ -2,21 +2,101 @@ package easyalert_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/mocks"
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 )
 
 func TestHumanStatus(t *testing.T) {
 	var (
-		pending = easyalert.Alert{Status: 0}
-		sent    = easyalert.Alert{Status: 1}
-		failed  = easyalert.Alert{Status: 2}
-		invalid = easyalert.Alert{Status: 3}
+		pending   = easyalert.Alert{Status: 0}
+		sent      = easyalert.Alert{Status: 1}
+		failed    = easyalert.Alert{Status: 2}
+		scheduled = easyalert.Alert{Status: 3}
+		canceled  = easyalert.Alert{Status: 4}
+		invalid   = easyalert.Alert{Status: 5}
 	)
 
 	require.Equal(t, "pending", pending.HumanStatus())
 	require.Equal(t, "sent", sent.HumanStatus())
 	require.Equal(t, "failed", failed.HumanStatus())
+	require.Equal(t, "scheduled", scheduled.HumanStatus())
+	require.Equal(t, "canceled", canceled.HumanStatus())
 	require.Equal(t, "invalid status", invalid.HumanStatus())
 }
+
+func TestParseStatus(t *testing.T) {
+	for _, human := range []string{"pending", "sent", "failed", "scheduled", "canceled"} {
+		status, err := easyalert.ParseStatus(human)
+		require.Nil(t, err)
+
+		alert := easyalert.Alert{Status: status}
+		require.Equal(t, human, alert.HumanStatus())
+	}
+
+	_, err := easyalert.ParseStatus("delivered")
+	require.Equal(t, easyalert.ErrInvalidStatus, err)
+}
+
+func TestHumanSeverity(t *testing.T) {
+	var (
+		info     = easyalert.Alert{Severity: 0}
+		warning  = easyalert.Alert{Severity: 1}
+		critical = easyalert.Alert{Severity: 2}
+		invalid  = easyalert.Alert{Severity: 3}
+	)
+
+	require.Equal(t, "info", info.HumanSeverity())
+	require.Equal(t, "warning", warning.HumanSeverity())
+	require.Equal(t, "critical", critical.HumanSeverity())
+	require.Equal(t, "invalid severity", invalid.HumanSeverity())
+}
+
+func TestParseSeverity(t *testing.T) {
+	for human, expected := range map[string]uint{
+		"":         easyalert.AlertSeverityInfo,
+		"info":     easyalert.AlertSeverityInfo,
+		"warning":  easyalert.AlertSeverityWarning,
+		"critical": easyalert.AlertSeverityCritical,
+	} {
+		severity, err := easyalert.ParseSeverity(human)
+		require.Nil(t, err)
+		require.Equal(t, expected, severity)
+	}
+
+	_, err := easyalert.ParseSeverity("fatal")
+	require.Equal(t, easyalert.ErrInvalidSeverity, err)
+}
+
+func TestSeverityFromName(t *testing.T) {
+	for name, expected := range map[string]uint{
+		"":         easyalert.AlertSeverityInfo,
+		"debug":    easyalert.AlertSeverityInfo,
+		"Warning":  easyalert.AlertSeverityWarning,
+		"warn":     easyalert.AlertSeverityWarning,
+		"CRITICAL": easyalert.AlertSeverityCritical,
+		"page":     easyalert.AlertSeverityCritical,
+		"alerting": easyalert.AlertSeverityCritical,
+	} {
+		require.Equal(t, expected, easyalert.SeverityFromName(name))
+	}
+}
+
+func TestCreateOrFoldAlert_ShouldNotFoldIntoCanceledAlerts(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	alert := easyalert.Alert{UserID: 1, DedupKey: "backup"}
+
+	repo := mocks.NewMockAlertRepository(mockCtrl)
+	repo.EXPECT().FoldAlert(gomock.Any(), uint(1), "backup", int64(3600), easyalert.AlertStatusCanceled).Return(easyalert.Alert{}, easyalert.ErrRecordDoesNotExist)
+	repo.EXPECT().CreateAlert(alert).Return(easyalert.Alert{ID: 5}, nil)
+
+	created, folded, err := easyalert.CreateOrFoldAlert(repo, alert, time.Hour)
+	require.Nil(t, err)
+	require.False(t, folded)
+	require.Equal(t, uint(5), created.ID)
+}
@@ -0,0 +1,194 @@
+// Package alertmanager converts webhook notifications of the Prometheus
+// Alertmanager into alerts.
+package alertmanager
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bakku/easyalert"
+)
+
+// Version is the only version of the webhook payload which is supported.
+const Version = "4"
+
+// GroupKeyLabel is added to every alert so that all alerts of a notification
+// can be found together.
+const GroupKeyLabel = "alertmanager_group"
+
+// DedupWindow is the window in which repeated notifications about the same
+// alert are folded. Alertmanager resends all alerts of a group whenever the
+// group changes, which would otherwise send the same email again.
+const DedupWindow = time.Hour
+
+// Payload is the body of a webhook notification.
+type Payload struct {
+	Version           string            `json:"version"`
+	GroupKey          string            `json:"groupKey"`
+	TruncatedAlerts   int               `json:"truncatedAlerts"`
+	Status            string            `json:"status"`
+	Receiver          string            `json:"receiver"`
+	GroupLabels       map[string]string `json:"groupLabels"`
+	CommonLabels      map[string]string `json:"commonLabels"`
+	CommonAnnotations map[string]string `json:"commonAnnotations"`
+	ExternalURL       string            `json:"externalURL"`
+	Alerts            []Alert           `json:"alerts"`
+}
+
+// Alert is a single alert inside a notification.
+type Alert struct {
+	Status       string            `json:"status"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL"`
+	Fingerprint  string            `json:"fingerprint"`
+}
+
+const (
+	statusFiring   = "firing"
+	statusResolved = "resolved"
+)
+
+// Validate checks that the payload can be converted.
+func (p Payload) Validate() error {
+	if p.Version != Version {
+		return fmt.Errorf("Unsupported payload version %q, expected %s.", p.Version, Version)
+	}
+
+	if len(p.Alerts) == 0 {
+		return errors.New("No alerts given.")
+	}
+
+	for _, a := range p.Alerts {
+		if a.Status != statusFiring && a.Status != statusResolved {
+			return fmt.Errorf("Invalid alert status %q, expected firing or resolved.", a.Status)
+		}
+	}
+
+	return nil
+}
+
+// Notifications converts every alert of the payload into an alert of the
+// user together with the message which is sent for it. Labels are kept as
+// labels, annotations become part of the message as they may contain
+// details which should not be stored.
+func (p Payload) Notifications(user easyalert.User) []easyalert.Notification {
+	notifications := make([]easyalert.Notification, len(p.Alerts))
+
+	for i, a := range p.Alerts {
+		labels := map[string]string{}
+
+		for key, value := range a.Labels {
+			// colons are reserved for label filters
+			labels[strings.Replace(key, ":", "_", -1)] = value
+		}
+
+		if p.GroupKey != "" {
+			labels[GroupKeyLabel] = p.GroupKey
+		}
+
+		alert := easyalert.Alert{
+			Subject:     a.subject(),
+			Status:      easyalert.AlertStatusPending,
+			UserID:      user.ID,
+			Occurrences: 1,
+			Severity:    a.severity(),
+			Labels:      labels,
+		}
+
+		if a.Fingerprint != "" {
+			// firing and resolved are reported separately, and an alert
+			// firing again after it resolved starts a new incident
+			alert.DedupKey = fmt.Sprintf("alertmanager:%s:%d:%s", a.Fingerprint, a.StartsAt.Unix(), a.Status)
+		}
+
+		notifications[i] = easyalert.Notification{
+			Alert:   alert,
+			Message: a.message(p.ExternalURL),
+			Format:  easyalert.MessageFormatText,
+		}
+	}
+
+	return notifications
+}
+
+// subject is e.g. "[FIRING] HighLatency: Latency of api is above 500ms".
+func (a Alert) subject() string {
+	name := a.Labels["alertname"]
+	if name == "" {
+		name = "Alert"
+	}
+
+	subject := "[" + strings.ToUpper(a.Status) + "] " + name
+
+	if summary := a.Annotations["summary"]; summary != "" {
+		subject += ": " + summary
+	}
+
+	return subject
+}
+
+// severity uses the common severity label. Resolved alerts are informational.
+func (a Alert) severity() uint {
+	if a.Status == statusResolved {
+		return easyalert.AlertSeverityInfo
+	}
+
+	return easyalert.SeverityFromName(a.Labels["severity"])
+}
+
+func (a Alert) message(externalURL string) string {
+	var b strings.Builder
+
+	for _, key := range sortedKeys(a.Annotations) {
+		if key == "summary" {
+			continue
+		}
+
+		fmt.Fprintf(&b, "%s: %s\n", key, a.Annotations[key])
+	}
+
+	if b.Len() > 0 {
+		b.WriteString("\n")
+	}
+
+	b.WriteString("Labels:\n")
+
+	for _, key := range sortedKeys(a.Labels) {
+		fmt.Fprintf(&b, "  %s = %s\n", key, a.Labels[key])
+	}
+
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "Started: %s\n", a.StartsAt.UTC().Format(time.RFC3339))
+
+	if a.Status == statusResolved && !a.EndsAt.IsZero() {
+		fmt.Fprintf(&b, "Resolved: %s\n", a.EndsAt.UTC().Format(time.RFC3339))
+	}
+
+	if a.GeneratorURL != "" {
+		fmt.Fprintf(&b, "Source: %s\n", a.GeneratorURL)
+	}
+
+	if externalURL != "" {
+		fmt.Fprintf(&b, "Alertmanager: %s\n", externalURL)
+	}
+
+	return b.String()
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
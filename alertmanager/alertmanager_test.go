@@ -0,0 +1,101 @@
+package alertmanager_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/alertmanager"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPayloadValidate(t *testing.T) {
+	tests := []struct {
+		payload alertmanager.Payload
+		err     string
+	}{
+		{alertmanager.Payload{Version: "4", Alerts: []alertmanager.Alert{{Status: "firing"}}}, ""},
+		{alertmanager.Payload{Version: "3", Alerts: []alertmanager.Alert{{Status: "firing"}}}, `Unsupported payload version "3", expected 4.`},
+		{alertmanager.Payload{Version: "4"}, "No alerts given."},
+		{alertmanager.Payload{Version: "4", Alerts: []alertmanager.Alert{{Status: "silenced"}}}, `Invalid alert status "silenced", expected firing or resolved.`},
+	}
+
+	for _, test := range tests {
+		err := test.payload.Validate()
+
+		if test.err == "" {
+			require.Nil(t, err)
+		} else {
+			require.EqualError(t, err, test.err)
+		}
+	}
+}
+
+func TestPayloadNotifications(t *testing.T) {
+	startsAt := time.Date(2019, 1, 25, 10, 0, 0, 0, time.UTC)
+
+	payload := alertmanager.Payload{
+		Version:     "4",
+		GroupKey:    `{}:{alertname="HighLatency"}`,
+		ExternalURL: "http://alertmanager:9093",
+		Alerts: []alertmanager.Alert{
+			{
+				Status:       "firing",
+				Labels:       map[string]string{"alertname": "HighLatency", "severity": "page", "instance": "api:8080"},
+				Annotations:  map[string]string{"summary": "Latency is above 500ms", "runbook": "https://wiki/latency"},
+				StartsAt:     startsAt,
+				GeneratorURL: "http://prometheus:9090/graph",
+				Fingerprint:  "abc123",
+			},
+			{
+				Status:   "resolved",
+				Labels:   map[string]string{"severity": "warning"},
+				StartsAt: startsAt,
+				EndsAt:   startsAt.Add(time.Hour),
+			},
+		},
+	}
+
+	notifications := payload.Notifications(easyalert.User{ID: 2})
+	require.Len(t, notifications, 2)
+
+	firing := notifications[0]
+	require.Equal(t, "[FIRING] HighLatency: Latency is above 500ms", firing.Alert.Subject)
+	require.Equal(t, uint(easyalert.AlertSeverityCritical), firing.Alert.Severity)
+	require.Equal(t, uint(2), firing.Alert.UserID)
+	require.Equal(t, "alertmanager:abc123:1548410400:firing", firing.Alert.DedupKey)
+	require.Equal(t, map[string]string{
+		"alertname":          "HighLatency",
+		"severity":           "page",
+		"instance":           "api:8080",
+		"alertmanager_group": `{}:{alertname="HighLatency"}`,
+	}, firing.Alert.Labels)
+	require.Equal(t, easyalert.MessageFormatText, firing.Format)
+	require.Equal(t, "runbook: https://wiki/latency\n\n"+
+		"Labels:\n"+
+		"  alertname = HighLatency\n"+
+		"  instance = api:8080\n"+
+		"  severity = page\n\n"+
+		"Started: 2019-01-25T10:00:00Z\n"+
+		"Source: http://prometheus:9090/graph\n"+
+		"Alertmanager: http://alertmanager:9093\n", firing.Message)
+
+	resolved := notifications[1]
+	require.Equal(t, "[RESOLVED] Alert", resolved.Alert.Subject)
+	require.Equal(t, uint(easyalert.AlertSeverityInfo), resolved.Alert.Severity)
+	require.Equal(t, "", resolved.Alert.DedupKey)
+	require.Contains(t, resolved.Message, "Resolved: 2019-01-25T11:00:00Z\n")
+}
+
+func TestPayloadNotifications_ShouldNotFoldAlertFiringAgain(t *testing.T) {
+	startsAt := time.Date(2019, 1, 25, 10, 0, 0, 0, time.UTC)
+
+	first := alertmanager.Alert{Status: "firing", StartsAt: startsAt, Fingerprint: "abc123"}
+	again := alertmanager.Alert{Status: "firing", StartsAt: startsAt.Add(20 * time.Minute), Fingerprint: "abc123"}
+
+	notifications := alertmanager.Payload{Version: "4", Alerts: []alertmanager.Alert{first, first, again}}.Notifications(easyalert.User{ID: 2})
+
+	// resending the same incident is folded, firing again after resolving is not
+	require.Equal(t, notifications[0].Alert.DedupKey, notifications[1].Alert.DedupKey)
+	require.NotEqual(t, notifications[0].Alert.DedupKey, notifications[2].Alert.DedupKey)
+}
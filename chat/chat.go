@@ -0,0 +1,91 @@
+// Package chat renders alerts into the incoming webhook messages of chat
+// tools like Slack, Discord, Mattermost and Microsoft Teams and into Matrix
+// room messages.
+package chat
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/bakku/easyalert"
+)
+
+// ErrUnknownKind is returned if a message should be rendered for an unknown
+// kind of chat channel.
+var ErrUnknownKind = errors.New("unknown chat kind")
+
+// renderers returns the message of a notification per kind of channel.
+var renderers = map[string]func(n easyalert.Notification) interface{}{
+	easyalert.ChatKindSlack:      slackMessage,
+	easyalert.ChatKindDiscord:    discordMessage,
+	easyalert.ChatKindMattermost: mattermostMessage,
+	easyalert.ChatKindTeams:      teamsMessage,
+}
+
+// ValidKind returns whether messages can be rendered for the kind.
+func ValidKind(kind string) bool {
+	_, ok := renderers[kind]
+	return ok
+}
+
+// Render returns the JSON message which is posted to an incoming webhook of
+// the given kind.
+func Render(kind string, n easyalert.Notification) ([]byte, error) {
+	render, ok := renderers[kind]
+	if !ok {
+		return nil, ErrUnknownKind
+	}
+
+	return json.Marshal(render(n))
+}
+
+// Colors of the severities as hex RGB.
+const (
+	colorCritical = "#d32f2f"
+	colorWarning  = "#f9a825"
+	colorInfo     = "#1976d2"
+)
+
+func color(alert easyalert.Alert) string {
+	switch alert.Severity {
+	case easyalert.AlertSeverityCritical:
+		return colorCritical
+	case easyalert.AlertSeverityWarning:
+		return colorWarning
+	default:
+		return colorInfo
+	}
+}
+
+type label struct {
+	key   string
+	value string
+}
+
+// labels returns the labels of the alert sorted by key, so that messages
+// look the same every time.
+func labels(alert easyalert.Alert) []label {
+	result := make([]label, 0, len(alert.Labels))
+
+	for k, v := range alert.Labels {
+		result = append(result, label{k, v})
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].key < result[j].key })
+
+	return result
+}
+
+// truncate shortens s to at most n characters, as the tools reject messages
+// exceeding their limits.
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+
+	runes := []rune(s)
+
+	return string(runes[:n-1]) + "…"
+}
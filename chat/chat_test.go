@@ -0,0 +1,127 @@
+package chat_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/chat"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender_ShouldRejectUnknownKinds(t *testing.T) {
+	require.False(t, chat.ValidKind("irc"))
+
+	_, err := chat.Render("irc", easyalert.Notification{})
+	require.Equal(t, chat.ErrUnknownKind, err)
+}
+
+func TestRender_ShouldKeepDiscordLimits(t *testing.T) {
+	labels := map[string]string{}
+	for i := 0; i < 30; i++ {
+		labels[fmt.Sprintf("l%02d", i)] = "v"
+	}
+
+	b, err := chat.Render(easyalert.ChatKindDiscord, easyalert.Notification{
+		Alert: easyalert.Alert{Subject: strings.Repeat("s", 300), Labels: labels},
+	})
+	require.Nil(t, err)
+
+	var payload struct {
+		Embeds []struct {
+			Title  string
+			Color  int
+			Fields []struct{ Name string }
+		}
+	}
+	require.Nil(t, json.Unmarshal(b, &payload))
+
+	embed := payload.Embeds[0]
+	require.Equal(t, 256, len([]rune(embed.Title)))
+	require.True(t, strings.HasSuffix(embed.Title, "…"))
+	require.Len(t, embed.Fields, 25)
+	require.Equal(t, "l00", embed.Fields[0].Name)
+	require.Equal(t, 0x1976d2, embed.Color)
+}
+
+func TestRender_ShouldKeepDiscordEmbedBudget(t *testing.T) {
+	labels := map[string]string{}
+	for i := 0; i < 10; i++ {
+		labels[fmt.Sprintf("l%02d", i)] = strings.Repeat("v", 1024)
+	}
+
+	b, err := chat.Render(easyalert.ChatKindDiscord, easyalert.Notification{
+		Alert:   easyalert.Alert{Subject: strings.Repeat("s", 300), Labels: labels},
+		Message: strings.Repeat("m", 5000),
+	})
+	require.Nil(t, err)
+
+	var payload struct {
+		Embeds []struct {
+			Title       string
+			Description string
+			Fields      []struct{ Name, Value string }
+			Footer      struct{ Text string }
+		}
+	}
+	require.Nil(t, json.Unmarshal(b, &payload))
+
+	embed := payload.Embeds[0]
+	require.Equal(t, 4096, len([]rune(embed.Description)))
+
+	total := len([]rune(embed.Title)) + len([]rune(embed.Description)) + len([]rune(embed.Footer.Text))
+	for _, f := range embed.Fields {
+		total += len([]rune(f.Name)) + len([]rune(f.Value))
+	}
+
+	require.True(t, total <= 6000, "%d characters", total)
+
+	// one field fits, the second is shortened and the rest is dropped
+	require.Len(t, embed.Fields, 2)
+	require.Equal(t, strings.Repeat("v", 1024), embed.Fields[0].Value)
+	require.True(t, strings.HasSuffix(embed.Fields[1].Value, "…"))
+	require.Equal(t, 6000, total)
+}
+
+func TestRender_ShouldReplaceEmptyDiscordFieldValues(t *testing.T) {
+	b, err := chat.Render(easyalert.ChatKindDiscord, easyalert.Notification{
+		Alert: easyalert.Alert{Subject: "Disk full", Labels: map[string]string{"env": "", "host": " ", "team": "ops"}},
+	})
+	require.Nil(t, err)
+
+	var payload struct {
+		Embeds []struct {
+			Fields []struct{ Name, Value string }
+		}
+	}
+	require.Nil(t, json.Unmarshal(b, &payload))
+
+	fields := payload.Embeds[0].Fields
+	require.Len(t, fields, 3)
+	require.Equal(t, "-", fields[0].Value)
+	require.Equal(t, "-", fields[1].Value)
+	require.Equal(t, "ops", fields[2].Value)
+}
+
+func TestRender_ShouldLeaveOutMissingMessage(t *testing.T) {
+	b, err := chat.Render(easyalert.ChatKindSlack, easyalert.Notification{
+		Alert: easyalert.Alert{Subject: "Heartbeat missed", Severity: easyalert.AlertSeverityWarning},
+	})
+	require.Nil(t, err)
+
+	require.NotContains(t, string(b), `"section"`)
+	require.Contains(t, string(b), `"color":"#f9a825"`)
+}
+
+func TestNewMatrixMessage_ShouldRenderMarkdown(t *testing.T) {
+	m := chat.NewMatrixMessage(easyalert.Notification{
+		Alert:   easyalert.Alert{Subject: "Deploy finished"},
+		Message: "**all** green",
+		Format:  easyalert.MessageFormatMarkdown,
+	})
+
+	require.Equal(t, "[info] Deploy finished\n\n**all** green", m.Body)
+	require.Equal(t, "<p><strong><span data-mx-color=\"#1976d2\">[info]</span> Deploy finished</strong></p><p><strong>all</strong> green</p>\n", m.FormattedBody)
+}
@@ -0,0 +1,99 @@
+package chat
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bakku/easyalert"
+)
+
+type discordPayload struct {
+	Embeds []discordEmbed `json:"embeds"`
+}
+
+type discordEmbed struct {
+	Title       string         `json:"title"`
+	Description string         `json:"description,omitempty"`
+	Color       int            `json:"color"`
+	Fields      []discordField `json:"fields,omitempty"`
+	Footer      discordFooter  `json:"footer"`
+	Timestamp   string         `json:"timestamp,omitempty"`
+}
+
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+type discordFooter struct {
+	Text string `json:"text"`
+}
+
+// discordFieldLimit is the maximum number of fields of an embed.
+const discordFieldLimit = 25
+
+// discordEmbedLimit is the maximum number of characters of the title,
+// description, fields and footer of an embed together. Title, description
+// and footer always fit, so only the fields are shortened.
+const discordEmbedLimit = 6000
+
+// discordMinFieldValue is the shortest value a field is shortened to before
+// it is dropped.
+const discordMinFieldValue = 16
+
+// discordEmptyValue replaces blank label values, as Discord rejects fields
+// without a value.
+const discordEmptyValue = "-"
+
+// discordMessage renders the alert as embed whose color is given by the
+// severity and whose fields are the labels.
+func discordMessage(n easyalert.Notification) interface{} {
+	// colors are given as decimal RGB value
+	c, _ := strconv.ParseInt(color(n.Alert)[1:], 16, 32)
+
+	embed := discordEmbed{
+		Title:       truncate(n.Alert.Subject, 256),
+		Description: truncate(n.Message, 4096),
+		Color:       int(c),
+		Footer:      discordFooter{"easyalert · " + n.Alert.HumanSeverity()},
+	}
+
+	if !n.Alert.CreatedAt.IsZero() {
+		embed.Timestamp = n.Alert.CreatedAt.UTC().Format(time.RFC3339)
+	}
+
+	budget := discordEmbedLimit - utf8.RuneCountInString(embed.Title) -
+		utf8.RuneCountInString(embed.Description) - utf8.RuneCountInString(embed.Footer.Text)
+
+	for _, l := range labels(n.Alert) {
+		if len(embed.Fields) == discordFieldLimit {
+			break
+		}
+
+		value := l.value
+		if strings.TrimSpace(value) == "" {
+			value = discordEmptyValue
+		}
+
+		name, value := truncate(l.key, 256), truncate(value, 1024)
+
+		// the field exceeding the budget is shortened if enough of its
+		// value is left, the remaining labels are dropped
+		left := budget - utf8.RuneCountInString(name)
+		if utf8.RuneCountInString(value) > left {
+			if left >= discordMinFieldValue {
+				embed.Fields = append(embed.Fields, discordField{name, truncate(value, left), true})
+			}
+
+			break
+		}
+
+		budget = left - utf8.RuneCountInString(value)
+		embed.Fields = append(embed.Fields, discordField{name, value, true})
+	}
+
+	return discordPayload{Embeds: []discordEmbed{embed}}
+}
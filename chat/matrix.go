@@ -0,0 +1,61 @@
+package chat
+
+import (
+	"html"
+	"strings"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/markdown"
+)
+
+// MatrixMessage is the content of an m.room.message event.
+type MatrixMessage struct {
+	MsgType       string `json:"msgtype"`
+	Body          string `json:"body"`
+	Format        string `json:"format"`
+	FormattedBody string `json:"formatted_body"`
+}
+
+// NewMatrixMessage renders the alert as HTML message with the severity
+// colored in front of the subject and the labels below the message. Clients
+// without HTML support show the plain text body.
+func NewMatrixMessage(n easyalert.Notification) MatrixMessage {
+	severity := n.Alert.HumanSeverity()
+
+	var body, formatted strings.Builder
+
+	body.WriteString("[" + severity + "] " + n.Alert.Subject)
+	formatted.WriteString(`<p><strong><span data-mx-color="` + color(n.Alert) + `">[` + severity + `]</span> ` + html.EscapeString(n.Alert.Subject) + `</strong></p>`)
+
+	if n.Message != "" {
+		message := truncate(n.Message, 30000)
+
+		body.WriteString("\n\n" + message)
+
+		if n.Format == easyalert.MessageFormatMarkdown {
+			formatted.WriteString(markdown.ToHTML(message))
+		} else {
+			formatted.WriteString("<p>" + strings.Replace(html.EscapeString(message), "\n", "<br>", -1) + "</p>")
+		}
+	}
+
+	if l := labels(n.Alert); len(l) > 0 {
+		plain := make([]string, len(l))
+		codes := make([]string, len(l))
+
+		for i, label := range l {
+			plain[i] = label.key + "=" + label.value
+			codes[i] = "<code>" + html.EscapeString(plain[i]) + "</code>"
+		}
+
+		body.WriteString("\n\n" + strings.Join(plain, ", "))
+		formatted.WriteString("<p>" + strings.Join(codes, " ") + "</p>")
+	}
+
+	return MatrixMessage{
+		MsgType:       "m.text",
+		Body:          body.String(),
+		Format:        "org.matrix.custom.html",
+		FormattedBody: formatted.String(),
+	}
+}
@@ -0,0 +1,39 @@
+package chat
+
+import "github.com/bakku/easyalert"
+
+type mattermostPayload struct {
+	Attachments []mattermostAttachment `json:"attachments"`
+}
+
+type mattermostAttachment struct {
+	Fallback string            `json:"fallback"`
+	Color    string            `json:"color"`
+	Title    string            `json:"title"`
+	Text     string            `json:"text,omitempty"`
+	Fields   []mattermostField `json:"fields"`
+}
+
+type mattermostField struct {
+	Short bool   `json:"short"`
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+// mattermostMessage renders the alert as message attachment, which
+// Mattermost renders as colored box with the severity and labels as fields.
+func mattermostMessage(n easyalert.Notification) interface{} {
+	fields := []mattermostField{{true, "severity", n.Alert.HumanSeverity()}}
+
+	for _, l := range labels(n.Alert) {
+		fields = append(fields, mattermostField{true, l.key, l.value})
+	}
+
+	return mattermostPayload{Attachments: []mattermostAttachment{{
+		Fallback: n.Alert.Subject,
+		Color:    color(n.Alert),
+		Title:    n.Alert.Subject,
+		Text:     truncate(n.Message, 7000),
+		Fields:   fields,
+	}}}
+}
@@ -0,0 +1,63 @@
+package chat
+
+import (
+	"strings"
+
+	"github.com/bakku/easyalert"
+)
+
+type slackPayload struct {
+	Text        string            `json:"text"`
+	Attachments []slackAttachment `json:"attachments"`
+}
+
+type slackAttachment struct {
+	Color  string       `json:"color"`
+	Blocks []slackBlock `json:"blocks"`
+}
+
+type slackBlock struct {
+	Type     string      `json:"type"`
+	Text     *slackText  `json:"text,omitempty"`
+	Elements []slackText `json:"elements,omitempty"`
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// slackContextLimit is the maximum number of elements of a context block.
+const slackContextLimit = 10
+
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// slackMessage renders the alert as attachment colored by severity, with a
+// header block for the subject, a section for the message and a context
+// block for severity and labels.
+func slackMessage(n easyalert.Notification) interface{} {
+	blocks := []slackBlock{
+		{Type: "header", Text: &slackText{"plain_text", truncate(n.Alert.Subject, 150)}},
+	}
+
+	if n.Message != "" {
+		blocks = append(blocks, slackBlock{Type: "section", Text: &slackText{"mrkdwn", truncate(slackEscaper.Replace(n.Message), 3000)}})
+	}
+
+	context := []slackText{{"mrkdwn", "*severity:* " + n.Alert.HumanSeverity()}}
+
+	for _, l := range labels(n.Alert) {
+		if len(context) == slackContextLimit {
+			break
+		}
+
+		context = append(context, slackText{"mrkdwn", "*" + slackEscaper.Replace(l.key) + ":* " + slackEscaper.Replace(l.value)})
+	}
+
+	blocks = append(blocks, slackBlock{Type: "context", Elements: context})
+
+	return slackPayload{
+		Text:        slackEscaper.Replace(n.Alert.Subject),
+		Attachments: []slackAttachment{{Color: color(n.Alert), Blocks: blocks}},
+	}
+}
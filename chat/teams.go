@@ -0,0 +1,81 @@
+package chat
+
+import "github.com/bakku/easyalert"
+
+type teamsPayload struct {
+	Type        string            `json:"type"`
+	Attachments []teamsAttachment `json:"attachments"`
+}
+
+type teamsAttachment struct {
+	ContentType string    `json:"contentType"`
+	Content     teamsCard `json:"content"`
+}
+
+type teamsCard struct {
+	Schema  string           `json:"$schema"`
+	Type    string           `json:"type"`
+	Version string           `json:"version"`
+	Body    []teamsCardBlock `json:"body"`
+}
+
+type teamsCardBlock struct {
+	Type   string      `json:"type"`
+	Text   string      `json:"text,omitempty"`
+	Weight string      `json:"weight,omitempty"`
+	Size   string      `json:"size,omitempty"`
+	Color  string      `json:"color,omitempty"`
+	Wrap   bool        `json:"wrap,omitempty"`
+	Facts  []teamsFact `json:"facts,omitempty"`
+}
+
+type teamsFact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+// teamsColor returns the color of the severity, as adaptive cards only
+// support a few named colors.
+func teamsColor(alert easyalert.Alert) string {
+	switch alert.Severity {
+	case easyalert.AlertSeverityCritical:
+		return "Attention"
+	case easyalert.AlertSeverityWarning:
+		return "Warning"
+	default:
+		return "Accent"
+	}
+}
+
+// teamsMessage renders the alert as adaptive card with the subject as
+// colored title and the severity and labels as facts.
+func teamsMessage(n easyalert.Notification) interface{} {
+	body := []teamsCardBlock{
+		{Type: "TextBlock", Text: n.Alert.Subject, Weight: "Bolder", Size: "Medium", Color: teamsColor(n.Alert), Wrap: true},
+	}
+
+	if n.Message != "" {
+		body = append(body, teamsCardBlock{Type: "TextBlock", Text: truncate(n.Message, 10000), Wrap: true})
+	}
+
+	facts := []teamsFact{{"severity", n.Alert.HumanSeverity()}}
+
+	for _, l := range labels(n.Alert) {
+		facts = append(facts, teamsFact{l.key, l.value})
+	}
+
+	body = append(body, teamsCardBlock{Type: "FactSet", Facts: facts})
+
+	return teamsPayload{
+		Type: "message",
+		Attachments: []teamsAttachment{{
+			ContentType: "application/vnd.microsoft.card.adaptive",
+			Content: teamsCard{
+				Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+				Type:    "AdaptiveCard",
+				Version: "1.4",
+				Body:    body,
+			},
+		}},
+	}
+}
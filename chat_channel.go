@@ -0,0 +1,32 @@
+package easyalert
+
+import "time"
+
+// Kinds of chat channels.
+const (
+	ChatKindSlack      = "slack"
+	ChatKindDiscord    = "discord"
+	ChatKindMattermost = "mattermost"
+	ChatKindTeams      = "teams"
+)
+
+// ChatChannelRepository wraps all CRUD operations for chat channels
+type ChatChannelRepository interface {
+	FindChatChannel(query string, params ...interface{}) (ChatChannel, error)
+	FindChatChannels(query string, params ...interface{}) ([]ChatChannel, error)
+	CreateChatChannel(channel ChatChannel) (ChatChannel, error)
+	UpdateChatChannel(channel ChatChannel) (ChatChannel, error)
+	DeleteChatChannel(channel ChatChannel) error
+}
+
+// ChatChannel is an incoming webhook of a chat tool like Slack which alerts
+// of the user are posted to. The URL contains the credentials of the webhook.
+type ChatChannel struct {
+	ID        uint
+	Name      string
+	Kind      string
+	URL       string
+	UserID    uint
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
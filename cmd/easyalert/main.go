@@ -4,8 +4,16 @@ import (
 	"database/sql"
 	"fmt"
 	"os"
+	"strconv"
 
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/delivery"
+	"github.com/bakku/easyalert/dispatch"
 	"github.com/bakku/easyalert/postgres"
+	"github.com/bakku/easyalert/sms"
+	"github.com/bakku/easyalert/smtpd"
+	"github.com/bakku/easyalert/syslogd"
+	"github.com/bakku/easyalert/watchdog"
 	"github.com/bakku/easyalert/web"
 	_ "github.com/lib/pq"
 )
@@ -23,6 +31,18 @@ func main() {
 		return
 	}
 
+	smtpAddr := os.Getenv("SMTP_ADDR")
+	if smtpAddr == "" {
+		fmt.Println("no SMTP_ADDR env given")
+		return
+	}
+
+	smtpFrom := os.Getenv("SMTP_FROM")
+	if smtpFrom == "" {
+		fmt.Println("no SMTP_FROM env given")
+		return
+	}
+
 	db, err := sql.Open("postgres", dbConnStr)
 	if err != nil {
 		fmt.Println("error while connecting to database:", err)
@@ -37,7 +57,99 @@ func main() {
 
 	userRepo := postgres.UserRepository{db}
 	alertRepo := postgres.AlertRepository{db}
+	heartbeatRepo := postgres.HeartbeatRepository{db}
+	templateRepo := postgres.TemplateRepository{db}
+	integrationRepo := postgres.IntegrationRepository{db}
+	syslogSourceRepo := postgres.SyslogSourceRepository{db}
+	webhookEndpointRepo := postgres.WebhookEndpointRepository{db}
+	webhookDeliveryRepo := postgres.WebhookDeliveryRepository{db}
+	chatChannelRepo := postgres.ChatChannelRepository{db}
+	pushChannelRepo := postgres.PushChannelRepository{db}
+	matrixRoomRepo := postgres.MatrixRoomRepository{db}
+	routingRepo := postgres.RoutingRepository{db}
+	idempotencyRepo := postgres.IdempotencyKeyRepository{db}
+
+	smtpChannel := delivery.SMTPChannel{
+		Addr:     smtpAddr,
+		From:     smtpFrom,
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+	}
+
+	webhookChannel := delivery.WebhookChannel{EndpointRepo: webhookEndpointRepo, DeliveryRepo: webhookDeliveryRepo}
+	chatChannel := delivery.ChatChannel{ChannelRepo: chatChannelRepo}
+	pushChannel := delivery.PushChannel{ChannelRepo: pushChannelRepo}
+	matrixChannel := delivery.MatrixChannel{RoomRepo: matrixRoomRepo}
+
+	channels := []easyalert.Channel{smtpChannel, webhookChannel, chatChannel, pushChannel, matrixChannel}
+
+	alertLinks := sms.LinkSigner{Secret: []byte(os.Getenv("ALERT_LINK_SECRET"))}
+	if alertLinks.Enabled() && len(alertLinks.Secret) < 32 {
+		fmt.Println("ALERT_LINK_SECRET must be at least 32 characters long")
+		return
+	}
+
+	// the sender stays nil without a gateway, so that phone numbers can not
+	// be verified
+	var smsSender easyalert.SMSSender
+
+	if gatewayURL := os.Getenv("SMS_GATEWAY_URL"); gatewayURL != "" {
+		gateway, err := sms.ParseHTTPGateway(gatewayURL, os.Getenv("SMS_GATEWAY_BODY"))
+		if err != nil {
+			fmt.Println("invalid SMS_GATEWAY_URL or SMS_GATEWAY_BODY:", err)
+			return
+		}
+
+		gateway.Method = os.Getenv("SMS_GATEWAY_METHOD")
+		gateway.ContentType = os.Getenv("SMS_GATEWAY_CONTENT_TYPE")
+		gateway.Username = os.Getenv("SMS_GATEWAY_USERNAME")
+		gateway.Password = os.Getenv("SMS_GATEWAY_PASSWORD")
+		gateway.Authorization = os.Getenv("SMS_GATEWAY_AUTHORIZATION")
+		gateway.From = os.Getenv("SMS_FROM")
+
+		maxLength := sms.DefaultMaxLength
+
+		if s := os.Getenv("SMS_MAX_LENGTH"); s != "" {
+			maxLength, err = strconv.Atoi(s)
+			if err != nil || maxLength < 1 {
+				fmt.Println("invalid SMS_MAX_LENGTH env given")
+				return
+			}
+		}
+
+		smsSender = delivery.SMSSender{Gateway: gateway}
+		channels = append(channels, delivery.SMSChannel{Sender: smsSender, BaseURL: os.Getenv("PUBLIC_URL"), Links: alertLinks, MaxLength: maxLength})
+	}
+
+	notifier := delivery.Notifier{AlertRepo: alertRepo, RoutingRepo: routingRepo, Channels: channels}
+
+	stop := make(chan struct{})
+	defer close(stop)
+
+	dispatcher := dispatch.Dispatcher{UserRepo: userRepo, AlertRepo: alertRepo, Notifier: notifier, IdempotencyRepo: idempotencyRepo}
+	go dispatcher.Run(stop)
+
+	heartbeatWatchdog := watchdog.Watchdog{UserRepo: userRepo, HeartbeatRepo: heartbeatRepo, AlertRepo: alertRepo, Notifier: notifier}
+	go heartbeatWatchdog.Run(stop)
+
+	inboundAddr := os.Getenv("INBOUND_SMTP_ADDR")
+	inboundDomain := os.Getenv("INBOUND_SMTP_DOMAIN")
+
+	if inboundAddr != "" && inboundDomain == "" {
+		fmt.Println("no INBOUND_SMTP_DOMAIN env given")
+		return
+	}
+
+	if inboundAddr != "" {
+		inboundServer := smtpd.Server{Addr: inboundAddr, Domain: inboundDomain, UserRepo: userRepo, AlertRepo: alertRepo, Notifier: notifier}
+		go inboundServer.Run(stop)
+	}
+
+	if syslogAddr := os.Getenv("SYSLOG_ADDR"); syslogAddr != "" {
+		syslogServer := syslogd.Server{Addr: syslogAddr, SourceRepo: syslogSourceRepo, UserRepo: userRepo, AlertRepo: alertRepo, Notifier: notifier}
+		go syslogServer.Run(stop)
+	}
 
-	server := web.NewServer(port, userRepo, alertRepo)
+	server := web.NewServer(port, userRepo, alertRepo, heartbeatRepo, templateRepo, integrationRepo, syslogSourceRepo, webhookEndpointRepo, webhookDeliveryRepo, chatChannelRepo, pushChannelRepo, matrixRoomRepo, routingRepo, idempotencyRepo, notifier, smsSender, alertLinks)
 	server.Start()
 }
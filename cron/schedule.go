@@ -0,0 +1,297 @@
+// Package cron implements parsing of cron expressions and calculating the
+// times at which a cron schedule fires.
+//
+// Expressions consist of five fields: minute, hour, day of month, month and
+// day of week. Every field accepts *, single values, ranges (1-5), lists
+// (1,15) and steps (*/10, 1-30/2). Months and days of week can also be given
+// by their english three letter names (jan, mon). In addition the macros
+// @yearly, @annually, @monthly, @weekly, @daily, @midnight and @hourly are
+// supported.
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed cron expression.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+
+	// like in vixie cron a day matches if either day of month or day of
+	// week matches, unless one of them is unrestricted
+	domStar, dowStar bool
+}
+
+type bounds struct {
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteBounds = bounds{0, 59, nil}
+	hourBounds   = bounds{0, 23, nil}
+	domBounds    = bounds{1, 31, nil}
+	monthBounds  = bounds{1, 12, map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// 7 is accepted as sunday as well and folded into 0 after parsing
+	dowBounds = bounds{0, 7, map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse parses a cron expression.
+func Parse(expr string) (Schedule, error) {
+	expr = strings.TrimSpace(expr)
+
+	if strings.HasPrefix(expr, "@") {
+		expanded, ok := macros[strings.ToLower(expr)]
+		if !ok {
+			return Schedule{}, fmt.Errorf("unknown macro %s", expr)
+		}
+
+		expr = expanded
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return Schedule{}, fmt.Errorf("expected 5 fields, got %d", len(fields))
+	}
+
+	var (
+		s   Schedule
+		err error
+	)
+
+	if s.minute, err = parseField(fields[0], minuteBounds); err != nil {
+		return Schedule{}, fmt.Errorf("minute: %v", err)
+	}
+
+	if s.hour, err = parseField(fields[1], hourBounds); err != nil {
+		return Schedule{}, fmt.Errorf("hour: %v", err)
+	}
+
+	if s.dom, err = parseField(fields[2], domBounds); err != nil {
+		return Schedule{}, fmt.Errorf("day of month: %v", err)
+	}
+
+	if s.month, err = parseField(fields[3], monthBounds); err != nil {
+		return Schedule{}, fmt.Errorf("month: %v", err)
+	}
+
+	if s.dow, err = parseField(fields[4], dowBounds); err != nil {
+		return Schedule{}, fmt.Errorf("day of week: %v", err)
+	}
+
+	if s.dow&(1<<7) != 0 {
+		s.dow = s.dow&^(1<<7) | 1
+	}
+
+	s.domStar = strings.HasPrefix(fields[2], "*")
+	s.dowStar = strings.HasPrefix(fields[4], "*")
+
+	return s, nil
+}
+
+// parseField parses a comma separated list of ranges into a bit set.
+func parseField(field string, b bounds) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(field, ",") {
+		partBits, err := parseRange(part, b)
+		if err != nil {
+			return 0, err
+		}
+
+		bits |= partBits
+	}
+
+	return bits, nil
+}
+
+// parseRange parses *, a single value or a range, each with an optional step.
+func parseRange(part string, b bounds) (uint64, error) {
+	var (
+		start, end int
+		step       = 1
+		err        error
+	)
+
+	rangeAndStep := strings.SplitN(part, "/", 2)
+	if len(rangeAndStep) == 2 {
+		step, err = strconv.Atoi(rangeAndStep[1])
+		if err != nil || step <= 0 {
+			return 0, fmt.Errorf("invalid step in %q", part)
+		}
+	}
+
+	if rangeAndStep[0] == "*" {
+		start, end = b.min, b.max
+	} else {
+		startAndEnd := strings.SplitN(rangeAndStep[0], "-", 2)
+
+		start, err = parseValue(startAndEnd[0], b)
+		if err != nil {
+			return 0, err
+		}
+
+		switch {
+		case len(startAndEnd) == 2:
+			end, err = parseValue(startAndEnd[1], b)
+			if err != nil {
+				return 0, err
+			}
+		case len(rangeAndStep) == 2:
+			// 5/10 means every 10th value starting at 5
+			end = b.max
+		default:
+			end = start
+		}
+	}
+
+	if start > end {
+		return 0, fmt.Errorf("invalid range %q", part)
+	}
+
+	var bits uint64
+	for i := start; i <= end; i += step {
+		bits |= 1 << uint(i)
+	}
+
+	return bits, nil
+}
+
+func parseValue(value string, b bounds) (int, error) {
+	if i, ok := b.names[strings.ToLower(value)]; ok {
+		return i, nil
+	}
+
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", value)
+	}
+
+	if i < b.min || i > b.max {
+		return 0, fmt.Errorf("value %d out of range %d-%d", i, b.min, b.max)
+	}
+
+	return i, nil
+}
+
+// Next returns the first time after t at which the schedule fires. The
+// schedule is evaluated in the location of t. If the schedule never fires,
+// e.g. for 0 0 30 2 *, the zero time is returned.
+//
+// Times are stepped in absolute time, so daylight saving time is handled
+// like in vixie cron: when clocks are turned forward, the times skipped fire
+// right after the gap. When clocks are turned back, the repeated hour only
+// fires again if the schedule fires every hour, e.g. */15 * * * *, while
+// schedules for a specific hour, e.g. 30 2 * * *, fire once.
+func (s Schedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+
+	t = t.Truncate(time.Minute).Add(time.Minute)
+
+	// the rarest satisfiable schedule is the 29th of February which can
+	// be up to eight years apart, e.g. 2096 and 2104
+	limit := t.Year() + 8
+
+	for t.Year() <= limit {
+		if s.skippedBefore(t) {
+			return t
+		}
+
+		if !has(s.month, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+
+		if !has(s.hour, t.Hour()) {
+			t = t.Add(time.Duration(60-t.Minute()) * time.Minute)
+			continue
+		}
+
+		if !has(s.minute, t.Minute()) || s.hour != everyHour && repeated(t) {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+// everyHour is the hour bit set of schedules firing every hour.
+const everyHour = 1<<24 - 1
+
+// skippedBefore returns whether the schedule fires at a wall clock time
+// which was skipped because clocks were turned forward right before t.
+func (s Schedule) skippedBefore(t time.Time) bool {
+	_, offset := t.Zone()
+	_, before := t.Add(-time.Minute).Zone()
+
+	if offset <= before {
+		return false
+	}
+
+	// the wall clock is compared in UTC, which has no gaps
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, time.UTC)
+
+	for skipped := wall.Add(-time.Duration(offset-before) * time.Second); skipped.Before(wall); skipped = skipped.Add(time.Minute) {
+		if has(s.month, int(skipped.Month())) && s.dayMatches(skipped) && has(s.hour, skipped.Hour()) && has(s.minute, skipped.Minute()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// repeated returns whether the wall clock time of t already occurred up to
+// an hour earlier because clocks were turned back.
+func repeated(t time.Time) bool {
+	_, offset := t.Zone()
+	_, before := t.Add(-time.Hour).Zone()
+
+	if before <= offset {
+		return false
+	}
+
+	earlier := t.Add(-time.Duration(before-offset) * time.Second)
+
+	return earlier.Hour() == t.Hour() && earlier.Minute() == t.Minute()
+}
+
+func (s Schedule) dayMatches(t time.Time) bool {
+	domMatches := has(s.dom, t.Day())
+	dowMatches := has(s.dow, int(t.Weekday()))
+
+	if s.domStar || s.dowStar {
+		return domMatches && dowMatches
+	}
+
+	return domMatches || dowMatches
+}
+
+func has(bits uint64, i int) bool {
+	return bits&(1<<uint(i)) != 0
+}
@@ -0,0 +1,185 @@
+package cron_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bakku/easyalert/cron"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse_InvalidExpressions(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"* * * foo *",
+		"@every",
+	} {
+		_, err := cron.Parse(expr)
+		require.NotNil(t, err, expr)
+	}
+}
+
+func TestNext(t *testing.T) {
+	from := time.Date(2019, 1, 10, 10, 30, 0, 0, time.UTC) // a thursday
+
+	for expr, expected := range map[string]time.Time{
+		"* * * * *":        time.Date(2019, 1, 10, 10, 31, 0, 0, time.UTC),
+		"*/15 * * * *":     time.Date(2019, 1, 10, 10, 45, 0, 0, time.UTC),
+		"30 * * * *":       time.Date(2019, 1, 10, 11, 30, 0, 0, time.UTC),
+		"0 2 * * *":        time.Date(2019, 1, 11, 2, 0, 0, 0, time.UTC),
+		"0 9-17/4 * * *":   time.Date(2019, 1, 10, 13, 0, 0, 0, time.UTC),
+		"0 0 * * mon":      time.Date(2019, 1, 14, 0, 0, 0, 0, time.UTC),
+		"0 0 * * 7":        time.Date(2019, 1, 13, 0, 0, 0, 0, time.UTC),
+		"0 0 1 * *":        time.Date(2019, 2, 1, 0, 0, 0, 0, time.UTC),
+		"0 0 1,15 * *":     time.Date(2019, 1, 15, 0, 0, 0, 0, time.UTC),
+		"0 0 31 * *":       time.Date(2019, 1, 31, 0, 0, 0, 0, time.UTC),
+		"0 0 1 JUN *":      time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC),
+		"0 0 29 2 *":       time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC),
+		"0 0 13 * fri":     time.Date(2019, 1, 11, 0, 0, 0, 0, time.UTC),
+		"0 0 */10 * mon":   time.Date(2019, 1, 21, 0, 0, 0, 0, time.UTC),
+		"5/20 * * * *":     time.Date(2019, 1, 10, 10, 45, 0, 0, time.UTC),
+		"@hourly":          time.Date(2019, 1, 10, 11, 0, 0, 0, time.UTC),
+		"@daily":           time.Date(2019, 1, 11, 0, 0, 0, 0, time.UTC),
+		"@weekly":          time.Date(2019, 1, 13, 0, 0, 0, 0, time.UTC),
+		"@monthly":         time.Date(2019, 2, 1, 0, 0, 0, 0, time.UTC),
+		"@yearly":          time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		"  0 12 * * 1-5  ": time.Date(2019, 1, 10, 12, 0, 0, 0, time.UTC),
+	} {
+		schedule, err := cron.Parse(expr)
+		require.Nil(t, err, expr)
+
+		require.Equal(t, expected, schedule.Next(from), expr)
+	}
+}
+
+func TestNext_SkipsSeconds(t *testing.T) {
+	schedule, err := cron.Parse("* * * * *")
+	require.Nil(t, err)
+
+	from := time.Date(2019, 1, 10, 10, 30, 59, 999, time.UTC)
+
+	require.Equal(t, time.Date(2019, 1, 10, 10, 31, 0, 0, time.UTC), schedule.Next(from))
+}
+
+func TestNext_NeverFires(t *testing.T) {
+	schedule, err := cron.Parse("0 0 30 2 *")
+	require.Nil(t, err)
+
+	require.True(t, schedule.Next(time.Now()).IsZero())
+}
+
+func TestNext_TimeZone(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.Nil(t, err)
+
+	schedule, err := cron.Parse("0 2 * * *")
+	require.Nil(t, err)
+
+	from := time.Date(2019, 1, 10, 10, 30, 0, 0, time.UTC).In(berlin)
+	next := schedule.Next(from)
+
+	require.Equal(t, time.Date(2019, 1, 11, 2, 0, 0, 0, berlin), next)
+	require.Equal(t, time.Date(2019, 1, 11, 1, 0, 0, 0, time.UTC), next.UTC())
+}
+
+func TestNext_DaylightSavingTime(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.Nil(t, err)
+
+	schedule, err := cron.Parse("30 3 * * *")
+	require.Nil(t, err)
+
+	// clocks are moved from 02:00 to 03:00 on the 31st of March 2019
+	from := time.Date(2019, 3, 30, 12, 0, 0, 0, berlin)
+	next := schedule.Next(from)
+
+	require.Equal(t, time.Date(2019, 3, 31, 3, 30, 0, 0, berlin), next)
+	require.Equal(t, time.Date(2019, 3, 31, 1, 30, 0, 0, time.UTC), next.UTC())
+}
+
+func TestNext_DaylightSavingTimeGap(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.Nil(t, err)
+
+	// clocks are moved from 02:00 to 03:00 on the 31st of March 2019, so
+	// jobs between 02:00 and 03:00 fire right after the gap
+	afterGap := time.Date(2019, 3, 31, 1, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		expr     string
+		from     time.Time
+		expected time.Time
+	}{
+		{"30 2 * * *", time.Date(2019, 3, 30, 12, 0, 0, 0, berlin), afterGap},
+		{"30 2 * * *", afterGap, time.Date(2019, 4, 1, 0, 30, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2019, 3, 31, 1, 45, 0, 0, berlin), afterGap},
+		{"*/15 * * * *", afterGap, time.Date(2019, 3, 31, 1, 15, 0, 0, time.UTC)},
+		{"0 3 * * *", time.Date(2019, 3, 30, 12, 0, 0, 0, berlin), afterGap},
+	}
+
+	for _, test := range tests {
+		schedule, err := cron.Parse(test.expr)
+		require.Nil(t, err)
+
+		next := schedule.Next(test.from.In(berlin))
+		require.True(t, test.expected.Equal(next), "%s from %s: %s", test.expr, test.from, next)
+	}
+}
+
+func TestNext_DaylightSavingTimeRepeatedHour(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.Nil(t, err)
+
+	// clocks are moved from 03:00 back to 02:00 on the 27th of October
+	// 2019, so 02:00 to 03:00 is repeated from 01:00 UTC on
+	start := time.Date(2019, 10, 26, 23, 50, 0, 0, time.UTC).In(berlin)
+
+	tests := []struct {
+		expr     string
+		expected []time.Time
+	}{
+		{"*/15 * * * *", []time.Time{
+			time.Date(2019, 10, 27, 0, 0, 0, 0, time.UTC),
+			time.Date(2019, 10, 27, 0, 15, 0, 0, time.UTC),
+			time.Date(2019, 10, 27, 0, 30, 0, 0, time.UTC),
+			time.Date(2019, 10, 27, 0, 45, 0, 0, time.UTC),
+			time.Date(2019, 10, 27, 1, 0, 0, 0, time.UTC),
+			time.Date(2019, 10, 27, 1, 15, 0, 0, time.UTC),
+			time.Date(2019, 10, 27, 1, 30, 0, 0, time.UTC),
+			time.Date(2019, 10, 27, 1, 45, 0, 0, time.UTC),
+			time.Date(2019, 10, 27, 2, 0, 0, 0, time.UTC),
+		}},
+		{"30 2 * * *", []time.Time{
+			time.Date(2019, 10, 27, 0, 30, 0, 0, time.UTC),
+			time.Date(2019, 10, 28, 1, 30, 0, 0, time.UTC),
+		}},
+	}
+
+	for _, test := range tests {
+		schedule, err := cron.Parse(test.expr)
+		require.Nil(t, err)
+
+		next := start
+		for _, expected := range test.expected {
+			next = schedule.Next(next)
+			require.True(t, expected.Equal(next), "%s: expected %s, got %s", test.expr, expected, next.UTC())
+		}
+	}
+
+	// a restart within the repeated hour does not fire 30 2 * * * again
+	schedule, err := cron.Parse("30 2 * * *")
+	require.Nil(t, err)
+
+	next := schedule.Next(time.Date(2019, 10, 27, 1, 10, 0, 0, time.UTC).In(berlin))
+	require.True(t, time.Date(2019, 10, 28, 1, 30, 0, 0, time.UTC).Equal(next), next.UTC())
+}
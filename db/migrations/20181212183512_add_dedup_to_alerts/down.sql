@@ -0,0 +1,6 @@
+BEGIN;
+  ALTER TABLE alerts
+  DROP COLUMN dedup_key,
+  DROP COLUMN occurrences,
+  DROP COLUMN last_seen_at;
+COMMIT;
@@ -0,0 +1,10 @@
+BEGIN;
+  ALTER TABLE alerts
+  ADD COLUMN dedup_key TEXT DEFAULT NULL,
+  ADD COLUMN occurrences INTEGER NOT NULL DEFAULT 1,
+  ADD COLUMN last_seen_at TIMESTAMP NOT NULL DEFAULT NOW();
+
+  UPDATE alerts SET last_seen_at = created_at;
+
+  CREATE INDEX ON alerts (user_id, dedup_key);
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+  ALTER TABLE alerts
+  DROP COLUMN severity,
+  DROP COLUMN labels;
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+  ALTER TABLE alerts
+  ADD COLUMN severity smallint NOT NULL DEFAULT 0,
+  ADD COLUMN labels JSONB NOT NULL DEFAULT '{}';
+
+  CREATE INDEX ON alerts (user_id, severity);
+  CREATE INDEX ON alerts USING GIN (labels);
+COMMIT;
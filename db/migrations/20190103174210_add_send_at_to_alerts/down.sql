@@ -0,0 +1,4 @@
+BEGIN;
+  ALTER TABLE alerts
+  DROP COLUMN send_at;
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+  ALTER TABLE alerts
+  ADD COLUMN send_at TIMESTAMP DEFAULT NULL;
+
+  CREATE INDEX ON alerts (status, send_at);
+COMMIT;
@@ -0,0 +1,16 @@
+BEGIN;
+  CREATE TABLE heartbeats (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    interval_seconds INTEGER NOT NULL,
+    grace_seconds INTEGER NOT NULL DEFAULT 0,
+    status smallint NOT NULL,
+    last_ping_at TIMESTAMP DEFAULT NULL,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+  );
+
+  CREATE INDEX ON heartbeats (user_id);
+  CREATE INDEX ON heartbeats (status);
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+  ALTER TABLE heartbeats
+  DROP COLUMN schedule,
+  DROP COLUMN timezone;
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+  ALTER TABLE heartbeats
+  ADD COLUMN schedule TEXT DEFAULT NULL,
+  ADD COLUMN timezone TEXT NOT NULL DEFAULT 'UTC';
+COMMIT;
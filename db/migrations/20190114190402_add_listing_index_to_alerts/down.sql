@@ -0,0 +1,3 @@
+BEGIN;
+  DROP INDEX alerts_user_id_created_at_id_idx;
+COMMIT;
@@ -0,0 +1,3 @@
+BEGIN;
+  CREATE INDEX alerts_user_id_created_at_id_idx ON alerts (user_id, created_at DESC, id DESC);
+COMMIT;
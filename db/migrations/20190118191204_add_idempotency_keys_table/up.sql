@@ -0,0 +1,14 @@
+BEGIN;
+  CREATE TABLE idempotency_keys (
+    id BIGSERIAL PRIMARY KEY,
+    key TEXT NOT NULL,
+    request_hash TEXT NOT NULL,
+    status_code smallint NOT NULL,
+    location TEXT NOT NULL DEFAULT '',
+    body TEXT NOT NULL,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL
+  );
+
+  CREATE UNIQUE INDEX ON idempotency_keys (user_id, key);
+COMMIT;
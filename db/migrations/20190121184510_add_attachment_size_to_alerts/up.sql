@@ -0,0 +1,4 @@
+BEGIN;
+  ALTER TABLE alerts
+  ADD COLUMN attachment_size BIGINT NOT NULL DEFAULT 0;
+COMMIT;
@@ -0,0 +1,14 @@
+BEGIN;
+  CREATE TABLE templates (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    body TEXT NOT NULL,
+    format TEXT NOT NULL DEFAULT 'text',
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+  );
+
+  CREATE UNIQUE INDEX ON templates (user_id, name);
+COMMIT;
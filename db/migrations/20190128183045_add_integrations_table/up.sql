@@ -0,0 +1,16 @@
+BEGIN;
+  CREATE TABLE integrations (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    preset TEXT NOT NULL DEFAULT '',
+    subject_expr TEXT NOT NULL,
+    message_expr TEXT NOT NULL DEFAULT '',
+    severity_expr TEXT NOT NULL DEFAULT '',
+    dedup_key_expr TEXT NOT NULL DEFAULT '',
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+  );
+
+  CREATE UNIQUE INDEX ON integrations (user_id, name);
+COMMIT;
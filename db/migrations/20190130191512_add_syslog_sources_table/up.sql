@@ -0,0 +1,13 @@
+BEGIN;
+  CREATE TABLE syslog_sources (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    address TEXT NOT NULL UNIQUE,
+    rules JSONB NOT NULL DEFAULT '[]',
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+  );
+
+  CREATE UNIQUE INDEX ON syslog_sources (user_id, name);
+COMMIT;
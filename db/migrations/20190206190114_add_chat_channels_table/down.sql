@@ -0,0 +1,3 @@
+BEGIN;
+  DROP TABLE chat_channels;
+COMMIT;
@@ -0,0 +1,13 @@
+BEGIN;
+  CREATE TABLE chat_channels (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    url TEXT NOT NULL,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+  );
+
+  CREATE UNIQUE INDEX ON chat_channels (user_id, name);
+COMMIT;
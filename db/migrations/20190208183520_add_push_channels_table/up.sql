@@ -0,0 +1,15 @@
+BEGIN;
+  CREATE TABLE push_channels (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    server_url TEXT NOT NULL,
+    topic TEXT NOT NULL DEFAULT '',
+    token TEXT NOT NULL DEFAULT '',
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+  );
+
+  CREATE UNIQUE INDEX ON push_channels (user_id, name);
+COMMIT;
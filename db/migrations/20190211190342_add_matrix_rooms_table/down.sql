@@ -0,0 +1,3 @@
+BEGIN;
+  DROP TABLE matrix_rooms;
+COMMIT;
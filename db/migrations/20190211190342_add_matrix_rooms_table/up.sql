@@ -0,0 +1,14 @@
+BEGIN;
+  CREATE TABLE matrix_rooms (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    homeserver_url TEXT NOT NULL,
+    room_id TEXT NOT NULL,
+    access_token TEXT NOT NULL,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+  );
+
+  CREATE UNIQUE INDEX ON matrix_rooms (user_id, name);
+COMMIT;
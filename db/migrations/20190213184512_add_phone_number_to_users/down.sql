@@ -0,0 +1,8 @@
+BEGIN;
+  ALTER TABLE users
+  DROP COLUMN phone_number,
+  DROP COLUMN phone_verified,
+  DROP COLUMN phone_code_digest,
+  DROP COLUMN phone_code_expires_at,
+  DROP COLUMN phone_code_attempts;
+COMMIT;
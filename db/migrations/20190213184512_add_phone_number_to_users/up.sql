@@ -0,0 +1,8 @@
+BEGIN;
+  ALTER TABLE users
+  ADD COLUMN phone_number TEXT NOT NULL DEFAULT '',
+  ADD COLUMN phone_verified BOOLEAN NOT NULL DEFAULT false,
+  ADD COLUMN phone_code_digest TEXT NOT NULL DEFAULT '',
+  ADD COLUMN phone_code_expires_at TIMESTAMP DEFAULT NULL,
+  ADD COLUMN phone_code_attempts INTEGER NOT NULL DEFAULT 0;
+COMMIT;
@@ -0,0 +1,10 @@
+BEGIN;
+  CREATE TABLE routings (
+    id BIGSERIAL PRIMARY KEY,
+    rules JSONB NOT NULL DEFAULT '[]',
+    default_channels JSONB NOT NULL DEFAULT '[]',
+    user_id BIGINT NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+  );
+COMMIT;
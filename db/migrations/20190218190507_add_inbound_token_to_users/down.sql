@@ -0,0 +1,4 @@
+BEGIN;
+  ALTER TABLE users
+  DROP COLUMN inbound_token;
+COMMIT;
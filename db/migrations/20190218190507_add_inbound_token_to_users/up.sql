@@ -0,0 +1,4 @@
+BEGIN;
+  ALTER TABLE users
+  ADD COLUMN inbound_token TEXT UNIQUE;
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+  ALTER TABLE users
+  DROP COLUMN phone_codes_sent,
+  DROP COLUMN phone_codes_sent_since;
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+  ALTER TABLE users
+  ADD COLUMN phone_codes_sent INTEGER NOT NULL DEFAULT 0,
+  ADD COLUMN phone_codes_sent_since TIMESTAMP DEFAULT NULL;
+COMMIT;
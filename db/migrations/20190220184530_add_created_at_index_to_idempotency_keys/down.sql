@@ -0,0 +1,3 @@
+BEGIN;
+  DROP INDEX idempotency_keys_created_at_idx;
+COMMIT;
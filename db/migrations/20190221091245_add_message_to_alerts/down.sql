@@ -0,0 +1,3 @@
+BEGIN;
+  ALTER TABLE alerts DROP COLUMN message, DROP COLUMN format;
+COMMIT;
@@ -0,0 +1,3 @@
+BEGIN;
+  ALTER TABLE alerts ADD COLUMN message TEXT NOT NULL DEFAULT '', ADD COLUMN format TEXT NOT NULL DEFAULT '';
+COMMIT;
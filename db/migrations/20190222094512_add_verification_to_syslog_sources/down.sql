@@ -0,0 +1,6 @@
+BEGIN;
+  DELETE FROM syslog_sources WHERE NOT verified;
+  DROP INDEX syslog_sources_address_idx;
+  ALTER TABLE syslog_sources ADD CONSTRAINT syslog_sources_address_key UNIQUE (address);
+  ALTER TABLE syslog_sources DROP COLUMN verified, DROP COLUMN verification_token;
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+  ALTER TABLE syslog_sources ADD COLUMN verified BOOLEAN NOT NULL DEFAULT false, ADD COLUMN verification_token TEXT NOT NULL DEFAULT '';
+  UPDATE syslog_sources SET verified = true;
+  ALTER TABLE syslog_sources DROP CONSTRAINT syslog_sources_address_key;
+  CREATE UNIQUE INDEX ON syslog_sources (address) WHERE verified;
+COMMIT;
@@ -6,11 +6,147 @@ CREATE TABLE alerts (
   status smallint NOT NULL,
   sent_at TIMESTAMP DEFAULT NULL,
   user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  dedup_key TEXT DEFAULT NULL,
+  occurrences INTEGER NOT NULL DEFAULT 1,
+  last_seen_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  severity smallint NOT NULL DEFAULT 0,
+  labels JSONB NOT NULL DEFAULT '{}',
+  send_at TIMESTAMP DEFAULT NULL,
+  attachment_size BIGINT NOT NULL DEFAULT 0,
+  message TEXT NOT NULL DEFAULT '',
+  format TEXT NOT NULL DEFAULT '',
   created_at TIMESTAMP NOT NULL,
   updated_at TIMESTAMP NOT NULL
 );
 
 CREATE INDEX ON alerts (user_id);
+CREATE INDEX ON alerts (user_id, dedup_key);
+CREATE INDEX ON alerts (user_id, severity);
+CREATE INDEX ON alerts USING GIN (labels);
+CREATE INDEX ON alerts (status, send_at);
+CREATE INDEX alerts_user_id_created_at_id_idx ON alerts (user_id, created_at DESC, id DESC);
+
+CREATE TABLE chat_channels (
+  id BIGSERIAL PRIMARY KEY,
+  name TEXT NOT NULL,
+  kind TEXT NOT NULL,
+  url TEXT NOT NULL,
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL
+);
+
+CREATE UNIQUE INDEX ON chat_channels (user_id, name);
+
+CREATE TABLE heartbeats (
+  id BIGSERIAL PRIMARY KEY,
+  name TEXT NOT NULL,
+  interval_seconds INTEGER NOT NULL,
+  schedule TEXT DEFAULT NULL,
+  timezone TEXT NOT NULL DEFAULT 'UTC',
+  grace_seconds INTEGER NOT NULL DEFAULT 0,
+  status smallint NOT NULL,
+  last_ping_at TIMESTAMP DEFAULT NULL,
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX ON heartbeats (user_id);
+CREATE INDEX ON heartbeats (status);
+
+CREATE TABLE idempotency_keys (
+  id BIGSERIAL PRIMARY KEY,
+  key TEXT NOT NULL,
+  request_hash TEXT NOT NULL,
+  status_code smallint NOT NULL,
+  location TEXT NOT NULL DEFAULT '',
+  body TEXT NOT NULL,
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  created_at TIMESTAMP NOT NULL
+);
+
+CREATE UNIQUE INDEX ON idempotency_keys (user_id, key);
+CREATE INDEX idempotency_keys_created_at_idx ON idempotency_keys (created_at);
+
+CREATE TABLE integrations (
+  id BIGSERIAL PRIMARY KEY,
+  name TEXT NOT NULL,
+  preset TEXT NOT NULL DEFAULT '',
+  subject_expr TEXT NOT NULL,
+  message_expr TEXT NOT NULL DEFAULT '',
+  severity_expr TEXT NOT NULL DEFAULT '',
+  dedup_key_expr TEXT NOT NULL DEFAULT '',
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL
+);
+
+CREATE UNIQUE INDEX ON integrations (user_id, name);
+
+CREATE TABLE matrix_rooms (
+  id BIGSERIAL PRIMARY KEY,
+  name TEXT NOT NULL,
+  homeserver_url TEXT NOT NULL,
+  room_id TEXT NOT NULL,
+  access_token TEXT NOT NULL,
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL
+);
+
+CREATE UNIQUE INDEX ON matrix_rooms (user_id, name);
+
+CREATE TABLE push_channels (
+  id BIGSERIAL PRIMARY KEY,
+  name TEXT NOT NULL,
+  kind TEXT NOT NULL,
+  server_url TEXT NOT NULL,
+  topic TEXT NOT NULL DEFAULT '',
+  token TEXT NOT NULL DEFAULT '',
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL
+);
+
+CREATE UNIQUE INDEX ON push_channels (user_id, name);
+
+CREATE TABLE routings (
+  id BIGSERIAL PRIMARY KEY,
+  rules JSONB NOT NULL DEFAULT '[]',
+  default_channels JSONB NOT NULL DEFAULT '[]',
+  user_id BIGINT NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE syslog_sources (
+  id BIGSERIAL PRIMARY KEY,
+  name TEXT NOT NULL,
+  address TEXT NOT NULL,
+  rules JSONB NOT NULL DEFAULT '[]',
+  verified BOOLEAN NOT NULL DEFAULT false,
+  verification_token TEXT NOT NULL DEFAULT '',
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL
+);
+
+CREATE UNIQUE INDEX ON syslog_sources (user_id, name);
+CREATE UNIQUE INDEX ON syslog_sources (address) WHERE verified;
+
+CREATE TABLE templates (
+  id BIGSERIAL PRIMARY KEY,
+  name TEXT NOT NULL,
+  subject TEXT NOT NULL,
+  body TEXT NOT NULL,
+  format TEXT NOT NULL DEFAULT 'text',
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL
+);
+
+CREATE UNIQUE INDEX ON templates (user_id, name);
 
 CREATE TABLE users (
     id BIGSERIAL PRIMARY KEY,
@@ -18,9 +154,46 @@ CREATE TABLE users (
     password_digest TEXT NOT NULL,
     token TEXT NOT NULL UNIQUE,
     created_at TIMESTAMP NOT NULL,
-    updated_at TIMESTAMP NOT NULL
+    updated_at TIMESTAMP NOT NULL,
+    phone_number TEXT NOT NULL DEFAULT '',
+    phone_verified BOOLEAN NOT NULL DEFAULT false,
+    phone_code_digest TEXT NOT NULL DEFAULT '',
+    phone_code_expires_at TIMESTAMP DEFAULT NULL,
+    phone_code_attempts INTEGER NOT NULL DEFAULT 0,
+    inbound_token TEXT UNIQUE,
+    phone_codes_sent INTEGER NOT NULL DEFAULT 0,
+    phone_codes_sent_since TIMESTAMP DEFAULT NULL
+);
+
+CREATE TABLE webhook_deliveries (
+  id BIGSERIAL PRIMARY KEY,
+  endpoint_id BIGINT NOT NULL REFERENCES webhook_endpoints(id) ON DELETE CASCADE,
+  alert_id BIGINT NOT NULL REFERENCES alerts(id) ON DELETE CASCADE,
+  attempt INTEGER NOT NULL,
+  status_code INTEGER NOT NULL DEFAULT 0,
+  latency_ms INTEGER NOT NULL,
+  response TEXT NOT NULL DEFAULT '',
+  error TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMP NOT NULL
 );
 
+CREATE INDEX ON webhook_deliveries (endpoint_id, created_at DESC);
+
+CREATE TABLE webhook_endpoints (
+  id BIGSERIAL PRIMARY KEY,
+  name TEXT NOT NULL,
+  url TEXT NOT NULL,
+  secret TEXT NOT NULL,
+  timeout_seconds INTEGER NOT NULL,
+  max_retries INTEGER NOT NULL,
+  retry_backoff_seconds INTEGER NOT NULL,
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL
+);
+
+CREATE UNIQUE INDEX ON webhook_endpoints (user_id, name);
+
 CREATE TABLE schema_migrations (
 	migration CHAR(14)
 ) ;
@@ -28,3 +201,25 @@ CREATE TABLE schema_migrations (
 INSERT INTO schema_migrations VALUES ("20180611170754") ;
 INSERT INTO schema_migrations VALUES ("20181127180911") ;
 INSERT INTO schema_migrations VALUES ("20181204181116") ;
+INSERT INTO schema_migrations VALUES ("20181212183512") ;
+INSERT INTO schema_migrations VALUES ("20181218192045") ;
+INSERT INTO schema_migrations VALUES ("20190103174210") ;
+INSERT INTO schema_migrations VALUES ("20190107191530") ;
+INSERT INTO schema_migrations VALUES ("20190110203318") ;
+INSERT INTO schema_migrations VALUES ("20190114190402") ;
+INSERT INTO schema_migrations VALUES ("20190118191204") ;
+INSERT INTO schema_migrations VALUES ("20190121184510") ;
+INSERT INTO schema_migrations VALUES ("20190124190230") ;
+INSERT INTO schema_migrations VALUES ("20190128183045") ;
+INSERT INTO schema_migrations VALUES ("20190130191512") ;
+INSERT INTO schema_migrations VALUES ("20190204184233") ;
+INSERT INTO schema_migrations VALUES ("20190206190114") ;
+INSERT INTO schema_migrations VALUES ("20190208183520") ;
+INSERT INTO schema_migrations VALUES ("20190211190342") ;
+INSERT INTO schema_migrations VALUES ("20190213184512") ;
+INSERT INTO schema_migrations VALUES ("20190215191024") ;
+INSERT INTO schema_migrations VALUES ("20190218190507") ;
+INSERT INTO schema_migrations VALUES ("20190219183012") ;
+INSERT INTO schema_migrations VALUES ("20190220184530") ;
+INSERT INTO schema_migrations VALUES ("20190221091245") ;
+INSERT INTO schema_migrations VALUES ("20190222094512") ;
\ No newline at end of file
@@ -0,0 +1,78 @@
+package delivery
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/chat"
+)
+
+// ChatChannel posts notifications to the incoming webhooks of all chat
+// channels of the user, e.g. Slack or Microsoft Teams.
+type ChatChannel struct {
+	ChannelRepo easyalert.ChatChannelRepository
+
+	// Client posts to the webhooks of the channels, see clientOrDefault.
+	Client *http.Client
+}
+
+// Deliver posts the notification to every chat channel of the user which
+// is selected by the notification. It succeeds if at least one post
+// succeeded, otherwise the last error is returned. Users without selected
+// chat channels are skipped with ErrNotConfigured.
+func (c ChatChannel) Deliver(user easyalert.User, n easyalert.Notification) error {
+	channels, err := c.ChannelRepo.FindChatChannels("WHERE user_id = $1 ORDER BY id", user.ID)
+	if err != nil {
+		return err
+	}
+
+	var selected []easyalert.ChatChannel
+
+	for _, channel := range channels {
+		if n.Selection.Selects(easyalert.ChannelKindChat, channel.Name) {
+			selected = append(selected, channel)
+		}
+	}
+
+	if len(selected) == 0 {
+		return ErrNotConfigured
+	}
+
+	var deliverErr error
+
+	delivered := false
+
+	for _, channel := range selected {
+		err := c.post(channel, n)
+		if err != nil {
+			deliverErr = fmt.Errorf("%s channel %q: %v", channel.Kind, channel.Name, err)
+			continue
+		}
+
+		delivered = true
+	}
+
+	if !delivered {
+		return deliverErr
+	}
+
+	return nil
+}
+
+func (c ChatChannel) post(channel easyalert.ChatChannel, n easyalert.Notification) error {
+	body, err := chat.Render(channel.Kind, n)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", channel.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+
+	return send(c.Client, req)
+}
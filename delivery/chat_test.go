@@ -0,0 +1,227 @@
+package delivery_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/delivery"
+	"github.com/bakku/easyalert/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+var chatNotification = easyalert.Notification{
+	Alert: easyalert.Alert{
+		ID:        5,
+		Subject:   "Backup failed",
+		Severity:  easyalert.AlertSeverityCritical,
+		Labels:    map[string]string{"host": "db1", "env": "prod"},
+		CreatedAt: time.Date(2019, 2, 6, 19, 0, 0, 0, time.UTC),
+	},
+	Message: "Disk <full>",
+}
+
+func TestChatChannel_ShouldPostPlatformPayloads(t *testing.T) {
+	tests := []struct {
+		kind     string
+		expected string
+	}{
+		{
+			easyalert.ChatKindSlack,
+			`{
+				"text": "Backup failed",
+				"attachments": [{
+					"color": "#d32f2f",
+					"blocks": [
+						{"type": "header", "text": {"type": "plain_text", "text": "Backup failed"}},
+						{"type": "section", "text": {"type": "mrkdwn", "text": "Disk &lt;full&gt;"}},
+						{"type": "context", "elements": [
+							{"type": "mrkdwn", "text": "*severity:* critical"},
+							{"type": "mrkdwn", "text": "*env:* prod"},
+							{"type": "mrkdwn", "text": "*host:* db1"}
+						]}
+					]
+				}]
+			}`,
+		},
+		{
+			easyalert.ChatKindDiscord,
+			`{
+				"embeds": [{
+					"title": "Backup failed",
+					"description": "Disk <full>",
+					"color": 13840175,
+					"fields": [
+						{"name": "env", "value": "prod", "inline": true},
+						{"name": "host", "value": "db1", "inline": true}
+					],
+					"footer": {"text": "easyalert · critical"},
+					"timestamp": "2019-02-06T19:00:00Z"
+				}]
+			}`,
+		},
+		{
+			easyalert.ChatKindMattermost,
+			`{
+				"attachments": [{
+					"fallback": "Backup failed",
+					"color": "#d32f2f",
+					"title": "Backup failed",
+					"text": "Disk <full>",
+					"fields": [
+						{"short": true, "title": "severity", "value": "critical"},
+						{"short": true, "title": "env", "value": "prod"},
+						{"short": true, "title": "host", "value": "db1"}
+					]
+				}]
+			}`,
+		},
+		{
+			easyalert.ChatKindTeams,
+			`{
+				"type": "message",
+				"attachments": [{
+					"contentType": "application/vnd.microsoft.card.adaptive",
+					"content": {
+						"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+						"type": "AdaptiveCard",
+						"version": "1.4",
+						"body": [
+							{"type": "TextBlock", "text": "Backup failed", "weight": "Bolder", "size": "Medium", "color": "Attention", "wrap": true},
+							{"type": "TextBlock", "text": "Disk <full>", "wrap": true},
+							{"type": "FactSet", "facts": [
+								{"title": "severity", "value": "critical"},
+								{"title": "env", "value": "prod"},
+								{"title": "host", "value": "db1"}
+							]}
+						]
+					}
+				}]
+			}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.kind, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			var body []byte
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, "POST", r.Method)
+				require.Equal(t, "application/json; charset=UTF-8", r.Header.Get("Content-Type"))
+
+				var err error
+				body, err = ioutil.ReadAll(r.Body)
+				require.Nil(t, err)
+			}))
+			defer server.Close()
+
+			channelRepo := mocks.NewMockChatChannelRepository(mockCtrl)
+			channelRepo.EXPECT().FindChatChannels("WHERE user_id = $1 ORDER BY id", uint(1)).
+				Return([]easyalert.ChatChannel{{ID: 2, Name: "ops", Kind: test.kind, URL: server.URL + "/hook"}}, nil)
+
+			c := delivery.ChatChannel{ChannelRepo: channelRepo, Client: http.DefaultClient}
+
+			err := c.Deliver(easyalert.User{ID: 1}, chatNotification)
+			require.Nil(t, err)
+
+			require.JSONEq(t, test.expected, string(body))
+		})
+	}
+}
+
+func TestChatChannel_ShouldSucceedIfOneChannelSucceeds(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer failing.Close()
+
+	working := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer working.Close()
+
+	channelRepo := mocks.NewMockChatChannelRepository(mockCtrl)
+	channelRepo.EXPECT().FindChatChannels(gomock.Any(), gomock.Any()).Return([]easyalert.ChatChannel{
+		{Name: "old", Kind: easyalert.ChatKindDiscord, URL: failing.URL},
+		{Name: "ops", Kind: easyalert.ChatKindSlack, URL: working.URL},
+	}, nil)
+
+	c := delivery.ChatChannel{ChannelRepo: channelRepo, Client: http.DefaultClient}
+
+	err := c.Deliver(easyalert.User{ID: 1}, chatNotification)
+	require.Nil(t, err)
+}
+
+func TestChatChannel_ShouldNotLeakWebhookURLInErrors(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	channelRepo := mocks.NewMockChatChannelRepository(mockCtrl)
+	channelRepo.EXPECT().FindChatChannels(gomock.Any(), gomock.Any()).Return([]easyalert.ChatChannel{
+		{Name: "ops", Kind: easyalert.ChatKindSlack, URL: server.URL + "/services/T0/B0/secret"},
+	}, nil)
+
+	c := delivery.ChatChannel{ChannelRepo: channelRepo, Client: http.DefaultClient}
+
+	err := c.Deliver(easyalert.User{ID: 1}, chatNotification)
+	require.NotNil(t, err)
+	require.True(t, strings.HasPrefix(err.Error(), `slack channel "ops": `))
+	require.NotContains(t, err.Error(), "secret")
+}
+
+func TestChatChannel_ShouldSkipUsersWithoutChannels(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	channelRepo := mocks.NewMockChatChannelRepository(mockCtrl)
+	channelRepo.EXPECT().FindChatChannels(gomock.Any(), gomock.Any()).Return(nil, nil)
+
+	c := delivery.ChatChannel{ChannelRepo: channelRepo, Client: http.DefaultClient}
+
+	err := c.Deliver(easyalert.User{ID: 1}, chatNotification)
+	require.Equal(t, delivery.ErrNotConfigured, err)
+}
+
+func TestChatChannel_ShouldOnlyPostToSelectedChannels(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	var posted []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posted = append(posted, r.URL.Path)
+	}))
+	defer server.Close()
+
+	channelRepo := mocks.NewMockChatChannelRepository(mockCtrl)
+	channelRepo.EXPECT().FindChatChannels(gomock.Any(), gomock.Any()).Return([]easyalert.ChatChannel{
+		{Name: "dev", Kind: easyalert.ChatKindSlack, URL: server.URL + "/dev"},
+		{Name: "ops", Kind: easyalert.ChatKindSlack, URL: server.URL + "/ops"},
+	}, nil).Times(2)
+
+	c := delivery.ChatChannel{ChannelRepo: channelRepo, Client: http.DefaultClient}
+
+	n := chatNotification
+	n.Selection = easyalert.Selection{"email", "chat:ops"}
+
+	err := c.Deliver(easyalert.User{ID: 1}, n)
+	require.Nil(t, err)
+	require.Equal(t, []string{"/ops"}, posted)
+
+	n.Selection = easyalert.Selection{"email"}
+
+	err = c.Deliver(easyalert.User{ID: 1}, n)
+	require.Equal(t, delivery.ErrNotConfigured, err)
+}
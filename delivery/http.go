@@ -0,0 +1,51 @@
+package delivery
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/bakku/easyalert/netguard"
+)
+
+// defaultHTTPTimeout limits a single request of channels which post to
+// chat tools or push servers.
+const defaultHTTPTimeout = 10 * time.Second
+
+// clientOrDefault returns the client of a channel. Without one, requests
+// time out after defaultHTTPTimeout, may only reach public addresses, as the
+// URLs were given by the user, and do not follow redirects.
+func clientOrDefault(client *http.Client) *http.Client {
+	if client == nil {
+		return netguard.NewClient(defaultHTTPTimeout)
+	}
+
+	return client
+}
+
+// send sends the request with clientOrDefault and expects a 2xx response.
+// Network errors are returned without the URL, which often holds
+// credentials like the token of a chat webhook.
+func send(client *http.Client, req *http.Request) error {
+	res, err := clientOrDefault(client).Do(req)
+	if err != nil {
+		if e, ok := err.(*url.Error); ok {
+			return e.Err
+		}
+
+		return err
+	}
+	defer res.Body.Close()
+
+	// drain the body so that the connection can be reused
+	io.Copy(ioutil.Discard, io.LimitReader(res.Body, maxLoggedResponseSize))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+
+	return nil
+}
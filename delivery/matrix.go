@@ -0,0 +1,186 @@
+package delivery
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/chat"
+	"github.com/bakku/easyalert/random"
+)
+
+// Retry policy of Matrix requests. Retries are safe as every message keeps
+// its transaction ID, which the homeserver uses to drop duplicates.
+const (
+	maxMatrixRetries    = 3
+	matrixRetryBackoff  = time.Second
+	maxMatrixRetryAfter = 30 * time.Second
+)
+
+// matrixError is the error response of the client-server API.
+type matrixError struct {
+	ErrCode      string `json:"errcode"`
+	Error        string `json:"error"`
+	RetryAfterMS int64  `json:"retry_after_ms"`
+}
+
+// MatrixChannel sends notifications to all Matrix rooms of the user via the
+// client-server API.
+type MatrixChannel struct {
+	RoomRepo easyalert.MatrixRoomRepository
+
+	// Client sends the messages to the homeservers, see clientOrDefault.
+	Client *http.Client
+	// Sleep waits between retries, defaults to time.Sleep.
+	Sleep func(d time.Duration)
+}
+
+// Deliver sends the notification to every room of the user which is
+// selected by the notification. It succeeds if at least one room received
+// the message, otherwise the last error is returned. Users without selected
+// rooms are skipped with ErrNotConfigured.
+func (c MatrixChannel) Deliver(user easyalert.User, n easyalert.Notification) error {
+	rooms, err := c.RoomRepo.FindMatrixRooms("WHERE user_id = $1 ORDER BY id", user.ID)
+	if err != nil {
+		return err
+	}
+
+	var selected []easyalert.MatrixRoom
+
+	for _, room := range rooms {
+		if n.Selection.Selects(easyalert.ChannelKindMatrix, room.Name) {
+			selected = append(selected, room)
+		}
+	}
+
+	if len(selected) == 0 {
+		return ErrNotConfigured
+	}
+
+	body, err := json.Marshal(chat.NewMatrixMessage(n))
+	if err != nil {
+		return err
+	}
+
+	var deliverErr error
+
+	delivered := false
+
+	for _, room := range selected {
+		err := c.send(room, n.Alert.ID, body)
+		if err != nil {
+			deliverErr = fmt.Errorf("matrix room %q: %v", room.Name, err)
+			continue
+		}
+
+		delivered = true
+	}
+
+	if !delivered {
+		return deliverErr
+	}
+
+	return nil
+}
+
+// send puts the message into the room. Rate limited requests are retried
+// after the time given by the homeserver, network errors and 5xx responses
+// after a backoff which doubles on every retry.
+func (c MatrixChannel) send(room easyalert.MatrixRoom, alertID uint, body []byte) error {
+	sleep := c.Sleep
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+
+	client := clientOrDefault(c.Client)
+
+	nonce, err := random.String(16)
+	if err != nil {
+		return err
+	}
+
+	txnID := fmt.Sprintf("easyalert.%d.%s", alertID, nonce)
+	endpoint := strings.TrimRight(room.HomeserverURL, "/") + "/_matrix/client/v3/rooms/" +
+		url.PathEscape(room.RoomID) + "/send/m.room.message/" + url.PathEscape(txnID)
+
+	backoff := matrixRetryBackoff
+
+	for attempt := 0; ; attempt++ {
+		wait, err := c.attempt(client, endpoint, room.AccessToken, body)
+		if err == nil {
+			return nil
+		}
+
+		if wait == 0 || attempt == maxMatrixRetries {
+			return err
+		}
+
+		if wait < 0 {
+			wait = backoff
+			backoff *= 2
+		}
+
+		if wait > maxMatrixRetryAfter {
+			return err
+		}
+
+		sleep(wait)
+	}
+}
+
+// attempt sends a single request. If it failed, the returned duration is
+// the time to wait before a retry, negative to use the backoff and zero if
+// the request must not be retried.
+func (c MatrixChannel) attempt(client *http.Client, endpoint, token string, body []byte) (time.Duration, error) {
+	req, err := http.NewRequest("PUT", endpoint, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+
+	res, err := client.Do(req)
+	if err != nil {
+		// the URL is left out as it contains the room
+		if e, ok := err.(*url.Error); ok {
+			return -1, e.Err
+		}
+
+		return -1, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 200 && res.StatusCode <= 299 {
+		io.Copy(ioutil.Discard, io.LimitReader(res.Body, maxLoggedResponseSize))
+		return 0, nil
+	}
+
+	var matrixErr matrixError
+	json.NewDecoder(io.LimitReader(res.Body, maxLoggedResponseSize)).Decode(&matrixErr)
+
+	err = fmt.Errorf("unexpected status %d", res.StatusCode)
+	if matrixErr.ErrCode != "" {
+		err = fmt.Errorf("%s: %s", matrixErr.ErrCode, matrixErr.Error)
+	}
+
+	switch {
+	case matrixErr.ErrCode == "M_LIMIT_EXCEEDED" || res.StatusCode == http.StatusTooManyRequests:
+		if matrixErr.RetryAfterMS > 0 {
+			return time.Duration(matrixErr.RetryAfterMS) * time.Millisecond, err
+		}
+
+		return -1, err
+	case res.StatusCode >= 500:
+		return -1, err
+	default:
+		return 0, err
+	}
+}
@@ -0,0 +1,164 @@
+package delivery_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/delivery"
+	"github.com/bakku/easyalert/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeHomeserver answers the requests with the given responses in order
+// and records the paths and bodies it received.
+type fakeHomeserver struct {
+	*httptest.Server
+	paths  []string
+	bodies []string
+}
+
+func newFakeHomeserver(t *testing.T, responses ...func(w http.ResponseWriter)) *fakeHomeserver {
+	s := &fakeHomeserver{}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "PUT", r.Method)
+		require.Equal(t, "Bearer syt_secret", r.Header.Get("Authorization"))
+
+		body, err := ioutil.ReadAll(r.Body)
+		require.Nil(t, err)
+
+		s.paths = append(s.paths, r.URL.Path)
+		s.bodies = append(s.bodies, string(body))
+
+		responses[len(s.paths)-1](w)
+	}))
+
+	return s
+}
+
+func matrixResponse(status int, body string) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}
+}
+
+func newMatrixChannel(mockCtrl *gomock.Controller, server *fakeHomeserver, sleeps *[]time.Duration) delivery.MatrixChannel {
+	roomRepo := mocks.NewMockMatrixRoomRepository(mockCtrl)
+	roomRepo.EXPECT().FindMatrixRooms("WHERE user_id = $1 ORDER BY id", uint(1)).Return([]easyalert.MatrixRoom{
+		{Name: "ops", HomeserverURL: server.URL + "/", RoomID: "!abc:example.org", AccessToken: "syt_secret"},
+	}, nil)
+
+	return delivery.MatrixChannel{
+		RoomRepo: roomRepo,
+		Client:   http.DefaultClient,
+		Sleep:    func(d time.Duration) { *sleeps = append(*sleeps, d) },
+	}
+}
+
+var matrixNotification = easyalert.Notification{
+	Alert: easyalert.Alert{
+		ID:       5,
+		Subject:  "Backup <db1> failed",
+		Severity: easyalert.AlertSeverityCritical,
+		Labels:   map[string]string{"host": "db1"},
+	},
+	Message: "Disk full\nOnly 2% left",
+}
+
+func TestMatrixChannel_ShouldSendHTMLMessage(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	server := newFakeHomeserver(t, matrixResponse(http.StatusOK, `{"event_id": "$1"}`))
+	defer server.Close()
+
+	var sleeps []time.Duration
+
+	err := newMatrixChannel(mockCtrl, server, &sleeps).Deliver(easyalert.User{ID: 1}, matrixNotification)
+	require.Nil(t, err)
+
+	require.Len(t, server.paths, 1)
+	require.True(t, strings.HasPrefix(server.paths[0], "/_matrix/client/v3/rooms/!abc:example.org/send/m.room.message/easyalert.5."))
+
+	require.JSONEq(t, `{
+		"msgtype": "m.text",
+		"body": "[critical] Backup <db1> failed\n\nDisk full\nOnly 2% left\n\nhost=db1",
+		"format": "org.matrix.custom.html",
+		"formatted_body": "<p><strong><span data-mx-color=\"#d32f2f\">[critical]</span> Backup &lt;db1&gt; failed</strong></p><p>Disk full<br>Only 2% left</p><p><code>host=db1</code></p>"
+	}`, server.bodies[0])
+}
+
+func TestMatrixChannel_ShouldRetryRateLimitedRequestsWithSameTransactionID(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	server := newFakeHomeserver(t,
+		matrixResponse(http.StatusTooManyRequests, `{"errcode": "M_LIMIT_EXCEEDED", "error": "Too many requests", "retry_after_ms": 1500}`),
+		matrixResponse(http.StatusBadGateway, ``),
+		matrixResponse(http.StatusOK, `{"event_id": "$1"}`),
+	)
+	defer server.Close()
+
+	var sleeps []time.Duration
+
+	err := newMatrixChannel(mockCtrl, server, &sleeps).Deliver(easyalert.User{ID: 1}, matrixNotification)
+	require.Nil(t, err)
+
+	require.Len(t, server.paths, 3)
+	require.Equal(t, server.paths[0], server.paths[1])
+	require.Equal(t, server.paths[0], server.paths[2])
+	require.Equal(t, []time.Duration{1500 * time.Millisecond, time.Second}, sleeps)
+}
+
+func TestMatrixChannel_ShouldNotRetryForbiddenRequests(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	server := newFakeHomeserver(t,
+		matrixResponse(http.StatusForbidden, `{"errcode": "M_FORBIDDEN", "error": "User is not in the room"}`),
+	)
+	defer server.Close()
+
+	var sleeps []time.Duration
+
+	err := newMatrixChannel(mockCtrl, server, &sleeps).Deliver(easyalert.User{ID: 1}, matrixNotification)
+	require.EqualError(t, err, `matrix room "ops": M_FORBIDDEN: User is not in the room`)
+	require.Empty(t, sleeps)
+}
+
+func TestMatrixChannel_ShouldGiveUpIfRateLimitedForTooLong(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	server := newFakeHomeserver(t,
+		matrixResponse(http.StatusTooManyRequests, `{"errcode": "M_LIMIT_EXCEEDED", "error": "Too many requests", "retry_after_ms": 600000}`),
+	)
+	defer server.Close()
+
+	var sleeps []time.Duration
+
+	err := newMatrixChannel(mockCtrl, server, &sleeps).Deliver(easyalert.User{ID: 1}, matrixNotification)
+	require.EqualError(t, err, `matrix room "ops": M_LIMIT_EXCEEDED: Too many requests`)
+	require.Empty(t, sleeps)
+}
+
+func TestMatrixChannel_ShouldSkipUsersWithoutRooms(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	roomRepo := mocks.NewMockMatrixRoomRepository(mockCtrl)
+	roomRepo.EXPECT().FindMatrixRooms(gomock.Any(), gomock.Any()).Return(nil, nil)
+
+	c := delivery.MatrixChannel{RoomRepo: roomRepo}
+
+	err := c.Deliver(easyalert.User{ID: 1}, matrixNotification)
+	require.Equal(t, delivery.ErrNotConfigured, err)
+}
@@ -0,0 +1,129 @@
+package delivery
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/routing"
+)
+
+// ErrNoChannels is returned if a notification could not be delivered since
+// no channel is configured.
+var ErrNoChannels = errors.New("no delivery channel configured")
+
+// ErrNotConfigured is returned by channels which the user did not set up,
+// e.g. without any webhook endpoint, or which the routing of the user did
+// not select. Such channels are skipped.
+var ErrNotConfigured = errors.New("channel not configured for user")
+
+// Notifier delivers notifications through the channels selected by the
+// routing of the user and marks their alerts as sent or failed.
+type Notifier struct {
+	AlertRepo easyalert.AlertRepository
+	// RoutingRepo is optional, without it notifications are delivered
+	// through all channels.
+	RoutingRepo easyalert.RoutingRepository
+	Channels    []easyalert.Channel
+}
+
+// Notify delivers the notification in the background. Errors are logged
+// without the content of the notification.
+func (n Notifier) Notify(user easyalert.User, notification easyalert.Notification) {
+	go func() {
+		err := n.Deliver(user, notification)
+		if err != nil {
+			log.Printf("Delivery error for alert %d: %v", notification.Alert.ID, err)
+		}
+	}()
+}
+
+// Deliver delivers the notification through the channels selected by the
+// routing of the user. Channels deliver at the same time, so that a slow
+// channel like a retried webhook does not delay the others. The alert is
+// marked as sent if at least one channel succeeded and as failed otherwise.
+// The error of the last failed channel is returned even if the alert was
+// sent. Channels which are not configured for the user neither count as
+// success nor failure.
+func (n Notifier) Deliver(user easyalert.User, notification easyalert.Notification) error {
+	notification.Selection = n.route(user, notification.Alert)
+
+	errs := make([]error, len(n.Channels))
+
+	var wg sync.WaitGroup
+
+	for i, channel := range n.Channels {
+		wg.Add(1)
+
+		go func(i int, channel easyalert.Channel) {
+			defer wg.Done()
+			errs[i] = channel.Deliver(user, notification)
+		}(i, channel)
+	}
+
+	wg.Wait()
+
+	var (
+		sent       bool
+		configured bool
+		deliverErr error
+	)
+
+	for _, err := range errs {
+		if err == ErrNotConfigured {
+			continue
+		}
+
+		configured = true
+
+		if err != nil {
+			deliverErr = err
+			continue
+		}
+
+		sent = true
+	}
+
+	if !configured {
+		deliverErr = ErrNoChannels
+	}
+
+	// only the outcome is stored, the alert might have been folded meanwhile
+	alert := easyalert.Alert{ID: notification.Alert.ID, Status: easyalert.AlertStatusFailed}
+
+	if sent {
+		now := time.Now().UTC()
+
+		alert.Status = easyalert.AlertStatusSent
+		alert.SentAt = &now
+	}
+
+	_, err := n.AlertRepo.UpdateAlertDelivery(alert)
+	if err != nil {
+		return err
+	}
+
+	return deliverErr
+}
+
+// route returns the channels the routing of the user selects for the alert.
+// If the routing can not be fetched all channels are selected, since an
+// alert delivered too often is better than a lost one.
+func (n Notifier) route(user easyalert.User, alert easyalert.Alert) easyalert.Selection {
+	if n.RoutingRepo == nil {
+		return nil
+	}
+
+	r, err := n.RoutingRepo.FindRouting("WHERE user_id = $1", user.ID)
+	if err != nil {
+		if err != easyalert.ErrRecordDoesNotExist {
+			log.Printf("Could not fetch routing of user %d, delivering alert %d through all channels: %v", user.ID, alert.ID, err)
+		}
+
+		return nil
+	}
+
+	return routing.Route(r, alert, time.Now()).Channels
+}
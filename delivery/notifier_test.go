@@ -0,0 +1,214 @@
+package delivery_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/delivery"
+	"github.com/bakku/easyalert/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeliver_ShouldMarkAlertAsSent(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	user := easyalert.User{ID: 1, Email: "test@mail.com"}
+	notification := easyalert.Notification{Alert: easyalert.Alert{ID: 5}, Message: "Disk full"}
+
+	channel := mocks.NewMockChannel(mockCtrl)
+	channel.EXPECT().Deliver(user, notification).Return(nil)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().UpdateAlertDelivery(gomock.Any()).DoAndReturn(func(alert easyalert.Alert) (easyalert.Alert, error) {
+		require.Equal(t, uint(5), alert.ID)
+		require.Equal(t, "sent", alert.HumanStatus())
+		require.NotNil(t, alert.SentAt)
+		return alert, nil
+	})
+
+	n := delivery.Notifier{AlertRepo: alertRepo, Channels: []easyalert.Channel{channel}}
+
+	err := n.Deliver(user, notification)
+	require.Nil(t, err)
+}
+
+func TestDeliver_ShouldMarkAlertAsSentIfOneChannelSucceeds(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	failing := mocks.NewMockChannel(mockCtrl)
+	failing.EXPECT().Deliver(gomock.Any(), gomock.Any()).Return(errors.New("Error!!"))
+
+	working := mocks.NewMockChannel(mockCtrl)
+	working.EXPECT().Deliver(gomock.Any(), gomock.Any()).Return(nil)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().UpdateAlertDelivery(gomock.Any()).DoAndReturn(func(alert easyalert.Alert) (easyalert.Alert, error) {
+		require.Equal(t, "sent", alert.HumanStatus())
+		return alert, nil
+	})
+
+	n := delivery.Notifier{AlertRepo: alertRepo, Channels: []easyalert.Channel{failing, working}}
+
+	err := n.Deliver(easyalert.User{}, easyalert.Notification{Alert: easyalert.Alert{ID: 5}})
+	require.NotNil(t, err)
+}
+
+func TestDeliver_ShouldDeliverThroughChannelsAtTheSameTime(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	released := make(chan struct{})
+
+	// the slow channel only finishes once the fast one delivered, which
+	// would never happen if channels were run one after another
+	slow := mocks.NewMockChannel(mockCtrl)
+	slow.EXPECT().Deliver(gomock.Any(), gomock.Any()).DoAndReturn(func(easyalert.User, easyalert.Notification) error {
+		select {
+		case <-released:
+			return nil
+		case <-time.After(5 * time.Second):
+			return errors.New("timed out")
+		}
+	})
+
+	fast := mocks.NewMockChannel(mockCtrl)
+	fast.EXPECT().Deliver(gomock.Any(), gomock.Any()).DoAndReturn(func(easyalert.User, easyalert.Notification) error {
+		close(released)
+		return nil
+	})
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().UpdateAlertDelivery(gomock.Any()).Return(easyalert.Alert{}, nil)
+
+	n := delivery.Notifier{AlertRepo: alertRepo, Channels: []easyalert.Channel{slow, fast}}
+
+	err := n.Deliver(easyalert.User{}, easyalert.Notification{Alert: easyalert.Alert{ID: 5}})
+	require.Nil(t, err)
+}
+
+func TestDeliver_ShouldMarkAlertAsFailedIfAllChannelsFail(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	channel := mocks.NewMockChannel(mockCtrl)
+	channel.EXPECT().Deliver(gomock.Any(), gomock.Any()).Return(errors.New("Error!!"))
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().UpdateAlertDelivery(gomock.Any()).DoAndReturn(func(alert easyalert.Alert) (easyalert.Alert, error) {
+		require.Equal(t, "failed", alert.HumanStatus())
+		require.Nil(t, alert.SentAt)
+		return alert, nil
+	})
+
+	n := delivery.Notifier{AlertRepo: alertRepo, Channels: []easyalert.Channel{channel}}
+
+	err := n.Deliver(easyalert.User{}, easyalert.Notification{Alert: easyalert.Alert{ID: 5}})
+	require.NotNil(t, err)
+}
+
+func TestDeliver_ShouldMarkAlertAsFailedWithoutChannels(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().UpdateAlertDelivery(gomock.Any()).Return(easyalert.Alert{}, nil)
+
+	n := delivery.Notifier{AlertRepo: alertRepo}
+
+	err := n.Deliver(easyalert.User{}, easyalert.Notification{Alert: easyalert.Alert{ID: 5}})
+	require.Equal(t, delivery.ErrNoChannels, err)
+}
+
+func TestDeliver_ShouldIgnoreChannelsWhichAreNotConfigured(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	skipped := mocks.NewMockChannel(mockCtrl)
+	skipped.EXPECT().Deliver(gomock.Any(), gomock.Any()).Return(delivery.ErrNotConfigured)
+
+	working := mocks.NewMockChannel(mockCtrl)
+	working.EXPECT().Deliver(gomock.Any(), gomock.Any()).Return(nil)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().UpdateAlertDelivery(gomock.Any()).DoAndReturn(func(alert easyalert.Alert) (easyalert.Alert, error) {
+		require.Equal(t, "sent", alert.HumanStatus())
+		return alert, nil
+	})
+
+	n := delivery.Notifier{AlertRepo: alertRepo, Channels: []easyalert.Channel{skipped, working}}
+
+	err := n.Deliver(easyalert.User{}, easyalert.Notification{Alert: easyalert.Alert{ID: 5}})
+	require.Nil(t, err)
+}
+
+func TestDeliver_ShouldMarkAlertAsFailedIfNoChannelIsConfigured(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	skipped := mocks.NewMockChannel(mockCtrl)
+	skipped.EXPECT().Deliver(gomock.Any(), gomock.Any()).Return(delivery.ErrNotConfigured)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().UpdateAlertDelivery(gomock.Any()).DoAndReturn(func(alert easyalert.Alert) (easyalert.Alert, error) {
+		require.Equal(t, "failed", alert.HumanStatus())
+		return alert, nil
+	})
+
+	n := delivery.Notifier{AlertRepo: alertRepo, Channels: []easyalert.Channel{skipped}}
+
+	err := n.Deliver(easyalert.User{}, easyalert.Notification{Alert: easyalert.Alert{ID: 5}})
+	require.Equal(t, delivery.ErrNoChannels, err)
+}
+
+func TestDeliver_ShouldSelectChannelsByRouting(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	user := easyalert.User{ID: 1}
+	alert := easyalert.Alert{ID: 5, Subject: "Backup failed", Labels: map[string]string{"env": "prod"}}
+
+	routingRepo := mocks.NewMockRoutingRepository(mockCtrl)
+	routingRepo.EXPECT().FindRouting("WHERE user_id = $1", uint(1)).Return(easyalert.Routing{
+		Rules: []easyalert.RoutingRule{
+			{Name: "staging", Labels: map[string]string{"env": "staging"}, Channels: easyalert.Selection{"email"}},
+			{Name: "prod", Labels: map[string]string{"env": "prod"}, Channels: easyalert.Selection{"chat:ops", "sms"}},
+		},
+	}, nil)
+
+	channel := mocks.NewMockChannel(mockCtrl)
+	channel.EXPECT().Deliver(user, easyalert.Notification{Alert: alert, Selection: easyalert.Selection{"chat:ops", "sms"}}).Return(nil)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().UpdateAlertDelivery(gomock.Any()).Return(alert, nil)
+
+	n := delivery.Notifier{AlertRepo: alertRepo, RoutingRepo: routingRepo, Channels: []easyalert.Channel{channel}}
+
+	err := n.Deliver(user, easyalert.Notification{Alert: alert})
+	require.Nil(t, err)
+}
+
+func TestDeliver_ShouldSelectAllChannelsWithoutRouting(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	alert := easyalert.Alert{ID: 5}
+
+	routingRepo := mocks.NewMockRoutingRepository(mockCtrl)
+	routingRepo.EXPECT().FindRouting(gomock.Any(), gomock.Any()).Return(easyalert.Routing{}, easyalert.ErrRecordDoesNotExist)
+
+	channel := mocks.NewMockChannel(mockCtrl)
+	channel.EXPECT().Deliver(gomock.Any(), easyalert.Notification{Alert: alert}).Return(nil)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().UpdateAlertDelivery(gomock.Any()).Return(alert, nil)
+
+	n := delivery.Notifier{AlertRepo: alertRepo, RoutingRepo: routingRepo, Channels: []easyalert.Channel{channel}}
+
+	err := n.Deliver(easyalert.User{ID: 1}, easyalert.Notification{Alert: alert})
+	require.Nil(t, err)
+}
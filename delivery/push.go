@@ -0,0 +1,65 @@
+package delivery
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/push"
+)
+
+// PushChannel publishes notifications to all push channels of the user,
+// e.g. an ntfy topic or a Gotify application.
+type PushChannel struct {
+	ChannelRepo easyalert.PushChannelRepository
+
+	// Client publishes to the servers of the channels, see clientOrDefault.
+	Client *http.Client
+}
+
+// Deliver publishes the notification to every push channel of the user
+// which is selected by the notification. It succeeds if at least one
+// channel succeeded, otherwise the last error is returned. Users without
+// selected push channels are skipped with ErrNotConfigured.
+func (c PushChannel) Deliver(user easyalert.User, n easyalert.Notification) error {
+	channels, err := c.ChannelRepo.FindPushChannels("WHERE user_id = $1 ORDER BY id", user.ID)
+	if err != nil {
+		return err
+	}
+
+	var selected []easyalert.PushChannel
+
+	for _, channel := range channels {
+		if n.Selection.Selects(easyalert.ChannelKindPush, channel.Name) {
+			selected = append(selected, channel)
+		}
+	}
+
+	if len(selected) == 0 {
+		return ErrNotConfigured
+	}
+
+	var deliverErr error
+
+	delivered := false
+
+	for _, channel := range selected {
+		req, err := push.NewRequest(channel, n)
+		if err == nil {
+			err = send(c.Client, req)
+		}
+
+		if err != nil {
+			deliverErr = fmt.Errorf("%s channel %q: %v", channel.Kind, channel.Name, err)
+			continue
+		}
+
+		delivered = true
+	}
+
+	if !delivered {
+		return deliverErr
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package delivery_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/delivery"
+	"github.com/bakku/easyalert/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePushServer struct {
+	*httptest.Server
+	path   string
+	header http.Header
+	body   string
+}
+
+func newFakePushServer(t *testing.T, status int) *fakePushServer {
+	s := &fakePushServer{}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.Nil(t, err)
+
+		s.path = r.URL.Path
+		s.header = r.Header
+		s.body = string(body)
+
+		w.WriteHeader(status)
+	}))
+
+	return s
+}
+
+var pushNotification = easyalert.Notification{
+	Alert: easyalert.Alert{
+		ID:       5,
+		Subject:  "Backup failed",
+		Severity: easyalert.AlertSeverityWarning,
+		Labels:   map[string]string{"host": "db1", "env": "prod"},
+	},
+	Message: "Disk full",
+}
+
+func TestPushChannel_ShouldPublishToNtfy(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	server := newFakePushServer(t, http.StatusOK)
+	defer server.Close()
+
+	channelRepo := mocks.NewMockPushChannelRepository(mockCtrl)
+	channelRepo.EXPECT().FindPushChannels("WHERE user_id = $1 ORDER BY id", uint(1)).Return([]easyalert.PushChannel{
+		{Name: "phone", Kind: easyalert.PushKindNtfy, ServerURL: server.URL + "/", Topic: "alerts", Token: "tk_123"},
+	}, nil)
+
+	c := delivery.PushChannel{ChannelRepo: channelRepo, Client: http.DefaultClient}
+
+	err := c.Deliver(easyalert.User{ID: 1}, pushNotification)
+	require.Nil(t, err)
+
+	require.Equal(t, "/", server.path)
+	require.Equal(t, "Bearer tk_123", server.header.Get("Authorization"))
+	require.JSONEq(t, `{
+		"topic": "alerts",
+		"title": "Backup failed",
+		"message": "Disk full",
+		"priority": 4,
+		"tags": ["warning", "env=prod", "host=db1"]
+	}`, server.body)
+}
+
+func TestPushChannel_ShouldPublishToGotify(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	server := newFakePushServer(t, http.StatusOK)
+	defer server.Close()
+
+	channelRepo := mocks.NewMockPushChannelRepository(mockCtrl)
+	channelRepo.EXPECT().FindPushChannels(gomock.Any(), gomock.Any()).Return([]easyalert.PushChannel{
+		{Name: "phone", Kind: easyalert.PushKindGotify, ServerURL: server.URL + "/gotify", Token: "AbC.123"},
+	}, nil)
+
+	c := delivery.PushChannel{ChannelRepo: channelRepo, Client: http.DefaultClient}
+
+	notification := pushNotification
+	notification.Alert.Severity = easyalert.AlertSeverityCritical
+	notification.Format = easyalert.MessageFormatMarkdown
+
+	err := c.Deliver(easyalert.User{ID: 1}, notification)
+	require.Nil(t, err)
+
+	require.Equal(t, "/gotify/message", server.path)
+	require.Equal(t, "AbC.123", server.header.Get("X-Gotify-Key"))
+	require.JSONEq(t, `{
+		"title": "Backup failed",
+		"message": "Disk full\n\nLabels: env=prod, host=db1",
+		"priority": 8,
+		"extras": {"client::display": {"contentType": "text/markdown"}}
+	}`, server.body)
+}
+
+func TestPushChannel_ShouldReturnErrorIfAllChannelsFail(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	server := newFakePushServer(t, http.StatusUnauthorized)
+	defer server.Close()
+
+	channelRepo := mocks.NewMockPushChannelRepository(mockCtrl)
+	channelRepo.EXPECT().FindPushChannels(gomock.Any(), gomock.Any()).Return([]easyalert.PushChannel{
+		{Name: "phone", Kind: easyalert.PushKindGotify, ServerURL: server.URL, Token: "wrong"},
+	}, nil)
+
+	c := delivery.PushChannel{ChannelRepo: channelRepo, Client: http.DefaultClient}
+
+	err := c.Deliver(easyalert.User{ID: 1}, pushNotification)
+	require.EqualError(t, err, `gotify channel "phone": unexpected status 401`)
+}
+
+func TestPushChannel_ShouldSkipUsersWithoutChannels(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	channelRepo := mocks.NewMockPushChannelRepository(mockCtrl)
+	channelRepo.EXPECT().FindPushChannels(gomock.Any(), gomock.Any()).Return(nil, nil)
+
+	c := delivery.PushChannel{ChannelRepo: channelRepo, Client: http.DefaultClient}
+
+	err := c.Deliver(easyalert.User{ID: 1}, pushNotification)
+	require.Equal(t, delivery.ErrNotConfigured, err)
+}
@@ -0,0 +1,78 @@
+package delivery
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/sms"
+)
+
+// SMSSender sends text messages through the HTTP API of an SMS gateway.
+type SMSSender struct {
+	Gateway sms.HTTPGateway
+
+	// Client is used to send the requests, defaults to a client with a
+	// timeout of ten seconds. Unlike other channels it may reach private
+	// addresses, since the gateway is configured by the operator.
+	Client *http.Client
+}
+
+// SendSMS sends the text to the phone number.
+func (s SMSSender) SendSMS(to, text string) error {
+	req, err := s.Gateway.NewRequest(to, text)
+	if err != nil {
+		return err
+	}
+
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: defaultHTTPTimeout}
+	}
+
+	return send(client, req)
+}
+
+// SMSChannel sends notifications as text messages to the verified phone
+// number of the user. Only the severity, the subject and a link to the
+// alert are sent, the message is left out.
+type SMSChannel struct {
+	Sender easyalert.SMSSender
+
+	// BaseURL is the public URL of easyalert which links to alerts start
+	// with. No link is sent if it is empty or Links has no secret.
+	BaseURL string
+	// Links signs the tokens of the links, so that they can be opened
+	// without logging in.
+	Links sms.LinkSigner
+	// MaxLength of the text messages, defaults to sms.DefaultMaxLength.
+	MaxLength int
+}
+
+// Deliver sends the notification to the phone number of the user. Users
+// without a verified phone number are skipped with ErrNotConfigured, just
+// like notifications which are not sent as SMS, see Selection.SendsSMS.
+func (c SMSChannel) Deliver(user easyalert.User, n easyalert.Notification) error {
+	if user.PhoneNumber == "" || !user.PhoneVerified || !n.Selection.SendsSMS(n.Alert) {
+		return ErrNotConfigured
+	}
+
+	link := ""
+	if c.BaseURL != "" && c.Links.Enabled() {
+		link = strings.TrimRight(c.BaseURL, "/") + "/a/" + c.Links.Sign(n.Alert.ID, time.Now())
+	}
+
+	maxLength := c.MaxLength
+	if maxLength <= 0 {
+		maxLength = sms.DefaultMaxLength
+	}
+
+	err := c.Sender.SendSMS(user.PhoneNumber, sms.Text(n.Alert, link, maxLength))
+	if err != nil {
+		return fmt.Errorf("sms: %v", err)
+	}
+
+	return nil
+}
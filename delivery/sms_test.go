@@ -0,0 +1,133 @@
+package delivery_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/delivery"
+	"github.com/bakku/easyalert/mocks"
+	"github.com/bakku/easyalert/sms"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+var smsNotification = easyalert.Notification{
+	Alert: easyalert.Alert{
+		ID:       5,
+		Subject:  "Backup failed",
+		Severity: easyalert.AlertSeverityCritical,
+	},
+	Message: "Disk full",
+}
+
+func TestSMSChannel_ShouldSendSeverityAndSubject(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	sender := mocks.NewMockSMSSender(mockCtrl)
+	sender.EXPECT().SendSMS("+4915112345678", "[critical] Backup failed").Return(nil)
+
+	c := delivery.SMSChannel{Sender: sender}
+
+	err := c.Deliver(easyalert.User{PhoneNumber: "+4915112345678", PhoneVerified: true}, smsNotification)
+	require.Nil(t, err)
+}
+
+func TestSMSChannel_ShouldSendSignedLink(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	links := sms.LinkSigner{Secret: []byte("secret")}
+
+	var text string
+	sender := mocks.NewMockSMSSender(mockCtrl)
+	sender.EXPECT().SendSMS("+4915112345678", gomock.Any()).DoAndReturn(func(to, t string) error {
+		text = t
+		return nil
+	})
+
+	c := delivery.SMSChannel{Sender: sender, BaseURL: "https://ea.example.com/", Links: links}
+
+	err := c.Deliver(easyalert.User{PhoneNumber: "+4915112345678", PhoneVerified: true}, smsNotification)
+	require.Nil(t, err)
+
+	prefix := "[critical] Backup failed https://ea.example.com/a/"
+	require.True(t, strings.HasPrefix(text, prefix), text)
+
+	id, err := links.Verify(strings.TrimPrefix(text, prefix), time.Now())
+	require.Nil(t, err)
+	require.Equal(t, uint(5), id)
+}
+
+func TestSMSChannel_ShouldSkipUnverifiedPhoneNumbers(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	c := delivery.SMSChannel{Sender: mocks.NewMockSMSSender(mockCtrl)}
+
+	err := c.Deliver(easyalert.User{PhoneNumber: "+4915112345678"}, smsNotification)
+	require.Equal(t, delivery.ErrNotConfigured, err)
+}
+
+func TestSMSChannel_ShouldOnlySendCriticalAlertsWithoutRouting(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	sender := mocks.NewMockSMSSender(mockCtrl)
+	sender.EXPECT().SendSMS("+4915112345678", "[info] Backup failed").Return(nil)
+
+	c := delivery.SMSChannel{Sender: sender}
+	user := easyalert.User{PhoneNumber: "+4915112345678", PhoneVerified: true}
+
+	n := smsNotification
+	n.Alert.Severity = easyalert.AlertSeverityInfo
+
+	err := c.Deliver(user, n)
+	require.Equal(t, delivery.ErrNotConfigured, err)
+
+	n.Selection = easyalert.Selection{"sms"}
+
+	err = c.Deliver(user, n)
+	require.Nil(t, err)
+}
+
+func TestSMSChannel_ShouldReturnErrorOfSender(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	sender := mocks.NewMockSMSSender(mockCtrl)
+	sender.EXPECT().SendSMS(gomock.Any(), gomock.Any()).Return(errors.New("unexpected status 402"))
+
+	c := delivery.SMSChannel{Sender: sender}
+
+	err := c.Deliver(easyalert.User{PhoneNumber: "+4915112345678", PhoneVerified: true}, smsNotification)
+	require.EqualError(t, err, "sms: unexpected status 402")
+}
+
+func TestSMSSender_ShouldSendRequestOfGateway(t *testing.T) {
+	var body string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := ioutil.ReadAll(r.Body)
+		require.Nil(t, err)
+
+		body = string(b)
+		require.Equal(t, "application/x-www-form-urlencoded", r.Header.Get("Content-Type"))
+	}))
+	defer server.Close()
+
+	gateway, err := sms.ParseHTTPGateway(server.URL+"/send", "to={{urlquery .To}}&text={{urlquery .Text}}")
+	require.Nil(t, err)
+
+	s := delivery.SMSSender{Gateway: gateway}
+
+	err = s.SendSMS("+4915112345678", "Backup failed")
+	require.Nil(t, err)
+	require.Equal(t, "to=%2B4915112345678&text=Backup+failed", body)
+}
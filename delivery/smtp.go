@@ -0,0 +1,245 @@
+package delivery
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"time"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/markdown"
+)
+
+// SMTPChannel delivers notifications as emails to the address of the user.
+type SMTPChannel struct {
+	// Addr is the address of the SMTP server in the form host:port.
+	Addr     string
+	From     string
+	Username string
+	Password string
+
+	// SendMail is used to send the mail, defaults to smtp.SendMail.
+	SendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// Deliver sends the notification as email. Markdown messages are rendered to
+// HTML and attachments are added as additional MIME parts. Notifications
+// which do not select email are skipped with ErrNotConfigured.
+func (c SMTPChannel) Deliver(user easyalert.User, n easyalert.Notification) error {
+	if !n.Selection.Selects(easyalert.ChannelKindEmail, "") {
+		return ErrNotConfigured
+	}
+
+	msg, err := buildMail(c.From, user.Email, n, time.Now())
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+
+	if c.Username != "" {
+		host, _, err := net.SplitHostPort(c.Addr)
+		if err != nil {
+			return err
+		}
+
+		auth = smtp.PlainAuth("", c.Username, c.Password, host)
+	}
+
+	sendMail := c.SendMail
+	if sendMail == nil {
+		sendMail = smtp.SendMail
+	}
+
+	return sendMail(c.Addr, auth, c.From, []string{user.Email}, msg)
+}
+
+// buildMail returns the notification as MIME message. Alerts whose message
+// was not given, like scheduled or resent ones, only contain their subject.
+func buildMail(from, to string, n easyalert.Notification, now time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+
+	header := textproto.MIMEHeader{}
+	header.Set("From", from)
+	header.Set("To", to)
+	header.Set("Subject", mime.QEncoding.Encode("utf-8", n.Alert.Subject))
+	header.Set("Date", now.Format(time.RFC1123Z))
+	header.Set("Message-ID", fmt.Sprintf("<%d.%d@easyalert>", n.Alert.ID, now.UnixNano()))
+	header.Set("MIME-Version", "1.0")
+	header.Set("X-Easyalert-Severity", n.Alert.HumanSeverity())
+
+	body := n.Message
+	if body == "" {
+		body = n.Alert.Subject
+	}
+
+	bodyHeader, content, err := buildBody(n.Alert.Subject, body, n.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(n.Attachments) == 0 {
+		for key, values := range bodyHeader {
+			header[key] = values
+		}
+
+		writeHeader(&buf, header)
+		buf.Write(content)
+
+		return buf.Bytes(), nil
+	}
+
+	mw := multipart.NewWriter(&buf)
+
+	header.Set("Content-Type", "multipart/mixed; boundary="+mw.Boundary())
+	writeHeader(&buf, header)
+
+	part, err := mw.CreatePart(bodyHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = part.Write(content)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range n.Attachments {
+		contentType := a.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+
+		part, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {contentType},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename})},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		err = writeBase64(part, a.Data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = mw.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// buildBody returns the headers and the encoded content of the mail body.
+// Markdown messages are sent as multipart/alternative with the markdown
+// itself as plain text fallback next to the rendered HTML.
+func buildBody(subject, body, format string) (textproto.MIMEHeader, []byte, error) {
+	var buf bytes.Buffer
+
+	if format != easyalert.MessageFormatMarkdown {
+		err := writeQuotedPrintable(&buf, body)
+
+		return textproto.MIMEHeader{
+			"Content-Type":              {"text/plain; charset=UTF-8"},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		}, buf.Bytes(), err
+	}
+
+	mw := multipart.NewWriter(&buf)
+
+	parts := []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=UTF-8", body},
+		{"text/html; charset=UTF-8", HTMLDocument(subject, markdown.ToHTML(body))},
+	}
+
+	for _, p := range parts {
+		part, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {p.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		err = writeQuotedPrintable(part, p.content)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	err := mw.Close()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return textproto.MIMEHeader{
+		"Content-Type": {"multipart/alternative; boundary=" + mw.Boundary()},
+	}, buf.Bytes(), nil
+}
+
+// HTMLDocument wraps the rendered HTML body of an alert into a document.
+func HTMLDocument(subject, body string) string {
+	return "<!DOCTYPE html>\n" +
+		"<html>\n" +
+		"<head>\n" +
+		"<meta charset=\"UTF-8\">\n" +
+		"<title>" + html.EscapeString(subject) + "</title>\n" +
+		"</head>\n" +
+		"<body>\n" +
+		body +
+		"</body>\n" +
+		"</html>\n"
+}
+
+func writeHeader(buf *bytes.Buffer, header textproto.MIMEHeader) {
+	for _, key := range []string{"From", "To", "Subject", "Date", "Message-ID", "MIME-Version",
+		"X-Easyalert-Severity", "Content-Type", "Content-Transfer-Encoding"} {
+		if value := header.Get(key); value != "" {
+			fmt.Fprintf(buf, "%s: %s\r\n", key, value)
+		}
+	}
+
+	buf.WriteString("\r\n")
+}
+
+func writeQuotedPrintable(w io.Writer, body string) error {
+	qw := quotedprintable.NewWriter(w)
+
+	_, err := qw.Write([]byte(body))
+	if err != nil {
+		return err
+	}
+
+	return qw.Close()
+}
+
+// writeBase64 writes the data base64 encoded with lines of 76 characters.
+func writeBase64(w io.Writer, data []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(data)
+
+	for len(encoded) > 76 {
+		_, err := fmt.Fprintf(w, "%s\r\n", encoded[:76])
+		if err != nil {
+			return err
+		}
+
+		encoded = encoded[76:]
+	}
+
+	_, err := fmt.Fprintf(w, "%s\r\n", encoded)
+	return err
+}
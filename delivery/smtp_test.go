@@ -0,0 +1,227 @@
+package delivery_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/smtp"
+	"testing"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/delivery"
+	"github.com/stretchr/testify/require"
+)
+
+type sentMail struct {
+	addr string
+	auth smtp.Auth
+	from string
+	to   []string
+	msg  []byte
+}
+
+func recordMail(sent *sentMail) func(string, smtp.Auth, string, []string, []byte) error {
+	return func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		*sent = sentMail{addr, a, from, to, msg}
+		return nil
+	}
+}
+
+func TestSMTPDeliver_ShouldSendPlainTextMail(t *testing.T) {
+	var sent sentMail
+
+	c := delivery.SMTPChannel{
+		Addr:     "mail.example.com:587",
+		From:     "alerts@example.com",
+		Username: "easyalert",
+		Password: "secret",
+		SendMail: recordMail(&sent),
+	}
+
+	user := easyalert.User{Email: "test@mail.com"}
+	n := easyalert.Notification{
+		Alert:   easyalert.Alert{ID: 5, Subject: "Backup failed", Severity: easyalert.AlertSeverityCritical},
+		Message: "Disk full",
+	}
+
+	err := c.Deliver(user, n)
+	require.Nil(t, err)
+
+	require.Equal(t, "mail.example.com:587", sent.addr)
+	require.NotNil(t, sent.auth)
+	require.Equal(t, "alerts@example.com", sent.from)
+	require.Equal(t, []string{"test@mail.com"}, sent.to)
+
+	msg, err := mail.ReadMessage(bytes.NewReader(sent.msg))
+	require.Nil(t, err)
+
+	require.Equal(t, "alerts@example.com", msg.Header.Get("From"))
+	require.Equal(t, "test@mail.com", msg.Header.Get("To"))
+	require.Equal(t, "Backup failed", msg.Header.Get("Subject"))
+	require.Equal(t, "critical", msg.Header.Get("X-Easyalert-Severity"))
+	require.Equal(t, "text/plain; charset=UTF-8", msg.Header.Get("Content-Type"))
+
+	body, err := ioutil.ReadAll(msg.Body)
+	require.Nil(t, err)
+	require.Equal(t, "Disk full", string(body))
+}
+
+func TestSMTPDeliver_ShouldUseSubjectIfMessageIsMissing(t *testing.T) {
+	var sent sentMail
+
+	c := delivery.SMTPChannel{Addr: "localhost:25", From: "alerts@example.com", SendMail: recordMail(&sent)}
+
+	err := c.Deliver(easyalert.User{Email: "test@mail.com"}, easyalert.Notification{Alert: easyalert.Alert{Subject: "Backup failed"}})
+	require.Nil(t, err)
+
+	require.Nil(t, sent.auth)
+
+	msg, err := mail.ReadMessage(bytes.NewReader(sent.msg))
+	require.Nil(t, err)
+
+	body, err := ioutil.ReadAll(msg.Body)
+	require.Nil(t, err)
+	require.Equal(t, "Backup failed", string(body))
+}
+
+func TestSMTPDeliver_ShouldAddAttachmentsAsMIMEParts(t *testing.T) {
+	var sent sentMail
+
+	c := delivery.SMTPChannel{Addr: "localhost:25", From: "alerts@example.com", SendMail: recordMail(&sent)}
+
+	n := easyalert.Notification{
+		Alert:   easyalert.Alert{Subject: "Backup failed"},
+		Message: "See log",
+		Attachments: []easyalert.Attachment{
+			{Filename: "backup.log", ContentType: "text/plain", Data: []byte("rsync error: code 12")},
+			{Filename: "dump.bin", Data: bytes.Repeat([]byte{0xff}, 100)},
+		},
+	}
+
+	err := c.Deliver(easyalert.User{Email: "test@mail.com"}, n)
+	require.Nil(t, err)
+
+	msg, err := mail.ReadMessage(bytes.NewReader(sent.msg))
+	require.Nil(t, err)
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.Nil(t, err)
+	require.Equal(t, "multipart/mixed", mediaType)
+
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+
+	// multipart.Reader transparently decodes quoted-printable parts
+	part, err := mr.NextPart()
+	require.Nil(t, err)
+	body, err := ioutil.ReadAll(part)
+	require.Nil(t, err)
+	require.Equal(t, "See log", string(body))
+
+	part, err = mr.NextPart()
+	require.Nil(t, err)
+	require.Equal(t, "backup.log", part.FileName())
+	require.Equal(t, "text/plain", part.Header.Get("Content-Type"))
+	require.Equal(t, "base64", part.Header.Get("Content-Transfer-Encoding"))
+
+	part, err = mr.NextPart()
+	require.Nil(t, err)
+	require.Equal(t, "dump.bin", part.FileName())
+	require.Equal(t, "application/octet-stream", part.Header.Get("Content-Type"))
+
+	_, err = mr.NextPart()
+	require.NotNil(t, err)
+}
+
+func TestSMTPDeliver_ShouldReturnSendError(t *testing.T) {
+	c := delivery.SMTPChannel{
+		Addr: "localhost:25",
+		From: "alerts@example.com",
+		SendMail: func(string, smtp.Auth, string, []string, []byte) error {
+			return errors.New("Error!!")
+		},
+	}
+
+	err := c.Deliver(easyalert.User{Email: "test@mail.com"}, easyalert.Notification{})
+	require.NotNil(t, err)
+}
+
+func TestSMTPDeliver_ShouldSendMarkdownAsAlternativeHTML(t *testing.T) {
+	var sent sentMail
+
+	c := delivery.SMTPChannel{Addr: "localhost:25", From: "alerts@example.com", SendMail: recordMail(&sent)}
+
+	n := easyalert.Notification{
+		Alert:   easyalert.Alert{Subject: "Backup <failed>"},
+		Message: "The job **exited** <script>alert(1)</script>",
+		Format:  easyalert.MessageFormatMarkdown,
+	}
+
+	err := c.Deliver(easyalert.User{Email: "test@mail.com"}, n)
+	require.Nil(t, err)
+
+	msg, err := mail.ReadMessage(bytes.NewReader(sent.msg))
+	require.Nil(t, err)
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.Nil(t, err)
+	require.Equal(t, "multipart/alternative", mediaType)
+
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+
+	part, err := mr.NextPart()
+	require.Nil(t, err)
+	require.Equal(t, "text/plain; charset=UTF-8", part.Header.Get("Content-Type"))
+	body, err := ioutil.ReadAll(part)
+	require.Nil(t, err)
+	require.Equal(t, "The job **exited** <script>alert(1)</script>", string(body))
+
+	part, err = mr.NextPart()
+	require.Nil(t, err)
+	require.Equal(t, "text/html; charset=UTF-8", part.Header.Get("Content-Type"))
+	body, err = ioutil.ReadAll(part)
+	require.Nil(t, err)
+	require.Contains(t, string(body), "<title>Backup &lt;failed&gt;</title>")
+	require.Contains(t, string(body), "<p>The job <strong>exited</strong> alert(1)</p>")
+	require.NotContains(t, string(body), "<script>")
+
+	_, err = mr.NextPart()
+	require.NotNil(t, err)
+}
+
+func TestSMTPDeliver_ShouldNestMarkdownBodyNextToAttachments(t *testing.T) {
+	var sent sentMail
+
+	c := delivery.SMTPChannel{Addr: "localhost:25", From: "alerts@example.com", SendMail: recordMail(&sent)}
+
+	n := easyalert.Notification{
+		Alert:       easyalert.Alert{Subject: "Backup failed"},
+		Message:     "See *log*",
+		Format:      easyalert.MessageFormatMarkdown,
+		Attachments: []easyalert.Attachment{{Filename: "backup.log", Data: []byte("rsync error")}},
+	}
+
+	err := c.Deliver(easyalert.User{Email: "test@mail.com"}, n)
+	require.Nil(t, err)
+
+	msg, err := mail.ReadMessage(bytes.NewReader(sent.msg))
+	require.Nil(t, err)
+
+	_, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.Nil(t, err)
+
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+
+	part, err := mr.NextPart()
+	require.Nil(t, err)
+
+	mediaType, _, err := mime.ParseMediaType(part.Header.Get("Content-Type"))
+	require.Nil(t, err)
+	require.Equal(t, "multipart/alternative", mediaType)
+
+	part, err = mr.NextPart()
+	require.Nil(t, err)
+	require.Equal(t, "backup.log", part.FileName())
+}
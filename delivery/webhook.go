@@ -0,0 +1,242 @@
+package delivery
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/netguard"
+)
+
+// maxLoggedResponseSize limits the part of a response body which is kept in
+// the delivery log.
+const maxLoggedResponseSize = 1024
+
+// Headers of webhook requests. The signature is the hex encoded
+// HMAC-SHA256 of "<timestamp>.<body>" using the secret of the endpoint.
+const (
+	WebhookTimestampHeader = "X-Easyalert-Timestamp"
+	WebhookSignatureHeader = "X-Easyalert-Signature"
+)
+
+// ErrWebhookFailed is returned if an alert could not be delivered to any
+// webhook endpoint of the user.
+var ErrWebhookFailed = errors.New("webhook delivery failed")
+
+// WebhookChannel posts notifications as signed JSON to all webhook endpoints
+// of the user and records every attempt.
+type WebhookChannel struct {
+	EndpointRepo easyalert.WebhookEndpointRepository
+	DeliveryRepo easyalert.WebhookDeliveryRepository
+
+	// Client is used to send the requests, defaults to a client which only
+	// connects to public addresses and does not follow redirects. The
+	// timeout of the endpoint is applied on top of it.
+	Client *http.Client
+	// Sleep waits between retries, defaults to time.Sleep.
+	Sleep func(d time.Duration)
+	// Now returns the time used for signing, defaults to time.Now.
+	Now func() time.Time
+}
+
+// webhookPayload is the JSON representation of an alert sent to endpoints.
+type webhookPayload struct {
+	ID          uint              `json:"id"`
+	Subject     string            `json:"subject"`
+	Message     string            `json:"message,omitempty"`
+	Format      string            `json:"format,omitempty"`
+	Severity    string            `json:"severity"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	DedupKey    string            `json:"dedup_key,omitempty"`
+	Occurrences uint              `json:"occurrences"`
+	CreatedAt   string            `json:"created_at"`
+}
+
+// Deliver posts the notification to every endpoint of the user which is
+// selected by the notification. Users without selected endpoints are
+// skipped with ErrNotConfigured.
+func (c WebhookChannel) Deliver(user easyalert.User, n easyalert.Notification) error {
+	endpoints, err := c.EndpointRepo.FindWebhookEndpoints("WHERE user_id = $1 ORDER BY id", user.ID)
+	if err != nil {
+		return err
+	}
+
+	var selected []easyalert.WebhookEndpoint
+
+	for _, endpoint := range endpoints {
+		if n.Selection.Selects(easyalert.ChannelKindWebhook, endpoint.Name) {
+			selected = append(selected, endpoint)
+		}
+	}
+
+	if len(selected) == 0 {
+		return ErrNotConfigured
+	}
+
+	body, err := json.Marshal(webhookPayload{
+		ID:          n.Alert.ID,
+		Subject:     n.Alert.Subject,
+		Message:     n.Message,
+		Format:      n.Format,
+		Severity:    n.Alert.HumanSeverity(),
+		Labels:      n.Alert.Labels,
+		DedupKey:    n.Alert.DedupKey,
+		Occurrences: n.Alert.Occurrences,
+		CreatedAt:   n.Alert.CreatedAt.Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+
+	// endpoints are posted to at the same time, so a slow endpoint does
+	// not delay the others
+	delivered := make([]bool, len(selected))
+
+	var wg sync.WaitGroup
+
+	for i, endpoint := range selected {
+		wg.Add(1)
+
+		go func(i int, endpoint easyalert.WebhookEndpoint) {
+			defer wg.Done()
+			delivered[i] = c.deliverTo(endpoint, n.Alert.ID, body)
+		}(i, endpoint)
+	}
+
+	wg.Wait()
+
+	for _, ok := range delivered {
+		if ok {
+			return nil
+		}
+	}
+
+	return ErrWebhookFailed
+}
+
+// deliverTo posts the body to the endpoint until it succeeds or all retries
+// are used up. Retries wait the backoff of the endpoint which doubles
+// after every retry. Endpoints are validated to wait at most
+// easyalert.MaxWebhookRetryWait, retries of endpoints saved before are
+// still stopped once they would wait longer.
+func (c WebhookChannel) deliverTo(endpoint easyalert.WebhookEndpoint, alertID uint, body []byte) bool {
+	sleep := c.Sleep
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+
+	backoff := endpoint.RetryBackoff
+	waited := time.Duration(0)
+
+	for attempt := uint(1); ; attempt++ {
+		d, retry := c.attempt(endpoint, body)
+		d.EndpointID = endpoint.ID
+		d.AlertID = alertID
+		d.Attempt = attempt
+
+		_, err := c.DeliveryRepo.CreateWebhookDelivery(d)
+		if err != nil {
+			log.Printf("Could not record webhook delivery of alert %d: %v", alertID, err)
+		}
+
+		if d.Error == "" {
+			return true
+		}
+
+		if !retry || attempt > endpoint.MaxRetries || waited+backoff > easyalert.MaxWebhookRetryWait {
+			return false
+		}
+
+		sleep(backoff)
+		waited += backoff
+		backoff *= 2
+	}
+}
+
+// attempt sends a single request and returns its log entry. The entry has an
+// error if the endpoint did not respond with 2xx. Only network errors, 429
+// and 5xx responses are retried.
+func (c WebhookChannel) attempt(endpoint easyalert.WebhookEndpoint, body []byte) (easyalert.WebhookDelivery, bool) {
+	now := time.Now
+	if c.Now != nil {
+		now = c.Now
+	}
+
+	client := netguard.NewClient(0)
+	if c.Client != nil {
+		client = c.Client
+	}
+
+	timed := *client
+	timed.Timeout = endpoint.Timeout
+
+	req, err := http.NewRequest("POST", endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return easyalert.WebhookDelivery{Error: err.Error()}, false
+	}
+
+	timestamp := strconv.FormatInt(now().Unix(), 10)
+
+	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+	req.Header.Set("User-Agent", "easyalert")
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, "sha256="+SignWebhook(endpoint.Secret, timestamp, body))
+
+	start := time.Now()
+	res, err := timed.Do(req)
+	if err != nil {
+		return easyalert.WebhookDelivery{Latency: time.Since(start), Error: err.Error()}, true
+	}
+	defer res.Body.Close()
+
+	response, _ := ioutil.ReadAll(io.LimitReader(res.Body, maxLoggedResponseSize))
+
+	d := easyalert.WebhookDelivery{
+		StatusCode: res.StatusCode,
+		Latency:    time.Since(start),
+		Response:   printable(response),
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		d.Error = fmt.Sprintf("unexpected status %d", res.StatusCode)
+	}
+
+	return d, res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500
+}
+
+// printable replaces invalid UTF-8, e.g. of a truncated character, and drops
+// NUL bytes, so the response can be stored as text.
+func printable(b []byte) string {
+	return strings.Map(func(r rune) rune {
+		if r == 0 {
+			return -1
+		}
+
+		return r
+	}, string(b))
+}
+
+// SignWebhook returns the hex encoded signature of a webhook request. The
+// timestamp is part of the signature, so receivers can reject old requests
+// to prevent replays.
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
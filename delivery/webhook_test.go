@@ -0,0 +1,228 @@
+package delivery_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/delivery"
+	"github.com/bakku/easyalert/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookChannel_ShouldPostSignedAlert(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.Nil(t, err)
+
+		require.Equal(t, "1549300000", r.Header.Get(delivery.WebhookTimestampHeader))
+		require.Equal(t, "sha256="+delivery.SignWebhook("secret", "1549300000", body), r.Header.Get(delivery.WebhookSignatureHeader))
+
+		var payload map[string]interface{}
+		require.Nil(t, json.Unmarshal(body, &payload))
+		require.Equal(t, "Disk full", payload["subject"])
+		require.Equal(t, "Only 2% left", payload["message"])
+		require.Equal(t, "critical", payload["severity"])
+
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	endpointRepo := mocks.NewMockWebhookEndpointRepository(mockCtrl)
+	endpointRepo.EXPECT().FindWebhookEndpoints("WHERE user_id = $1 ORDER BY id", uint(1)).
+		Return([]easyalert.WebhookEndpoint{{ID: 3, URL: server.URL, Secret: "secret", Timeout: time.Second}}, nil)
+
+	deliveryRepo := mocks.NewMockWebhookDeliveryRepository(mockCtrl)
+	deliveryRepo.EXPECT().CreateWebhookDelivery(gomock.Any()).DoAndReturn(func(d easyalert.WebhookDelivery) (easyalert.WebhookDelivery, error) {
+		require.Equal(t, uint(3), d.EndpointID)
+		require.Equal(t, uint(5), d.AlertID)
+		require.Equal(t, uint(1), d.Attempt)
+		require.Equal(t, 200, d.StatusCode)
+		require.Equal(t, "ok", d.Response)
+		require.Empty(t, d.Error)
+		return d, nil
+	})
+
+	c := delivery.WebhookChannel{
+		EndpointRepo: endpointRepo,
+		DeliveryRepo: deliveryRepo,
+		Client:       http.DefaultClient,
+		Now:          func() time.Time { return time.Unix(1549300000, 0) },
+	}
+
+	err := c.Deliver(easyalert.User{ID: 1}, easyalert.Notification{
+		Alert:   easyalert.Alert{ID: 5, Subject: "Disk full", Severity: easyalert.AlertSeverityCritical},
+		Message: "Only 2% left",
+	})
+	require.Nil(t, err)
+}
+
+func TestWebhookChannel_ShouldRetryServerErrors(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	requests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	endpointRepo := mocks.NewMockWebhookEndpointRepository(mockCtrl)
+	endpointRepo.EXPECT().FindWebhookEndpoints(gomock.Any(), gomock.Any()).
+		Return([]easyalert.WebhookEndpoint{{ID: 3, URL: server.URL, Timeout: time.Second, MaxRetries: 2, RetryBackoff: time.Second}}, nil)
+
+	var attempts []uint
+
+	deliveryRepo := mocks.NewMockWebhookDeliveryRepository(mockCtrl)
+	deliveryRepo.EXPECT().CreateWebhookDelivery(gomock.Any()).Times(3).DoAndReturn(func(d easyalert.WebhookDelivery) (easyalert.WebhookDelivery, error) {
+		require.Equal(t, 502, d.StatusCode)
+		require.Equal(t, "unexpected status 502", d.Error)
+		attempts = append(attempts, d.Attempt)
+		return d, nil
+	})
+
+	var sleeps []time.Duration
+
+	c := delivery.WebhookChannel{
+		EndpointRepo: endpointRepo,
+		DeliveryRepo: deliveryRepo,
+		Client:       http.DefaultClient,
+		Sleep:        func(d time.Duration) { sleeps = append(sleeps, d) },
+	}
+
+	err := c.Deliver(easyalert.User{ID: 1}, easyalert.Notification{Alert: easyalert.Alert{ID: 5}})
+	require.Equal(t, delivery.ErrWebhookFailed, err)
+
+	require.Equal(t, 3, requests)
+	require.Equal(t, []uint{1, 2, 3}, attempts)
+	require.Equal(t, []time.Duration{time.Second, 2 * time.Second}, sleeps)
+}
+
+func TestWebhookChannel_ShouldStopRetryingBeforeWaitingTooLong(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	endpointRepo := mocks.NewMockWebhookEndpointRepository(mockCtrl)
+	endpointRepo.EXPECT().FindWebhookEndpoints(gomock.Any(), gomock.Any()).
+		Return([]easyalert.WebhookEndpoint{{ID: 3, URL: server.URL, Timeout: time.Second, MaxRetries: 5, RetryBackoff: 4 * time.Second}}, nil)
+
+	deliveryRepo := mocks.NewMockWebhookDeliveryRepository(mockCtrl)
+	deliveryRepo.EXPECT().CreateWebhookDelivery(gomock.Any()).Times(2).Return(easyalert.WebhookDelivery{}, nil)
+
+	var sleeps []time.Duration
+
+	c := delivery.WebhookChannel{
+		EndpointRepo: endpointRepo,
+		DeliveryRepo: deliveryRepo,
+		Client:       http.DefaultClient,
+		Sleep:        func(d time.Duration) { sleeps = append(sleeps, d) },
+	}
+
+	err := c.Deliver(easyalert.User{ID: 1}, easyalert.Notification{Alert: easyalert.Alert{ID: 5}})
+	require.Equal(t, delivery.ErrWebhookFailed, err)
+
+	// a second retry would wait 8s more, 12s in total
+	require.Equal(t, []time.Duration{4 * time.Second}, sleeps)
+}
+
+func TestWebhookChannel_ShouldNotRetryClientErrors(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer server.Close()
+
+	endpointRepo := mocks.NewMockWebhookEndpointRepository(mockCtrl)
+	endpointRepo.EXPECT().FindWebhookEndpoints(gomock.Any(), gomock.Any()).
+		Return([]easyalert.WebhookEndpoint{{ID: 3, URL: server.URL, Timeout: time.Second, MaxRetries: 2}}, nil)
+
+	deliveryRepo := mocks.NewMockWebhookDeliveryRepository(mockCtrl)
+	deliveryRepo.EXPECT().CreateWebhookDelivery(gomock.Any()).Times(1).Return(easyalert.WebhookDelivery{}, nil)
+
+	c := delivery.WebhookChannel{EndpointRepo: endpointRepo, DeliveryRepo: deliveryRepo, Client: http.DefaultClient}
+
+	err := c.Deliver(easyalert.User{ID: 1}, easyalert.Notification{Alert: easyalert.Alert{ID: 5}})
+	require.Equal(t, delivery.ErrWebhookFailed, err)
+}
+
+func TestWebhookChannel_ShouldTruncateResponse(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("a", 1023) + "ä" + strings.Repeat("b", 1000)))
+	}))
+	defer server.Close()
+
+	endpointRepo := mocks.NewMockWebhookEndpointRepository(mockCtrl)
+	endpointRepo.EXPECT().FindWebhookEndpoints(gomock.Any(), gomock.Any()).
+		Return([]easyalert.WebhookEndpoint{{ID: 3, URL: server.URL, Timeout: time.Second}}, nil)
+
+	deliveryRepo := mocks.NewMockWebhookDeliveryRepository(mockCtrl)
+	deliveryRepo.EXPECT().CreateWebhookDelivery(gomock.Any()).DoAndReturn(func(d easyalert.WebhookDelivery) (easyalert.WebhookDelivery, error) {
+		// the cut through "ä" leaves an invalid byte which is replaced
+		require.Equal(t, strings.Repeat("a", 1023)+"\ufffd", d.Response)
+		return d, nil
+	})
+
+	c := delivery.WebhookChannel{EndpointRepo: endpointRepo, DeliveryRepo: deliveryRepo, Client: http.DefaultClient}
+
+	err := c.Deliver(easyalert.User{ID: 1}, easyalert.Notification{Alert: easyalert.Alert{ID: 5}})
+	require.Nil(t, err)
+}
+
+func TestWebhookChannel_ShouldNotConnectToPrivateAddressesByDefault(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request reached loopback address")
+	}))
+	defer server.Close()
+
+	endpointRepo := mocks.NewMockWebhookEndpointRepository(mockCtrl)
+	endpointRepo.EXPECT().FindWebhookEndpoints(gomock.Any(), gomock.Any()).
+		Return([]easyalert.WebhookEndpoint{{ID: 3, URL: server.URL, Timeout: time.Second}}, nil)
+
+	deliveryRepo := mocks.NewMockWebhookDeliveryRepository(mockCtrl)
+	deliveryRepo.EXPECT().CreateWebhookDelivery(gomock.Any()).DoAndReturn(func(d easyalert.WebhookDelivery) (easyalert.WebhookDelivery, error) {
+		require.Contains(t, d.Error, "connecting to 127.0.0.1 is not allowed")
+		return d, nil
+	})
+
+	c := delivery.WebhookChannel{EndpointRepo: endpointRepo, DeliveryRepo: deliveryRepo}
+
+	err := c.Deliver(easyalert.User{ID: 1}, easyalert.Notification{Alert: easyalert.Alert{ID: 5}})
+	require.Equal(t, delivery.ErrWebhookFailed, err)
+}
+
+func TestWebhookChannel_ShouldSkipUsersWithoutEndpoints(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	endpointRepo := mocks.NewMockWebhookEndpointRepository(mockCtrl)
+	endpointRepo.EXPECT().FindWebhookEndpoints(gomock.Any(), gomock.Any()).Return(nil, nil)
+
+	c := delivery.WebhookChannel{EndpointRepo: endpointRepo}
+
+	err := c.Deliver(easyalert.User{ID: 1}, easyalert.Notification{Alert: easyalert.Alert{ID: 5}})
+	require.Equal(t, delivery.ErrNotConfigured, err)
+}
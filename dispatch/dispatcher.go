@@ -0,0 +1,112 @@
+package dispatch
+
+import (
+	"log"
+	"time"
+
+	"github.com/bakku/easyalert"
+)
+
+// DefaultInterval is used if a Dispatcher is not given an interval.
+const DefaultInterval = 30 * time.Second
+
+// cleanUpInterval is how often expired records are deleted.
+const cleanUpInterval = time.Hour
+
+// Dispatcher periodically releases scheduled alerts whose time has come
+// by moving them to pending and handing them to the notifier. Once an hour
+// it also deletes expired idempotency keys.
+type Dispatcher struct {
+	UserRepo  easyalert.UserRepository
+	AlertRepo easyalert.AlertRepository
+	Notifier  easyalert.Notifier
+	// IdempotencyRepo is optional, without it no idempotency keys are
+	// deleted.
+	IdempotencyRepo easyalert.IdempotencyKeyRepository
+	Interval        time.Duration
+}
+
+// Run dispatches due alerts every interval until stop is closed.
+func (d Dispatcher) Run(stop <-chan struct{}) {
+	interval := d.Interval
+	if interval == 0 {
+		interval = DefaultInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var cleanedUpAt time.Time
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			err := d.Dispatch(now)
+			if err != nil {
+				log.Println("Dispatch error:", err)
+			}
+
+			if now.Sub(cleanedUpAt) >= cleanUpInterval {
+				err = d.CleanUp()
+				if err != nil {
+					log.Println("Clean up error:", err)
+				}
+
+				cleanedUpAt = now
+			}
+		}
+	}
+}
+
+// CleanUp deletes the idempotency keys whose lifetime is over, since they
+// contain the responses to their requests.
+func (d Dispatcher) CleanUp() error {
+	if d.IdempotencyRepo == nil {
+		return nil
+	}
+
+	return d.IdempotencyRepo.DeleteExpiredIdempotencyKeys()
+}
+
+// Dispatch releases all scheduled alerts which are due at the given time.
+// Alerts which were canceled after they were fetched are skipped.
+func (d Dispatcher) Dispatch(now time.Time) error {
+	alerts, err := d.AlertRepo.FindAlerts(`
+		WHERE status = $1 AND send_at <= $2
+		ORDER BY send_at
+	`, easyalert.AlertStatusScheduled, now.UTC())
+
+	if err != nil {
+		return err
+	}
+
+	for _, alert := range alerts {
+		notification := easyalert.Notification{Message: alert.Message, Format: alert.Format}
+
+		// the message is only kept until the alert is released
+		alert.Status = easyalert.AlertStatusPending
+		alert.Message = ""
+		alert.Format = ""
+
+		alert, err = d.AlertRepo.UpdateAlertStatus(alert, easyalert.AlertStatusScheduled)
+		if err == easyalert.ErrRecordDoesNotExist {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		user, err := d.UserRepo.FindUser("WHERE id = $1", alert.UserID)
+		if err != nil {
+			return err
+		}
+
+		notification.Alert = alert
+		d.Notifier.Notify(user, notification)
+	}
+
+	return nil
+}
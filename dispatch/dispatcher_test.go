@@ -0,0 +1,100 @@
+package dispatch_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/dispatch"
+	"github.com/bakku/easyalert/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDispatch_ShouldReturnErrorIfFetchingAlertsFails(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().FindAlerts(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("Error!!"))
+
+	d := dispatch.Dispatcher{AlertRepo: alertRepo}
+
+	err := d.Dispatch(time.Now())
+	require.NotNil(t, err)
+}
+
+func TestDispatch_ShouldReleaseDueAlerts(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	now := time.Date(2019, 1, 3, 9, 0, 0, 0, time.UTC)
+
+	due := []easyalert.Alert{
+		{ID: 1, Status: easyalert.AlertStatusScheduled, Message: "Maintenance *tonight*", Format: easyalert.MessageFormatMarkdown},
+		{ID: 2, Status: easyalert.AlertStatusScheduled, Message: "Backup started"},
+	}
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().FindAlerts(gomock.Any(), easyalert.AlertStatusScheduled, now).Return(due, nil)
+	alertRepo.EXPECT().UpdateAlertStatus(easyalert.Alert{ID: 1, Status: easyalert.AlertStatusPending}, uint(easyalert.AlertStatusScheduled)).Return(easyalert.Alert{ID: 1, UserID: 3}, nil)
+	alertRepo.EXPECT().UpdateAlertStatus(easyalert.Alert{ID: 2, Status: easyalert.AlertStatusPending}, uint(easyalert.AlertStatusScheduled)).Return(easyalert.Alert{ID: 2, UserID: 3}, nil)
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser("WHERE id = $1", uint(3)).Return(easyalert.User{ID: 3}, nil).Times(2)
+
+	notifier := mocks.NewMockNotifier(mockCtrl)
+	notifier.EXPECT().Notify(easyalert.User{ID: 3}, easyalert.Notification{
+		Alert:   easyalert.Alert{ID: 1, UserID: 3},
+		Message: "Maintenance *tonight*",
+		Format:  easyalert.MessageFormatMarkdown,
+	})
+	notifier.EXPECT().Notify(easyalert.User{ID: 3}, easyalert.Notification{Alert: easyalert.Alert{ID: 2, UserID: 3}, Message: "Backup started"})
+
+	d := dispatch.Dispatcher{UserRepo: userRepo, AlertRepo: alertRepo, Notifier: notifier}
+
+	err := d.Dispatch(now)
+	require.Nil(t, err)
+}
+
+func TestDispatch_ShouldSkipAlertsCanceledMeanwhile(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	now := time.Date(2019, 1, 3, 9, 0, 0, 0, time.UTC)
+
+	due := []easyalert.Alert{
+		{ID: 1, Status: easyalert.AlertStatusScheduled},
+		{ID: 2, Status: easyalert.AlertStatusScheduled},
+	}
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().FindAlerts(gomock.Any(), gomock.Any(), gomock.Any()).Return(due, nil)
+	alertRepo.EXPECT().UpdateAlertStatus(easyalert.Alert{ID: 1, Status: easyalert.AlertStatusPending}, gomock.Any()).Return(easyalert.Alert{}, easyalert.ErrRecordDoesNotExist)
+	alertRepo.EXPECT().UpdateAlertStatus(easyalert.Alert{ID: 2, Status: easyalert.AlertStatusPending}, gomock.Any()).Return(easyalert.Alert{ID: 2, UserID: 3}, nil)
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 3}, nil)
+
+	notifier := mocks.NewMockNotifier(mockCtrl)
+	notifier.EXPECT().Notify(easyalert.User{ID: 3}, easyalert.Notification{Alert: easyalert.Alert{ID: 2, UserID: 3}})
+
+	d := dispatch.Dispatcher{UserRepo: userRepo, AlertRepo: alertRepo, Notifier: notifier}
+
+	err := d.Dispatch(now)
+	require.Nil(t, err)
+}
+
+func TestCleanUp_ShouldDeleteExpiredIdempotencyKeys(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	idempotencyRepo := mocks.NewMockIdempotencyKeyRepository(mockCtrl)
+	idempotencyRepo.EXPECT().DeleteExpiredIdempotencyKeys().Return(nil)
+
+	d := dispatch.Dispatcher{IdempotencyRepo: idempotencyRepo}
+
+	err := d.CleanUp()
+	require.Nil(t, err)
+}
@@ -4,3 +4,12 @@ import "errors"
 
 // ErrRecordDoesNotExist is a generic error in case a record which is searched does not exist
 var ErrRecordDoesNotExist = errors.New("record does not exist")
+
+// ErrEmailTaken is returned if a user is saved with an email which belongs to another user
+var ErrEmailTaken = errors.New("Email is already taken.")
+
+// ErrPhoneCodeLimit is returned if a phone code is sent or entered too often
+var ErrPhoneCodeLimit = errors.New("phone code limit reached")
+
+// ErrRecordAlreadyExists is a generic error in case a record which is created already exists
+var ErrRecordAlreadyExists = errors.New("record already exists")
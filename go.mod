@@ -9,6 +9,7 @@ require (
 	github.com/gorilla/mux v1.6.2
 	github.com/lib/pq v0.0.0-20180523175426-90697d60dd84
 	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/russross/blackfriday/v2 v2.1.0
 	github.com/stretchr/testify v1.2.2
 	golang.org/x/crypto v0.0.0-20181112202954-3d3f9f413869
 	golang.org/x/net v0.0.0-20181106065722-10aee1819953 // indirect
@@ -0,0 +1,138 @@
+package easyalert
+
+import (
+	"time"
+
+	"github.com/bakku/easyalert/cron"
+)
+
+// HeartbeatRepository wraps all CRUD operations for heartbeats
+type HeartbeatRepository interface {
+	FindHeartbeat(query string, params ...interface{}) (Heartbeat, error)
+	FindHeartbeats(query string, params ...interface{}) ([]Heartbeat, error)
+	CreateHeartbeat(heartbeat Heartbeat) (Heartbeat, error)
+	UpdateHeartbeat(heartbeat Heartbeat) (Heartbeat, error)
+	// MarkHeartbeatDown sets the status of the heartbeat to down if it is
+	// not down yet and was not pinged since it was fetched. Otherwise
+	// ErrRecordDoesNotExist is returned.
+	MarkHeartbeatDown(heartbeat Heartbeat) (Heartbeat, error)
+	DeleteHeartbeat(heartbeat Heartbeat) error
+}
+
+const (
+	HeartbeatStatusNew = iota
+	HeartbeatStatusUp
+	HeartbeatStatusDown
+)
+
+// Heartbeat is a dead man's switch. The job it watches has to ping it at
+// least once per interval, otherwise an alert is created after the grace
+// period has passed. Instead of an interval a heartbeat can be given a cron
+// schedule and time zone, in which case a ping is expected after every run
+// of the schedule.
+type Heartbeat struct {
+	ID          uint
+	Name        string
+	Interval    time.Duration
+	Schedule    string
+	Timezone    string
+	GracePeriod time.Duration
+	Status      uint
+	LastPingAt  *time.Time
+	UserID      uint
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (h *Heartbeat) HumanStatus() string {
+	switch h.Status {
+	case HeartbeatStatusNew:
+		return "new"
+	case HeartbeatStatusUp:
+		return "up"
+	case HeartbeatStatusDown:
+		return "down"
+	default:
+		return "invalid status"
+	}
+}
+
+// NextPingDueAt returns the time until which the next ping is expected,
+// grace period not included. Heartbeats which were never pinged are
+// expected to be pinged one interval, or the first run of the schedule,
+// after their creation. If the schedule or time zone are invalid or the
+// schedule never fires the zero time is returned.
+func (h *Heartbeat) NextPingDueAt() time.Time {
+	since := h.CreatedAt
+	if h.LastPingAt != nil {
+		since = *h.LastPingAt
+	}
+
+	if h.Schedule == "" {
+		return since.Add(h.Interval)
+	}
+
+	schedule, err := cron.Parse(h.Schedule)
+	if err != nil {
+		return time.Time{}
+	}
+
+	loc, err := time.LoadLocation(h.Timezone)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return schedule.Next(since.In(loc))
+}
+
+// DeadlineAt returns the time after which the next ping is considered missed.
+func (h *Heartbeat) DeadlineAt() time.Time {
+	due := h.NextPingDueAt()
+	if due.IsZero() {
+		return due
+	}
+
+	return due.Add(h.GracePeriod)
+}
+
+// Overdue reports whether the next ping is missing even with the grace period.
+func (h *Heartbeat) Overdue(now time.Time) bool {
+	deadline := h.DeadlineAt()
+
+	return !deadline.IsZero() && now.After(deadline)
+}
+
+// Ping records a ping and reports whether the heartbeat was down before,
+// i.e. whether the watched job recovered.
+func (h *Heartbeat) Ping(now time.Time) bool {
+	recovered := h.Status == HeartbeatStatusDown
+
+	h.LastPingAt = &now
+	h.Status = HeartbeatStatusUp
+
+	return recovered
+}
+
+// MissedAlert returns the alert which is created when the heartbeat is overdue.
+func (h *Heartbeat) MissedAlert() Alert {
+	return Alert{
+		Subject:     "Heartbeat " + h.Name + " is overdue",
+		Status:      AlertStatusPending,
+		UserID:      h.UserID,
+		Occurrences: 1,
+		Severity:    AlertSeverityCritical,
+		Labels:      map[string]string{"heartbeat": h.Name},
+	}
+}
+
+// RecoveredAlert returns the alert which is created when an overdue heartbeat is pinged again.
+func (h *Heartbeat) RecoveredAlert() Alert {
+	return Alert{
+		Subject:     "Heartbeat " + h.Name + " recovered",
+		Status:      AlertStatusPending,
+		UserID:      h.UserID,
+		Occurrences: 1,
+		Severity:    AlertSeverityInfo,
+		Labels:      map[string]string{"heartbeat": h.Name},
+	}
+}
@@ -0,0 +1,109 @@
+package easyalert_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bakku/easyalert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeartbeatHumanStatus(t *testing.T) {
+	var (
+		fresh   = easyalert.Heartbeat{Status: 0}
+		up      = easyalert.Heartbeat{Status: 1}
+		down    = easyalert.Heartbeat{Status: 2}
+		invalid = easyalert.Heartbeat{Status: 3}
+	)
+
+	require.Equal(t, "new", fresh.HumanStatus())
+	require.Equal(t, "up", up.HumanStatus())
+	require.Equal(t, "down", down.HumanStatus())
+	require.Equal(t, "invalid status", invalid.HumanStatus())
+}
+
+func TestNextPingDueAt_NeverPinged(t *testing.T) {
+	createdAt := time.Date(2019, 1, 7, 10, 0, 0, 0, time.UTC)
+
+	h := easyalert.Heartbeat{Interval: time.Hour, CreatedAt: createdAt}
+
+	require.Equal(t, createdAt.Add(time.Hour), h.NextPingDueAt())
+}
+
+func TestNextPingDueAt_Pinged(t *testing.T) {
+	createdAt := time.Date(2019, 1, 7, 10, 0, 0, 0, time.UTC)
+	lastPingAt := time.Date(2019, 1, 7, 12, 30, 0, 0, time.UTC)
+
+	h := easyalert.Heartbeat{Interval: time.Hour, LastPingAt: &lastPingAt, CreatedAt: createdAt}
+
+	require.Equal(t, lastPingAt.Add(time.Hour), h.NextPingDueAt())
+}
+
+func TestNextPingDueAt_Schedule(t *testing.T) {
+	lastPingAt := time.Date(2019, 1, 7, 1, 5, 0, 0, time.UTC)
+
+	h := easyalert.Heartbeat{Schedule: "0 2 * * *", Timezone: "Europe/Berlin", LastPingAt: &lastPingAt}
+
+	// 01:05 UTC is 02:05 in Berlin, so the run of the same day already happened
+	require.True(t, time.Date(2019, 1, 8, 1, 0, 0, 0, time.UTC).Equal(h.NextPingDueAt()))
+}
+
+func TestNextPingDueAt_InvalidSchedule(t *testing.T) {
+	h := easyalert.Heartbeat{Schedule: "every day"}
+
+	require.True(t, h.NextPingDueAt().IsZero())
+	require.False(t, h.Overdue(time.Now()))
+}
+
+func TestDeadlineAt(t *testing.T) {
+	createdAt := time.Date(2019, 1, 7, 10, 0, 0, 0, time.UTC)
+
+	h := easyalert.Heartbeat{Schedule: "30 * * * *", GracePeriod: 5 * time.Minute, CreatedAt: createdAt}
+
+	require.Equal(t, time.Date(2019, 1, 7, 10, 35, 0, 0, time.UTC), h.DeadlineAt())
+}
+
+func TestOverdue(t *testing.T) {
+	lastPingAt := time.Date(2019, 1, 7, 12, 0, 0, 0, time.UTC)
+
+	h := easyalert.Heartbeat{Interval: time.Hour, GracePeriod: 10 * time.Minute, LastPingAt: &lastPingAt}
+
+	require.False(t, h.Overdue(lastPingAt.Add(time.Hour)))
+	require.False(t, h.Overdue(lastPingAt.Add(70*time.Minute)))
+	require.True(t, h.Overdue(lastPingAt.Add(71*time.Minute)))
+}
+
+func TestPing(t *testing.T) {
+	now := time.Date(2019, 1, 7, 12, 0, 0, 0, time.UTC)
+
+	h := easyalert.Heartbeat{Status: easyalert.HeartbeatStatusNew}
+	require.False(t, h.Ping(now))
+	require.Equal(t, "up", h.HumanStatus())
+	require.Equal(t, now, *h.LastPingAt)
+
+	h.Status = easyalert.HeartbeatStatusDown
+	require.True(t, h.Ping(now))
+	require.Equal(t, "up", h.HumanStatus())
+}
+
+func TestMissedAlert(t *testing.T) {
+	h := easyalert.Heartbeat{Name: "backup", UserID: 1}
+
+	alert := h.MissedAlert()
+
+	require.Equal(t, "Heartbeat backup is overdue", alert.Subject)
+	require.Equal(t, "pending", alert.HumanStatus())
+	require.Equal(t, "critical", alert.HumanSeverity())
+	require.Equal(t, uint(1), alert.UserID)
+	require.Equal(t, map[string]string{"heartbeat": "backup"}, alert.Labels)
+}
+
+func TestRecoveredAlert(t *testing.T) {
+	h := easyalert.Heartbeat{Name: "backup", UserID: 1}
+
+	alert := h.RecoveredAlert()
+
+	require.Equal(t, "Heartbeat backup recovered", alert.Subject)
+	require.Equal(t, "info", alert.HumanSeverity())
+	require.Equal(t, uint(1), alert.UserID)
+}
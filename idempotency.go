@@ -0,0 +1,36 @@
+package easyalert
+
+import "time"
+
+const (
+	// IdempotencyKeyLifetime is how long the response to an idempotent request is kept.
+	IdempotencyKeyLifetime = 24 * time.Hour
+
+	// IdempotencyKeyLockTimeout is how long a key stays claimed by a request
+	// which has not stored its response yet, e.g. because the server stopped.
+	IdempotencyKeyLockTimeout = time.Minute
+)
+
+type IdempotencyKeyRepository interface {
+	FindIdempotencyKey(query string, params ...interface{}) (IdempotencyKey, error)
+	CreateIdempotencyKey(key IdempotencyKey) (IdempotencyKey, error)
+	UpdateIdempotencyKey(key IdempotencyKey) (IdempotencyKey, error)
+	DeleteIdempotencyKey(key IdempotencyKey) error
+	// DeleteExpiredIdempotencyKeys deletes all keys older than
+	// IdempotencyKeyLifetime together with their responses.
+	DeleteExpiredIdempotencyKeys() error
+}
+
+// IdempotencyKey stores the response to a request sent with an
+// Idempotency-Key header so that retries of the request can be replayed.
+// A key is created with a StatusCode of 0 while its request is in progress.
+type IdempotencyKey struct {
+	ID          uint
+	Key         string
+	RequestHash string
+	StatusCode  int
+	Location    string
+	Body        string
+	UserID      uint
+	CreatedAt   time.Time
+}
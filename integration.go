@@ -0,0 +1,33 @@
+package easyalert
+
+import "time"
+
+// IntegrationRepository wraps all CRUD operations for integrations
+type IntegrationRepository interface {
+	FindIntegration(query string, params ...interface{}) (Integration, error)
+	FindIntegrations(query string, params ...interface{}) ([]Integration, error)
+	CreateIntegration(integration Integration) (Integration, error)
+	UpdateIntegration(integration Integration) (Integration, error)
+	DeleteIntegration(integration Integration) error
+}
+
+// Integration receives the JSON webhooks of a tool and maps their fields
+// onto alerts. The mapping is either given by the user or taken from a preset.
+type Integration struct {
+	ID        uint
+	Name      string
+	Preset    string
+	Mapping   FieldMapping
+	UserID    uint
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// FieldMapping contains an expression for every field of an alert which is
+// taken from a webhook payload, e.g. "$.title" or "grafana:{$.groupKey}".
+type FieldMapping struct {
+	Subject  string
+	Message  string
+	Severity string
+	DedupKey string
+}
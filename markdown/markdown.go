@@ -0,0 +1,22 @@
+// Package markdown renders alert messages written in markdown to HTML.
+package markdown
+
+import (
+	"github.com/russross/blackfriday/v2"
+)
+
+const htmlFlags = blackfriday.SkipHTML | blackfriday.SkipImages | blackfriday.Safelink |
+	blackfriday.NofollowLinks | blackfriday.NoreferrerLinks
+
+// ToHTML renders the markdown to HTML. Raw HTML and images are dropped and
+// only links to trusted protocols are kept, so the result is safe to embed
+// into an email even though the message is user input.
+func ToHTML(src string) string {
+	renderer := blackfriday.NewHTMLRenderer(blackfriday.HTMLRendererParameters{
+		Flags: htmlFlags,
+	})
+
+	return string(blackfriday.Run([]byte(src),
+		blackfriday.WithRenderer(renderer),
+		blackfriday.WithExtensions(blackfriday.CommonExtensions)))
+}
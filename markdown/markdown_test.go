@@ -0,0 +1,43 @@
+package markdown_test
+
+import (
+	"testing"
+
+	"github.com/bakku/easyalert/markdown"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToHTML_ShouldRenderMarkdown(t *testing.T) {
+	html := markdown.ToHTML("# Backup failed\n\nThe job **exited** with:\n\n```\nrsync error: code 12\n```\n\n- disk `sda` full\n")
+
+	require.Equal(t, "<h1>Backup failed</h1>\n\n"+
+		"<p>The job <strong>exited</strong> with:</p>\n\n"+
+		"<pre><code>rsync error: code 12\n</code></pre>\n\n"+
+		"<ul>\n<li>disk <code>sda</code> full</li>\n</ul>\n", html)
+}
+
+func TestToHTML_ShouldDropRawHTML(t *testing.T) {
+	html := markdown.ToHTML("Hi <script>alert(1)</script>\n\n<iframe src=\"https://example.com\"></iframe>\n")
+
+	require.NotContains(t, html, "<script>")
+	require.NotContains(t, html, "<iframe")
+}
+
+func TestToHTML_ShouldEscapeText(t *testing.T) {
+	html := markdown.ToHTML("if a < b && c > d")
+
+	require.Equal(t, "<p>if a &lt; b &amp;&amp; c &gt; d</p>\n", html)
+}
+
+func TestToHTML_ShouldOnlyKeepSafeLinks(t *testing.T) {
+	html := markdown.ToHTML("[logs](https://logs.example.com) [click](javascript:alert(1))")
+
+	require.Contains(t, html, `<a href="https://logs.example.com" rel="nofollow noreferrer">logs</a>`)
+	require.NotContains(t, html, "javascript:")
+}
+
+func TestToHTML_ShouldDropImages(t *testing.T) {
+	html := markdown.ToHTML("![tracker](https://example.com/pixel.gif)")
+
+	require.NotContains(t, html, "<img")
+}
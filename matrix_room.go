@@ -0,0 +1,26 @@
+package easyalert
+
+import "time"
+
+// MatrixRoomRepository wraps all CRUD operations for Matrix rooms
+type MatrixRoomRepository interface {
+	FindMatrixRoom(query string, params ...interface{}) (MatrixRoom, error)
+	FindMatrixRooms(query string, params ...interface{}) ([]MatrixRoom, error)
+	CreateMatrixRoom(room MatrixRoom) (MatrixRoom, error)
+	UpdateMatrixRoom(room MatrixRoom) (MatrixRoom, error)
+	DeleteMatrixRoom(room MatrixRoom) error
+}
+
+// MatrixRoom is a Matrix room which alerts of the user are sent to, using
+// the access token of an account which joined the room.
+type MatrixRoom struct {
+	ID            uint
+	Name          string
+	HomeserverURL string
+	// RoomID is the internal ID of the room like "!abc:example.org".
+	RoomID      string
+	AccessToken string
+	UserID      uint
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
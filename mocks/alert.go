@@ -5,10 +5,9 @@
 package mocks
 
 import (
-	reflect "reflect"
-
 	easyalert "github.com/bakku/easyalert"
 	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
 )
 
 // MockAlertRepository is a mock of AlertRepository interface
@@ -83,6 +82,19 @@ func (mr *MockAlertRepositoryMockRecorder) CreateAlert(alert interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAlert", reflect.TypeOf((*MockAlertRepository)(nil).CreateAlert), alert)
 }
 
+// CreateAlerts mocks base method
+func (m *MockAlertRepository) CreateAlerts(alerts []easyalert.Alert) ([]easyalert.Alert, error) {
+	ret := m.ctrl.Call(m, "CreateAlerts", alerts)
+	ret0, _ := ret[0].([]easyalert.Alert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAlerts indicates an expected call of CreateAlerts
+func (mr *MockAlertRepositoryMockRecorder) CreateAlerts(alerts interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAlerts", reflect.TypeOf((*MockAlertRepository)(nil).CreateAlerts), alerts)
+}
+
 // UpdateAlert mocks base method
 func (m *MockAlertRepository) UpdateAlert(alert easyalert.Alert) (easyalert.Alert, error) {
 	ret := m.ctrl.Call(m, "UpdateAlert", alert)
@@ -96,6 +108,50 @@ func (mr *MockAlertRepositoryMockRecorder) UpdateAlert(alert interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAlert", reflect.TypeOf((*MockAlertRepository)(nil).UpdateAlert), alert)
 }
 
+// FoldAlert mocks base method
+func (m *MockAlertRepository) FoldAlert(query string, params ...interface{}) (easyalert.Alert, error) {
+	varargs := []interface{}{query}
+	for _, a := range params {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "FoldAlert", varargs...)
+	ret0, _ := ret[0].(easyalert.Alert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FoldAlert indicates an expected call of FoldAlert
+func (mr *MockAlertRepositoryMockRecorder) FoldAlert(query interface{}, params ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{query}, params...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FoldAlert", reflect.TypeOf((*MockAlertRepository)(nil).FoldAlert), varargs...)
+}
+
+// UpdateAlertDelivery mocks base method
+func (m *MockAlertRepository) UpdateAlertDelivery(alert easyalert.Alert) (easyalert.Alert, error) {
+	ret := m.ctrl.Call(m, "UpdateAlertDelivery", alert)
+	ret0, _ := ret[0].(easyalert.Alert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateAlertDelivery indicates an expected call of UpdateAlertDelivery
+func (mr *MockAlertRepositoryMockRecorder) UpdateAlertDelivery(alert interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAlertDelivery", reflect.TypeOf((*MockAlertRepository)(nil).UpdateAlertDelivery), alert)
+}
+
+// UpdateAlertStatus mocks base method
+func (m *MockAlertRepository) UpdateAlertStatus(alert easyalert.Alert, from uint) (easyalert.Alert, error) {
+	ret := m.ctrl.Call(m, "UpdateAlertStatus", alert, from)
+	ret0, _ := ret[0].(easyalert.Alert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateAlertStatus indicates an expected call of UpdateAlertStatus
+func (mr *MockAlertRepositoryMockRecorder) UpdateAlertStatus(alert, from interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAlertStatus", reflect.TypeOf((*MockAlertRepository)(nil).UpdateAlertStatus), alert, from)
+}
+
 // DeleteAlert mocks base method
 func (m *MockAlertRepository) DeleteAlert(alert easyalert.Alert) error {
 	ret := m.ctrl.Call(m, "DeleteAlert", alert)
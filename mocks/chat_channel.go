@@ -0,0 +1,108 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: chat_channel.go
+
+// Package mock_easyalert is a generated GoMock package.
+package mocks
+
+import (
+	easyalert "github.com/bakku/easyalert"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockChatChannelRepository is a mock of ChatChannelRepository interface
+type MockChatChannelRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockChatChannelRepositoryMockRecorder
+}
+
+// MockChatChannelRepositoryMockRecorder is the mock recorder for MockChatChannelRepository
+type MockChatChannelRepositoryMockRecorder struct {
+	mock *MockChatChannelRepository
+}
+
+// NewMockChatChannelRepository creates a new mock instance
+func NewMockChatChannelRepository(ctrl *gomock.Controller) *MockChatChannelRepository {
+	mock := &MockChatChannelRepository{ctrl: ctrl}
+	mock.recorder = &MockChatChannelRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockChatChannelRepository) EXPECT() *MockChatChannelRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindChatChannel mocks base method
+func (m *MockChatChannelRepository) FindChatChannel(query string, params ...interface{}) (easyalert.ChatChannel, error) {
+	varargs := []interface{}{query}
+	for _, a := range params {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "FindChatChannel", varargs...)
+	ret0, _ := ret[0].(easyalert.ChatChannel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindChatChannel indicates an expected call of FindChatChannel
+func (mr *MockChatChannelRepositoryMockRecorder) FindChatChannel(query interface{}, params ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{query}, params...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindChatChannel", reflect.TypeOf((*MockChatChannelRepository)(nil).FindChatChannel), varargs...)
+}
+
+// FindChatChannels mocks base method
+func (m *MockChatChannelRepository) FindChatChannels(query string, params ...interface{}) ([]easyalert.ChatChannel, error) {
+	varargs := []interface{}{query}
+	for _, a := range params {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "FindChatChannels", varargs...)
+	ret0, _ := ret[0].([]easyalert.ChatChannel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindChatChannels indicates an expected call of FindChatChannels
+func (mr *MockChatChannelRepositoryMockRecorder) FindChatChannels(query interface{}, params ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{query}, params...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindChatChannels", reflect.TypeOf((*MockChatChannelRepository)(nil).FindChatChannels), varargs...)
+}
+
+// CreateChatChannel mocks base method
+func (m *MockChatChannelRepository) CreateChatChannel(channel easyalert.ChatChannel) (easyalert.ChatChannel, error) {
+	ret := m.ctrl.Call(m, "CreateChatChannel", channel)
+	ret0, _ := ret[0].(easyalert.ChatChannel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateChatChannel indicates an expected call of CreateChatChannel
+func (mr *MockChatChannelRepositoryMockRecorder) CreateChatChannel(channel interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateChatChannel", reflect.TypeOf((*MockChatChannelRepository)(nil).CreateChatChannel), channel)
+}
+
+// UpdateChatChannel mocks base method
+func (m *MockChatChannelRepository) UpdateChatChannel(channel easyalert.ChatChannel) (easyalert.ChatChannel, error) {
+	ret := m.ctrl.Call(m, "UpdateChatChannel", channel)
+	ret0, _ := ret[0].(easyalert.ChatChannel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateChatChannel indicates an expected call of UpdateChatChannel
+func (mr *MockChatChannelRepositoryMockRecorder) UpdateChatChannel(channel interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateChatChannel", reflect.TypeOf((*MockChatChannelRepository)(nil).UpdateChatChannel), channel)
+}
+
+// DeleteChatChannel mocks base method
+func (m *MockChatChannelRepository) DeleteChatChannel(channel easyalert.ChatChannel) error {
+	ret := m.ctrl.Call(m, "DeleteChatChannel", channel)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteChatChannel indicates an expected call of DeleteChatChannel
+func (mr *MockChatChannelRepositoryMockRecorder) DeleteChatChannel(channel interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteChatChannel", reflect.TypeOf((*MockChatChannelRepository)(nil).DeleteChatChannel), channel)
+}
@@ -0,0 +1,121 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: heartbeat.go
+
+// Package mock_easyalert is a generated GoMock package.
+package mocks
+
+import (
+	easyalert "github.com/bakku/easyalert"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockHeartbeatRepository is a mock of HeartbeatRepository interface
+type MockHeartbeatRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockHeartbeatRepositoryMockRecorder
+}
+
+// MockHeartbeatRepositoryMockRecorder is the mock recorder for MockHeartbeatRepository
+type MockHeartbeatRepositoryMockRecorder struct {
+	mock *MockHeartbeatRepository
+}
+
+// NewMockHeartbeatRepository creates a new mock instance
+func NewMockHeartbeatRepository(ctrl *gomock.Controller) *MockHeartbeatRepository {
+	mock := &MockHeartbeatRepository{ctrl: ctrl}
+	mock.recorder = &MockHeartbeatRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockHeartbeatRepository) EXPECT() *MockHeartbeatRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindHeartbeat mocks base method
+func (m *MockHeartbeatRepository) FindHeartbeat(query string, params ...interface{}) (easyalert.Heartbeat, error) {
+	varargs := []interface{}{query}
+	for _, a := range params {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "FindHeartbeat", varargs...)
+	ret0, _ := ret[0].(easyalert.Heartbeat)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindHeartbeat indicates an expected call of FindHeartbeat
+func (mr *MockHeartbeatRepositoryMockRecorder) FindHeartbeat(query interface{}, params ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{query}, params...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindHeartbeat", reflect.TypeOf((*MockHeartbeatRepository)(nil).FindHeartbeat), varargs...)
+}
+
+// FindHeartbeats mocks base method
+func (m *MockHeartbeatRepository) FindHeartbeats(query string, params ...interface{}) ([]easyalert.Heartbeat, error) {
+	varargs := []interface{}{query}
+	for _, a := range params {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "FindHeartbeats", varargs...)
+	ret0, _ := ret[0].([]easyalert.Heartbeat)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindHeartbeats indicates an expected call of FindHeartbeats
+func (mr *MockHeartbeatRepositoryMockRecorder) FindHeartbeats(query interface{}, params ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{query}, params...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindHeartbeats", reflect.TypeOf((*MockHeartbeatRepository)(nil).FindHeartbeats), varargs...)
+}
+
+// CreateHeartbeat mocks base method
+func (m *MockHeartbeatRepository) CreateHeartbeat(heartbeat easyalert.Heartbeat) (easyalert.Heartbeat, error) {
+	ret := m.ctrl.Call(m, "CreateHeartbeat", heartbeat)
+	ret0, _ := ret[0].(easyalert.Heartbeat)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateHeartbeat indicates an expected call of CreateHeartbeat
+func (mr *MockHeartbeatRepositoryMockRecorder) CreateHeartbeat(heartbeat interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateHeartbeat", reflect.TypeOf((*MockHeartbeatRepository)(nil).CreateHeartbeat), heartbeat)
+}
+
+// UpdateHeartbeat mocks base method
+func (m *MockHeartbeatRepository) UpdateHeartbeat(heartbeat easyalert.Heartbeat) (easyalert.Heartbeat, error) {
+	ret := m.ctrl.Call(m, "UpdateHeartbeat", heartbeat)
+	ret0, _ := ret[0].(easyalert.Heartbeat)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateHeartbeat indicates an expected call of UpdateHeartbeat
+func (mr *MockHeartbeatRepositoryMockRecorder) UpdateHeartbeat(heartbeat interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateHeartbeat", reflect.TypeOf((*MockHeartbeatRepository)(nil).UpdateHeartbeat), heartbeat)
+}
+
+// MarkHeartbeatDown mocks base method
+func (m *MockHeartbeatRepository) MarkHeartbeatDown(heartbeat easyalert.Heartbeat) (easyalert.Heartbeat, error) {
+	ret := m.ctrl.Call(m, "MarkHeartbeatDown", heartbeat)
+	ret0, _ := ret[0].(easyalert.Heartbeat)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkHeartbeatDown indicates an expected call of MarkHeartbeatDown
+func (mr *MockHeartbeatRepositoryMockRecorder) MarkHeartbeatDown(heartbeat interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkHeartbeatDown", reflect.TypeOf((*MockHeartbeatRepository)(nil).MarkHeartbeatDown), heartbeat)
+}
+
+// DeleteHeartbeat mocks base method
+func (m *MockHeartbeatRepository) DeleteHeartbeat(heartbeat easyalert.Heartbeat) error {
+	ret := m.ctrl.Call(m, "DeleteHeartbeat", heartbeat)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteHeartbeat indicates an expected call of DeleteHeartbeat
+func (mr *MockHeartbeatRepositoryMockRecorder) DeleteHeartbeat(heartbeat interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteHeartbeat", reflect.TypeOf((*MockHeartbeatRepository)(nil).DeleteHeartbeat), heartbeat)
+}
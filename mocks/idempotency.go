@@ -0,0 +1,102 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: idempotency.go
+
+// Package mock_easyalert is a generated GoMock package.
+package mocks
+
+import (
+	easyalert "github.com/bakku/easyalert"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockIdempotencyKeyRepository is a mock of IdempotencyKeyRepository interface
+type MockIdempotencyKeyRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIdempotencyKeyRepositoryMockRecorder
+}
+
+// MockIdempotencyKeyRepositoryMockRecorder is the mock recorder for MockIdempotencyKeyRepository
+type MockIdempotencyKeyRepositoryMockRecorder struct {
+	mock *MockIdempotencyKeyRepository
+}
+
+// NewMockIdempotencyKeyRepository creates a new mock instance
+func NewMockIdempotencyKeyRepository(ctrl *gomock.Controller) *MockIdempotencyKeyRepository {
+	mock := &MockIdempotencyKeyRepository{ctrl: ctrl}
+	mock.recorder = &MockIdempotencyKeyRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockIdempotencyKeyRepository) EXPECT() *MockIdempotencyKeyRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindIdempotencyKey mocks base method
+func (m *MockIdempotencyKeyRepository) FindIdempotencyKey(query string, params ...interface{}) (easyalert.IdempotencyKey, error) {
+	varargs := []interface{}{query}
+	for _, a := range params {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "FindIdempotencyKey", varargs...)
+	ret0, _ := ret[0].(easyalert.IdempotencyKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindIdempotencyKey indicates an expected call of FindIdempotencyKey
+func (mr *MockIdempotencyKeyRepositoryMockRecorder) FindIdempotencyKey(query interface{}, params ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{query}, params...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindIdempotencyKey", reflect.TypeOf((*MockIdempotencyKeyRepository)(nil).FindIdempotencyKey), varargs...)
+}
+
+// CreateIdempotencyKey mocks base method
+func (m *MockIdempotencyKeyRepository) CreateIdempotencyKey(key easyalert.IdempotencyKey) (easyalert.IdempotencyKey, error) {
+	ret := m.ctrl.Call(m, "CreateIdempotencyKey", key)
+	ret0, _ := ret[0].(easyalert.IdempotencyKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateIdempotencyKey indicates an expected call of CreateIdempotencyKey
+func (mr *MockIdempotencyKeyRepositoryMockRecorder) CreateIdempotencyKey(key interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIdempotencyKey", reflect.TypeOf((*MockIdempotencyKeyRepository)(nil).CreateIdempotencyKey), key)
+}
+
+// UpdateIdempotencyKey mocks base method
+func (m *MockIdempotencyKeyRepository) UpdateIdempotencyKey(key easyalert.IdempotencyKey) (easyalert.IdempotencyKey, error) {
+	ret := m.ctrl.Call(m, "UpdateIdempotencyKey", key)
+	ret0, _ := ret[0].(easyalert.IdempotencyKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateIdempotencyKey indicates an expected call of UpdateIdempotencyKey
+func (mr *MockIdempotencyKeyRepositoryMockRecorder) UpdateIdempotencyKey(key interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateIdempotencyKey", reflect.TypeOf((*MockIdempotencyKeyRepository)(nil).UpdateIdempotencyKey), key)
+}
+
+// DeleteIdempotencyKey mocks base method
+func (m *MockIdempotencyKeyRepository) DeleteIdempotencyKey(key easyalert.IdempotencyKey) error {
+	ret := m.ctrl.Call(m, "DeleteIdempotencyKey", key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteIdempotencyKey indicates an expected call of DeleteIdempotencyKey
+func (mr *MockIdempotencyKeyRepositoryMockRecorder) DeleteIdempotencyKey(key interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteIdempotencyKey", reflect.TypeOf((*MockIdempotencyKeyRepository)(nil).DeleteIdempotencyKey), key)
+}
+
+// DeleteExpiredIdempotencyKeys mocks base method
+func (m *MockIdempotencyKeyRepository) DeleteExpiredIdempotencyKeys() error {
+	ret := m.ctrl.Call(m, "DeleteExpiredIdempotencyKeys")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpiredIdempotencyKeys indicates an expected call of DeleteExpiredIdempotencyKeys
+func (mr *MockIdempotencyKeyRepositoryMockRecorder) DeleteExpiredIdempotencyKeys() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredIdempotencyKeys", reflect.TypeOf((*MockIdempotencyKeyRepository)(nil).DeleteExpiredIdempotencyKeys))
+}
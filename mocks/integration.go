@@ -0,0 +1,108 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: integration.go
+
+// Package mock_easyalert is a generated GoMock package.
+package mocks
+
+import (
+	easyalert "github.com/bakku/easyalert"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockIntegrationRepository is a mock of IntegrationRepository interface
+type MockIntegrationRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIntegrationRepositoryMockRecorder
+}
+
+// MockIntegrationRepositoryMockRecorder is the mock recorder for MockIntegrationRepository
+type MockIntegrationRepositoryMockRecorder struct {
+	mock *MockIntegrationRepository
+}
+
+// NewMockIntegrationRepository creates a new mock instance
+func NewMockIntegrationRepository(ctrl *gomock.Controller) *MockIntegrationRepository {
+	mock := &MockIntegrationRepository{ctrl: ctrl}
+	mock.recorder = &MockIntegrationRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockIntegrationRepository) EXPECT() *MockIntegrationRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindIntegration mocks base method
+func (m *MockIntegrationRepository) FindIntegration(query string, params ...interface{}) (easyalert.Integration, error) {
+	varargs := []interface{}{query}
+	for _, a := range params {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "FindIntegration", varargs...)
+	ret0, _ := ret[0].(easyalert.Integration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindIntegration indicates an expected call of FindIntegration
+func (mr *MockIntegrationRepositoryMockRecorder) FindIntegration(query interface{}, params ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{query}, params...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindIntegration", reflect.TypeOf((*MockIntegrationRepository)(nil).FindIntegration), varargs...)
+}
+
+// FindIntegrations mocks base method
+func (m *MockIntegrationRepository) FindIntegrations(query string, params ...interface{}) ([]easyalert.Integration, error) {
+	varargs := []interface{}{query}
+	for _, a := range params {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "FindIntegrations", varargs...)
+	ret0, _ := ret[0].([]easyalert.Integration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindIntegrations indicates an expected call of FindIntegrations
+func (mr *MockIntegrationRepositoryMockRecorder) FindIntegrations(query interface{}, params ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{query}, params...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindIntegrations", reflect.TypeOf((*MockIntegrationRepository)(nil).FindIntegrations), varargs...)
+}
+
+// CreateIntegration mocks base method
+func (m *MockIntegrationRepository) CreateIntegration(integration easyalert.Integration) (easyalert.Integration, error) {
+	ret := m.ctrl.Call(m, "CreateIntegration", integration)
+	ret0, _ := ret[0].(easyalert.Integration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateIntegration indicates an expected call of CreateIntegration
+func (mr *MockIntegrationRepositoryMockRecorder) CreateIntegration(integration interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIntegration", reflect.TypeOf((*MockIntegrationRepository)(nil).CreateIntegration), integration)
+}
+
+// UpdateIntegration mocks base method
+func (m *MockIntegrationRepository) UpdateIntegration(integration easyalert.Integration) (easyalert.Integration, error) {
+	ret := m.ctrl.Call(m, "UpdateIntegration", integration)
+	ret0, _ := ret[0].(easyalert.Integration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateIntegration indicates an expected call of UpdateIntegration
+func (mr *MockIntegrationRepositoryMockRecorder) UpdateIntegration(integration interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateIntegration", reflect.TypeOf((*MockIntegrationRepository)(nil).UpdateIntegration), integration)
+}
+
+// DeleteIntegration mocks base method
+func (m *MockIntegrationRepository) DeleteIntegration(integration easyalert.Integration) error {
+	ret := m.ctrl.Call(m, "DeleteIntegration", integration)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteIntegration indicates an expected call of DeleteIntegration
+func (mr *MockIntegrationRepositoryMockRecorder) DeleteIntegration(integration interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteIntegration", reflect.TypeOf((*MockIntegrationRepository)(nil).DeleteIntegration), integration)
+}
@@ -0,0 +1,108 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: matrix_room.go
+
+// Package mock_easyalert is a generated GoMock package.
+package mocks
+
+import (
+	easyalert "github.com/bakku/easyalert"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockMatrixRoomRepository is a mock of MatrixRoomRepository interface
+type MockMatrixRoomRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockMatrixRoomRepositoryMockRecorder
+}
+
+// MockMatrixRoomRepositoryMockRecorder is the mock recorder for MockMatrixRoomRepository
+type MockMatrixRoomRepositoryMockRecorder struct {
+	mock *MockMatrixRoomRepository
+}
+
+// NewMockMatrixRoomRepository creates a new mock instance
+func NewMockMatrixRoomRepository(ctrl *gomock.Controller) *MockMatrixRoomRepository {
+	mock := &MockMatrixRoomRepository{ctrl: ctrl}
+	mock.recorder = &MockMatrixRoomRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockMatrixRoomRepository) EXPECT() *MockMatrixRoomRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindMatrixRoom mocks base method
+func (m *MockMatrixRoomRepository) FindMatrixRoom(query string, params ...interface{}) (easyalert.MatrixRoom, error) {
+	varargs := []interface{}{query}
+	for _, a := range params {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "FindMatrixRoom", varargs...)
+	ret0, _ := ret[0].(easyalert.MatrixRoom)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindMatrixRoom indicates an expected call of FindMatrixRoom
+func (mr *MockMatrixRoomRepositoryMockRecorder) FindMatrixRoom(query interface{}, params ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{query}, params...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindMatrixRoom", reflect.TypeOf((*MockMatrixRoomRepository)(nil).FindMatrixRoom), varargs...)
+}
+
+// FindMatrixRooms mocks base method
+func (m *MockMatrixRoomRepository) FindMatrixRooms(query string, params ...interface{}) ([]easyalert.MatrixRoom, error) {
+	varargs := []interface{}{query}
+	for _, a := range params {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "FindMatrixRooms", varargs...)
+	ret0, _ := ret[0].([]easyalert.MatrixRoom)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindMatrixRooms indicates an expected call of FindMatrixRooms
+func (mr *MockMatrixRoomRepositoryMockRecorder) FindMatrixRooms(query interface{}, params ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{query}, params...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindMatrixRooms", reflect.TypeOf((*MockMatrixRoomRepository)(nil).FindMatrixRooms), varargs...)
+}
+
+// CreateMatrixRoom mocks base method
+func (m *MockMatrixRoomRepository) CreateMatrixRoom(room easyalert.MatrixRoom) (easyalert.MatrixRoom, error) {
+	ret := m.ctrl.Call(m, "CreateMatrixRoom", room)
+	ret0, _ := ret[0].(easyalert.MatrixRoom)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateMatrixRoom indicates an expected call of CreateMatrixRoom
+func (mr *MockMatrixRoomRepositoryMockRecorder) CreateMatrixRoom(room interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMatrixRoom", reflect.TypeOf((*MockMatrixRoomRepository)(nil).CreateMatrixRoom), room)
+}
+
+// UpdateMatrixRoom mocks base method
+func (m *MockMatrixRoomRepository) UpdateMatrixRoom(room easyalert.MatrixRoom) (easyalert.MatrixRoom, error) {
+	ret := m.ctrl.Call(m, "UpdateMatrixRoom", room)
+	ret0, _ := ret[0].(easyalert.MatrixRoom)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateMatrixRoom indicates an expected call of UpdateMatrixRoom
+func (mr *MockMatrixRoomRepositoryMockRecorder) UpdateMatrixRoom(room interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMatrixRoom", reflect.TypeOf((*MockMatrixRoomRepository)(nil).UpdateMatrixRoom), room)
+}
+
+// DeleteMatrixRoom mocks base method
+func (m *MockMatrixRoomRepository) DeleteMatrixRoom(room easyalert.MatrixRoom) error {
+	ret := m.ctrl.Call(m, "DeleteMatrixRoom", room)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteMatrixRoom indicates an expected call of DeleteMatrixRoom
+func (mr *MockMatrixRoomRepositoryMockRecorder) DeleteMatrixRoom(room interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMatrixRoom", reflect.TypeOf((*MockMatrixRoomRepository)(nil).DeleteMatrixRoom), room)
+}
@@ -0,0 +1,108 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: push_channel.go
+
+// Package mock_easyalert is a generated GoMock package.
+package mocks
+
+import (
+	easyalert "github.com/bakku/easyalert"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockPushChannelRepository is a mock of PushChannelRepository interface
+type MockPushChannelRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPushChannelRepositoryMockRecorder
+}
+
+// MockPushChannelRepositoryMockRecorder is the mock recorder for MockPushChannelRepository
+type MockPushChannelRepositoryMockRecorder struct {
+	mock *MockPushChannelRepository
+}
+
+// NewMockPushChannelRepository creates a new mock instance
+func NewMockPushChannelRepository(ctrl *gomock.Controller) *MockPushChannelRepository {
+	mock := &MockPushChannelRepository{ctrl: ctrl}
+	mock.recorder = &MockPushChannelRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockPushChannelRepository) EXPECT() *MockPushChannelRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindPushChannel mocks base method
+func (m *MockPushChannelRepository) FindPushChannel(query string, params ...interface{}) (easyalert.PushChannel, error) {
+	varargs := []interface{}{query}
+	for _, a := range params {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "FindPushChannel", varargs...)
+	ret0, _ := ret[0].(easyalert.PushChannel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPushChannel indicates an expected call of FindPushChannel
+func (mr *MockPushChannelRepositoryMockRecorder) FindPushChannel(query interface{}, params ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{query}, params...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPushChannel", reflect.TypeOf((*MockPushChannelRepository)(nil).FindPushChannel), varargs...)
+}
+
+// FindPushChannels mocks base method
+func (m *MockPushChannelRepository) FindPushChannels(query string, params ...interface{}) ([]easyalert.PushChannel, error) {
+	varargs := []interface{}{query}
+	for _, a := range params {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "FindPushChannels", varargs...)
+	ret0, _ := ret[0].([]easyalert.PushChannel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPushChannels indicates an expected call of FindPushChannels
+func (mr *MockPushChannelRepositoryMockRecorder) FindPushChannels(query interface{}, params ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{query}, params...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPushChannels", reflect.TypeOf((*MockPushChannelRepository)(nil).FindPushChannels), varargs...)
+}
+
+// CreatePushChannel mocks base method
+func (m *MockPushChannelRepository) CreatePushChannel(channel easyalert.PushChannel) (easyalert.PushChannel, error) {
+	ret := m.ctrl.Call(m, "CreatePushChannel", channel)
+	ret0, _ := ret[0].(easyalert.PushChannel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePushChannel indicates an expected call of CreatePushChannel
+func (mr *MockPushChannelRepositoryMockRecorder) CreatePushChannel(channel interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePushChannel", reflect.TypeOf((*MockPushChannelRepository)(nil).CreatePushChannel), channel)
+}
+
+// UpdatePushChannel mocks base method
+func (m *MockPushChannelRepository) UpdatePushChannel(channel easyalert.PushChannel) (easyalert.PushChannel, error) {
+	ret := m.ctrl.Call(m, "UpdatePushChannel", channel)
+	ret0, _ := ret[0].(easyalert.PushChannel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePushChannel indicates an expected call of UpdatePushChannel
+func (mr *MockPushChannelRepositoryMockRecorder) UpdatePushChannel(channel interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePushChannel", reflect.TypeOf((*MockPushChannelRepository)(nil).UpdatePushChannel), channel)
+}
+
+// DeletePushChannel mocks base method
+func (m *MockPushChannelRepository) DeletePushChannel(channel easyalert.PushChannel) error {
+	ret := m.ctrl.Call(m, "DeletePushChannel", channel)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePushChannel indicates an expected call of DeletePushChannel
+func (mr *MockPushChannelRepositoryMockRecorder) DeletePushChannel(channel interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePushChannel", reflect.TypeOf((*MockPushChannelRepository)(nil).DeletePushChannel), channel)
+}
@@ -0,0 +1,90 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: routing.go
+
+// Package mock_easyalert is a generated GoMock package.
+package mocks
+
+import (
+	easyalert "github.com/bakku/easyalert"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockRoutingRepository is a mock of RoutingRepository interface
+type MockRoutingRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRoutingRepositoryMockRecorder
+}
+
+// MockRoutingRepositoryMockRecorder is the mock recorder for MockRoutingRepository
+type MockRoutingRepositoryMockRecorder struct {
+	mock *MockRoutingRepository
+}
+
+// NewMockRoutingRepository creates a new mock instance
+func NewMockRoutingRepository(ctrl *gomock.Controller) *MockRoutingRepository {
+	mock := &MockRoutingRepository{ctrl: ctrl}
+	mock.recorder = &MockRoutingRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockRoutingRepository) EXPECT() *MockRoutingRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindRouting mocks base method
+func (m *MockRoutingRepository) FindRouting(query string, params ...interface{}) (easyalert.Routing, error) {
+	varargs := []interface{}{query}
+	for _, a := range params {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "FindRouting", varargs...)
+	ret0, _ := ret[0].(easyalert.Routing)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindRouting indicates an expected call of FindRouting
+func (mr *MockRoutingRepositoryMockRecorder) FindRouting(query interface{}, params ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{query}, params...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRouting", reflect.TypeOf((*MockRoutingRepository)(nil).FindRouting), varargs...)
+}
+
+// CreateRouting mocks base method
+func (m *MockRoutingRepository) CreateRouting(routing easyalert.Routing) (easyalert.Routing, error) {
+	ret := m.ctrl.Call(m, "CreateRouting", routing)
+	ret0, _ := ret[0].(easyalert.Routing)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRouting indicates an expected call of CreateRouting
+func (mr *MockRoutingRepositoryMockRecorder) CreateRouting(routing interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRouting", reflect.TypeOf((*MockRoutingRepository)(nil).CreateRouting), routing)
+}
+
+// UpdateRouting mocks base method
+func (m *MockRoutingRepository) UpdateRouting(routing easyalert.Routing) (easyalert.Routing, error) {
+	ret := m.ctrl.Call(m, "UpdateRouting", routing)
+	ret0, _ := ret[0].(easyalert.Routing)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateRouting indicates an expected call of UpdateRouting
+func (mr *MockRoutingRepositoryMockRecorder) UpdateRouting(routing interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRouting", reflect.TypeOf((*MockRoutingRepository)(nil).UpdateRouting), routing)
+}
+
+// DeleteRouting mocks base method
+func (m *MockRoutingRepository) DeleteRouting(routing easyalert.Routing) error {
+	ret := m.ctrl.Call(m, "DeleteRouting", routing)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRouting indicates an expected call of DeleteRouting
+func (mr *MockRoutingRepositoryMockRecorder) DeleteRouting(routing interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRouting", reflect.TypeOf((*MockRoutingRepository)(nil).DeleteRouting), routing)
+}
@@ -0,0 +1,121 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: syslog.go
+
+// Package mock_easyalert is a generated GoMock package.
+package mocks
+
+import (
+	easyalert "github.com/bakku/easyalert"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockSyslogSourceRepository is a mock of SyslogSourceRepository interface
+type MockSyslogSourceRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSyslogSourceRepositoryMockRecorder
+}
+
+// MockSyslogSourceRepositoryMockRecorder is the mock recorder for MockSyslogSourceRepository
+type MockSyslogSourceRepositoryMockRecorder struct {
+	mock *MockSyslogSourceRepository
+}
+
+// NewMockSyslogSourceRepository creates a new mock instance
+func NewMockSyslogSourceRepository(ctrl *gomock.Controller) *MockSyslogSourceRepository {
+	mock := &MockSyslogSourceRepository{ctrl: ctrl}
+	mock.recorder = &MockSyslogSourceRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockSyslogSourceRepository) EXPECT() *MockSyslogSourceRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindSyslogSource mocks base method
+func (m *MockSyslogSourceRepository) FindSyslogSource(query string, params ...interface{}) (easyalert.SyslogSource, error) {
+	varargs := []interface{}{query}
+	for _, a := range params {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "FindSyslogSource", varargs...)
+	ret0, _ := ret[0].(easyalert.SyslogSource)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindSyslogSource indicates an expected call of FindSyslogSource
+func (mr *MockSyslogSourceRepositoryMockRecorder) FindSyslogSource(query interface{}, params ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{query}, params...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSyslogSource", reflect.TypeOf((*MockSyslogSourceRepository)(nil).FindSyslogSource), varargs...)
+}
+
+// FindSyslogSources mocks base method
+func (m *MockSyslogSourceRepository) FindSyslogSources(query string, params ...interface{}) ([]easyalert.SyslogSource, error) {
+	varargs := []interface{}{query}
+	for _, a := range params {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "FindSyslogSources", varargs...)
+	ret0, _ := ret[0].([]easyalert.SyslogSource)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindSyslogSources indicates an expected call of FindSyslogSources
+func (mr *MockSyslogSourceRepositoryMockRecorder) FindSyslogSources(query interface{}, params ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{query}, params...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSyslogSources", reflect.TypeOf((*MockSyslogSourceRepository)(nil).FindSyslogSources), varargs...)
+}
+
+// CreateSyslogSource mocks base method
+func (m *MockSyslogSourceRepository) CreateSyslogSource(source easyalert.SyslogSource) (easyalert.SyslogSource, error) {
+	ret := m.ctrl.Call(m, "CreateSyslogSource", source)
+	ret0, _ := ret[0].(easyalert.SyslogSource)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSyslogSource indicates an expected call of CreateSyslogSource
+func (mr *MockSyslogSourceRepositoryMockRecorder) CreateSyslogSource(source interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSyslogSource", reflect.TypeOf((*MockSyslogSourceRepository)(nil).CreateSyslogSource), source)
+}
+
+// UpdateSyslogSource mocks base method
+func (m *MockSyslogSourceRepository) UpdateSyslogSource(source easyalert.SyslogSource) (easyalert.SyslogSource, error) {
+	ret := m.ctrl.Call(m, "UpdateSyslogSource", source)
+	ret0, _ := ret[0].(easyalert.SyslogSource)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateSyslogSource indicates an expected call of UpdateSyslogSource
+func (mr *MockSyslogSourceRepositoryMockRecorder) UpdateSyslogSource(source interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSyslogSource", reflect.TypeOf((*MockSyslogSourceRepository)(nil).UpdateSyslogSource), source)
+}
+
+// DeleteSyslogSource mocks base method
+func (m *MockSyslogSourceRepository) DeleteSyslogSource(source easyalert.SyslogSource) error {
+	ret := m.ctrl.Call(m, "DeleteSyslogSource", source)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSyslogSource indicates an expected call of DeleteSyslogSource
+func (mr *MockSyslogSourceRepositoryMockRecorder) DeleteSyslogSource(source interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSyslogSource", reflect.TypeOf((*MockSyslogSourceRepository)(nil).DeleteSyslogSource), source)
+}
+
+// VerifySyslogSource mocks base method
+func (m *MockSyslogSourceRepository) VerifySyslogSource(source easyalert.SyslogSource) (easyalert.SyslogSource, error) {
+	ret := m.ctrl.Call(m, "VerifySyslogSource", source)
+	ret0, _ := ret[0].(easyalert.SyslogSource)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifySyslogSource indicates an expected call of VerifySyslogSource
+func (mr *MockSyslogSourceRepositoryMockRecorder) VerifySyslogSource(source interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifySyslogSource", reflect.TypeOf((*MockSyslogSourceRepository)(nil).VerifySyslogSource), source)
+}
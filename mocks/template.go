@@ -0,0 +1,108 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: template.go
+
+// Package mock_easyalert is a generated GoMock package.
+package mocks
+
+import (
+	easyalert "github.com/bakku/easyalert"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockTemplateRepository is a mock of TemplateRepository interface
+type MockTemplateRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTemplateRepositoryMockRecorder
+}
+
+// MockTemplateRepositoryMockRecorder is the mock recorder for MockTemplateRepository
+type MockTemplateRepositoryMockRecorder struct {
+	mock *MockTemplateRepository
+}
+
+// NewMockTemplateRepository creates a new mock instance
+func NewMockTemplateRepository(ctrl *gomock.Controller) *MockTemplateRepository {
+	mock := &MockTemplateRepository{ctrl: ctrl}
+	mock.recorder = &MockTemplateRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockTemplateRepository) EXPECT() *MockTemplateRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindTemplate mocks base method
+func (m *MockTemplateRepository) FindTemplate(query string, params ...interface{}) (easyalert.Template, error) {
+	varargs := []interface{}{query}
+	for _, a := range params {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "FindTemplate", varargs...)
+	ret0, _ := ret[0].(easyalert.Template)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindTemplate indicates an expected call of FindTemplate
+func (mr *MockTemplateRepositoryMockRecorder) FindTemplate(query interface{}, params ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{query}, params...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindTemplate", reflect.TypeOf((*MockTemplateRepository)(nil).FindTemplate), varargs...)
+}
+
+// FindTemplates mocks base method
+func (m *MockTemplateRepository) FindTemplates(query string, params ...interface{}) ([]easyalert.Template, error) {
+	varargs := []interface{}{query}
+	for _, a := range params {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "FindTemplates", varargs...)
+	ret0, _ := ret[0].([]easyalert.Template)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindTemplates indicates an expected call of FindTemplates
+func (mr *MockTemplateRepositoryMockRecorder) FindTemplates(query interface{}, params ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{query}, params...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindTemplates", reflect.TypeOf((*MockTemplateRepository)(nil).FindTemplates), varargs...)
+}
+
+// CreateTemplate mocks base method
+func (m *MockTemplateRepository) CreateTemplate(tmpl easyalert.Template) (easyalert.Template, error) {
+	ret := m.ctrl.Call(m, "CreateTemplate", tmpl)
+	ret0, _ := ret[0].(easyalert.Template)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTemplate indicates an expected call of CreateTemplate
+func (mr *MockTemplateRepositoryMockRecorder) CreateTemplate(tmpl interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTemplate", reflect.TypeOf((*MockTemplateRepository)(nil).CreateTemplate), tmpl)
+}
+
+// UpdateTemplate mocks base method
+func (m *MockTemplateRepository) UpdateTemplate(tmpl easyalert.Template) (easyalert.Template, error) {
+	ret := m.ctrl.Call(m, "UpdateTemplate", tmpl)
+	ret0, _ := ret[0].(easyalert.Template)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateTemplate indicates an expected call of UpdateTemplate
+func (mr *MockTemplateRepositoryMockRecorder) UpdateTemplate(tmpl interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTemplate", reflect.TypeOf((*MockTemplateRepository)(nil).UpdateTemplate), tmpl)
+}
+
+// DeleteTemplate mocks base method
+func (m *MockTemplateRepository) DeleteTemplate(tmpl easyalert.Template) error {
+	ret := m.ctrl.Call(m, "DeleteTemplate", tmpl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTemplate indicates an expected call of DeleteTemplate
+func (mr *MockTemplateRepositoryMockRecorder) DeleteTemplate(tmpl interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTemplate", reflect.TypeOf((*MockTemplateRepository)(nil).DeleteTemplate), tmpl)
+}
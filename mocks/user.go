@@ -8,6 +8,7 @@ import (
 	easyalert "github.com/bakku/easyalert"
 	gomock "github.com/golang/mock/gomock"
 	reflect "reflect"
+	time "time"
 )
 
 // MockUserRepository is a mock of UserRepository interface
@@ -101,3 +102,29 @@ func (m *MockUserRepository) DeleteUser(user easyalert.User) error {
 func (mr *MockUserRepositoryMockRecorder) DeleteUser(user interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockUserRepository)(nil).DeleteUser), user)
 }
+
+// SavePhoneCode mocks base method
+func (m *MockUserRepository) SavePhoneCode(user easyalert.User, now time.Time) (easyalert.User, error) {
+	ret := m.ctrl.Call(m, "SavePhoneCode", user, now)
+	ret0, _ := ret[0].(easyalert.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SavePhoneCode indicates an expected call of SavePhoneCode
+func (mr *MockUserRepositoryMockRecorder) SavePhoneCode(user, now interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePhoneCode", reflect.TypeOf((*MockUserRepository)(nil).SavePhoneCode), user, now)
+}
+
+// CountPhoneCodeAttempt mocks base method
+func (m *MockUserRepository) CountPhoneCodeAttempt(user easyalert.User, now time.Time) (easyalert.User, error) {
+	ret := m.ctrl.Call(m, "CountPhoneCodeAttempt", user, now)
+	ret0, _ := ret[0].(easyalert.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountPhoneCodeAttempt indicates an expected call of CountPhoneCodeAttempt
+func (mr *MockUserRepositoryMockRecorder) CountPhoneCodeAttempt(user, now interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountPhoneCodeAttempt", reflect.TypeOf((*MockUserRepository)(nil).CountPhoneCodeAttempt), user, now)
+}
@@ -0,0 +1,162 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: webhook_endpoint.go
+
+// Package mock_easyalert is a generated GoMock package.
+package mocks
+
+import (
+	easyalert "github.com/bakku/easyalert"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockWebhookEndpointRepository is a mock of WebhookEndpointRepository interface
+type MockWebhookEndpointRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookEndpointRepositoryMockRecorder
+}
+
+// MockWebhookEndpointRepositoryMockRecorder is the mock recorder for MockWebhookEndpointRepository
+type MockWebhookEndpointRepositoryMockRecorder struct {
+	mock *MockWebhookEndpointRepository
+}
+
+// NewMockWebhookEndpointRepository creates a new mock instance
+func NewMockWebhookEndpointRepository(ctrl *gomock.Controller) *MockWebhookEndpointRepository {
+	mock := &MockWebhookEndpointRepository{ctrl: ctrl}
+	mock.recorder = &MockWebhookEndpointRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockWebhookEndpointRepository) EXPECT() *MockWebhookEndpointRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindWebhookEndpoint mocks base method
+func (m *MockWebhookEndpointRepository) FindWebhookEndpoint(query string, params ...interface{}) (easyalert.WebhookEndpoint, error) {
+	varargs := []interface{}{query}
+	for _, a := range params {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "FindWebhookEndpoint", varargs...)
+	ret0, _ := ret[0].(easyalert.WebhookEndpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindWebhookEndpoint indicates an expected call of FindWebhookEndpoint
+func (mr *MockWebhookEndpointRepositoryMockRecorder) FindWebhookEndpoint(query interface{}, params ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{query}, params...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindWebhookEndpoint", reflect.TypeOf((*MockWebhookEndpointRepository)(nil).FindWebhookEndpoint), varargs...)
+}
+
+// FindWebhookEndpoints mocks base method
+func (m *MockWebhookEndpointRepository) FindWebhookEndpoints(query string, params ...interface{}) ([]easyalert.WebhookEndpoint, error) {
+	varargs := []interface{}{query}
+	for _, a := range params {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "FindWebhookEndpoints", varargs...)
+	ret0, _ := ret[0].([]easyalert.WebhookEndpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindWebhookEndpoints indicates an expected call of FindWebhookEndpoints
+func (mr *MockWebhookEndpointRepositoryMockRecorder) FindWebhookEndpoints(query interface{}, params ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{query}, params...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindWebhookEndpoints", reflect.TypeOf((*MockWebhookEndpointRepository)(nil).FindWebhookEndpoints), varargs...)
+}
+
+// CreateWebhookEndpoint mocks base method
+func (m *MockWebhookEndpointRepository) CreateWebhookEndpoint(endpoint easyalert.WebhookEndpoint) (easyalert.WebhookEndpoint, error) {
+	ret := m.ctrl.Call(m, "CreateWebhookEndpoint", endpoint)
+	ret0, _ := ret[0].(easyalert.WebhookEndpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhookEndpoint indicates an expected call of CreateWebhookEndpoint
+func (mr *MockWebhookEndpointRepositoryMockRecorder) CreateWebhookEndpoint(endpoint interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhookEndpoint", reflect.TypeOf((*MockWebhookEndpointRepository)(nil).CreateWebhookEndpoint), endpoint)
+}
+
+// UpdateWebhookEndpoint mocks base method
+func (m *MockWebhookEndpointRepository) UpdateWebhookEndpoint(endpoint easyalert.WebhookEndpoint) (easyalert.WebhookEndpoint, error) {
+	ret := m.ctrl.Call(m, "UpdateWebhookEndpoint", endpoint)
+	ret0, _ := ret[0].(easyalert.WebhookEndpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateWebhookEndpoint indicates an expected call of UpdateWebhookEndpoint
+func (mr *MockWebhookEndpointRepositoryMockRecorder) UpdateWebhookEndpoint(endpoint interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebhookEndpoint", reflect.TypeOf((*MockWebhookEndpointRepository)(nil).UpdateWebhookEndpoint), endpoint)
+}
+
+// DeleteWebhookEndpoint mocks base method
+func (m *MockWebhookEndpointRepository) DeleteWebhookEndpoint(endpoint easyalert.WebhookEndpoint) error {
+	ret := m.ctrl.Call(m, "DeleteWebhookEndpoint", endpoint)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhookEndpoint indicates an expected call of DeleteWebhookEndpoint
+func (mr *MockWebhookEndpointRepositoryMockRecorder) DeleteWebhookEndpoint(endpoint interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhookEndpoint", reflect.TypeOf((*MockWebhookEndpointRepository)(nil).DeleteWebhookEndpoint), endpoint)
+}
+
+// MockWebhookDeliveryRepository is a mock of WebhookDeliveryRepository interface
+type MockWebhookDeliveryRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookDeliveryRepositoryMockRecorder
+}
+
+// MockWebhookDeliveryRepositoryMockRecorder is the mock recorder for MockWebhookDeliveryRepository
+type MockWebhookDeliveryRepositoryMockRecorder struct {
+	mock *MockWebhookDeliveryRepository
+}
+
+// NewMockWebhookDeliveryRepository creates a new mock instance
+func NewMockWebhookDeliveryRepository(ctrl *gomock.Controller) *MockWebhookDeliveryRepository {
+	mock := &MockWebhookDeliveryRepository{ctrl: ctrl}
+	mock.recorder = &MockWebhookDeliveryRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockWebhookDeliveryRepository) EXPECT() *MockWebhookDeliveryRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindWebhookDeliveries mocks base method
+func (m *MockWebhookDeliveryRepository) FindWebhookDeliveries(query string, params ...interface{}) ([]easyalert.WebhookDelivery, error) {
+	varargs := []interface{}{query}
+	for _, a := range params {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "FindWebhookDeliveries", varargs...)
+	ret0, _ := ret[0].([]easyalert.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindWebhookDeliveries indicates an expected call of FindWebhookDeliveries
+func (mr *MockWebhookDeliveryRepositoryMockRecorder) FindWebhookDeliveries(query interface{}, params ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{query}, params...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindWebhookDeliveries", reflect.TypeOf((*MockWebhookDeliveryRepository)(nil).FindWebhookDeliveries), varargs...)
+}
+
+// CreateWebhookDelivery mocks base method
+func (m *MockWebhookDeliveryRepository) CreateWebhookDelivery(delivery easyalert.WebhookDelivery) (easyalert.WebhookDelivery, error) {
+	ret := m.ctrl.Call(m, "CreateWebhookDelivery", delivery)
+	ret0, _ := ret[0].(easyalert.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhookDelivery indicates an expected call of CreateWebhookDelivery
+func (mr *MockWebhookDeliveryRepositoryMockRecorder) CreateWebhookDelivery(delivery interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhookDelivery", reflect.TypeOf((*MockWebhookDeliveryRepository)(nil).CreateWebhookDelivery), delivery)
+}
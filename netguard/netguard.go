@@ -0,0 +1,124 @@
+// Package netguard keeps requests to URLs given by users from reaching the
+// loopback, private and link-local networks of the server, e.g. cloud
+// metadata services or internal admin interfaces.
+package netguard
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// blockedNetworks are the networks which are not reachable from the public
+// internet.
+var blockedNetworks = parseNetworks(
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.0.0.0/24",
+	"192.168.0.0/16",
+	"198.18.0.0/15",
+	"224.0.0.0/4",
+	"240.0.0.0/4",
+	"::/128",
+	"::1/128",
+	"64:ff9b::/96",
+	"fc00::/7",
+	"fe80::/10",
+	"ff00::/8",
+)
+
+func parseNetworks(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, len(cidrs))
+
+	for i, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+
+		networks[i] = network
+	}
+
+	return networks
+}
+
+// PublicIP returns whether the IP is not part of a loopback, private,
+// link-local or otherwise reserved network.
+func PublicIP(ip net.IP) bool {
+	for _, network := range blockedNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// CheckHost returns an error if the host of a URL is an IP address which is
+// not public or names the local machine. Other host names can only be
+// checked when connecting, since they may resolve differently by then.
+func CheckHost(host string) error {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("host %s is not public", host)
+	}
+
+	if ip := net.ParseIP(host); ip != nil && !PublicIP(ip) {
+		return fmt.Errorf("host %s is not public", host)
+	}
+
+	return nil
+}
+
+// Control can be used as Control of a net.Dialer. It refuses to connect to
+// addresses which are not public after the host name was resolved.
+func Control(network, address string, c syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !PublicIP(ip) {
+		return fmt.Errorf("connecting to %s is not allowed", host)
+	}
+
+	return nil
+}
+
+// transport is shared by all clients, so that connections are reused. It
+// does not use proxies from the environment, as only the proxy would be
+// checked then.
+var transport = &http.Transport{
+	DialContext: (&net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   Control,
+	}).DialContext,
+	MaxIdleConns:          100,
+	IdleConnTimeout:       90 * time.Second,
+	TLSHandshakeTimeout:   10 * time.Second,
+	ExpectContinueTimeout: 1 * time.Second,
+}
+
+// NewClient returns an HTTP client with the timeout which only connects to
+// public addresses. Redirects are not followed, since they could point to
+// any address and turn a POST into a GET; the redirect response is returned
+// instead.
+func NewClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
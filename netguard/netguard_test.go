@@ -0,0 +1,61 @@
+package netguard_test
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bakku/easyalert/netguard"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublicIP(t *testing.T) {
+	tests := []struct {
+		ip     string
+		public bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"10.1.2.3", false},
+		{"172.20.0.1", false},
+		{"192.168.178.1", false},
+		{"169.254.169.254", false},
+		{"100.100.100.200", false},
+		{"0.0.0.0", false},
+		{"::1", false},
+		{"::ffff:127.0.0.1", false},
+		{"fd00:ec2::254", false},
+		{"fe80::1", false},
+	}
+
+	for _, test := range tests {
+		require.Equal(t, test.public, netguard.PublicIP(net.ParseIP(test.ip)), test.ip)
+	}
+}
+
+func TestCheckHost(t *testing.T) {
+	require.Nil(t, netguard.CheckHost("hooks.example.com"))
+	require.Nil(t, netguard.CheckHost("93.184.216.34"))
+
+	require.NotNil(t, netguard.CheckHost("localhost"))
+	require.NotNil(t, netguard.CheckHost("api.LOCALHOST."))
+	require.NotNil(t, netguard.CheckHost("169.254.169.254"))
+	require.NotNil(t, netguard.CheckHost("::1"))
+}
+
+func TestNewClient_ShouldNotConnectToLoopback(t *testing.T) {
+	requests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	_, err := netguard.NewClient(time.Second).Get(server.URL)
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "connecting to 127.0.0.1 is not allowed")
+	require.Equal(t, 0, requests)
+}
@@ -0,0 +1,55 @@
+package easyalert
+
+// Formats a message of a notification can be written in.
+const (
+	MessageFormatText     = "text"
+	MessageFormatMarkdown = "markdown"
+)
+
+// Notification is an alert together with its content. The content is
+// confidential, so a notification only lives in memory until it is delivered.
+type Notification struct {
+	Alert       Alert
+	Message     string
+	Format      string
+	Attachments []Attachment
+
+	// Selection limits the channels the notification is delivered through.
+	// It is decided by the routing of the user while delivering.
+	Selection Selection
+}
+
+// Attachment is a file which is sent together with an alert.
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// AttachmentSize returns the size of all attachments in bytes.
+func (n Notification) AttachmentSize() int64 {
+	var size int64
+
+	for _, a := range n.Attachments {
+		size += int64(len(a.Data))
+	}
+
+	return size
+}
+
+// Channel delivers a notification to a user, e.g. via email.
+type Channel interface {
+	Deliver(user User, n Notification) error
+}
+
+// SMSSender sends a text message to a phone number, e.g. through the HTTP
+// API of an SMS gateway.
+type SMSSender interface {
+	SendSMS(to, text string) error
+}
+
+// Notifier delivers notifications in the background and records the
+// outcome on their alerts.
+type Notifier interface {
+	Notify(user User, n Notification)
+}
@@ -2,10 +2,53 @@ package postgres
 
 import (
 	"database/sql"
+	"encoding/json"
 
 	"github.com/bakku/easyalert"
 )
 
+const alertColumns = `
+	id, subject, status, sent_at, user_id,
+	COALESCE(dedup_key, ''), occurrences, last_seen_at,
+	severity, labels, send_at, attachment_size, message, format,
+	created_at, updated_at
+`
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAlert(s scanner) (easyalert.Alert, error) {
+	var (
+		a      easyalert.Alert
+		labels []byte
+	)
+
+	err := s.Scan(&a.ID, &a.Subject, &a.Status, &a.SentAt, &a.UserID,
+		&a.DedupKey, &a.Occurrences, &a.LastSeenAt,
+		&a.Severity, &labels, &a.SendAt, &a.AttachmentSize, &a.Message, &a.Format,
+		&a.CreatedAt, &a.UpdatedAt)
+
+	if err != nil {
+		return easyalert.Alert{}, err
+	}
+
+	err = json.Unmarshal(labels, &a.Labels)
+
+	return a, err
+}
+
+// marshalLabels converts the labels into JSON which can be stored inside a JSONB column.
+func marshalLabels(labels map[string]string) (string, error) {
+	if labels == nil {
+		return "{}", nil
+	}
+
+	b, err := json.Marshal(labels)
+
+	return string(b), err
+}
+
 // AlertRepository is a postgres implementation of the AlertRepository interface
 type AlertRepository struct {
 	DB *sql.DB
@@ -13,16 +56,11 @@ type AlertRepository struct {
 
 // FindAlert fetches an alert using the query passed as a string and returns it. If the alert does not exist it will return easyalert.ErrRecordDoesNotExist.
 func (repo AlertRepository) FindAlert(query string, params ...interface{}) (easyalert.Alert, error) {
-	var alert easyalert.Alert
-
-	baseQuery := `
-		SELECT id, subject, status, sent_at, user_id, created_at, updated_at
-		FROM alerts
-	`
+	baseQuery := "SELECT " + alertColumns + " FROM alerts "
 
 	row := repo.DB.QueryRow(baseQuery+query, params...)
 
-	err := row.Scan(&alert.ID, &alert.Subject, &alert.Status, &alert.SentAt, &alert.UserID, &alert.CreatedAt, &alert.UpdatedAt)
+	alert, err := scanAlert(row)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -39,39 +77,45 @@ func (repo AlertRepository) FindAlert(query string, params ...interface{}) (easy
 func (repo AlertRepository) FindAlerts(query string, params ...interface{}) ([]easyalert.Alert, error) {
 	var alerts []easyalert.Alert
 
-	baseQuery := `
-		SELECT id, subject, status, sent_at, user_id, created_at, updated_at
-		FROM alerts
-	`
+	baseQuery := "SELECT " + alertColumns + " FROM alerts "
 
 	rows, err := repo.DB.Query(baseQuery+query, params...)
 
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
 	for rows.Next() {
-		var a easyalert.Alert
-
-		if err := rows.Scan(&a.ID, &a.Subject, &a.Status, &a.SentAt, &a.UserID, &a.CreatedAt, &a.UpdatedAt); err != nil {
+		a, err := scanAlert(rows)
+		if err != nil {
 			return nil, err
 		}
 
 		alerts = append(alerts, a)
 	}
 
-	return alerts, nil
+	return alerts, rows.Err()
 }
 
-// CreateAlert creates a new alert in the Postgres database and returns it with ID and created_at/updated_at filled.
+// CreateAlert creates a new alert in the Postgres database and returns it with ID, last_seen_at and created_at/updated_at filled.
 func (repo AlertRepository) CreateAlert(alert easyalert.Alert) (easyalert.Alert, error) {
+	labels, err := marshalLabels(alert.Labels)
+	if err != nil {
+		return easyalert.Alert{}, err
+	}
+
 	row := repo.DB.QueryRow(`
-		INSERT INTO alerts(subject, status, sent_at, user_id, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, NOW(), NOW())
-		RETURNING id, created_at, updated_at
-	`, alert.Subject, alert.Status, alert.SentAt, alert.UserID)
+		INSERT INTO alerts(subject, status, sent_at, user_id,
+			dedup_key, occurrences, last_seen_at, severity, labels,
+			send_at, attachment_size, message, format, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), GREATEST($6, 1), NOW(), $7, $8, $9, $10, $11, $12, NOW(), NOW())
+		RETURNING id, occurrences, last_seen_at, created_at, updated_at
+	`, alert.Subject, alert.Status, alert.SentAt, alert.UserID,
+		alert.DedupKey, alert.Occurrences, alert.Severity, labels,
+		alert.SendAt, alert.AttachmentSize, alert.Message, alert.Format)
 
-	err := row.Scan(&alert.ID, &alert.CreatedAt, &alert.UpdatedAt)
+	err = row.Scan(&alert.ID, &alert.Occurrences, &alert.LastSeenAt, &alert.CreatedAt, &alert.UpdatedAt)
 
 	if err != nil {
 		return easyalert.Alert{}, err
@@ -80,15 +124,144 @@ func (repo AlertRepository) CreateAlert(alert easyalert.Alert) (easyalert.Alert,
 	return alert, nil
 }
 
+// CreateAlerts creates all alerts inside one transaction and returns them with ID and created_at/updated_at filled.
+// If one alert can not be created none of them are.
+func (repo AlertRepository) CreateAlerts(alerts []easyalert.Alert) ([]easyalert.Alert, error) {
+	tx, err := repo.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO alerts(subject, status, sent_at, user_id,
+			dedup_key, occurrences, last_seen_at, severity, labels,
+			send_at, attachment_size, message, format, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), GREATEST($6, 1), NOW(), $7, $8, $9, $10, $11, $12, NOW(), NOW())
+		RETURNING id, occurrences, last_seen_at, created_at, updated_at
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	created := make([]easyalert.Alert, 0, len(alerts))
+
+	for _, alert := range alerts {
+		labels, err := marshalLabels(alert.Labels)
+		if err != nil {
+			return nil, err
+		}
+
+		row := stmt.QueryRow(alert.Subject, alert.Status, alert.SentAt, alert.UserID,
+			alert.DedupKey, alert.Occurrences, alert.Severity, labels,
+			alert.SendAt, alert.AttachmentSize, alert.Message, alert.Format)
+
+		err = row.Scan(&alert.ID, &alert.Occurrences, &alert.LastSeenAt, &alert.CreatedAt, &alert.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		created = append(created, alert)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
 // UpdateAlert updates an existing alert in the Postgres database and returns it with updated_at updated.
+// The occurrences and last_seen_at are left alone, they are only changed by FoldAlert.
 func (repo AlertRepository) UpdateAlert(alert easyalert.Alert) (easyalert.Alert, error) {
+	labels, err := marshalLabels(alert.Labels)
+	if err != nil {
+		return easyalert.Alert{}, err
+	}
+
 	row := repo.DB.QueryRow(`
 			UPDATE alerts
 			SET subject = $1, status = $2, sent_at = $3,
-			updated_at = NOW()
-			WHERE alerts.id = $4
+			severity = $4, labels = $5, send_at = $6,
+			message = $7, format = $8, updated_at = NOW()
+			WHERE alerts.id = $9
+			RETURNING updated_at
+		`, alert.Subject, alert.Status, alert.SentAt,
+		alert.Severity, labels, alert.SendAt, alert.Message, alert.Format, alert.ID)
+
+	err = row.Scan(&alert.UpdatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return easyalert.Alert{}, easyalert.ErrRecordDoesNotExist
+		}
+
+		return easyalert.Alert{}, err
+	}
+
+	return alert, nil
+}
+
+// FoldAlert increments the occurrences of the alert found by the query and
+// sets its last_seen_at to now. The alert is locked while it is selected, so
+// that it is checked against the query again if a concurrent statement
+// changed it. It returns easyalert.ErrRecordDoesNotExist if no alert is
+// found.
+func (repo AlertRepository) FoldAlert(query string, params ...interface{}) (easyalert.Alert, error) {
+	row := repo.DB.QueryRow(`
+			UPDATE alerts
+			SET occurrences = occurrences + 1, last_seen_at = NOW(), updated_at = NOW()
+			WHERE id = (SELECT id FROM alerts `+query+` FOR UPDATE)
+			RETURNING `+alertColumns, params...)
+
+	alert, err := scanAlert(row)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return easyalert.Alert{}, easyalert.ErrRecordDoesNotExist
+		}
+
+		return easyalert.Alert{}, err
+	}
+
+	return alert, nil
+}
+
+// UpdateAlertDelivery only sets the status and sent_at of the alert. A nil
+// sent_at keeps the stored one.
+func (repo AlertRepository) UpdateAlertDelivery(alert easyalert.Alert) (easyalert.Alert, error) {
+	row := repo.DB.QueryRow(`
+			UPDATE alerts
+			SET status = $1, sent_at = COALESCE($2, sent_at), updated_at = NOW()
+			WHERE alerts.id = $3
+			RETURNING sent_at, updated_at
+		`, alert.Status, alert.SentAt, alert.ID)
+
+	err := row.Scan(&alert.SentAt, &alert.UpdatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return easyalert.Alert{}, easyalert.ErrRecordDoesNotExist
+		}
+
+		return easyalert.Alert{}, err
+	}
+
+	return alert, nil
+}
+
+// UpdateAlertStatus sets the status and message of the alert if it still
+// has the status from. It returns easyalert.ErrRecordDoesNotExist if it does
+// not.
+func (repo AlertRepository) UpdateAlertStatus(alert easyalert.Alert, from uint) (easyalert.Alert, error) {
+	row := repo.DB.QueryRow(`
+			UPDATE alerts
+			SET status = $1, message = $2, format = $3, updated_at = NOW()
+			WHERE alerts.id = $4 AND status = $5
 			RETURNING updated_at
-		`, alert.Subject, alert.Status, alert.SentAt, alert.ID)
+		`, alert.Status, alert.Message, alert.Format, alert.ID, from)
 
 	err := row.Scan(&alert.UpdatedAt)
 
@@ -2,6 +2,7 @@ package postgres_test
 
 import (
 	"database/sql"
+	"sync"
 	"testing"
 	"time"
 
@@ -192,6 +193,9 @@ func TestCreateAlert_Success(t *testing.T) {
 	require.Equal(t, "pending", alert.HumanStatus())
 	require.Nil(t, alert.SentAt)
 	require.Equal(t, uint(1), alert.UserID)
+	require.Equal(t, "", alert.DedupKey)
+	require.Equal(t, uint(1), alert.Occurrences)
+	require.NotEqual(t, defaultTime, alert.LastSeenAt)
 	require.NotEqual(t, defaultTime, alert.CreatedAt)
 	require.NotEqual(t, defaultTime, alert.UpdatedAt)
 
@@ -202,6 +206,247 @@ func TestCreateAlert_Success(t *testing.T) {
 	require.True(t, exists)
 }
 
+func TestCreateAlerts_Success(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	repo := postgres.AlertRepository{DB: db}
+
+	alerts, err := repo.CreateAlerts([]easyalert.Alert{
+		{Subject: "First", UserID: 1},
+		{Subject: "Second", UserID: 1, Labels: map[string]string{"env": "prod"}},
+	})
+	require.Nil(t, err)
+
+	require.Len(t, alerts, 2)
+	require.NotEqual(t, uint(0), alerts[0].ID)
+	require.NotEqual(t, alerts[0].ID, alerts[1].ID)
+	require.Equal(t, "Second", alerts[1].Subject)
+
+	var count int
+
+	err = db.QueryRow("SELECT COUNT(*) FROM alerts WHERE user_id = $1", 1).Scan(&count)
+	require.Nil(t, err)
+	require.Equal(t, 2, count)
+}
+
+func TestCreateAlerts_ShouldCreateNoneIfOneFails(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	repo := postgres.AlertRepository{DB: db}
+
+	_, err = repo.CreateAlerts([]easyalert.Alert{
+		{Subject: "First", UserID: 1},
+		{Subject: "Second", UserID: 2},
+	})
+	require.NotNil(t, err)
+
+	var count int
+
+	err = db.QueryRow("SELECT COUNT(*) FROM alerts").Scan(&count)
+	require.Nil(t, err)
+	require.Equal(t, 0, count)
+}
+
+func TestCreateAlert_WithAttachmentSize(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	repo := postgres.AlertRepository{DB: db}
+
+	alert, err := repo.CreateAlert(easyalert.Alert{Subject: "Testing", UserID: 1, AttachmentSize: 1024})
+	require.Nil(t, err)
+
+	alert, err = repo.FindAlert("WHERE id = $1", alert.ID)
+	require.Nil(t, err)
+
+	require.Equal(t, int64(1024), alert.AttachmentSize)
+}
+
+func TestCreateAlert_WithDedupKey(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	repo := postgres.AlertRepository{DB: db}
+
+	alert := easyalert.Alert{Subject: "Testing", UserID: 1, DedupKey: "backup"}
+
+	alert, err = repo.CreateAlert(alert)
+	require.Nil(t, err)
+
+	found, err := repo.FindAlert("WHERE dedup_key = $1", "backup")
+	require.Nil(t, err)
+
+	require.Equal(t, alert.ID, found.ID)
+	require.Equal(t, "backup", found.DedupKey)
+	require.Equal(t, uint(1), found.Occurrences)
+}
+
+func TestCreateAlert_WithSeverityAndLabels(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	repo := postgres.AlertRepository{DB: db}
+
+	alert := easyalert.Alert{
+		Subject:  "Testing",
+		UserID:   1,
+		Severity: easyalert.AlertSeverityCritical,
+		Labels:   map[string]string{"env": "prod", "host": "db1"},
+	}
+
+	alert, err = repo.CreateAlert(alert)
+	require.Nil(t, err)
+
+	alerts, err := repo.FindAlerts("WHERE labels @> $1", `{"env":"prod"}`)
+	require.Nil(t, err)
+
+	require.Len(t, alerts, 1)
+	require.Equal(t, alert.ID, alerts[0].ID)
+	require.Equal(t, "critical", alerts[0].HumanSeverity())
+	require.Equal(t, map[string]string{"env": "prod", "host": "db1"}, alerts[0].Labels)
+}
+
+func TestFindAlerts_DueScheduledAlerts(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	repo := postgres.AlertRepository{DB: db}
+
+	now := time.Now().UTC()
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Hour)
+
+	due, err := repo.CreateAlert(easyalert.Alert{Subject: "Due", Status: easyalert.AlertStatusScheduled, SendAt: &past, UserID: 1, Message: "Disk *full*", Format: easyalert.MessageFormatMarkdown})
+	require.Nil(t, err)
+
+	_, err = repo.CreateAlert(easyalert.Alert{Subject: "Later", Status: easyalert.AlertStatusScheduled, SendAt: &future, UserID: 1})
+	require.Nil(t, err)
+
+	alerts, err := repo.FindAlerts("WHERE status = $1 AND send_at <= $2", easyalert.AlertStatusScheduled, now)
+	require.Nil(t, err)
+
+	require.Len(t, alerts, 1)
+	require.Equal(t, due.ID, alerts[0].ID)
+	require.NotNil(t, alerts[0].SendAt)
+	require.Equal(t, "Disk *full*", alerts[0].Message)
+	require.Equal(t, easyalert.MessageFormatMarkdown, alerts[0].Format)
+
+	// the message is cleared once the alert is released
+	_, err = repo.UpdateAlertStatus(easyalert.Alert{ID: due.ID, Status: easyalert.AlertStatusPending}, easyalert.AlertStatusScheduled)
+	require.Nil(t, err)
+
+	released, err := repo.FindAlert("WHERE id = $1", due.ID)
+	require.Nil(t, err)
+	require.Equal(t, "", released.Message)
+	require.Equal(t, "", released.Format)
+}
+
+func TestFoldAlert_CountsConcurrentFolds(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	createAlert(t, db, 1, "Test", 0, nil, 1)
+
+	repo := postgres.AlertRepository{DB: db}
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := repo.FoldAlert("WHERE id = $1", 1)
+			require.Nil(t, err)
+		}()
+	}
+
+	wg.Wait()
+
+	// a full update must not overwrite the counted occurrences
+	_, err = repo.UpdateAlert(easyalert.Alert{ID: 1, Subject: "Test", Occurrences: 1})
+	require.Nil(t, err)
+
+	alert, err := repo.FindAlert("WHERE id = $1", 1)
+	require.Nil(t, err)
+
+	require.Equal(t, uint(11), alert.Occurrences)
+	require.True(t, alert.LastSeenAt.After(alert.CreatedAt))
+}
+
+func TestFoldAlert_NotExists(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	repo := postgres.AlertRepository{DB: db}
+
+	_, err = repo.FoldAlert("WHERE id = $1", 1)
+	require.Equal(t, easyalert.ErrRecordDoesNotExist, err)
+}
+
+func TestUpdateAlertDelivery_OnlyUpdatesStatusAndSentAt(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	createAlert(t, db, 1, "Test", 0, nil, 1)
+
+	repo := postgres.AlertRepository{DB: db}
+
+	sentAt := time.Now().UTC().Truncate(time.Second)
+
+	_, err = repo.UpdateAlertDelivery(easyalert.Alert{ID: 1, Status: easyalert.AlertStatusSent, SentAt: &sentAt})
+	require.Nil(t, err)
+
+	// failing later keeps the time it was sent at
+	alert, err := repo.UpdateAlertDelivery(easyalert.Alert{ID: 1, Status: easyalert.AlertStatusFailed})
+	require.Nil(t, err)
+	require.True(t, sentAt.Equal(*alert.SentAt))
+
+	alert, err = repo.FindAlert("WHERE id = $1", 1)
+	require.Nil(t, err)
+
+	require.Equal(t, "Test", alert.Subject)
+	require.Equal(t, "failed", alert.HumanStatus())
+	require.Equal(t, uint(1), alert.Occurrences)
+}
+
 func TestUpdateAlert_Success(t *testing.T) {
 	db, err := setupDB()
 	require.Nil(t, err)
@@ -265,6 +510,38 @@ func TestUpdateAlert_NotExists(t *testing.T) {
 	require.Equal(t, easyalert.ErrRecordDoesNotExist, err)
 }
 
+func TestUpdateAlertStatus_OnlyUpdatesExpectedStatus(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	_, err = db.Exec(`
+		INSERT INTO alerts(id, subject, status,
+			sent_at, user_id, created_at, updated_at)
+		VALUES (1, 'Test', $1, NULL, 1, NOW(), NOW())
+	`, easyalert.AlertStatusCanceled)
+	require.Nil(t, err)
+
+	repo := postgres.AlertRepository{DB: db}
+
+	_, err = repo.UpdateAlertStatus(easyalert.Alert{ID: 1, Status: easyalert.AlertStatusPending}, easyalert.AlertStatusScheduled)
+	require.Equal(t, easyalert.ErrRecordDoesNotExist, err)
+
+	alert, err := repo.FindAlert("WHERE id = $1", 1)
+	require.Nil(t, err)
+	require.Equal(t, "canceled", alert.HumanStatus())
+
+	_, err = repo.UpdateAlertStatus(easyalert.Alert{ID: 1, Status: easyalert.AlertStatusPending}, easyalert.AlertStatusCanceled)
+	require.Nil(t, err)
+
+	alert, err = repo.FindAlert("WHERE id = $1", 1)
+	require.Nil(t, err)
+	require.Equal(t, "pending", alert.HumanStatus())
+}
+
 func TestDeleteAlert_Success(t *testing.T) {
 	db, err := setupDB()
 	require.Nil(t, err)
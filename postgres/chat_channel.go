@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"database/sql"
+
+	"github.com/bakku/easyalert"
+)
+
+const chatChannelColumns = `
+	id, name, kind, url, user_id, created_at, updated_at
+`
+
+func scanChatChannel(s scanner) (easyalert.ChatChannel, error) {
+	var c easyalert.ChatChannel
+
+	err := s.Scan(&c.ID, &c.Name, &c.Kind, &c.URL, &c.UserID, &c.CreatedAt, &c.UpdatedAt)
+
+	return c, err
+}
+
+// ChatChannelRepository is a postgres implementation of the ChatChannelRepository interface
+type ChatChannelRepository struct {
+	DB *sql.DB
+}
+
+// FindChatChannel fetches a chat channel using the query passed as a string and returns it. If the channel does not exist it will return easyalert.ErrRecordDoesNotExist.
+func (repo ChatChannelRepository) FindChatChannel(query string, params ...interface{}) (easyalert.ChatChannel, error) {
+	baseQuery := "SELECT " + chatChannelColumns + " FROM chat_channels "
+
+	row := repo.DB.QueryRow(baseQuery+query, params...)
+
+	channel, err := scanChatChannel(row)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return easyalert.ChatChannel{}, easyalert.ErrRecordDoesNotExist
+		}
+
+		return easyalert.ChatChannel{}, err
+	}
+
+	return channel, nil
+}
+
+// FindChatChannels fetches all chat channels based on the query and returns them.
+func (repo ChatChannelRepository) FindChatChannels(query string, params ...interface{}) ([]easyalert.ChatChannel, error) {
+	var channels []easyalert.ChatChannel
+
+	baseQuery := "SELECT " + chatChannelColumns + " FROM chat_channels "
+
+	rows, err := repo.DB.Query(baseQuery+query, params...)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		c, err := scanChatChannel(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		channels = append(channels, c)
+	}
+
+	return channels, rows.Err()
+}
+
+// CreateChatChannel creates a new chat channel in the Postgres database and returns it with ID and created_at/updated_at filled.
+func (repo ChatChannelRepository) CreateChatChannel(channel easyalert.ChatChannel) (easyalert.ChatChannel, error) {
+	row := repo.DB.QueryRow(`
+		INSERT INTO chat_channels(name, kind, url, user_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, NOW(), NOW())
+		RETURNING id, created_at, updated_at
+	`, channel.Name, channel.Kind, channel.URL, channel.UserID)
+
+	err := row.Scan(&channel.ID, &channel.CreatedAt, &channel.UpdatedAt)
+
+	if err != nil {
+		return easyalert.ChatChannel{}, err
+	}
+
+	return channel, nil
+}
+
+// UpdateChatChannel updates an existing chat channel in the Postgres database and returns it with updated_at updated.
+func (repo ChatChannelRepository) UpdateChatChannel(channel easyalert.ChatChannel) (easyalert.ChatChannel, error) {
+	row := repo.DB.QueryRow(`
+			UPDATE chat_channels
+			SET name = $1, kind = $2, url = $3, updated_at = NOW()
+			WHERE chat_channels.id = $4
+			RETURNING updated_at
+		`, channel.Name, channel.Kind, channel.URL, channel.ID)
+
+	err := row.Scan(&channel.UpdatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return easyalert.ChatChannel{}, easyalert.ErrRecordDoesNotExist
+		}
+
+		return easyalert.ChatChannel{}, err
+	}
+
+	return channel, nil
+}
+
+// DeleteChatChannel deletes the chat channel given as a parameter by using the ID.
+func (repo ChatChannelRepository) DeleteChatChannel(channel easyalert.ChatChannel) error {
+	_, err := repo.DB.Exec(`
+			DELETE FROM chat_channels
+			WHERE id = $1
+		`, channel.ID)
+
+	return err
+}
@@ -0,0 +1,75 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/bakku/easyalert"
+
+	"github.com/bakku/easyalert/postgres"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindChatChannel_NotExists(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	repo := postgres.ChatChannelRepository{DB: db}
+
+	_, err = repo.FindChatChannel("WHERE id = $1", 1)
+	require.Equal(t, easyalert.ErrRecordDoesNotExist, err)
+}
+
+func TestCreateChatChannel_Success(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	repo := postgres.ChatChannelRepository{DB: db}
+
+	created, err := repo.CreateChatChannel(easyalert.ChatChannel{Name: "ops", Kind: easyalert.ChatKindSlack, URL: "https://hooks.slack.com/services/T0/B0/X", UserID: 1})
+	require.Nil(t, err)
+
+	channels, err := repo.FindChatChannels("WHERE user_id = $1", 1)
+	require.Nil(t, err)
+
+	require.Len(t, channels, 1)
+	require.Equal(t, created.ID, channels[0].ID)
+	require.Equal(t, "slack", channels[0].Kind)
+}
+
+func TestUpdateChatChannel_NotExists(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	repo := postgres.ChatChannelRepository{DB: db}
+
+	_, err = repo.UpdateChatChannel(easyalert.ChatChannel{ID: 1})
+	require.Equal(t, easyalert.ErrRecordDoesNotExist, err)
+}
+
+func TestDeleteChatChannel_Success(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	repo := postgres.ChatChannelRepository{DB: db}
+
+	channel, err := repo.CreateChatChannel(easyalert.ChatChannel{Name: "ops", Kind: easyalert.ChatKindTeams, URL: "https://example.com", UserID: 1})
+	require.Nil(t, err)
+
+	err = repo.DeleteChatChannel(channel)
+	require.Nil(t, err)
+
+	_, err = repo.FindChatChannel("WHERE id = $1", channel.ID)
+	require.Equal(t, easyalert.ErrRecordDoesNotExist, err)
+}
@@ -0,0 +1,157 @@
+package postgres
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/bakku/easyalert"
+)
+
+const heartbeatColumns = `
+	id, name, interval_seconds, COALESCE(schedule, ''), timezone,
+	grace_seconds, status, last_ping_at, user_id, created_at, updated_at
+`
+
+func scanHeartbeat(s scanner) (easyalert.Heartbeat, error) {
+	var (
+		h           easyalert.Heartbeat
+		interval    int64
+		gracePeriod int64
+	)
+
+	err := s.Scan(&h.ID, &h.Name, &interval, &h.Schedule, &h.Timezone,
+		&gracePeriod, &h.Status, &h.LastPingAt, &h.UserID, &h.CreatedAt, &h.UpdatedAt)
+
+	h.Interval = time.Duration(interval) * time.Second
+	h.GracePeriod = time.Duration(gracePeriod) * time.Second
+
+	return h, err
+}
+
+// HeartbeatRepository is a postgres implementation of the HeartbeatRepository interface
+type HeartbeatRepository struct {
+	DB *sql.DB
+}
+
+// FindHeartbeat fetches a heartbeat using the query passed as a string and returns it. If the heartbeat does not exist it will return easyalert.ErrRecordDoesNotExist.
+func (repo HeartbeatRepository) FindHeartbeat(query string, params ...interface{}) (easyalert.Heartbeat, error) {
+	baseQuery := "SELECT " + heartbeatColumns + " FROM heartbeats "
+
+	row := repo.DB.QueryRow(baseQuery+query, params...)
+
+	heartbeat, err := scanHeartbeat(row)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return easyalert.Heartbeat{}, easyalert.ErrRecordDoesNotExist
+		}
+
+		return easyalert.Heartbeat{}, err
+	}
+
+	return heartbeat, nil
+}
+
+// FindHeartbeats fetches all heartbeats based on the query and returns them.
+func (repo HeartbeatRepository) FindHeartbeats(query string, params ...interface{}) ([]easyalert.Heartbeat, error) {
+	var heartbeats []easyalert.Heartbeat
+
+	baseQuery := "SELECT " + heartbeatColumns + " FROM heartbeats "
+
+	rows, err := repo.DB.Query(baseQuery+query, params...)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		h, err := scanHeartbeat(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		heartbeats = append(heartbeats, h)
+	}
+
+	return heartbeats, rows.Err()
+}
+
+// CreateHeartbeat creates a new heartbeat in the Postgres database and returns it with ID and created_at/updated_at filled.
+func (repo HeartbeatRepository) CreateHeartbeat(heartbeat easyalert.Heartbeat) (easyalert.Heartbeat, error) {
+	row := repo.DB.QueryRow(`
+		INSERT INTO heartbeats(name, interval_seconds, schedule, timezone,
+			grace_seconds, status, last_ping_at, user_id, created_at, updated_at)
+		VALUES ($1, $2, NULLIF($3, ''), COALESCE(NULLIF($4, ''), 'UTC'), $5, $6, $7, $8, NOW(), NOW())
+		RETURNING id, timezone, created_at, updated_at
+	`, heartbeat.Name, int64(heartbeat.Interval.Seconds()), heartbeat.Schedule, heartbeat.Timezone,
+		int64(heartbeat.GracePeriod.Seconds()), heartbeat.Status, heartbeat.LastPingAt, heartbeat.UserID)
+
+	err := row.Scan(&heartbeat.ID, &heartbeat.Timezone, &heartbeat.CreatedAt, &heartbeat.UpdatedAt)
+
+	if err != nil {
+		return easyalert.Heartbeat{}, err
+	}
+
+	return heartbeat, nil
+}
+
+// UpdateHeartbeat updates an existing heartbeat in the Postgres database and returns it with updated_at updated.
+func (repo HeartbeatRepository) UpdateHeartbeat(heartbeat easyalert.Heartbeat) (easyalert.Heartbeat, error) {
+	row := repo.DB.QueryRow(`
+			UPDATE heartbeats
+			SET name = $1, interval_seconds = $2, schedule = NULLIF($3, ''),
+			timezone = COALESCE(NULLIF($4, ''), 'UTC'), grace_seconds = $5,
+			status = $6, last_ping_at = $7, updated_at = NOW()
+			WHERE heartbeats.id = $8
+			RETURNING updated_at
+		`, heartbeat.Name, int64(heartbeat.Interval.Seconds()), heartbeat.Schedule, heartbeat.Timezone,
+		int64(heartbeat.GracePeriod.Seconds()), heartbeat.Status, heartbeat.LastPingAt, heartbeat.ID)
+
+	err := row.Scan(&heartbeat.UpdatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return easyalert.Heartbeat{}, easyalert.ErrRecordDoesNotExist
+		}
+
+		return easyalert.Heartbeat{}, err
+	}
+
+	return heartbeat, nil
+}
+
+// MarkHeartbeatDown sets the status of the heartbeat to down if it still has
+// the last ping of the given heartbeat. It returns easyalert.ErrRecordDoesNotExist if it does not.
+func (repo HeartbeatRepository) MarkHeartbeatDown(heartbeat easyalert.Heartbeat) (easyalert.Heartbeat, error) {
+	row := repo.DB.QueryRow(`
+			UPDATE heartbeats
+			SET status = $1, updated_at = NOW()
+			WHERE heartbeats.id = $2 AND status <> $1 AND last_ping_at IS NOT DISTINCT FROM $3
+			RETURNING updated_at
+		`, easyalert.HeartbeatStatusDown, heartbeat.ID, heartbeat.LastPingAt)
+
+	err := row.Scan(&heartbeat.UpdatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return easyalert.Heartbeat{}, easyalert.ErrRecordDoesNotExist
+		}
+
+		return easyalert.Heartbeat{}, err
+	}
+
+	heartbeat.Status = easyalert.HeartbeatStatusDown
+
+	return heartbeat, nil
+}
+
+// DeleteHeartbeat deletes the heartbeat given as a parameter by using the ID.
+func (repo HeartbeatRepository) DeleteHeartbeat(heartbeat easyalert.Heartbeat) error {
+	_, err := repo.DB.Exec(`
+			DELETE FROM heartbeats
+			WHERE id = $1
+		`, heartbeat.ID)
+
+	return err
+}
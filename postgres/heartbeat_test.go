@@ -0,0 +1,256 @@
+package postgres_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bakku/easyalert"
+
+	"github.com/bakku/easyalert/postgres"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindHeartbeat_Success(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	_, err = db.Exec(`
+		INSERT INTO heartbeats(id, name, interval_seconds, grace_seconds,
+			status, last_ping_at, user_id, created_at, updated_at)
+		VALUES (1, 'backup', 3600, 600, 1, NOW(), 1, NOW(), NOW())
+	`)
+	require.Nil(t, err)
+
+	repo := postgres.HeartbeatRepository{DB: db}
+
+	heartbeat, err := repo.FindHeartbeat("WHERE id = $1", 1)
+	require.Nil(t, err)
+
+	var defaultTime time.Time
+
+	require.Equal(t, uint(1), heartbeat.ID)
+	require.Equal(t, "backup", heartbeat.Name)
+	require.Equal(t, time.Hour, heartbeat.Interval)
+	require.Equal(t, 10*time.Minute, heartbeat.GracePeriod)
+	require.Equal(t, "up", heartbeat.HumanStatus())
+	require.NotNil(t, heartbeat.LastPingAt)
+	require.Equal(t, uint(1), heartbeat.UserID)
+	require.NotEqual(t, defaultTime, heartbeat.CreatedAt)
+	require.NotEqual(t, defaultTime, heartbeat.UpdatedAt)
+}
+
+func TestFindHeartbeat_NotExists(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	repo := postgres.HeartbeatRepository{DB: db}
+
+	_, err = repo.FindHeartbeat("WHERE id = $1", 1)
+	require.Equal(t, easyalert.ErrRecordDoesNotExist, err)
+}
+
+func TestFindHeartbeats_Success(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	repo := postgres.HeartbeatRepository{DB: db}
+
+	_, err = repo.CreateHeartbeat(easyalert.Heartbeat{Name: "backup", Interval: time.Hour, UserID: 1})
+	require.Nil(t, err)
+
+	_, err = repo.CreateHeartbeat(easyalert.Heartbeat{Name: "cleanup", Interval: time.Hour, UserID: 1})
+	require.Nil(t, err)
+
+	heartbeats, err := repo.FindHeartbeats("WHERE user_id = $1 ORDER BY id", 1)
+	require.Nil(t, err)
+
+	require.Len(t, heartbeats, 2)
+	require.Equal(t, "backup", heartbeats[0].Name)
+	require.Equal(t, "cleanup", heartbeats[1].Name)
+}
+
+func TestCreateHeartbeat_Success(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	repo := postgres.HeartbeatRepository{DB: db}
+
+	heartbeat := easyalert.Heartbeat{
+		Name:        "backup",
+		Interval:    24 * time.Hour,
+		GracePeriod: time.Hour,
+		UserID:      1,
+	}
+
+	heartbeat, err = repo.CreateHeartbeat(heartbeat)
+	require.Nil(t, err)
+
+	var defaultTime time.Time
+
+	require.NotEqual(t, uint(0), heartbeat.ID)
+	require.Equal(t, "new", heartbeat.HumanStatus())
+	require.Nil(t, heartbeat.LastPingAt)
+	require.NotEqual(t, defaultTime, heartbeat.CreatedAt)
+	require.NotEqual(t, defaultTime, heartbeat.UpdatedAt)
+
+	found, err := repo.FindHeartbeat("WHERE id = $1", heartbeat.ID)
+	require.Nil(t, err)
+
+	require.Equal(t, 24*time.Hour, found.Interval)
+	require.Equal(t, time.Hour, found.GracePeriod)
+}
+
+func TestCreateHeartbeat_WithSchedule(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	repo := postgres.HeartbeatRepository{DB: db}
+
+	heartbeat := easyalert.Heartbeat{
+		Name:     "backup",
+		Schedule: "0 2 * * *",
+		Timezone: "Europe/Berlin",
+		UserID:   1,
+	}
+
+	heartbeat, err = repo.CreateHeartbeat(heartbeat)
+	require.Nil(t, err)
+
+	found, err := repo.FindHeartbeat("WHERE id = $1", heartbeat.ID)
+	require.Nil(t, err)
+
+	require.Equal(t, "0 2 * * *", found.Schedule)
+	require.Equal(t, "Europe/Berlin", found.Timezone)
+	require.Equal(t, time.Duration(0), found.Interval)
+}
+
+func TestCreateHeartbeat_DefaultTimezone(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	repo := postgres.HeartbeatRepository{DB: db}
+
+	heartbeat, err := repo.CreateHeartbeat(easyalert.Heartbeat{Name: "backup", Interval: time.Hour, UserID: 1})
+	require.Nil(t, err)
+
+	require.Equal(t, "UTC", heartbeat.Timezone)
+	require.Equal(t, "", heartbeat.Schedule)
+}
+
+func TestUpdateHeartbeat_Success(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	repo := postgres.HeartbeatRepository{DB: db}
+
+	heartbeat, err := repo.CreateHeartbeat(easyalert.Heartbeat{Name: "backup", Interval: time.Hour, UserID: 1})
+	require.Nil(t, err)
+
+	heartbeat.Ping(time.Now().UTC())
+
+	updated, err := repo.UpdateHeartbeat(heartbeat)
+	require.Nil(t, err)
+	require.NotEqual(t, heartbeat.UpdatedAt, updated.UpdatedAt)
+
+	found, err := repo.FindHeartbeat("WHERE id = $1", heartbeat.ID)
+	require.Nil(t, err)
+
+	require.Equal(t, "up", found.HumanStatus())
+	require.NotNil(t, found.LastPingAt)
+}
+
+func TestUpdateHeartbeat_NotExists(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	repo := postgres.HeartbeatRepository{DB: db}
+
+	_, err = repo.UpdateHeartbeat(easyalert.Heartbeat{ID: 1})
+	require.Equal(t, easyalert.ErrRecordDoesNotExist, err)
+}
+
+func TestMarkHeartbeatDown_OnlyMarksHeartbeatsNotPingedInBetween(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	repo := postgres.HeartbeatRepository{DB: db}
+
+	heartbeat, err := repo.CreateHeartbeat(easyalert.Heartbeat{Name: "backup", Interval: time.Hour, UserID: 1})
+	require.Nil(t, err)
+
+	fetched, err := repo.FindHeartbeat("WHERE id = $1", heartbeat.ID)
+	require.Nil(t, err)
+
+	heartbeat.Ping(time.Now().UTC())
+
+	_, err = repo.UpdateHeartbeat(heartbeat)
+	require.Nil(t, err)
+
+	_, err = repo.MarkHeartbeatDown(fetched)
+	require.Equal(t, easyalert.ErrRecordDoesNotExist, err)
+
+	fetched, err = repo.FindHeartbeat("WHERE id = $1", heartbeat.ID)
+	require.Nil(t, err)
+
+	marked, err := repo.MarkHeartbeatDown(fetched)
+	require.Nil(t, err)
+	require.Equal(t, "down", marked.HumanStatus())
+
+	_, err = repo.MarkHeartbeatDown(fetched)
+	require.Equal(t, easyalert.ErrRecordDoesNotExist, err)
+}
+
+func TestDeleteHeartbeat_Success(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	repo := postgres.HeartbeatRepository{DB: db}
+
+	heartbeat, err := repo.CreateHeartbeat(easyalert.Heartbeat{Name: "backup", Interval: time.Hour, UserID: 1})
+	require.Nil(t, err)
+
+	err = repo.DeleteHeartbeat(heartbeat)
+	require.Nil(t, err)
+
+	var exists bool
+
+	err = db.QueryRow("SELECT EXISTS(SELECT 1 FROM heartbeats WHERE id = $1)", heartbeat.ID).Scan(&exists)
+	require.Nil(t, err)
+	require.False(t, exists)
+}
@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"database/sql"
+
+	"github.com/bakku/easyalert"
+)
+
+// IdempotencyKeyRepository is a postgres implementation of the IdempotencyKeyRepository interface
+type IdempotencyKeyRepository struct {
+	DB *sql.DB
+}
+
+// FindIdempotencyKey fetches an idempotency key using the query passed as a string and returns it. If the key does not exist it will return easyalert.ErrRecordDoesNotExist.
+func (repo IdempotencyKeyRepository) FindIdempotencyKey(query string, params ...interface{}) (easyalert.IdempotencyKey, error) {
+	var k easyalert.IdempotencyKey
+
+	baseQuery := `
+		SELECT id, key, request_hash, status_code, location, body, user_id, created_at
+		FROM idempotency_keys
+	`
+
+	row := repo.DB.QueryRow(baseQuery+query, params...)
+
+	err := row.Scan(&k.ID, &k.Key, &k.RequestHash, &k.StatusCode, &k.Location, &k.Body, &k.UserID, &k.CreatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return easyalert.IdempotencyKey{}, easyalert.ErrRecordDoesNotExist
+		}
+
+		return easyalert.IdempotencyKey{}, err
+	}
+
+	return k, nil
+}
+
+// CreateIdempotencyKey claims an idempotency key and returns it with ID and created_at filled.
+// An expired key of the same user or one whose request did not finish in time is replaced.
+// If the key is still valid it will return easyalert.ErrRecordAlreadyExists.
+func (repo IdempotencyKeyRepository) CreateIdempotencyKey(key easyalert.IdempotencyKey) (easyalert.IdempotencyKey, error) {
+	row := repo.DB.QueryRow(`
+		INSERT INTO idempotency_keys(key, request_hash, status_code, location, body, user_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		ON CONFLICT (user_id, key) DO UPDATE
+		SET request_hash = EXCLUDED.request_hash, status_code = EXCLUDED.status_code,
+		location = EXCLUDED.location, body = EXCLUDED.body, created_at = EXCLUDED.created_at
+		WHERE idempotency_keys.created_at <= NOW() - $7 * INTERVAL '1 second'
+		OR (idempotency_keys.status_code = 0 AND idempotency_keys.created_at <= NOW() - $8 * INTERVAL '1 second')
+		RETURNING id, created_at
+	`, key.Key, key.RequestHash, key.StatusCode, key.Location, key.Body, key.UserID,
+		int64(easyalert.IdempotencyKeyLifetime.Seconds()), int64(easyalert.IdempotencyKeyLockTimeout.Seconds()))
+
+	err := row.Scan(&key.ID, &key.CreatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return easyalert.IdempotencyKey{}, easyalert.ErrRecordAlreadyExists
+		}
+
+		return easyalert.IdempotencyKey{}, err
+	}
+
+	return key, nil
+}
+
+// UpdateIdempotencyKey stores the response of a claimed idempotency key and returns it.
+func (repo IdempotencyKeyRepository) UpdateIdempotencyKey(key easyalert.IdempotencyKey) (easyalert.IdempotencyKey, error) {
+	_, err := repo.DB.Exec(`
+		UPDATE idempotency_keys SET status_code = $1, location = $2, body = $3
+		WHERE id = $4
+	`, key.StatusCode, key.Location, key.Body, key.ID)
+
+	if err != nil {
+		return easyalert.IdempotencyKey{}, err
+	}
+
+	return key, nil
+}
+
+// DeleteExpiredIdempotencyKeys deletes all idempotency keys whose lifetime is over, so that
+// their responses are not kept forever.
+func (repo IdempotencyKeyRepository) DeleteExpiredIdempotencyKeys() error {
+	_, err := repo.DB.Exec(`
+		DELETE FROM idempotency_keys
+		WHERE created_at < NOW() - $1 * INTERVAL '1 second'
+	`, int64(easyalert.IdempotencyKeyLifetime.Seconds()))
+
+	return err
+}
+
+// DeleteIdempotencyKey deletes an idempotency key so that it can be used again.
+func (repo IdempotencyKeyRepository) DeleteIdempotencyKey(key easyalert.IdempotencyKey) error {
+	_, err := repo.DB.Exec("DELETE FROM idempotency_keys WHERE id = $1", key.ID)
+
+	return err
+}
@@ -0,0 +1,167 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/bakku/easyalert"
+
+	"github.com/bakku/easyalert/postgres"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindIdempotencyKey_NotExists(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	repo := postgres.IdempotencyKeyRepository{DB: db}
+
+	_, err = repo.FindIdempotencyKey("WHERE user_id = $1 AND key = $2", 1, "abc")
+	require.Equal(t, easyalert.ErrRecordDoesNotExist, err)
+}
+
+func TestCreateIdempotencyKey_Success(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	repo := postgres.IdempotencyKeyRepository{DB: db}
+
+	created, err := repo.CreateIdempotencyKey(easyalert.IdempotencyKey{
+		Key:         "abc",
+		RequestHash: "1234",
+		StatusCode:  201,
+		Location:    "/api/alerts/1",
+		Body:        "{}",
+		UserID:      1,
+	})
+	require.Nil(t, err)
+	require.NotEqual(t, uint(0), created.ID)
+
+	key, err := repo.FindIdempotencyKey("WHERE user_id = $1 AND key = $2", 1, "abc")
+	require.Nil(t, err)
+
+	require.Equal(t, created.ID, key.ID)
+	require.Equal(t, "1234", key.RequestHash)
+	require.Equal(t, 201, key.StatusCode)
+	require.Equal(t, "/api/alerts/1", key.Location)
+	require.Equal(t, "{}", key.Body)
+}
+
+func TestCreateIdempotencyKey_ShouldNotReplaceValidKey(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	repo := postgres.IdempotencyKeyRepository{DB: db}
+
+	_, err = repo.CreateIdempotencyKey(easyalert.IdempotencyKey{Key: "abc", RequestHash: "1", StatusCode: 201, Body: "{}", UserID: 1})
+	require.Nil(t, err)
+
+	_, err = repo.CreateIdempotencyKey(easyalert.IdempotencyKey{Key: "abc", RequestHash: "2", StatusCode: 201, Body: "{}", UserID: 1})
+	require.Equal(t, easyalert.ErrRecordAlreadyExists, err)
+}
+
+func TestCreateIdempotencyKey_ShouldReplaceUnfinishedKeyAfterLockTimeout(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	repo := postgres.IdempotencyKeyRepository{DB: db}
+
+	created, err := repo.CreateIdempotencyKey(easyalert.IdempotencyKey{Key: "abc", RequestHash: "1", UserID: 1})
+	require.Nil(t, err)
+
+	_, err = db.Exec("UPDATE idempotency_keys SET created_at = NOW() - INTERVAL '2 minutes' WHERE id = $1", created.ID)
+	require.Nil(t, err)
+
+	_, err = repo.CreateIdempotencyKey(easyalert.IdempotencyKey{Key: "abc", RequestHash: "2", UserID: 1})
+	require.Nil(t, err)
+}
+
+func TestUpdateIdempotencyKey_StoresResponse(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	repo := postgres.IdempotencyKeyRepository{DB: db}
+
+	created, err := repo.CreateIdempotencyKey(easyalert.IdempotencyKey{Key: "abc", RequestHash: "1", UserID: 1})
+	require.Nil(t, err)
+
+	created.StatusCode = 201
+	created.Location = "/api/alerts/1"
+	created.Body = "{}"
+
+	_, err = repo.UpdateIdempotencyKey(created)
+	require.Nil(t, err)
+
+	key, err := repo.FindIdempotencyKey("WHERE id = $1", created.ID)
+	require.Nil(t, err)
+
+	require.Equal(t, 201, key.StatusCode)
+	require.Equal(t, "/api/alerts/1", key.Location)
+	require.Equal(t, "{}", key.Body)
+}
+
+func TestDeleteIdempotencyKey_Success(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	repo := postgres.IdempotencyKeyRepository{DB: db}
+
+	created, err := repo.CreateIdempotencyKey(easyalert.IdempotencyKey{Key: "abc", RequestHash: "1", UserID: 1})
+	require.Nil(t, err)
+
+	err = repo.DeleteIdempotencyKey(created)
+	require.Nil(t, err)
+
+	_, err = repo.FindIdempotencyKey("WHERE id = $1", created.ID)
+	require.Equal(t, easyalert.ErrRecordDoesNotExist, err)
+}
+
+func TestDeleteExpiredIdempotencyKeys_KeepsValidKeys(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	repo := postgres.IdempotencyKeyRepository{DB: db}
+
+	expired, err := repo.CreateIdempotencyKey(easyalert.IdempotencyKey{Key: "old", RequestHash: "1", StatusCode: 201, Body: "{}", UserID: 1})
+	require.Nil(t, err)
+
+	_, err = db.Exec("UPDATE idempotency_keys SET created_at = NOW() - INTERVAL '25 hours' WHERE id = $1", expired.ID)
+	require.Nil(t, err)
+
+	valid, err := repo.CreateIdempotencyKey(easyalert.IdempotencyKey{Key: "new", RequestHash: "1", StatusCode: 201, Body: "{}", UserID: 1})
+	require.Nil(t, err)
+
+	err = repo.DeleteExpiredIdempotencyKeys()
+	require.Nil(t, err)
+
+	_, err = repo.FindIdempotencyKey("WHERE id = $1", expired.ID)
+	require.Equal(t, easyalert.ErrRecordDoesNotExist, err)
+
+	_, err = repo.FindIdempotencyKey("WHERE id = $1", valid.ID)
+	require.Nil(t, err)
+}
@@ -0,0 +1,124 @@
+package postgres
+
+import (
+	"database/sql"
+
+	"github.com/bakku/easyalert"
+)
+
+const integrationColumns = `
+	id, name, preset, subject_expr, message_expr, severity_expr, dedup_key_expr,
+	user_id, created_at, updated_at
+`
+
+func scanIntegration(s scanner) (easyalert.Integration, error) {
+	var i easyalert.Integration
+
+	err := s.Scan(
+		&i.ID, &i.Name, &i.Preset, &i.Mapping.Subject, &i.Mapping.Message, &i.Mapping.Severity, &i.Mapping.DedupKey,
+		&i.UserID, &i.CreatedAt, &i.UpdatedAt,
+	)
+
+	return i, err
+}
+
+// IntegrationRepository is a postgres implementation of the IntegrationRepository interface
+type IntegrationRepository struct {
+	DB *sql.DB
+}
+
+// FindIntegration fetches an integration using the query passed as a string and returns it. If the integration does not exist it will return easyalert.ErrRecordDoesNotExist.
+func (repo IntegrationRepository) FindIntegration(query string, params ...interface{}) (easyalert.Integration, error) {
+	baseQuery := "SELECT " + integrationColumns + " FROM integrations "
+
+	row := repo.DB.QueryRow(baseQuery+query, params...)
+
+	integration, err := scanIntegration(row)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return easyalert.Integration{}, easyalert.ErrRecordDoesNotExist
+		}
+
+		return easyalert.Integration{}, err
+	}
+
+	return integration, nil
+}
+
+// FindIntegrations fetches all integrations based on the query and returns them.
+func (repo IntegrationRepository) FindIntegrations(query string, params ...interface{}) ([]easyalert.Integration, error) {
+	var integrations []easyalert.Integration
+
+	baseQuery := "SELECT " + integrationColumns + " FROM integrations "
+
+	rows, err := repo.DB.Query(baseQuery+query, params...)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		i, err := scanIntegration(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		integrations = append(integrations, i)
+	}
+
+	return integrations, rows.Err()
+}
+
+// CreateIntegration creates a new integration in the Postgres database and returns it with ID and created_at/updated_at filled.
+func (repo IntegrationRepository) CreateIntegration(integration easyalert.Integration) (easyalert.Integration, error) {
+	row := repo.DB.QueryRow(`
+		INSERT INTO integrations(name, preset, subject_expr, message_expr, severity_expr, dedup_key_expr, user_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+		RETURNING id, created_at, updated_at
+	`, integration.Name, integration.Preset, integration.Mapping.Subject, integration.Mapping.Message,
+		integration.Mapping.Severity, integration.Mapping.DedupKey, integration.UserID)
+
+	err := row.Scan(&integration.ID, &integration.CreatedAt, &integration.UpdatedAt)
+
+	if err != nil {
+		return easyalert.Integration{}, err
+	}
+
+	return integration, nil
+}
+
+// UpdateIntegration updates an existing integration in the Postgres database and returns it with updated_at updated.
+func (repo IntegrationRepository) UpdateIntegration(integration easyalert.Integration) (easyalert.Integration, error) {
+	row := repo.DB.QueryRow(`
+			UPDATE integrations
+			SET name = $1, preset = $2, subject_expr = $3, message_expr = $4,
+			severity_expr = $5, dedup_key_expr = $6, updated_at = NOW()
+			WHERE integrations.id = $7
+			RETURNING updated_at
+		`, integration.Name, integration.Preset, integration.Mapping.Subject, integration.Mapping.Message,
+		integration.Mapping.Severity, integration.Mapping.DedupKey, integration.ID)
+
+	err := row.Scan(&integration.UpdatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return easyalert.Integration{}, easyalert.ErrRecordDoesNotExist
+		}
+
+		return easyalert.Integration{}, err
+	}
+
+	return integration, nil
+}
+
+// DeleteIntegration deletes the integration given as a parameter by using the ID.
+func (repo IntegrationRepository) DeleteIntegration(integration easyalert.Integration) error {
+	_, err := repo.DB.Exec(`
+			DELETE FROM integrations
+			WHERE id = $1
+		`, integration.ID)
+
+	return err
+}
@@ -0,0 +1,128 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/bakku/easyalert"
+
+	"github.com/bakku/easyalert/postgres"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindIntegration_NotExists(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	repo := postgres.IntegrationRepository{DB: db}
+
+	_, err = repo.FindIntegration("WHERE id = $1", 1)
+	require.Equal(t, easyalert.ErrRecordDoesNotExist, err)
+}
+
+func TestCreateIntegration_Success(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	repo := postgres.IntegrationRepository{DB: db}
+
+	created, err := repo.CreateIntegration(easyalert.Integration{
+		Name:    "grafana",
+		Preset:  "grafana",
+		Mapping: easyalert.FieldMapping{Subject: "$.title", DedupKey: "grafana:{$.groupKey}"},
+		UserID:  1,
+	})
+	require.Nil(t, err)
+
+	require.NotEqual(t, uint(0), created.ID)
+
+	integration, err := repo.FindIntegration("WHERE user_id = $1 AND name = $2", 1, "grafana")
+	require.Nil(t, err)
+
+	require.Equal(t, created.ID, integration.ID)
+	require.Equal(t, "grafana", integration.Preset)
+	require.Equal(t, easyalert.FieldMapping{Subject: "$.title", DedupKey: "grafana:{$.groupKey}"}, integration.Mapping)
+}
+
+func TestFindIntegrations_Success(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	repo := postgres.IntegrationRepository{DB: db}
+
+	_, err = repo.CreateIntegration(easyalert.Integration{Name: "grafana", Mapping: easyalert.FieldMapping{Subject: "$.title"}, UserID: 1})
+	require.Nil(t, err)
+
+	_, err = repo.CreateIntegration(easyalert.Integration{Name: "ci", Mapping: easyalert.FieldMapping{Subject: "$.job"}, UserID: 1})
+	require.Nil(t, err)
+
+	integrations, err := repo.FindIntegrations("WHERE user_id = $1 ORDER BY name", 1)
+	require.Nil(t, err)
+
+	require.Len(t, integrations, 2)
+	require.Equal(t, "ci", integrations[0].Name)
+}
+
+func TestUpdateIntegration_Success(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	repo := postgres.IntegrationRepository{DB: db}
+
+	integration, err := repo.CreateIntegration(easyalert.Integration{Name: "ci", Mapping: easyalert.FieldMapping{Subject: "$.job"}, UserID: 1})
+	require.Nil(t, err)
+
+	integration.Mapping.Severity = "$.level"
+
+	_, err = repo.UpdateIntegration(integration)
+	require.Nil(t, err)
+
+	integration, err = repo.FindIntegration("WHERE id = $1", integration.ID)
+	require.Nil(t, err)
+	require.Equal(t, "$.level", integration.Mapping.Severity)
+}
+
+func TestUpdateIntegration_NotExists(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	repo := postgres.IntegrationRepository{DB: db}
+
+	_, err = repo.UpdateIntegration(easyalert.Integration{ID: 1})
+	require.Equal(t, easyalert.ErrRecordDoesNotExist, err)
+}
+
+func TestDeleteIntegration_Success(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	repo := postgres.IntegrationRepository{DB: db}
+
+	integration, err := repo.CreateIntegration(easyalert.Integration{Name: "ci", Mapping: easyalert.FieldMapping{Subject: "$.job"}, UserID: 1})
+	require.Nil(t, err)
+
+	err = repo.DeleteIntegration(integration)
+	require.Nil(t, err)
+
+	_, err = repo.FindIntegration("WHERE id = $1", integration.ID)
+	require.Equal(t, easyalert.ErrRecordDoesNotExist, err)
+}
@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"database/sql"
+
+	"github.com/bakku/easyalert"
+)
+
+const matrixRoomColumns = `
+	id, name, homeserver_url, room_id, access_token, user_id, created_at, updated_at
+`
+
+func scanMatrixRoom(s scanner) (easyalert.MatrixRoom, error) {
+	var r easyalert.MatrixRoom
+
+	err := s.Scan(&r.ID, &r.Name, &r.HomeserverURL, &r.RoomID, &r.AccessToken, &r.UserID, &r.CreatedAt, &r.UpdatedAt)
+
+	return r, err
+}
+
+// MatrixRoomRepository is a postgres implementation of the MatrixRoomRepository interface
+type MatrixRoomRepository struct {
+	DB *sql.DB
+}
+
+// FindMatrixRoom fetches a Matrix room using the query passed as a string and returns it. If the room does not exist it will return easyalert.ErrRecordDoesNotExist.
+func (repo MatrixRoomRepository) FindMatrixRoom(query string, params ...interface{}) (easyalert.MatrixRoom, error) {
+	baseQuery := "SELECT " + matrixRoomColumns + " FROM matrix_rooms "
+
+	row := repo.DB.QueryRow(baseQuery+query, params...)
+
+	room, err := scanMatrixRoom(row)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return easyalert.MatrixRoom{}, easyalert.ErrRecordDoesNotExist
+		}
+
+		return easyalert.MatrixRoom{}, err
+	}
+
+	return room, nil
+}
+
+// FindMatrixRooms fetches all Matrix rooms based on the query and returns them.
+func (repo MatrixRoomRepository) FindMatrixRooms(query string, params ...interface{}) ([]easyalert.MatrixRoom, error) {
+	var rooms []easyalert.MatrixRoom
+
+	baseQuery := "SELECT " + matrixRoomColumns + " FROM matrix_rooms "
+
+	rows, err := repo.DB.Query(baseQuery+query, params...)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		r, err := scanMatrixRoom(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		rooms = append(rooms, r)
+	}
+
+	return rooms, rows.Err()
+}
+
+// CreateMatrixRoom creates a new Matrix room in the Postgres database and returns it with ID and created_at/updated_at filled.
+func (repo MatrixRoomRepository) CreateMatrixRoom(room easyalert.MatrixRoom) (easyalert.MatrixRoom, error) {
+	row := repo.DB.QueryRow(`
+		INSERT INTO matrix_rooms(name, homeserver_url, room_id, access_token, user_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+		RETURNING id, created_at, updated_at
+	`, room.Name, room.HomeserverURL, room.RoomID, room.AccessToken, room.UserID)
+
+	err := row.Scan(&room.ID, &room.CreatedAt, &room.UpdatedAt)
+
+	if err != nil {
+		return easyalert.MatrixRoom{}, err
+	}
+
+	return room, nil
+}
+
+// UpdateMatrixRoom updates an existing Matrix room in the Postgres database and returns it with updated_at updated.
+func (repo MatrixRoomRepository) UpdateMatrixRoom(room easyalert.MatrixRoom) (easyalert.MatrixRoom, error) {
+	row := repo.DB.QueryRow(`
+			UPDATE matrix_rooms
+			SET name = $1, homeserver_url = $2, room_id = $3, access_token = $4, updated_at = NOW()
+			WHERE matrix_rooms.id = $5
+			RETURNING updated_at
+		`, room.Name, room.HomeserverURL, room.RoomID, room.AccessToken, room.ID)
+
+	err := row.Scan(&room.UpdatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return easyalert.MatrixRoom{}, easyalert.ErrRecordDoesNotExist
+		}
+
+		return easyalert.MatrixRoom{}, err
+	}
+
+	return room, nil
+}
+
+// DeleteMatrixRoom deletes the Matrix room given as a parameter by using the ID.
+func (repo MatrixRoomRepository) DeleteMatrixRoom(room easyalert.MatrixRoom) error {
+	_, err := repo.DB.Exec(`
+			DELETE FROM matrix_rooms
+			WHERE id = $1
+		`, room.ID)
+
+	return err
+}
@@ -0,0 +1,80 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/bakku/easyalert"
+
+	"github.com/bakku/easyalert/postgres"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindMatrixRoom_NotExists(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	repo := postgres.MatrixRoomRepository{DB: db}
+
+	_, err = repo.FindMatrixRoom("WHERE id = $1", 1)
+	require.Equal(t, easyalert.ErrRecordDoesNotExist, err)
+}
+
+func TestCreateMatrixRoom_Success(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	repo := postgres.MatrixRoomRepository{DB: db}
+
+	created, err := repo.CreateMatrixRoom(easyalert.MatrixRoom{
+		Name:          "ops",
+		HomeserverURL: "https://matrix.example.org",
+		RoomID:        "!abc:example.org",
+		AccessToken:   "syt_123",
+		UserID:        1,
+	})
+	require.Nil(t, err)
+
+	room, err := repo.FindMatrixRoom("WHERE id = $1", created.ID)
+	require.Nil(t, err)
+
+	require.Equal(t, "!abc:example.org", room.RoomID)
+	require.Equal(t, "syt_123", room.AccessToken)
+}
+
+func TestUpdateMatrixRoom_NotExists(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	repo := postgres.MatrixRoomRepository{DB: db}
+
+	_, err = repo.UpdateMatrixRoom(easyalert.MatrixRoom{ID: 1})
+	require.Equal(t, easyalert.ErrRecordDoesNotExist, err)
+}
+
+func TestDeleteMatrixRoom_Success(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	repo := postgres.MatrixRoomRepository{DB: db}
+
+	room, err := repo.CreateMatrixRoom(easyalert.MatrixRoom{Name: "ops", HomeserverURL: "https://matrix.example.org", RoomID: "!abc:example.org", AccessToken: "x", UserID: 1})
+	require.Nil(t, err)
+
+	err = repo.DeleteMatrixRoom(room)
+	require.Nil(t, err)
+
+	_, err = repo.FindMatrixRoom("WHERE id = $1", room.ID)
+	require.Equal(t, easyalert.ErrRecordDoesNotExist, err)
+}
@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"database/sql"
+
+	"github.com/bakku/easyalert"
+)
+
+const pushChannelColumns = `
+	id, name, kind, server_url, topic, token, user_id, created_at, updated_at
+`
+
+func scanPushChannel(s scanner) (easyalert.PushChannel, error) {
+	var c easyalert.PushChannel
+
+	err := s.Scan(&c.ID, &c.Name, &c.Kind, &c.ServerURL, &c.Topic, &c.Token, &c.UserID, &c.CreatedAt, &c.UpdatedAt)
+
+	return c, err
+}
+
+// PushChannelRepository is a postgres implementation of the PushChannelRepository interface
+type PushChannelRepository struct {
+	DB *sql.DB
+}
+
+// FindPushChannel fetches a push channel using the query passed as a string and returns it. If the channel does not exist it will return easyalert.ErrRecordDoesNotExist.
+func (repo PushChannelRepository) FindPushChannel(query string, params ...interface{}) (easyalert.PushChannel, error) {
+	baseQuery := "SELECT " + pushChannelColumns + " FROM push_channels "
+
+	row := repo.DB.QueryRow(baseQuery+query, params...)
+
+	channel, err := scanPushChannel(row)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return easyalert.PushChannel{}, easyalert.ErrRecordDoesNotExist
+		}
+
+		return easyalert.PushChannel{}, err
+	}
+
+	return channel, nil
+}
+
+// FindPushChannels fetches all push channels based on the query and returns them.
+func (repo PushChannelRepository) FindPushChannels(query string, params ...interface{}) ([]easyalert.PushChannel, error) {
+	var channels []easyalert.PushChannel
+
+	baseQuery := "SELECT " + pushChannelColumns + " FROM push_channels "
+
+	rows, err := repo.DB.Query(baseQuery+query, params...)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		c, err := scanPushChannel(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		channels = append(channels, c)
+	}
+
+	return channels, rows.Err()
+}
+
+// CreatePushChannel creates a new push channel in the Postgres database and returns it with ID and created_at/updated_at filled.
+func (repo PushChannelRepository) CreatePushChannel(channel easyalert.PushChannel) (easyalert.PushChannel, error) {
+	row := repo.DB.QueryRow(`
+		INSERT INTO push_channels(name, kind, server_url, topic, token, user_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+		RETURNING id, created_at, updated_at
+	`, channel.Name, channel.Kind, channel.ServerURL, channel.Topic, channel.Token, channel.UserID)
+
+	err := row.Scan(&channel.ID, &channel.CreatedAt, &channel.UpdatedAt)
+
+	if err != nil {
+		return easyalert.PushChannel{}, err
+	}
+
+	return channel, nil
+}
+
+// UpdatePushChannel updates an existing push channel in the Postgres database and returns it with updated_at updated.
+func (repo PushChannelRepository) UpdatePushChannel(channel easyalert.PushChannel) (easyalert.PushChannel, error) {
+	row := repo.DB.QueryRow(`
+			UPDATE push_channels
+			SET name = $1, kind = $2, server_url = $3, topic = $4, token = $5, updated_at = NOW()
+			WHERE push_channels.id = $6
+			RETURNING updated_at
+		`, channel.Name, channel.Kind, channel.ServerURL, channel.Topic, channel.Token, channel.ID)
+
+	err := row.Scan(&channel.UpdatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return easyalert.PushChannel{}, easyalert.ErrRecordDoesNotExist
+		}
+
+		return easyalert.PushChannel{}, err
+	}
+
+	return channel, nil
+}
+
+// DeletePushChannel deletes the push channel given as a parameter by using the ID.
+func (repo PushChannelRepository) DeletePushChannel(channel easyalert.PushChannel) error {
+	_, err := repo.DB.Exec(`
+			DELETE FROM push_channels
+			WHERE id = $1
+		`, channel.ID)
+
+	return err
+}
@@ -0,0 +1,98 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/bakku/easyalert"
+
+	"github.com/bakku/easyalert/postgres"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindPushChannel_NotExists(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	repo := postgres.PushChannelRepository{DB: db}
+
+	_, err = repo.FindPushChannel("WHERE id = $1", 1)
+	require.Equal(t, easyalert.ErrRecordDoesNotExist, err)
+}
+
+func TestCreatePushChannel_Success(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	repo := postgres.PushChannelRepository{DB: db}
+
+	created, err := repo.CreatePushChannel(easyalert.PushChannel{Name: "phone", Kind: easyalert.PushKindNtfy, ServerURL: "https://ntfy.example.com", Topic: "alerts", UserID: 1})
+	require.Nil(t, err)
+
+	channel, err := repo.FindPushChannel("WHERE id = $1", created.ID)
+	require.Nil(t, err)
+
+	require.Equal(t, "https://ntfy.example.com", channel.ServerURL)
+	require.Equal(t, "alerts", channel.Topic)
+	require.Equal(t, "", channel.Token)
+}
+
+func TestUpdatePushChannel_Success(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	repo := postgres.PushChannelRepository{DB: db}
+
+	channel, err := repo.CreatePushChannel(easyalert.PushChannel{Name: "phone", Kind: easyalert.PushKindGotify, ServerURL: "https://gotify.example.com", Token: "old", UserID: 1})
+	require.Nil(t, err)
+
+	channel.Token = "new"
+
+	_, err = repo.UpdatePushChannel(channel)
+	require.Nil(t, err)
+
+	channel, err = repo.FindPushChannel("WHERE id = $1", channel.ID)
+	require.Nil(t, err)
+	require.Equal(t, "new", channel.Token)
+}
+
+func TestUpdatePushChannel_NotExists(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	repo := postgres.PushChannelRepository{DB: db}
+
+	_, err = repo.UpdatePushChannel(easyalert.PushChannel{ID: 1})
+	require.Equal(t, easyalert.ErrRecordDoesNotExist, err)
+}
+
+func TestDeletePushChannel_Success(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	repo := postgres.PushChannelRepository{DB: db}
+
+	channel, err := repo.CreatePushChannel(easyalert.PushChannel{Name: "phone", Kind: easyalert.PushKindNtfy, ServerURL: "https://ntfy.sh", Topic: "x", UserID: 1})
+	require.Nil(t, err)
+
+	err = repo.DeletePushChannel(channel)
+	require.Nil(t, err)
+
+	_, err = repo.FindPushChannel("WHERE id = $1", channel.ID)
+	require.Equal(t, easyalert.ErrRecordDoesNotExist, err)
+}
@@ -0,0 +1,180 @@
+package postgres
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/bakku/easyalert"
+)
+
+const routingColumns = `
+	id, rules, default_channels, user_id, created_at, updated_at
+`
+
+// routingRule is the JSON representation of a rule inside the rules column.
+type routingRule struct {
+	Name     string            `json:"name"`
+	Subject  string            `json:"subject,omitempty"`
+	Severity string            `json:"severity,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	From     string            `json:"from,omitempty"`
+	Until    string            `json:"until,omitempty"`
+	Timezone string            `json:"timezone,omitempty"`
+	Channels []string          `json:"channels"`
+}
+
+func scanRouting(s scanner) (easyalert.Routing, error) {
+	var (
+		routing         easyalert.Routing
+		rules           []byte
+		defaultChannels []byte
+	)
+
+	err := s.Scan(&routing.ID, &rules, &defaultChannels, &routing.UserID, &routing.CreatedAt, &routing.UpdatedAt)
+	if err != nil {
+		return easyalert.Routing{}, err
+	}
+
+	var stored []routingRule
+
+	err = json.Unmarshal(rules, &stored)
+	if err != nil {
+		return easyalert.Routing{}, err
+	}
+
+	for _, r := range stored {
+		routing.Rules = append(routing.Rules, easyalert.RoutingRule{
+			Name:     r.Name,
+			Subject:  r.Subject,
+			Severity: r.Severity,
+			Labels:   r.Labels,
+			From:     r.From,
+			Until:    r.Until,
+			Timezone: r.Timezone,
+			Channels: r.Channels,
+		})
+	}
+
+	err = json.Unmarshal(defaultChannels, &routing.DefaultChannels)
+	if err != nil {
+		return easyalert.Routing{}, err
+	}
+
+	return routing, nil
+}
+
+// marshalRouting converts the rules and default channels of the routing
+// into JSON which can be stored inside JSONB columns.
+func marshalRouting(routing easyalert.Routing) (string, string, error) {
+	stored := make([]routingRule, len(routing.Rules))
+
+	for i, r := range routing.Rules {
+		stored[i] = routingRule{
+			Name:     r.Name,
+			Subject:  r.Subject,
+			Severity: r.Severity,
+			Labels:   r.Labels,
+			From:     r.From,
+			Until:    r.Until,
+			Timezone: r.Timezone,
+			Channels: r.Channels,
+		}
+	}
+
+	rules, err := json.Marshal(stored)
+	if err != nil {
+		return "", "", err
+	}
+
+	defaultChannels := routing.DefaultChannels
+	if defaultChannels == nil {
+		defaultChannels = easyalert.Selection{}
+	}
+
+	channels, err := json.Marshal(defaultChannels)
+
+	return string(rules), string(channels), err
+}
+
+// RoutingRepository is a postgres implementation of the RoutingRepository interface
+type RoutingRepository struct {
+	DB *sql.DB
+}
+
+// FindRouting fetches a routing using the query passed as a string and returns it. If the routing does not exist it will return easyalert.ErrRecordDoesNotExist.
+func (repo RoutingRepository) FindRouting(query string, params ...interface{}) (easyalert.Routing, error) {
+	baseQuery := "SELECT " + routingColumns + " FROM routings "
+
+	row := repo.DB.QueryRow(baseQuery+query, params...)
+
+	routing, err := scanRouting(row)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return easyalert.Routing{}, easyalert.ErrRecordDoesNotExist
+		}
+
+		return easyalert.Routing{}, err
+	}
+
+	return routing, nil
+}
+
+// CreateRouting creates a new routing in the Postgres database and returns it with ID and created_at/updated_at filled.
+func (repo RoutingRepository) CreateRouting(routing easyalert.Routing) (easyalert.Routing, error) {
+	rules, defaultChannels, err := marshalRouting(routing)
+	if err != nil {
+		return easyalert.Routing{}, err
+	}
+
+	row := repo.DB.QueryRow(`
+		INSERT INTO routings(rules, default_channels, user_id, created_at, updated_at)
+		VALUES ($1, $2, $3, NOW(), NOW())
+		RETURNING id, created_at, updated_at
+	`, rules, defaultChannels, routing.UserID)
+
+	err = row.Scan(&routing.ID, &routing.CreatedAt, &routing.UpdatedAt)
+
+	if err != nil {
+		return easyalert.Routing{}, err
+	}
+
+	return routing, nil
+}
+
+// UpdateRouting updates an existing routing in the Postgres database and returns it with updated_at updated.
+func (repo RoutingRepository) UpdateRouting(routing easyalert.Routing) (easyalert.Routing, error) {
+	rules, defaultChannels, err := marshalRouting(routing)
+	if err != nil {
+		return easyalert.Routing{}, err
+	}
+
+	row := repo.DB.QueryRow(`
+			UPDATE routings
+			SET rules = $1, default_channels = $2, updated_at = NOW()
+			WHERE routings.id = $3
+			RETURNING updated_at
+		`, rules, defaultChannels, routing.ID)
+
+	err = row.Scan(&routing.UpdatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return easyalert.Routing{}, easyalert.ErrRecordDoesNotExist
+		}
+
+		return easyalert.Routing{}, err
+	}
+
+	return routing, nil
+}
+
+// DeleteRouting deletes the routing given as a parameter by using the ID.
+func (repo RoutingRepository) DeleteRouting(routing easyalert.Routing) error {
+	_, err := repo.DB.Exec(`
+			DELETE FROM routings
+			WHERE id = $1
+		`, routing.ID)
+
+	return err
+}
@@ -0,0 +1,94 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/bakku/easyalert"
+
+	"github.com/bakku/easyalert/postgres"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindRouting_NotExists(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	repo := postgres.RoutingRepository{DB: db}
+
+	_, err = repo.FindRouting("WHERE user_id = $1", 1)
+	require.Equal(t, easyalert.ErrRecordDoesNotExist, err)
+}
+
+func TestCreateRouting_Success(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	repo := postgres.RoutingRepository{DB: db}
+
+	rules := []easyalert.RoutingRule{
+		{Name: "night", Severity: "critical", From: "22:00", Until: "07:00", Timezone: "Europe/Berlin", Channels: easyalert.Selection{"sms"}},
+		{Name: "prod", Labels: map[string]string{"env": "^prod$"}, Channels: easyalert.Selection{"chat:ops", "email"}},
+	}
+
+	created, err := repo.CreateRouting(easyalert.Routing{Rules: rules, UserID: 1})
+	require.Nil(t, err)
+
+	require.NotEqual(t, uint(0), created.ID)
+
+	routing, err := repo.FindRouting("WHERE user_id = $1", 1)
+	require.Nil(t, err)
+
+	require.Equal(t, created.ID, routing.ID)
+	require.Equal(t, rules, routing.Rules)
+	require.Empty(t, routing.DefaultChannels)
+}
+
+func TestUpdateRouting_Success(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	repo := postgres.RoutingRepository{DB: db}
+
+	routing, err := repo.CreateRouting(easyalert.Routing{UserID: 1})
+	require.Nil(t, err)
+
+	routing.DefaultChannels = easyalert.Selection{"email", "push"}
+
+	_, err = repo.UpdateRouting(routing)
+	require.Nil(t, err)
+
+	routing, err = repo.FindRouting("WHERE user_id = $1", 1)
+	require.Nil(t, err)
+
+	require.Equal(t, easyalert.Selection{"email", "push"}, routing.DefaultChannels)
+}
+
+func TestDeleteRouting_Success(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	repo := postgres.RoutingRepository{DB: db}
+
+	routing, err := repo.CreateRouting(easyalert.Routing{UserID: 1})
+	require.Nil(t, err)
+
+	err = repo.DeleteRouting(routing)
+	require.Nil(t, err)
+
+	_, err = repo.FindRouting("WHERE user_id = $1", 1)
+	require.Equal(t, easyalert.ErrRecordDoesNotExist, err)
+}
@@ -0,0 +1,193 @@
+package postgres
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/bakku/easyalert"
+)
+
+const syslogSourceColumns = `
+	id, name, address, rules, verified, verification_token, user_id, created_at, updated_at
+`
+
+// syslogRule is the JSON representation of a rule inside the rules column.
+type syslogRule struct {
+	Facility string `json:"facility,omitempty"`
+	Severity string `json:"severity,omitempty"`
+	Pattern  string `json:"pattern,omitempty"`
+}
+
+func scanSyslogSource(s scanner) (easyalert.SyslogSource, error) {
+	var (
+		source easyalert.SyslogSource
+		rules  []byte
+	)
+
+	err := s.Scan(&source.ID, &source.Name, &source.Address, &rules, &source.Verified, &source.VerificationToken, &source.UserID, &source.CreatedAt, &source.UpdatedAt)
+	if err != nil {
+		return easyalert.SyslogSource{}, err
+	}
+
+	var stored []syslogRule
+
+	err = json.Unmarshal(rules, &stored)
+	if err != nil {
+		return easyalert.SyslogSource{}, err
+	}
+
+	for _, r := range stored {
+		source.Rules = append(source.Rules, easyalert.SyslogRule{Facility: r.Facility, Severity: r.Severity, Pattern: r.Pattern})
+	}
+
+	return source, nil
+}
+
+// marshalRules converts the rules into JSON which can be stored inside a JSONB column.
+func marshalRules(rules []easyalert.SyslogRule) (string, error) {
+	stored := make([]syslogRule, len(rules))
+
+	for i, r := range rules {
+		stored[i] = syslogRule{Facility: r.Facility, Severity: r.Severity, Pattern: r.Pattern}
+	}
+
+	b, err := json.Marshal(stored)
+
+	return string(b), err
+}
+
+// SyslogSourceRepository is a postgres implementation of the SyslogSourceRepository interface
+type SyslogSourceRepository struct {
+	DB *sql.DB
+}
+
+// FindSyslogSource fetches a syslog source using the query passed as a string and returns it. If the source does not exist it will return easyalert.ErrRecordDoesNotExist.
+func (repo SyslogSourceRepository) FindSyslogSource(query string, params ...interface{}) (easyalert.SyslogSource, error) {
+	baseQuery := "SELECT " + syslogSourceColumns + " FROM syslog_sources "
+
+	row := repo.DB.QueryRow(baseQuery+query, params...)
+
+	source, err := scanSyslogSource(row)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return easyalert.SyslogSource{}, easyalert.ErrRecordDoesNotExist
+		}
+
+		return easyalert.SyslogSource{}, err
+	}
+
+	return source, nil
+}
+
+// FindSyslogSources fetches all syslog sources based on the query and returns them.
+func (repo SyslogSourceRepository) FindSyslogSources(query string, params ...interface{}) ([]easyalert.SyslogSource, error) {
+	var sources []easyalert.SyslogSource
+
+	baseQuery := "SELECT " + syslogSourceColumns + " FROM syslog_sources "
+
+	rows, err := repo.DB.Query(baseQuery+query, params...)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		s, err := scanSyslogSource(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		sources = append(sources, s)
+	}
+
+	return sources, rows.Err()
+}
+
+// CreateSyslogSource creates a new syslog source in the Postgres database and returns it with ID and created_at/updated_at filled.
+func (repo SyslogSourceRepository) CreateSyslogSource(source easyalert.SyslogSource) (easyalert.SyslogSource, error) {
+	rules, err := marshalRules(source.Rules)
+	if err != nil {
+		return easyalert.SyslogSource{}, err
+	}
+
+	row := repo.DB.QueryRow(`
+		INSERT INTO syslog_sources(name, address, rules, verified, verification_token, user_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+		RETURNING id, created_at, updated_at
+	`, source.Name, source.Address, rules, source.Verified, source.VerificationToken, source.UserID)
+
+	err = row.Scan(&source.ID, &source.CreatedAt, &source.UpdatedAt)
+
+	if err != nil {
+		return easyalert.SyslogSource{}, err
+	}
+
+	return source, nil
+}
+
+// UpdateSyslogSource updates an existing syslog source in the Postgres database and returns it with updated_at updated.
+func (repo SyslogSourceRepository) UpdateSyslogSource(source easyalert.SyslogSource) (easyalert.SyslogSource, error) {
+	rules, err := marshalRules(source.Rules)
+	if err != nil {
+		return easyalert.SyslogSource{}, err
+	}
+
+	row := repo.DB.QueryRow(`
+			UPDATE syslog_sources
+			SET name = $1, address = $2, rules = $3, verified = $4, verification_token = $5, updated_at = NOW()
+			WHERE syslog_sources.id = $6
+			RETURNING updated_at
+		`, source.Name, source.Address, rules, source.Verified, source.VerificationToken, source.ID)
+
+	err = row.Scan(&source.UpdatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return easyalert.SyslogSource{}, easyalert.ErrRecordDoesNotExist
+		}
+
+		return easyalert.SyslogSource{}, err
+	}
+
+	return source, nil
+}
+
+// DeleteSyslogSource deletes the syslog source given as a parameter by using the ID.
+func (repo SyslogSourceRepository) DeleteSyslogSource(source easyalert.SyslogSource) error {
+	_, err := repo.DB.Exec(`
+			DELETE FROM syslog_sources
+			WHERE id = $1
+		`, source.ID)
+
+	return err
+}
+
+// VerifySyslogSource marks the syslog source as verified and removes its token. If the source is verified already or another source with the same address was verified before it will return easyalert.ErrRecordDoesNotExist.
+func (repo SyslogSourceRepository) VerifySyslogSource(source easyalert.SyslogSource) (easyalert.SyslogSource, error) {
+	// the unique index on verified addresses would reject a concurrent
+	// verification anyway, checking first avoids the constraint error
+	row := repo.DB.QueryRow(`
+			UPDATE syslog_sources
+			SET verified = true, verification_token = '', updated_at = NOW()
+			WHERE id = $1 AND NOT verified
+			AND NOT EXISTS (SELECT 1 FROM syslog_sources s WHERE s.address = syslog_sources.address AND s.verified)
+			RETURNING updated_at
+		`, source.ID)
+
+	err := row.Scan(&source.UpdatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return easyalert.SyslogSource{}, easyalert.ErrRecordDoesNotExist
+		}
+
+		return easyalert.SyslogSource{}, err
+	}
+
+	source.Verified = true
+	source.VerificationToken = ""
+
+	return source, nil
+}
@@ -0,0 +1,167 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/bakku/easyalert"
+
+	"github.com/bakku/easyalert/postgres"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindSyslogSource_NotExists(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	repo := postgres.SyslogSourceRepository{DB: db}
+
+	_, err = repo.FindSyslogSource("WHERE id = $1", 1)
+	require.Equal(t, easyalert.ErrRecordDoesNotExist, err)
+}
+
+func TestCreateSyslogSource_Success(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	repo := postgres.SyslogSourceRepository{DB: db}
+
+	created, err := repo.CreateSyslogSource(easyalert.SyslogSource{
+		Name:    "router",
+		Address: "192.0.2.1",
+		Rules:   []easyalert.SyslogRule{{Facility: "auth", Pattern: "Failed password"}, {Severity: "err"}},
+		UserID:  1,
+	})
+	require.Nil(t, err)
+
+	require.NotEqual(t, uint(0), created.ID)
+
+	source, err := repo.FindSyslogSource("WHERE address = $1", "192.0.2.1")
+	require.Nil(t, err)
+
+	require.Equal(t, created.ID, source.ID)
+	require.Equal(t, "router", source.Name)
+	require.Equal(t, []easyalert.SyslogRule{{Facility: "auth", Pattern: "Failed password"}, {Severity: "err"}}, source.Rules)
+}
+
+func TestFindSyslogSources_Success(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	repo := postgres.SyslogSourceRepository{DB: db}
+
+	_, err = repo.CreateSyslogSource(easyalert.SyslogSource{Name: "router", Address: "192.0.2.1", UserID: 1})
+	require.Nil(t, err)
+
+	_, err = repo.CreateSyslogSource(easyalert.SyslogSource{Name: "nas", Address: "192.0.2.2", UserID: 1})
+	require.Nil(t, err)
+
+	sources, err := repo.FindSyslogSources("WHERE user_id = $1 ORDER BY name", 1)
+	require.Nil(t, err)
+
+	require.Len(t, sources, 2)
+	require.Equal(t, "nas", sources[0].Name)
+}
+
+func TestUpdateSyslogSource_Success(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	repo := postgres.SyslogSourceRepository{DB: db}
+
+	source, err := repo.CreateSyslogSource(easyalert.SyslogSource{Name: "nas", Address: "192.0.2.2", UserID: 1})
+	require.Nil(t, err)
+
+	source.Rules = []easyalert.SyslogRule{{Severity: "crit"}}
+
+	_, err = repo.UpdateSyslogSource(source)
+	require.Nil(t, err)
+
+	source, err = repo.FindSyslogSource("WHERE id = $1", source.ID)
+	require.Nil(t, err)
+	require.Equal(t, []easyalert.SyslogRule{{Severity: "crit"}}, source.Rules)
+}
+
+func TestUpdateSyslogSource_NotExists(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	repo := postgres.SyslogSourceRepository{DB: db}
+
+	_, err = repo.UpdateSyslogSource(easyalert.SyslogSource{ID: 1})
+	require.Equal(t, easyalert.ErrRecordDoesNotExist, err)
+}
+
+func TestDeleteSyslogSource_Success(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	repo := postgres.SyslogSourceRepository{DB: db}
+
+	source, err := repo.CreateSyslogSource(easyalert.SyslogSource{Name: "nas", Address: "192.0.2.2", UserID: 1})
+	require.Nil(t, err)
+
+	err = repo.DeleteSyslogSource(source)
+	require.Nil(t, err)
+
+	_, err = repo.FindSyslogSource("WHERE id = $1", source.ID)
+	require.Equal(t, easyalert.ErrRecordDoesNotExist, err)
+}
+
+func TestVerifySyslogSource_OnlyVerifiesOneSourcePerAddress(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+	createUserWithId(t, db, 2)
+
+	repo := postgres.SyslogSourceRepository{DB: db}
+
+	// unverified sources of different users may claim the same address
+	first, err := repo.CreateSyslogSource(easyalert.SyslogSource{Name: "router", Address: "192.0.2.1", VerificationToken: "abc", UserID: 1})
+	require.Nil(t, err)
+
+	second, err := repo.CreateSyslogSource(easyalert.SyslogSource{Name: "router", Address: "192.0.2.1", VerificationToken: "def", UserID: 2})
+	require.Nil(t, err)
+
+	verified, err := repo.VerifySyslogSource(second)
+	require.Nil(t, err)
+	require.True(t, verified.Verified)
+	require.Equal(t, "", verified.VerificationToken)
+
+	_, err = repo.VerifySyslogSource(first)
+	require.Equal(t, easyalert.ErrRecordDoesNotExist, err)
+
+	_, err = repo.VerifySyslogSource(second)
+	require.Equal(t, easyalert.ErrRecordDoesNotExist, err)
+
+	first, err = repo.FindSyslogSource("WHERE id = $1", first.ID)
+	require.Nil(t, err)
+	require.False(t, first.Verified)
+
+	first.Verified = true
+
+	_, err = repo.UpdateSyslogSource(first)
+	require.NotNil(t, err)
+}
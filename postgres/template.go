@@ -0,0 +1,118 @@
+package postgres
+
+import (
+	"database/sql"
+
+	"github.com/bakku/easyalert"
+)
+
+const templateColumns = `
+	id, name, subject, body, format, user_id, created_at, updated_at
+`
+
+func scanTemplate(s scanner) (easyalert.Template, error) {
+	var t easyalert.Template
+
+	err := s.Scan(&t.ID, &t.Name, &t.Subject, &t.Body, &t.Format, &t.UserID, &t.CreatedAt, &t.UpdatedAt)
+
+	return t, err
+}
+
+// TemplateRepository is a postgres implementation of the TemplateRepository interface
+type TemplateRepository struct {
+	DB *sql.DB
+}
+
+// FindTemplate fetches a template using the query passed as a string and returns it. If the template does not exist it will return easyalert.ErrRecordDoesNotExist.
+func (repo TemplateRepository) FindTemplate(query string, params ...interface{}) (easyalert.Template, error) {
+	baseQuery := "SELECT " + templateColumns + " FROM templates "
+
+	row := repo.DB.QueryRow(baseQuery+query, params...)
+
+	tmpl, err := scanTemplate(row)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return easyalert.Template{}, easyalert.ErrRecordDoesNotExist
+		}
+
+		return easyalert.Template{}, err
+	}
+
+	return tmpl, nil
+}
+
+// FindTemplates fetches all templates based on the query and returns them.
+func (repo TemplateRepository) FindTemplates(query string, params ...interface{}) ([]easyalert.Template, error) {
+	var templates []easyalert.Template
+
+	baseQuery := "SELECT " + templateColumns + " FROM templates "
+
+	rows, err := repo.DB.Query(baseQuery+query, params...)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		t, err := scanTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		templates = append(templates, t)
+	}
+
+	return templates, rows.Err()
+}
+
+// CreateTemplate creates a new template in the Postgres database and returns it with ID and created_at/updated_at filled.
+func (repo TemplateRepository) CreateTemplate(tmpl easyalert.Template) (easyalert.Template, error) {
+	row := repo.DB.QueryRow(`
+		INSERT INTO templates(name, subject, body, format, user_id, created_at, updated_at)
+		VALUES ($1, $2, $3, COALESCE(NULLIF($4, ''), 'text'), $5, NOW(), NOW())
+		RETURNING id, format, created_at, updated_at
+	`, tmpl.Name, tmpl.Subject, tmpl.Body, tmpl.Format, tmpl.UserID)
+
+	err := row.Scan(&tmpl.ID, &tmpl.Format, &tmpl.CreatedAt, &tmpl.UpdatedAt)
+
+	if err != nil {
+		return easyalert.Template{}, err
+	}
+
+	return tmpl, nil
+}
+
+// UpdateTemplate updates an existing template in the Postgres database and returns it with updated_at updated.
+func (repo TemplateRepository) UpdateTemplate(tmpl easyalert.Template) (easyalert.Template, error) {
+	row := repo.DB.QueryRow(`
+			UPDATE templates
+			SET name = $1, subject = $2, body = $3,
+			format = COALESCE(NULLIF($4, ''), 'text'), updated_at = NOW()
+			WHERE templates.id = $5
+			RETURNING format, updated_at
+		`, tmpl.Name, tmpl.Subject, tmpl.Body, tmpl.Format, tmpl.ID)
+
+	err := row.Scan(&tmpl.Format, &tmpl.UpdatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return easyalert.Template{}, easyalert.ErrRecordDoesNotExist
+		}
+
+		return easyalert.Template{}, err
+	}
+
+	return tmpl, nil
+}
+
+// DeleteTemplate deletes the template given as a parameter by using the ID.
+func (repo TemplateRepository) DeleteTemplate(tmpl easyalert.Template) error {
+	_, err := repo.DB.Exec(`
+			DELETE FROM templates
+			WHERE id = $1
+		`, tmpl.ID)
+
+	return err
+}
@@ -0,0 +1,125 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/bakku/easyalert"
+
+	"github.com/bakku/easyalert/postgres"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindTemplate_NotExists(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	repo := postgres.TemplateRepository{DB: db}
+
+	_, err = repo.FindTemplate("WHERE id = $1", 1)
+	require.Equal(t, easyalert.ErrRecordDoesNotExist, err)
+}
+
+func TestCreateTemplate_Success(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	repo := postgres.TemplateRepository{DB: db}
+
+	created, err := repo.CreateTemplate(easyalert.Template{Name: "backup", Subject: "Backup of {{.host}} failed", Body: "{{.log}}", UserID: 1})
+	require.Nil(t, err)
+
+	require.NotEqual(t, uint(0), created.ID)
+	require.Equal(t, "text", created.Format)
+
+	tmpl, err := repo.FindTemplate("WHERE user_id = $1 AND name = $2", 1, "backup")
+	require.Nil(t, err)
+
+	require.Equal(t, created.ID, tmpl.ID)
+	require.Equal(t, "Backup of {{.host}} failed", tmpl.Subject)
+	require.Equal(t, "{{.log}}", tmpl.Body)
+}
+
+func TestFindTemplates_Success(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	repo := postgres.TemplateRepository{DB: db}
+
+	_, err = repo.CreateTemplate(easyalert.Template{Name: "backup", Subject: "-", Body: "-", UserID: 1})
+	require.Nil(t, err)
+
+	_, err = repo.CreateTemplate(easyalert.Template{Name: "cleanup", Subject: "-", Body: "-", Format: "markdown", UserID: 1})
+	require.Nil(t, err)
+
+	templates, err := repo.FindTemplates("WHERE user_id = $1 ORDER BY name", 1)
+	require.Nil(t, err)
+
+	require.Len(t, templates, 2)
+	require.Equal(t, "backup", templates[0].Name)
+	require.Equal(t, "markdown", templates[1].Format)
+}
+
+func TestUpdateTemplate_Success(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	repo := postgres.TemplateRepository{DB: db}
+
+	tmpl, err := repo.CreateTemplate(easyalert.Template{Name: "backup", Subject: "-", Body: "-", UserID: 1})
+	require.Nil(t, err)
+
+	tmpl.Subject = "Backup failed"
+
+	_, err = repo.UpdateTemplate(tmpl)
+	require.Nil(t, err)
+
+	tmpl, err = repo.FindTemplate("WHERE id = $1", tmpl.ID)
+	require.Nil(t, err)
+	require.Equal(t, "Backup failed", tmpl.Subject)
+}
+
+func TestUpdateTemplate_NotExists(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	repo := postgres.TemplateRepository{DB: db}
+
+	_, err = repo.UpdateTemplate(easyalert.Template{ID: 1})
+	require.Equal(t, easyalert.ErrRecordDoesNotExist, err)
+}
+
+func TestDeleteTemplate_Success(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	repo := postgres.TemplateRepository{DB: db}
+
+	tmpl, err := repo.CreateTemplate(easyalert.Template{Name: "backup", Subject: "-", Body: "-", UserID: 1})
+	require.Nil(t, err)
+
+	err = repo.DeleteTemplate(tmpl)
+	require.Nil(t, err)
+
+	_, err = repo.FindTemplate("WHERE id = $1", tmpl.ID)
+	require.Equal(t, easyalert.ErrRecordDoesNotExist, err)
+}
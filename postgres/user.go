@@ -3,6 +3,7 @@ package postgres
 import (
 	"database/sql"
 	"errors"
+	"time"
 
 	"github.com/bakku/easyalert"
 	"github.com/lib/pq"
@@ -18,12 +19,18 @@ func (repo UserRepository) FindUser(query string, params ...interface{}) (easyal
 	var user easyalert.User
 
 	baseQuery := `
-		SELECT id, email, password_digest, token, created_at, updated_at
+		SELECT id, email, password_digest, token, created_at, updated_at,
+			phone_number, phone_verified, phone_code_digest,
+			phone_code_expires_at, phone_code_attempts,
+			COALESCE(inbound_token, ''), phone_codes_sent, phone_codes_sent_since
 		FROM users
 	`
 	row := repo.DB.QueryRow(baseQuery+query, params...)
 
-	err := row.Scan(&user.ID, &user.Email, &user.PasswordDigest, &user.Token, &user.CreatedAt, &user.UpdatedAt)
+	err := row.Scan(&user.ID, &user.Email, &user.PasswordDigest, &user.Token, &user.CreatedAt, &user.UpdatedAt,
+		&user.PhoneNumber, &user.PhoneVerified, &user.PhoneCodeDigest,
+		&user.PhoneCodeExpiresAt, &user.PhoneCodeAttempts,
+		&user.InboundToken, &user.PhoneCodesSent, &user.PhoneCodesSentSince)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -65,17 +72,17 @@ func (repo UserRepository) FindUsers() ([]easyalert.User, error) {
 // CreateUser creates a user in the Postgres database and returns it with ID and created_at/updated_at filled.
 func (repo UserRepository) CreateUser(user easyalert.User) (easyalert.User, error) {
 	row := repo.DB.QueryRow(`
-			INSERT INTO users(email, password_digest, token, created_at, updated_at)
-			VALUES ($1, $2, $3, NOW(), NOW())
+			INSERT INTO users(email, password_digest, token, inbound_token, created_at, updated_at)
+			VALUES ($1, $2, $3, NULLIF($4, ''), NOW(), NOW())
 			RETURNING id, created_at, updated_at
-		`, user.Email, user.PasswordDigest, user.Token)
+		`, user.Email, user.PasswordDigest, user.Token, user.InboundToken)
 
 	err := row.Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
 
 	if err != nil {
 		if pqErr, ok := err.(*pq.Error); ok {
 			if pqErr.Constraint == "users_email_key" {
-				return easyalert.User{}, errors.New("Email is already taken.")
+				return easyalert.User{}, easyalert.ErrEmailTaken
 			}
 		}
 
@@ -90,10 +97,16 @@ func (repo UserRepository) UpdateUser(user easyalert.User) (easyalert.User, erro
 	row := repo.DB.QueryRow(`
 			UPDATE users
 			SET email = $1, password_digest = $2,
-				token = $3, updated_at = NOW()
-			WHERE users.id = $4
+				token = $3, phone_number = $4, phone_verified = $5,
+				phone_code_digest = $6, phone_code_expires_at = $7,
+				phone_code_attempts = $8, inbound_token = NULLIF($9, ''),
+				phone_codes_sent = $10, phone_codes_sent_since = $11,
+				updated_at = NOW()
+			WHERE users.id = $12
 			RETURNING updated_at
-		`, user.Email, user.PasswordDigest, user.Token, user.ID)
+		`, user.Email, user.PasswordDigest, user.Token, user.PhoneNumber, user.PhoneVerified,
+		user.PhoneCodeDigest, user.PhoneCodeExpiresAt, user.PhoneCodeAttempts, user.InboundToken,
+		user.PhoneCodesSent, user.PhoneCodesSentSince, user.ID)
 
 	err := row.Scan(&user.UpdatedAt)
 
@@ -108,6 +121,65 @@ func (repo UserRepository) UpdateUser(user easyalert.User) (easyalert.User, erro
 	return user, nil
 }
 
+// SavePhoneCode saves the phone number and code of the user and counts the
+// code towards the daily limit, unless a limit was reached. The limits are
+// part of the WHERE clause, so concurrent requests are checked against the
+// row updated by the first one.
+func (repo UserRepository) SavePhoneCode(user easyalert.User, now time.Time) (easyalert.User, error) {
+	row := repo.DB.QueryRow(`
+			UPDATE users
+			SET phone_number = $1, phone_verified = false,
+				phone_code_digest = $2, phone_code_expires_at = $3,
+				phone_code_attempts = 0,
+				phone_codes_sent = CASE WHEN phone_codes_sent_since > $4 THEN phone_codes_sent + 1 ELSE 1 END,
+				phone_codes_sent_since = CASE WHEN phone_codes_sent_since > $4 THEN phone_codes_sent_since ELSE $5 END,
+				updated_at = NOW()
+			WHERE users.id = $6
+				AND (phone_code_expires_at IS NULL OR phone_code_expires_at <= $7)
+				AND (phone_codes_sent_since IS NULL OR phone_codes_sent_since <= $4 OR phone_codes_sent < $8)
+			RETURNING phone_codes_sent, phone_codes_sent_since, updated_at
+		`, user.PhoneNumber, user.PhoneCodeDigest, user.PhoneCodeExpiresAt,
+		now.Add(-24*time.Hour), now, user.ID,
+		now.Add(easyalert.PhoneCodeValidity-easyalert.PhoneCodeResendInterval), easyalert.MaxPhoneCodesPerDay)
+
+	err := row.Scan(&user.PhoneCodesSent, &user.PhoneCodesSentSince, &user.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return easyalert.User{}, easyalert.ErrPhoneCodeLimit
+		}
+
+		return easyalert.User{}, err
+	}
+
+	user.PhoneVerified = false
+	user.PhoneCodeAttempts = 0
+
+	return user, nil
+}
+
+// CountPhoneCodeAttempt increments the attempts of the current code of the
+// user, unless it expired, was replaced or has no attempts left.
+func (repo UserRepository) CountPhoneCodeAttempt(user easyalert.User, now time.Time) (easyalert.User, error) {
+	row := repo.DB.QueryRow(`
+			UPDATE users
+			SET phone_code_attempts = phone_code_attempts + 1, updated_at = NOW()
+			WHERE users.id = $1 AND phone_code_digest <> '' AND phone_code_digest = $2
+				AND phone_code_expires_at > $3 AND phone_code_attempts < $4
+			RETURNING phone_code_attempts, updated_at
+		`, user.ID, user.PhoneCodeDigest, now, easyalert.MaxPhoneCodeAttempts)
+
+	err := row.Scan(&user.PhoneCodeAttempts, &user.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return easyalert.User{}, easyalert.ErrPhoneCodeLimit
+		}
+
+		return easyalert.User{}, err
+	}
+
+	return user, nil
+}
+
 // DeleteUser deletes a user and returns an error if one occurs.
 func (repo UserRepository) DeleteUser(user easyalert.User) error {
 	_, err := repo.DB.Exec(`
@@ -2,6 +2,8 @@ package postgres_test
 
 import (
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -209,6 +211,81 @@ func TestUpdateUser_Success(t *testing.T) {
 	require.Equal(t, user.UpdatedAt, newUser.UpdatedAt)
 }
 
+func TestUpdateUser_StoresPhoneNumber(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	_, err = db.Exec(`
+		INSERT INTO users(id, email, password_digest,
+			token, created_at, updated_at)
+		VALUES (1, 'test@mail.com', '1234',
+			'1234', NOW(), NOW())
+	`)
+	require.Nil(t, err)
+
+	repo := postgres.UserRepository{DB: db}
+
+	user, err := repo.FindUser("WHERE id = $1", 1)
+	require.Nil(t, err)
+	require.Equal(t, "", user.PhoneNumber)
+	require.Nil(t, user.PhoneCodeExpiresAt)
+
+	expiresAt := time.Date(2019, 2, 13, 18, 10, 0, 0, time.UTC)
+
+	user.PhoneNumber = "+4915112345678"
+	user.PhoneCodeDigest = "digest"
+	user.PhoneCodeExpiresAt = &expiresAt
+	user.PhoneCodeAttempts = 2
+
+	_, err = repo.UpdateUser(user)
+	require.Nil(t, err)
+
+	user, err = repo.FindUser("WHERE id = $1", 1)
+	require.Nil(t, err)
+
+	require.Equal(t, "+4915112345678", user.PhoneNumber)
+	require.False(t, user.PhoneVerified)
+	require.Equal(t, "digest", user.PhoneCodeDigest)
+	require.True(t, expiresAt.Equal(*user.PhoneCodeExpiresAt))
+	require.Equal(t, uint(2), user.PhoneCodeAttempts)
+}
+
+func TestUpdateUser_StoresInboundToken(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	_, err = db.Exec(`
+		INSERT INTO users(id, email, password_digest,
+			token, created_at, updated_at)
+		VALUES (1, 'test@mail.com', '1234',
+			'1234', NOW(), NOW())
+	`)
+	require.Nil(t, err)
+
+	repo := postgres.UserRepository{DB: db}
+
+	user, err := repo.FindUser("WHERE id = $1", 1)
+	require.Nil(t, err)
+	require.Equal(t, "", user.InboundToken)
+
+	user.InboundToken = "abcdef"
+
+	_, err = repo.UpdateUser(user)
+	require.Nil(t, err)
+
+	user, err = repo.FindUser("WHERE inbound_token = $1", "abcdef")
+	require.Nil(t, err)
+	require.Equal(t, uint(1), user.ID)
+
+	// the API token does not identify inbound emails
+	_, err = repo.FindUser("WHERE inbound_token = $1", "1234")
+	require.Equal(t, easyalert.ErrRecordDoesNotExist, err)
+}
+
 func TestUpdateUser_NotExists(t *testing.T) {
 	db, err := setupDB()
 	require.Nil(t, err)
@@ -275,3 +352,150 @@ func TestDeleteUser_NotExists(t *testing.T) {
 	err = repo.DeleteUser(user)
 	require.Nil(t, err)
 }
+
+func TestSavePhoneCode_ShouldCountCodesOnce(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	_, err = db.Exec(`
+		INSERT INTO users(id, email, password_digest,
+			token, created_at, updated_at)
+		VALUES (1, 'test@mail.com', '1234',
+			'1234', NOW(), NOW())
+	`)
+	require.Nil(t, err)
+
+	repo := postgres.UserRepository{DB: db}
+
+	user, err := repo.FindUser("WHERE id = $1", 1)
+	require.Nil(t, err)
+	require.Nil(t, user.PhoneCodesSentSince)
+
+	now := time.Now().UTC()
+
+	err = user.SetPhoneNumber("+4915112345678", "123456", now.Add(easyalert.PhoneCodeValidity))
+	require.Nil(t, err)
+
+	// concurrent requests read the same user, only one of them may send
+	// a code
+	var (
+		wg   sync.WaitGroup
+		sent int32
+	)
+
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := repo.SavePhoneCode(user, now)
+			if err == nil {
+				atomic.AddInt32(&sent, 1)
+				return
+			}
+
+			require.Equal(t, easyalert.ErrPhoneCodeLimit, err)
+		}()
+	}
+
+	wg.Wait()
+	require.Equal(t, int32(1), sent)
+
+	user, err = repo.FindUser("WHERE id = $1", 1)
+	require.Nil(t, err)
+	require.Equal(t, "+4915112345678", user.PhoneNumber)
+	require.Equal(t, uint(1), user.PhoneCodesSent)
+	require.NotNil(t, user.PhoneCodesSentSince)
+}
+
+func TestSavePhoneCode_ShouldLimitCodesPerDay(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	_, err = db.Exec(`
+		INSERT INTO users(id, email, password_digest,
+			token, created_at, updated_at, phone_codes_sent, phone_codes_sent_since)
+		VALUES (1, 'test@mail.com', '1234',
+			'1234', NOW(), NOW(), $1, $2)
+	`, easyalert.MaxPhoneCodesPerDay, time.Now().UTC().Add(-23*time.Hour))
+	require.Nil(t, err)
+
+	repo := postgres.UserRepository{DB: db}
+
+	user, err := repo.FindUser("WHERE id = $1", 1)
+	require.Nil(t, err)
+
+	now := time.Now().UTC()
+
+	err = user.SetPhoneNumber("+4915112345678", "123456", now.Add(easyalert.PhoneCodeValidity))
+	require.Nil(t, err)
+
+	_, err = repo.SavePhoneCode(user, now)
+	require.Equal(t, easyalert.ErrPhoneCodeLimit, err)
+
+	// a day after the first code the count starts again
+	user, err = repo.SavePhoneCode(user, now.Add(time.Hour))
+	require.Nil(t, err)
+	require.Equal(t, uint(1), user.PhoneCodesSent)
+}
+
+func TestCountPhoneCodeAttempt_ShouldLimitConcurrentAttempts(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	_, err = db.Exec(`
+		INSERT INTO users(id, email, password_digest,
+			token, created_at, updated_at)
+		VALUES (1, 'test@mail.com', '1234',
+			'1234', NOW(), NOW())
+	`)
+	require.Nil(t, err)
+
+	repo := postgres.UserRepository{DB: db}
+
+	user, err := repo.FindUser("WHERE id = $1", 1)
+	require.Nil(t, err)
+
+	now := time.Now().UTC()
+
+	err = user.SetPhoneNumber("+4915112345678", "123456", now.Add(easyalert.PhoneCodeValidity))
+	require.Nil(t, err)
+
+	user, err = repo.SavePhoneCode(user, now)
+	require.Nil(t, err)
+
+	var (
+		wg       sync.WaitGroup
+		attempts int32
+	)
+
+	for i := 0; i < 2*easyalert.MaxPhoneCodeAttempts; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := repo.CountPhoneCodeAttempt(user, now)
+			if err == nil {
+				atomic.AddInt32(&attempts, 1)
+				return
+			}
+
+			require.Equal(t, easyalert.ErrPhoneCodeLimit, err)
+		}()
+	}
+
+	wg.Wait()
+	require.Equal(t, int32(easyalert.MaxPhoneCodeAttempts), attempts)
+
+	user, err = repo.FindUser("WHERE id = $1", 1)
+	require.Nil(t, err)
+	require.Equal(t, uint(easyalert.MaxPhoneCodeAttempts), user.PhoneCodeAttempts)
+}
@@ -0,0 +1,196 @@
+package postgres
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/bakku/easyalert"
+)
+
+const webhookEndpointColumns = `
+	id, name, url, secret, timeout_seconds, max_retries, retry_backoff_seconds,
+	user_id, created_at, updated_at
+`
+
+func scanWebhookEndpoint(s scanner) (easyalert.WebhookEndpoint, error) {
+	var (
+		e       easyalert.WebhookEndpoint
+		timeout int64
+		backoff int64
+	)
+
+	err := s.Scan(&e.ID, &e.Name, &e.URL, &e.Secret, &timeout, &e.MaxRetries, &backoff,
+		&e.UserID, &e.CreatedAt, &e.UpdatedAt)
+
+	e.Timeout = time.Duration(timeout) * time.Second
+	e.RetryBackoff = time.Duration(backoff) * time.Second
+
+	return e, err
+}
+
+// WebhookEndpointRepository is a postgres implementation of the WebhookEndpointRepository interface
+type WebhookEndpointRepository struct {
+	DB *sql.DB
+}
+
+// FindWebhookEndpoint fetches a webhook endpoint using the query passed as a string and returns it. If the endpoint does not exist it will return easyalert.ErrRecordDoesNotExist.
+func (repo WebhookEndpointRepository) FindWebhookEndpoint(query string, params ...interface{}) (easyalert.WebhookEndpoint, error) {
+	baseQuery := "SELECT " + webhookEndpointColumns + " FROM webhook_endpoints "
+
+	row := repo.DB.QueryRow(baseQuery+query, params...)
+
+	endpoint, err := scanWebhookEndpoint(row)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return easyalert.WebhookEndpoint{}, easyalert.ErrRecordDoesNotExist
+		}
+
+		return easyalert.WebhookEndpoint{}, err
+	}
+
+	return endpoint, nil
+}
+
+// FindWebhookEndpoints fetches all webhook endpoints based on the query and returns them.
+func (repo WebhookEndpointRepository) FindWebhookEndpoints(query string, params ...interface{}) ([]easyalert.WebhookEndpoint, error) {
+	var endpoints []easyalert.WebhookEndpoint
+
+	baseQuery := "SELECT " + webhookEndpointColumns + " FROM webhook_endpoints "
+
+	rows, err := repo.DB.Query(baseQuery+query, params...)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		e, err := scanWebhookEndpoint(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		endpoints = append(endpoints, e)
+	}
+
+	return endpoints, rows.Err()
+}
+
+// CreateWebhookEndpoint creates a new webhook endpoint in the Postgres database and returns it with ID and created_at/updated_at filled.
+func (repo WebhookEndpointRepository) CreateWebhookEndpoint(endpoint easyalert.WebhookEndpoint) (easyalert.WebhookEndpoint, error) {
+	row := repo.DB.QueryRow(`
+		INSERT INTO webhook_endpoints(name, url, secret, timeout_seconds, max_retries, retry_backoff_seconds, user_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+		RETURNING id, created_at, updated_at
+	`, endpoint.Name, endpoint.URL, endpoint.Secret, int64(endpoint.Timeout/time.Second),
+		endpoint.MaxRetries, int64(endpoint.RetryBackoff/time.Second), endpoint.UserID)
+
+	err := row.Scan(&endpoint.ID, &endpoint.CreatedAt, &endpoint.UpdatedAt)
+
+	if err != nil {
+		return easyalert.WebhookEndpoint{}, err
+	}
+
+	return endpoint, nil
+}
+
+// UpdateWebhookEndpoint updates an existing webhook endpoint in the Postgres database and returns it with updated_at updated.
+func (repo WebhookEndpointRepository) UpdateWebhookEndpoint(endpoint easyalert.WebhookEndpoint) (easyalert.WebhookEndpoint, error) {
+	row := repo.DB.QueryRow(`
+			UPDATE webhook_endpoints
+			SET name = $1, url = $2, secret = $3, timeout_seconds = $4, max_retries = $5,
+				retry_backoff_seconds = $6, updated_at = NOW()
+			WHERE webhook_endpoints.id = $7
+			RETURNING updated_at
+		`, endpoint.Name, endpoint.URL, endpoint.Secret, int64(endpoint.Timeout/time.Second),
+		endpoint.MaxRetries, int64(endpoint.RetryBackoff/time.Second), endpoint.ID)
+
+	err := row.Scan(&endpoint.UpdatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return easyalert.WebhookEndpoint{}, easyalert.ErrRecordDoesNotExist
+		}
+
+		return easyalert.WebhookEndpoint{}, err
+	}
+
+	return endpoint, nil
+}
+
+// DeleteWebhookEndpoint deletes the webhook endpoint given as a parameter by using the ID. Its deliveries are deleted as well.
+func (repo WebhookEndpointRepository) DeleteWebhookEndpoint(endpoint easyalert.WebhookEndpoint) error {
+	_, err := repo.DB.Exec(`
+			DELETE FROM webhook_endpoints
+			WHERE id = $1
+		`, endpoint.ID)
+
+	return err
+}
+
+const webhookDeliveryColumns = `
+	id, endpoint_id, alert_id, attempt, status_code, latency_ms, response, error, created_at
+`
+
+func scanWebhookDelivery(s scanner) (easyalert.WebhookDelivery, error) {
+	var (
+		d       easyalert.WebhookDelivery
+		latency int64
+	)
+
+	err := s.Scan(&d.ID, &d.EndpointID, &d.AlertID, &d.Attempt, &d.StatusCode, &latency,
+		&d.Response, &d.Error, &d.CreatedAt)
+
+	d.Latency = time.Duration(latency) * time.Millisecond
+
+	return d, err
+}
+
+// WebhookDeliveryRepository is a postgres implementation of the WebhookDeliveryRepository interface
+type WebhookDeliveryRepository struct {
+	DB *sql.DB
+}
+
+// FindWebhookDeliveries fetches all webhook deliveries based on the query and returns them.
+func (repo WebhookDeliveryRepository) FindWebhookDeliveries(query string, params ...interface{}) ([]easyalert.WebhookDelivery, error) {
+	var deliveries []easyalert.WebhookDelivery
+
+	baseQuery := "SELECT " + webhookDeliveryColumns + " FROM webhook_deliveries "
+
+	rows, err := repo.DB.Query(baseQuery+query, params...)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// CreateWebhookDelivery creates a new webhook delivery in the Postgres database and returns it with ID and created_at filled.
+func (repo WebhookDeliveryRepository) CreateWebhookDelivery(delivery easyalert.WebhookDelivery) (easyalert.WebhookDelivery, error) {
+	row := repo.DB.QueryRow(`
+		INSERT INTO webhook_deliveries(endpoint_id, alert_id, attempt, status_code, latency_ms, response, error, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		RETURNING id, created_at
+	`, delivery.EndpointID, delivery.AlertID, delivery.Attempt, delivery.StatusCode,
+		int64(delivery.Latency/time.Millisecond), delivery.Response, delivery.Error)
+
+	err := row.Scan(&delivery.ID, &delivery.CreatedAt)
+
+	if err != nil {
+		return easyalert.WebhookDelivery{}, err
+	}
+
+	return delivery, nil
+}
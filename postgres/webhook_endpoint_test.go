@@ -0,0 +1,98 @@
+package postgres_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bakku/easyalert"
+
+	"github.com/bakku/easyalert/postgres"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindWebhookEndpoint_NotExists(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	repo := postgres.WebhookEndpointRepository{DB: db}
+
+	_, err = repo.FindWebhookEndpoint("WHERE id = $1", 1)
+	require.Equal(t, easyalert.ErrRecordDoesNotExist, err)
+}
+
+func TestCreateWebhookEndpoint_Success(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+
+	repo := postgres.WebhookEndpointRepository{DB: db}
+
+	created, err := repo.CreateWebhookEndpoint(easyalert.WebhookEndpoint{
+		Name:         "ops",
+		URL:          "https://example.com/hook",
+		Secret:       "secret",
+		Timeout:      10 * time.Second,
+		MaxRetries:   3,
+		RetryBackoff: 5 * time.Second,
+		UserID:       1,
+	})
+	require.Nil(t, err)
+
+	require.NotEqual(t, uint(0), created.ID)
+
+	endpoint, err := repo.FindWebhookEndpoint("WHERE id = $1", created.ID)
+	require.Nil(t, err)
+
+	require.Equal(t, "https://example.com/hook", endpoint.URL)
+	require.Equal(t, 10*time.Second, endpoint.Timeout)
+	require.Equal(t, uint(3), endpoint.MaxRetries)
+	require.Equal(t, 5*time.Second, endpoint.RetryBackoff)
+}
+
+func TestUpdateWebhookEndpoint_NotExists(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	repo := postgres.WebhookEndpointRepository{DB: db}
+
+	_, err = repo.UpdateWebhookEndpoint(easyalert.WebhookEndpoint{ID: 1})
+	require.Equal(t, easyalert.ErrRecordDoesNotExist, err)
+}
+
+func TestDeleteWebhookEndpoint_DeletesDeliveries(t *testing.T) {
+	db, err := setupDB()
+	require.Nil(t, err)
+
+	defer cleanDB(db)
+
+	createUserWithId(t, db, 1)
+	createAlert(t, db, 1, "Disk full", easyalert.AlertStatusPending, nil, 1)
+
+	repo := postgres.WebhookEndpointRepository{DB: db}
+	deliveryRepo := postgres.WebhookDeliveryRepository{DB: db}
+
+	endpoint, err := repo.CreateWebhookEndpoint(easyalert.WebhookEndpoint{Name: "ops", URL: "https://example.com/hook", UserID: 1})
+	require.Nil(t, err)
+
+	_, err = deliveryRepo.CreateWebhookDelivery(easyalert.WebhookDelivery{EndpointID: endpoint.ID, AlertID: 1, Attempt: 1, StatusCode: 200, Latency: 42 * time.Millisecond})
+	require.Nil(t, err)
+
+	deliveries, err := deliveryRepo.FindWebhookDeliveries("WHERE endpoint_id = $1", endpoint.ID)
+	require.Nil(t, err)
+	require.Len(t, deliveries, 1)
+	require.Equal(t, 42*time.Millisecond, deliveries[0].Latency)
+
+	err = repo.DeleteWebhookEndpoint(endpoint)
+	require.Nil(t, err)
+
+	deliveries, err = deliveryRepo.FindWebhookDeliveries("WHERE endpoint_id = $1", endpoint.ID)
+	require.Nil(t, err)
+	require.Len(t, deliveries, 0)
+}
@@ -0,0 +1,48 @@
+package push
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/bakku/easyalert"
+)
+
+type gotifyPayload struct {
+	Title    string                 `json:"title,omitempty"`
+	Message  string                 `json:"message"`
+	Priority int                    `json:"priority"`
+	Extras   map[string]interface{} `json:"extras,omitempty"`
+}
+
+// gotifyRequest creates a message of the application the token belongs to.
+// The Android app notifies with sound from priority 4 and pops up from
+// priority 8, so critical alerts get 8, warnings 5 and everything else 2.
+// Gotify has no tags, so labels are appended to the message.
+func gotifyRequest(c easyalert.PushChannel, n easyalert.Notification) (string, http.Header, interface{}) {
+	header := http.Header{}
+	header.Set("X-Gotify-Key", c.Token)
+
+	payload := gotifyPayload{}
+	payload.Title, payload.Message = content(n)
+
+	switch n.Alert.Severity {
+	case easyalert.AlertSeverityCritical:
+		payload.Priority = 8
+	case easyalert.AlertSeverityWarning:
+		payload.Priority = 5
+	default:
+		payload.Priority = 2
+	}
+
+	if l := labels(n.Alert); len(l) > 0 {
+		payload.Message += "\n\nLabels: " + strings.Join(l, ", ")
+	}
+
+	if n.Format == easyalert.MessageFormatMarkdown {
+		payload.Extras = map[string]interface{}{
+			"client::display": map[string]string{"contentType": "text/markdown"},
+		}
+	}
+
+	return endpoint(c.ServerURL, "/message"), header, payload
+}
@@ -0,0 +1,50 @@
+package push
+
+import (
+	"net/http"
+
+	"github.com/bakku/easyalert"
+)
+
+type ntfyPayload struct {
+	Topic    string   `json:"topic"`
+	Title    string   `json:"title,omitempty"`
+	Message  string   `json:"message"`
+	Priority int      `json:"priority"`
+	Tags     []string `json:"tags"`
+	Markdown bool     `json:"markdown,omitempty"`
+}
+
+// ntfyRequest publishes to the root of the server, which accepts the topic
+// as part of a JSON body. Severities map onto the priorities urgent, high and
+// default. The first tag is shown as emoji, the labels as further tags.
+func ntfyRequest(c easyalert.PushChannel, n easyalert.Notification) (string, http.Header, interface{}) {
+	header := http.Header{}
+
+	if c.Token != "" {
+		header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	payload := ntfyPayload{
+		Topic:    c.Topic,
+		Markdown: n.Format == easyalert.MessageFormatMarkdown,
+	}
+
+	payload.Title, payload.Message = content(n)
+
+	switch n.Alert.Severity {
+	case easyalert.AlertSeverityCritical:
+		payload.Priority = 5
+		payload.Tags = []string{"rotating_light"}
+	case easyalert.AlertSeverityWarning:
+		payload.Priority = 4
+		payload.Tags = []string{"warning"}
+	default:
+		payload.Priority = 3
+		payload.Tags = []string{"information_source"}
+	}
+
+	payload.Tags = append(payload.Tags, labels(n.Alert)...)
+
+	return endpoint(c.ServerURL, "/"), header, payload
+}
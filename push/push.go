@@ -0,0 +1,93 @@
+// Package push builds the requests which publish alerts to self-hosted push
+// notification servers like ntfy and Gotify.
+package push
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bakku/easyalert"
+)
+
+// ErrUnknownKind is returned if a request should be built for an unknown
+// kind of push channel.
+var ErrUnknownKind = errors.New("unknown push kind")
+
+// builders return the URL, headers and body of the request per kind.
+var builders = map[string]func(c easyalert.PushChannel, n easyalert.Notification) (string, http.Header, interface{}){
+	easyalert.PushKindNtfy:   ntfyRequest,
+	easyalert.PushKindGotify: gotifyRequest,
+}
+
+// ValidKind returns whether requests can be built for the kind.
+func ValidKind(kind string) bool {
+	_, ok := builders[kind]
+	return ok
+}
+
+// NewRequest returns the request which publishes the notification to the
+// server of the channel.
+func NewRequest(c easyalert.PushChannel, n easyalert.Notification) (*http.Request, error) {
+	build, ok := builders[c.Kind]
+	if !ok {
+		return nil, ErrUnknownKind
+	}
+
+	url, header, payload := build(c, n)
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header = header
+	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+
+	return req, nil
+}
+
+// maxMessageLength keeps messages below the limit of ntfy, longer messages
+// would be turned into attachments.
+const maxMessageLength = 4000
+
+// content returns title and message of the notification. Notifications
+// without message, like resent alerts, only consist of the subject.
+func content(n easyalert.Notification) (string, string) {
+	if n.Message == "" {
+		return "", n.Alert.Subject
+	}
+
+	message := n.Message
+	if utf8.RuneCountInString(message) > maxMessageLength {
+		message = string([]rune(message)[:maxMessageLength-1]) + "…"
+	}
+
+	return n.Alert.Subject, message
+}
+
+// labels returns the labels of the alert as key=value sorted by key.
+func labels(alert easyalert.Alert) []string {
+	result := make([]string, 0, len(alert.Labels))
+
+	for k, v := range alert.Labels {
+		result = append(result, k+"="+v)
+	}
+
+	sort.Strings(result)
+
+	return result
+}
+
+func endpoint(serverURL, path string) string {
+	return strings.TrimRight(serverURL, "/") + path
+}
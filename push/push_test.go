@@ -0,0 +1,33 @@
+package push_test
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/push"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRequest_ShouldRejectUnknownKinds(t *testing.T) {
+	require.False(t, push.ValidKind("pushover"))
+
+	_, err := push.NewRequest(easyalert.PushChannel{Kind: "pushover"}, easyalert.Notification{})
+	require.Equal(t, push.ErrUnknownKind, err)
+}
+
+func TestNewRequest_ShouldUseSubjectAsMessageIfMessageIsMissing(t *testing.T) {
+	req, err := push.NewRequest(
+		easyalert.PushChannel{Kind: easyalert.PushKindNtfy, ServerURL: "https://ntfy.sh", Topic: "alerts"},
+		easyalert.Notification{Alert: easyalert.Alert{Subject: "Heartbeat missed"}},
+	)
+	require.Nil(t, err)
+
+	require.Equal(t, "https://ntfy.sh/", req.URL.String())
+	require.Empty(t, req.Header.Get("Authorization"))
+
+	body, err := ioutil.ReadAll(req.Body)
+	require.Nil(t, err)
+
+	require.JSONEq(t, `{"topic": "alerts", "message": "Heartbeat missed", "priority": 3, "tags": ["information_source"]}`, string(body))
+}
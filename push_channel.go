@@ -0,0 +1,35 @@
+package easyalert
+
+import "time"
+
+// Kinds of push channels.
+const (
+	PushKindNtfy   = "ntfy"
+	PushKindGotify = "gotify"
+)
+
+// PushChannelRepository wraps all CRUD operations for push channels
+type PushChannelRepository interface {
+	FindPushChannel(query string, params ...interface{}) (PushChannel, error)
+	FindPushChannels(query string, params ...interface{}) ([]PushChannel, error)
+	CreatePushChannel(channel PushChannel) (PushChannel, error)
+	UpdatePushChannel(channel PushChannel) (PushChannel, error)
+	DeletePushChannel(channel PushChannel) error
+}
+
+// PushChannel is a self-hosted push notification server like ntfy or
+// Gotify which alerts of the user are published to.
+type PushChannel struct {
+	ID        uint
+	Name      string
+	Kind      string
+	ServerURL string
+	// Topic is the ntfy topic, Gotify does not use topics.
+	Topic string
+	// Token is the application token of Gotify or the optional access
+	// token of ntfy.
+	Token     string
+	UserID    uint
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
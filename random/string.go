@@ -47,3 +47,19 @@ func String(n uint) (string, error) {
 
 	return strings.Join(result, ""), nil
 }
+
+// Digits returns a random string consisting only of n decimal digits
+func Digits(n uint) (string, error) {
+	result := make([]byte, n)
+
+	for i := range result {
+		rnd, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+
+		result[i] = byte('0' + rnd.Int64())
+	}
+
+	return string(result), nil
+}
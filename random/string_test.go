@@ -13,3 +13,10 @@ func TestString(t *testing.T) {
 
 	require.Equal(t, 32, len(str))
 }
+
+func TestDigits(t *testing.T) {
+	str, err := random.Digits(6)
+	require.Nil(t, err)
+
+	require.Regexp(t, "^[0-9]{6}$", str)
+}
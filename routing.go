@@ -0,0 +1,106 @@
+package easyalert
+
+import (
+	"strings"
+	"time"
+)
+
+// Kinds of channels which routing rules select.
+const (
+	ChannelKindEmail   = "email"
+	ChannelKindWebhook = "webhook"
+	ChannelKindChat    = "chat"
+	ChannelKindPush    = "push"
+	ChannelKindMatrix  = "matrix"
+	ChannelKindSMS     = "sms"
+)
+
+// RoutingRepository wraps all CRUD operations for routings
+type RoutingRepository interface {
+	FindRouting(query string, params ...interface{}) (Routing, error)
+	CreateRouting(routing Routing) (Routing, error)
+	UpdateRouting(routing Routing) (Routing, error)
+	DeleteRouting(routing Routing) error
+}
+
+// Routing decides through which channels the alerts of a user are
+// delivered. The first matching rule selects the channels. Alerts matching
+// no rule are delivered through the default channels, or through all
+// channels if no default channels are given. Every user has at most one
+// routing.
+type Routing struct {
+	ID              uint
+	Rules           []RoutingRule
+	DefaultChannels Selection
+	UserID          uint
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// RoutingRule selects the channels of alerts. All given conditions have to
+// match.
+type RoutingRule struct {
+	// Name identifies the rule, e.g. in dry runs.
+	Name string
+	// Subject is a regular expression the subject has to match.
+	Subject string
+	// Severity is the least urgent severity which matches, e.g. "warning"
+	// matches warning and critical alerts. Empty matches all.
+	Severity string
+	// Labels maps label names to regular expressions their values have to
+	// match. Alerts without one of the labels do not match.
+	Labels map[string]string
+	// From and Until limit the rule to a time of day like "22:00" in
+	// Timezone. Until may be before From to span midnight. Both empty match
+	// the whole day.
+	From     string
+	Until    string
+	Timezone string
+	// Channels are selected if the rule matches.
+	Channels Selection
+}
+
+// Selection lists channels by kind, e.g. "chat" for all chat channels of
+// the user, or by kind and name, e.g. "chat:ops" for the chat channel named
+// ops. A nil selection selects all channels.
+type Selection []string
+
+// Selects returns whether the channel of the kind with the name is part of
+// the selection.
+func (s Selection) Selects(kind, name string) bool {
+	if s == nil {
+		return true
+	}
+
+	for _, item := range s {
+		itemKind, itemName := SplitSelector(item)
+
+		if itemKind == kind && (itemName == "" || itemName == name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SendsSMS returns whether the alert is sent as text message. Text messages
+// cost money and are meant to wake people up, so selecting all channels
+// only sends critical alerts as SMS. Less urgent alerts are only sent as SMS
+// if the selection lists SMS explicitly.
+func (s Selection) SendsSMS(alert Alert) bool {
+	if s == nil {
+		return alert.Severity == AlertSeverityCritical
+	}
+
+	return s.Selects(ChannelKindSMS, "")
+}
+
+// SplitSelector splits an item of a selection into the kind and the name,
+// which is empty if all channels of the kind are selected.
+func SplitSelector(item string) (kind, name string) {
+	if i := strings.Index(item, ":"); i >= 0 {
+		return item[:i], item[i+1:]
+	}
+
+	return item, ""
+}
@@ -0,0 +1,254 @@
+// Package routing validates the routing rules of users and decides through
+// which channels their alerts are delivered.
+package routing
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/bakku/easyalert"
+)
+
+// InvalidRuleError is returned if a field of a rule is invalid. Rule is the
+// index of the rule.
+type InvalidRuleError struct {
+	Rule  int
+	Field string
+	Err   error
+}
+
+func (e InvalidRuleError) Error() string {
+	return fmt.Sprintf("Invalid %s: %v.", e.Field, e.Err)
+}
+
+// namePattern matches the names of rules and of selected channels.
+var namePattern = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+
+// kinds are the kinds of channels which can be selected. Only the kinds
+// with true can be selected by name.
+var kinds = map[string]bool{
+	easyalert.ChannelKindEmail:   false,
+	easyalert.ChannelKindWebhook: true,
+	easyalert.ChannelKindChat:    true,
+	easyalert.ChannelKindPush:    true,
+	easyalert.ChannelKindMatrix:  true,
+	easyalert.ChannelKindSMS:     false,
+}
+
+// timeOfDayLayout is the layout of the From and Until fields of rules.
+const timeOfDayLayout = "15:04"
+
+// Result is the outcome of routing an alert.
+type Result struct {
+	// Rule is the name of the matching rule, empty if no rule matched.
+	Rule string
+	// Channels is the selection of the matching rule or the default
+	// channels.
+	Channels easyalert.Selection
+}
+
+// rule is a compiled easyalert.RoutingRule.
+type rule struct {
+	subject  *regexp.Regexp
+	severity uint
+	labels   map[string]*regexp.Regexp
+	// from and until are minutes since midnight, negative for the whole
+	// day.
+	from     int
+	until    int
+	location *time.Location
+}
+
+// Validate checks every rule of the routing and its default channels. Rule
+// names must be unique. Errors of rules are returned as InvalidRuleError,
+// errors of the default channels as is.
+func Validate(r easyalert.Routing) error {
+	names := make(map[string]bool)
+
+	for i, routingRule := range r.Rules {
+		if !namePattern.MatchString(routingRule.Name) {
+			return InvalidRuleError{i, "name", errors.New("name may only contain letters, digits, dots, dashes and underscores")}
+		}
+
+		if names[routingRule.Name] {
+			return InvalidRuleError{i, "name", fmt.Errorf("another rule is already named %q", routingRule.Name)}
+		}
+
+		names[routingRule.Name] = true
+
+		_, err := compileRule(routingRule)
+		if err != nil {
+			if e, ok := err.(InvalidRuleError); ok {
+				e.Rule = i
+				return e
+			}
+
+			return err
+		}
+
+		if len(routingRule.Channels) == 0 {
+			return InvalidRuleError{i, "channels", errors.New("at least one channel must be selected")}
+		}
+
+		err = ValidateSelection(routingRule.Channels)
+		if err != nil {
+			return InvalidRuleError{i, "channels", err}
+		}
+	}
+
+	return ValidateSelection(r.DefaultChannels)
+}
+
+// ValidateSelection checks that every item of the selection is a known kind
+// of channel, optionally followed by a colon and the name of a channel.
+func ValidateSelection(s easyalert.Selection) error {
+	for _, item := range s {
+		kind, name := easyalert.SplitSelector(item)
+
+		named, ok := kinds[kind]
+		if !ok {
+			return fmt.Errorf("unknown channel %q, expected one of email, webhook, chat, push, matrix or sms", kind)
+		}
+
+		if item == kind {
+			continue
+		}
+
+		if !named {
+			return fmt.Errorf("%s channels can not be selected by name", kind)
+		}
+
+		if !namePattern.MatchString(name) {
+			return fmt.Errorf("invalid channel name %q", name)
+		}
+	}
+
+	return nil
+}
+
+func compileRule(r easyalert.RoutingRule) (rule, error) {
+	compiled := rule{from: -1, until: -1, location: time.UTC}
+
+	if r.Subject != "" {
+		subject, err := regexp.Compile(r.Subject)
+		if err != nil {
+			return rule{}, InvalidRuleError{Field: "subject", Err: err}
+		}
+
+		compiled.subject = subject
+	}
+
+	severity, err := easyalert.ParseSeverity(r.Severity)
+	if err != nil {
+		return rule{}, InvalidRuleError{Field: "severity", Err: fmt.Errorf("unknown severity %q, expected one of info, warning or critical", r.Severity)}
+	}
+
+	compiled.severity = severity
+
+	if len(r.Labels) > 0 {
+		compiled.labels = make(map[string]*regexp.Regexp, len(r.Labels))
+
+		for key, pattern := range r.Labels {
+			value, err := regexp.Compile(pattern)
+			if err != nil {
+				return rule{}, InvalidRuleError{Field: "labels", Err: fmt.Errorf("label %q: %v", key, err)}
+			}
+
+			compiled.labels[key] = value
+		}
+	}
+
+	if (r.From == "") != (r.Until == "") {
+		return rule{}, InvalidRuleError{Field: "from", Err: errors.New("from and until must be given together")}
+	}
+
+	if r.From != "" {
+		compiled.from, err = parseTimeOfDay(r.From)
+		if err != nil {
+			return rule{}, InvalidRuleError{Field: "from", Err: err}
+		}
+
+		compiled.until, err = parseTimeOfDay(r.Until)
+		if err != nil {
+			return rule{}, InvalidRuleError{Field: "until", Err: err}
+		}
+
+		if compiled.from == compiled.until {
+			return rule{}, InvalidRuleError{Field: "until", Err: errors.New("until must differ from from")}
+		}
+	}
+
+	if r.Timezone != "" {
+		compiled.location, err = time.LoadLocation(r.Timezone)
+		if err != nil {
+			return rule{}, InvalidRuleError{Field: "timezone", Err: fmt.Errorf("unknown timezone %q", r.Timezone)}
+		}
+	}
+
+	return compiled, nil
+}
+
+// parseTimeOfDay returns the minutes since midnight of a time like 22:30.
+func parseTimeOfDay(s string) (int, error) {
+	t, err := time.Parse(timeOfDayLayout, s)
+	if err != nil {
+		return 0, fmt.Errorf("time %q must look like 22:30", s)
+	}
+
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func (r rule) matches(a easyalert.Alert, now time.Time) bool {
+	if a.Severity < r.severity {
+		return false
+	}
+
+	if r.subject != nil && !r.subject.MatchString(a.Subject) {
+		return false
+	}
+
+	for key, pattern := range r.labels {
+		value, ok := a.Labels[key]
+		if !ok || !pattern.MatchString(value) {
+			return false
+		}
+	}
+
+	if r.from < 0 {
+		return true
+	}
+
+	local := now.In(r.location)
+	minute := local.Hour()*60 + local.Minute()
+
+	if r.from < r.until {
+		return minute >= r.from && minute < r.until
+	}
+
+	// the time span crosses midnight
+	return minute >= r.from || minute < r.until
+}
+
+// Route returns the channels the alert is delivered through at the given
+// time. Invalid rules are skipped.
+func Route(r easyalert.Routing, a easyalert.Alert, now time.Time) Result {
+	for _, routingRule := range r.Rules {
+		compiled, err := compileRule(routingRule)
+		if err != nil {
+			continue
+		}
+
+		if compiled.matches(a, now) {
+			return Result{Rule: routingRule.Name, Channels: routingRule.Channels}
+		}
+	}
+
+	// an empty selection would select no channel at all
+	if len(r.DefaultChannels) == 0 {
+		return Result{}
+	}
+
+	return Result{Channels: r.DefaultChannels}
+}
@@ -0,0 +1,79 @@
+package routing_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/routing"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	valid := easyalert.RoutingRule{
+		Name:     "night",
+		Subject:  "^Backup",
+		Severity: "critical",
+		Labels:   map[string]string{"env": "^prod$"},
+		From:     "22:00",
+		Until:    "07:00",
+		Timezone: "Europe/Berlin",
+		Channels: easyalert.Selection{"sms", "chat:ops"},
+	}
+
+	require.Nil(t, routing.Validate(easyalert.Routing{Rules: []easyalert.RoutingRule{valid}, DefaultChannels: easyalert.Selection{"email"}}))
+
+	tests := []struct {
+		rule  easyalert.RoutingRule
+		field string
+	}{
+		{easyalert.RoutingRule{Name: "a b", Channels: easyalert.Selection{"email"}}, "name"},
+		{easyalert.RoutingRule{Name: "a", Subject: "(unclosed", Channels: easyalert.Selection{"email"}}, "subject"},
+		{easyalert.RoutingRule{Name: "a", Severity: "error", Channels: easyalert.Selection{"email"}}, "severity"},
+		{easyalert.RoutingRule{Name: "a", Labels: map[string]string{"env": "("}, Channels: easyalert.Selection{"email"}}, "labels"},
+		{easyalert.RoutingRule{Name: "a", From: "22:00", Channels: easyalert.Selection{"email"}}, "from"},
+		{easyalert.RoutingRule{Name: "a", From: "22:00", Until: "25:00", Channels: easyalert.Selection{"email"}}, "until"},
+		{easyalert.RoutingRule{Name: "a", Timezone: "Mars/Olympus", Channels: easyalert.Selection{"email"}}, "timezone"},
+		{easyalert.RoutingRule{Name: "a"}, "channels"},
+		{easyalert.RoutingRule{Name: "a", Channels: easyalert.Selection{"pager"}}, "channels"},
+		{easyalert.RoutingRule{Name: "a", Channels: easyalert.Selection{"sms:mobile"}}, "channels"},
+	}
+
+	for _, test := range tests {
+		err := routing.Validate(easyalert.Routing{Rules: []easyalert.RoutingRule{valid, test.rule}})
+		require.NotNil(t, err, test.field)
+		require.Equal(t, 1, err.(routing.InvalidRuleError).Rule)
+		require.Equal(t, test.field, err.(routing.InvalidRuleError).Field)
+	}
+
+	err := routing.Validate(easyalert.Routing{Rules: []easyalert.RoutingRule{valid, valid}})
+	require.Equal(t, "name", err.(routing.InvalidRuleError).Field)
+
+	err = routing.Validate(easyalert.Routing{DefaultChannels: easyalert.Selection{"chat:"}})
+	require.EqualError(t, err, `invalid channel name ""`)
+}
+
+func TestRoute(t *testing.T) {
+	r := easyalert.Routing{
+		Rules: []easyalert.RoutingRule{
+			{Name: "night", Severity: "critical", From: "22:00", Until: "07:00", Timezone: "Europe/Berlin", Channels: easyalert.Selection{"sms"}},
+			{Name: "prod", Subject: "(?i)database", Labels: map[string]string{"env": "^prod$"}, Channels: easyalert.Selection{"chat:ops", "email"}},
+		},
+		DefaultChannels: easyalert.Selection{"email"},
+	}
+
+	// 23:30 in Berlin
+	night := time.Date(2019, 2, 15, 22, 30, 0, 0, time.UTC)
+	day := time.Date(2019, 2, 15, 12, 0, 0, 0, time.UTC)
+
+	critical := easyalert.Alert{Subject: "Database down", Severity: easyalert.AlertSeverityCritical, Labels: map[string]string{"env": "prod"}}
+
+	require.Equal(t, routing.Result{Rule: "night", Channels: easyalert.Selection{"sms"}}, routing.Route(r, critical, night))
+	require.Equal(t, routing.Result{Rule: "prod", Channels: easyalert.Selection{"chat:ops", "email"}}, routing.Route(r, critical, day))
+
+	staging := easyalert.Alert{Subject: "Database down", Labels: map[string]string{"env": "staging"}}
+	require.Equal(t, routing.Result{Channels: easyalert.Selection{"email"}}, routing.Route(r, staging, day))
+
+	r.DefaultChannels = easyalert.Selection{}
+	require.Equal(t, routing.Result{}, routing.Route(r, staging, day))
+}
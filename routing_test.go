@@ -0,0 +1,31 @@
+package easyalert_test
+
+import (
+	"testing"
+
+	"github.com/bakku/easyalert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelection_Selects(t *testing.T) {
+	var all easyalert.Selection
+	require.True(t, all.Selects(easyalert.ChannelKindChat, "ops"))
+
+	s := easyalert.Selection{"email", "chat:ops"}
+	require.True(t, s.Selects(easyalert.ChannelKindEmail, ""))
+	require.True(t, s.Selects(easyalert.ChannelKindChat, "ops"))
+	require.False(t, s.Selects(easyalert.ChannelKindChat, "dev"))
+	require.False(t, s.Selects(easyalert.ChannelKindSMS, ""))
+}
+
+func TestSelection_SendsSMS(t *testing.T) {
+	critical := easyalert.Alert{Severity: easyalert.AlertSeverityCritical}
+	info := easyalert.Alert{Severity: easyalert.AlertSeverityInfo}
+
+	var all easyalert.Selection
+	require.True(t, all.SendsSMS(critical))
+	require.False(t, all.SendsSMS(info))
+
+	require.True(t, easyalert.Selection{"sms"}.SendsSMS(info))
+	require.False(t, easyalert.Selection{"email"}.SendsSMS(critical))
+}
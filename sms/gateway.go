@@ -0,0 +1,135 @@
+package sms
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+)
+
+// Message holds the fields the URL and body templates of a gateway are
+// rendered with.
+type Message struct {
+	To   string
+	From string
+	Text string
+}
+
+// templateFuncs escape the fields of a message next to the builtin urlquery.
+var templateFuncs = template.FuncMap{
+	"json": func(s string) (string, error) {
+		b, err := json.Marshal(s)
+		return string(b), err
+	},
+}
+
+// HTTPGateway describes the requests which send text messages through the
+// HTTP API of an SMS gateway, so that any provider can be used without a
+// dedicated driver.
+type HTTPGateway struct {
+	// Method defaults to POST.
+	Method string
+	URL    *template.Template
+	// Body may be nil to send requests without a body.
+	Body *template.Template
+	// ContentType of the body, defaults to
+	// application/x-www-form-urlencoded.
+	ContentType string
+	// Username and Password are sent with HTTP basic authentication if a
+	// username is given.
+	Username string
+	Password string
+	// Authorization is sent as Authorization header if given, e.g. to pass
+	// a bearer token.
+	Authorization string
+	// From is the sender of the messages as expected by the gateway.
+	From string
+}
+
+// ParseHTTPGateway parses the URL and body templates of a gateway. The body
+// template may be empty. Both are Go text/templates rendered with a Message,
+// e.g. https://sms.example.com/send?to={{urlquery .To}}. The templates are
+// rendered once with a sample message, so that errors like unknown fields
+// are returned right away.
+func ParseHTTPGateway(url, body string) (HTTPGateway, error) {
+	var g HTTPGateway
+
+	t, err := template.New("url").Funcs(templateFuncs).Parse(url)
+	if err != nil {
+		return HTTPGateway{}, err
+	}
+
+	g.URL = t
+
+	if body != "" {
+		t, err := template.New("body").Funcs(templateFuncs).Parse(body)
+		if err != nil {
+			return HTTPGateway{}, err
+		}
+
+		g.Body = t
+	}
+
+	_, err = g.NewRequest("+15550100", "sample")
+	if err != nil {
+		return HTTPGateway{}, err
+	}
+
+	return g, nil
+}
+
+// NewRequest returns the request which sends the text to the phone number.
+func (g HTTPGateway) NewRequest(to, text string) (*http.Request, error) {
+	msg := Message{To: to, From: g.From, Text: text}
+
+	var url strings.Builder
+
+	err := g.URL.Execute(&url, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	var body io.Reader
+
+	if g.Body != nil {
+		var b bytes.Buffer
+
+		err := g.Body.Execute(&b, msg)
+		if err != nil {
+			return nil, err
+		}
+
+		body = &b
+	}
+
+	method := g.Method
+	if method == "" {
+		method = "POST"
+	}
+
+	req, err := http.NewRequest(method, url.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	if g.Body != nil {
+		contentType := g.ContentType
+		if contentType == "" {
+			contentType = "application/x-www-form-urlencoded"
+		}
+
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	if g.Username != "" {
+		req.SetBasicAuth(g.Username, g.Password)
+	}
+
+	if g.Authorization != "" {
+		req.Header.Set("Authorization", g.Authorization)
+	}
+
+	return req, nil
+}
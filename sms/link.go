@@ -0,0 +1,91 @@
+package sms
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+// DefaultLinkTTL is how long links to alerts stay valid.
+const DefaultLinkTTL = 24 * time.Hour
+
+// linkMACSize is the number of bytes of the HMAC kept in a token. The full
+// HMAC would make the links too long for a text message.
+const linkMACSize = 12
+
+var (
+	// ErrInvalidLink is returned for tokens which were not signed with the
+	// secret.
+	ErrInvalidLink = errors.New("invalid link")
+	// ErrExpiredLink is returned for tokens whose TTL is over.
+	ErrExpiredLink = errors.New("expired link")
+)
+
+// LinkSigner signs the tokens of the short links to alerts which are sent in
+// text messages. A token contains the id of the alert and its expiry, so the
+// alert can be shown without logging in.
+type LinkSigner struct {
+	Secret []byte
+
+	// TTL of the links, defaults to DefaultLinkTTL.
+	TTL time.Duration
+}
+
+// Enabled returns whether links can be signed.
+func (s LinkSigner) Enabled() bool {
+	return len(s.Secret) > 0
+}
+
+// Sign returns the token of a link to the alert which expires after the TTL.
+func (s LinkSigner) Sign(alertID uint, now time.Time) string {
+	ttl := s.TTL
+	if ttl <= 0 {
+		ttl = DefaultLinkTTL
+	}
+
+	buf := make([]byte, 2*binary.MaxVarintLen64, 2*binary.MaxVarintLen64+linkMACSize)
+	n := binary.PutUvarint(buf, uint64(alertID))
+	n += binary.PutUvarint(buf[n:], uint64(now.Add(ttl).Unix()))
+	buf = buf[:n]
+
+	return base64.RawURLEncoding.EncodeToString(append(buf, s.mac(buf)...))
+}
+
+// Verify returns the id of the alert the token links to.
+func (s LinkSigner) Verify(token string, now time.Time) (uint, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || !s.Enabled() || len(buf) <= linkMACSize {
+		return 0, ErrInvalidLink
+	}
+
+	payload, mac := buf[:len(buf)-linkMACSize], buf[len(buf)-linkMACSize:]
+	if !hmac.Equal(mac, s.mac(payload)) {
+		return 0, ErrInvalidLink
+	}
+
+	alertID, n := binary.Uvarint(payload)
+	if n <= 0 {
+		return 0, ErrInvalidLink
+	}
+
+	expiresAt, m := binary.Uvarint(payload[n:])
+	if m <= 0 || n+m != len(payload) {
+		return 0, ErrInvalidLink
+	}
+
+	if now.Unix() >= int64(expiresAt) {
+		return 0, ErrExpiredLink
+	}
+
+	return uint(alertID), nil
+}
+
+func (s LinkSigner) mac(payload []byte) []byte {
+	h := hmac.New(sha256.New, s.Secret)
+	h.Write(payload)
+
+	return h.Sum(nil)[:linkMACSize]
+}
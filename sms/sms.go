@@ -0,0 +1,51 @@
+// Package sms shortens alerts to text messages and builds the requests which
+// send them through the HTTP API of an SMS gateway.
+package sms
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bakku/easyalert"
+)
+
+// DefaultMaxLength is the number of characters which fit into a single text
+// message.
+const DefaultMaxLength = 160
+
+// Text returns the text message for the alert. It consists of the severity,
+// the subject and the link, if one is given. The subject is shortened so
+// that the text is at most maxLength characters long. Truncation is marked
+// with dots instead of an ellipsis since the ellipsis is not part of the
+// GSM alphabet and would reduce the length of a message to 70 characters.
+func Text(a easyalert.Alert, link string, maxLength int) string {
+	prefix := "[" + a.HumanSeverity() + "] "
+
+	suffix := ""
+	if link != "" {
+		suffix = " " + link
+	}
+
+	// line breaks would only waste the few characters available
+	subject := strings.Join(strings.Fields(a.Subject), " ")
+
+	room := maxLength - utf8.RuneCountInString(prefix) - utf8.RuneCountInString(suffix)
+	if room <= 0 {
+		return truncate(prefix+subject+suffix, maxLength)
+	}
+
+	return prefix + truncate(subject, room) + suffix
+}
+
+// truncate shortens s to at most n characters and marks the cut with dots.
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+
+	if n <= 3 {
+		return string([]rune(s)[:n])
+	}
+
+	return string([]rune(s)[:n-3]) + "..."
+}
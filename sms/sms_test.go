@@ -0,0 +1,107 @@
+package sms_test
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/sms"
+	"github.com/stretchr/testify/require"
+)
+
+func TestText_ShouldContainSeveritySubjectAndLink(t *testing.T) {
+	a := easyalert.Alert{Subject: "Backup\nfailed", Severity: easyalert.AlertSeverityCritical}
+
+	require.Equal(t, "[critical] Backup failed https://ea.example.com/a/BQ", sms.Text(a, "https://ea.example.com/a/BQ", 160))
+	require.Equal(t, "[critical] Backup failed", sms.Text(a, "", 160))
+}
+
+func TestText_ShouldTruncateSubjectToKeepLink(t *testing.T) {
+	a := easyalert.Alert{Subject: "Disk of database server db1 is almost full", Severity: easyalert.AlertSeverityWarning}
+
+	text := sms.Text(a, "https://ea.example.com/a/BQ", 50)
+
+	require.Equal(t, "[warning] Disk of d... https://ea.example.com/a/BQ", text)
+	require.Len(t, []rune(text), 50)
+}
+
+func TestLinkSigner_ShouldVerifySignedTokens(t *testing.T) {
+	s := sms.LinkSigner{Secret: []byte("secret")}
+	now := time.Date(2019, 2, 20, 3, 0, 0, 0, time.UTC)
+
+	token := s.Sign(42, now)
+	require.True(t, len(token) < 30)
+
+	id, err := s.Verify(token, now.Add(time.Hour))
+	require.Nil(t, err)
+	require.Equal(t, uint(42), id)
+
+	_, err = s.Verify(token, now.Add(sms.DefaultLinkTTL))
+	require.Equal(t, sms.ErrExpiredLink, err)
+}
+
+func TestLinkSigner_ShouldRejectForgedTokens(t *testing.T) {
+	now := time.Date(2019, 2, 20, 3, 0, 0, 0, time.UTC)
+	token := sms.LinkSigner{Secret: []byte("other")}.Sign(42, now)
+
+	_, err := sms.LinkSigner{Secret: []byte("secret")}.Verify(token, now)
+	require.Equal(t, sms.ErrInvalidLink, err)
+
+	_, err = sms.LinkSigner{Secret: []byte("secret")}.Verify("42", now)
+	require.Equal(t, sms.ErrInvalidLink, err)
+
+	_, err = sms.LinkSigner{}.Verify(sms.LinkSigner{}.Sign(42, now), now)
+	require.Equal(t, sms.ErrInvalidLink, err)
+}
+
+func TestParseHTTPGateway_ShouldRejectUnknownFields(t *testing.T) {
+	_, err := sms.ParseHTTPGateway("https://sms.example.com/send?to={{.Number}}", "")
+	require.NotNil(t, err)
+}
+
+func TestHTTPGateway_NewRequest_ShouldRenderTemplates(t *testing.T) {
+	g, err := sms.ParseHTTPGateway(
+		"https://sms.example.com/v1/{{urlquery .From}}/send",
+		`{"to": {{json .To}}, "text": {{json .Text}}}`,
+	)
+	require.Nil(t, err)
+
+	g.From = "easyalert"
+	g.ContentType = "application/json"
+	g.Username = "user"
+	g.Password = "secret"
+
+	req, err := g.NewRequest("+4915112345678", `Disk "db1" full`)
+	require.Nil(t, err)
+
+	require.Equal(t, "POST", req.Method)
+	require.Equal(t, "https://sms.example.com/v1/easyalert/send", req.URL.String())
+	require.Equal(t, "application/json", req.Header.Get("Content-Type"))
+
+	username, password, ok := req.BasicAuth()
+	require.True(t, ok)
+	require.Equal(t, "user", username)
+	require.Equal(t, "secret", password)
+
+	body, err := ioutil.ReadAll(req.Body)
+	require.Nil(t, err)
+	require.JSONEq(t, `{"to": "+4915112345678", "text": "Disk \"db1\" full"}`, string(body))
+}
+
+func TestHTTPGateway_NewRequest_ShouldEscapeQueryParameters(t *testing.T) {
+	g, err := sms.ParseHTTPGateway("https://sms.example.com/send?to={{urlquery .To}}&text={{urlquery .Text}}", "")
+	require.Nil(t, err)
+
+	g.Method = "GET"
+	g.Authorization = "Bearer token"
+
+	req, err := g.NewRequest("+4915112345678", "a&b c")
+	require.Nil(t, err)
+
+	require.Equal(t, "GET", req.Method)
+	require.Equal(t, "+4915112345678", req.URL.Query().Get("to"))
+	require.Equal(t, "a&b c", req.URL.Query().Get("text"))
+	require.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+	require.Equal(t, "", req.Header.Get("Content-Type"))
+}
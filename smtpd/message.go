@@ -0,0 +1,130 @@
+package smtpd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+
+	"github.com/bakku/easyalert"
+)
+
+// message is the part of an email which is turned into an alert.
+type message struct {
+	subject string
+	body    string
+}
+
+func parseMessage(data []byte) (message, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		return message{}, err
+	}
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		subject = msg.Header.Get("Subject")
+	}
+
+	body, err := textBody(msg.Header.Get("Content-Type"), msg.Header.Get("Content-Transfer-Encoding"), msg.Body)
+	if err != nil {
+		return message{}, err
+	}
+
+	return message{
+		// a subject spanning multiple lines would break the mail header
+		subject: strings.Join(strings.Fields(subject), " "),
+		body:    strings.TrimSpace(strings.Replace(body, "\r\n", "\n", -1)),
+	}, nil
+}
+
+// alert returns the alert for the user. The sender is kept as label, so all
+// alerts of an appliance can be listed.
+func (m message) alert(user easyalert.User, from string) easyalert.Alert {
+	subject := m.subject
+	if subject == "" {
+		subject = "Email from " + from
+	}
+
+	return easyalert.Alert{
+		Subject:     subject,
+		Status:      easyalert.AlertStatusPending,
+		UserID:      user.ID,
+		Occurrences: 1,
+		Severity:    easyalert.AlertSeverityInfo,
+		Labels:      map[string]string{"source": "email", "from": from},
+	}
+}
+
+// textBody returns the first plain text part of the body. HTML is only used
+// if there is no plain text, attachments are skipped.
+func textBody(contentType, encoding string, body io.Reader) (string, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = "text/plain"
+	}
+
+	switch strings.ToLower(encoding) {
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	}
+
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		if !strings.HasPrefix(mediaType, "text/") {
+			return "", nil
+		}
+
+		b, err := ioutil.ReadAll(body)
+		return string(b), err
+	}
+
+	var html string
+
+	parts := multipart.NewReader(body, params["boundary"])
+
+	for {
+		part, err := parts.NextPart()
+		if err == io.EOF {
+			return html, nil
+		}
+
+		if err != nil {
+			return "", err
+		}
+
+		if strings.HasPrefix(part.Header.Get("Content-Disposition"), "attachment") {
+			continue
+		}
+
+		partType := part.Header.Get("Content-Type")
+		if partType == "" {
+			partType = "text/plain"
+		}
+
+		text, err := textBody(partType, part.Header.Get("Content-Transfer-Encoding"), part)
+		if err != nil {
+			return "", err
+		}
+
+		if text == "" {
+			continue
+		}
+
+		if strings.HasPrefix(partType, "text/html") {
+			if html == "" {
+				html = text
+			}
+
+			continue
+		}
+
+		return text, nil
+	}
+}
@@ -0,0 +1,419 @@
+// Package smtpd implements a small SMTP server which turns emails into
+// alerts. It is meant for appliances which can only send emails, e.g. NAS
+// boxes, UPS units or routers.
+package smtpd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bakku/easyalert"
+)
+
+// DefaultMaxMessageSize is used if a Server is not given a maximum message size.
+const DefaultMaxMessageSize = 1 << 20
+
+// DefaultTimeout is used if a Server is not given a timeout.
+const DefaultTimeout = 5 * time.Minute
+
+// DefaultMaxConnections is used if a Server is not given a maximum number of
+// connections.
+const DefaultMaxConnections = 100
+
+// maxRecipients limits the number of users a single email can alert.
+const maxRecipients = 10
+
+// maxLineLength is the maximum length of command and text lines including
+// the CRLF, see RFC 5321 section 4.5.3.1.
+const maxLineLength = 1000
+
+// errLineTooLong is returned by the reader of a connection once a line
+// exceeds maxLineLength.
+var errLineTooLong = errors.New("line too long")
+
+// Server accepts emails for addresses like <inbound token>@<domain>. The
+// inbound token identifies the user the alert is created for, so emails to
+// unknown tokens are rejected before their content is transferred. It only
+// allows to create alerts, unlike the API token of the user.
+type Server struct {
+	Addr           string
+	Domain         string
+	UserRepo       easyalert.UserRepository
+	AlertRepo      easyalert.AlertRepository
+	Notifier       easyalert.Notifier
+	MaxMessageSize int64
+	Timeout        time.Duration
+	// MaxConnections limits the number of concurrent connections, further
+	// connections are rejected with a temporary failure.
+	MaxConnections int
+}
+
+// Run listens on the address of the server until stop is closed.
+func (s Server) Run(stop <-chan struct{}) {
+	l, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		log.Println("SMTP server error:", err)
+		return
+	}
+
+	go func() {
+		<-stop
+		l.Close()
+	}()
+
+	err = s.Serve(l)
+
+	select {
+	case <-stop:
+	default:
+		log.Println("SMTP server error:", err)
+	}
+}
+
+// Serve accepts connections on the listener and handles each of them in its
+// own goroutine. It returns once the listener is closed.
+func (s Server) Serve(l net.Listener) error {
+	sem := make(chan struct{}, s.maxConnections())
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+
+		select {
+		case sem <- struct{}{}:
+			go func() {
+				defer func() { <-sem }()
+				s.handle(conn)
+			}()
+		default:
+			go s.reject(conn)
+		}
+	}
+}
+
+func (s Server) maxConnections() int {
+	if s.MaxConnections == 0 {
+		return DefaultMaxConnections
+	}
+
+	return s.MaxConnections
+}
+
+func (s Server) maxMessageSize() int64 {
+	if s.MaxMessageSize == 0 {
+		return DefaultMaxMessageSize
+	}
+
+	return s.MaxMessageSize
+}
+
+func (s Server) timeout() time.Duration {
+	if s.Timeout == 0 {
+		return DefaultTimeout
+	}
+
+	return s.Timeout
+}
+
+// session is the state of a single connection.
+type session struct {
+	server Server
+	text   *textproto.Conn
+	from   string
+	users  []easyalert.User
+}
+
+// reject closes a connection exceeding the maximum number of connections,
+// senders try again later.
+func (s Server) reject(conn net.Conn) {
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(s.timeout()))
+	fmt.Fprintf(conn, "421 4.7.0 %s Too many connections, try again later\r\n", s.Domain)
+}
+
+func (s Server) handle(conn net.Conn) {
+	defer conn.Close()
+
+	lines := &lineReader{r: conn}
+	text := textproto.NewConn(struct {
+		io.Reader
+		io.WriteCloser
+	}{lines, conn})
+
+	sess := &session{server: s, text: text}
+	sess.reply(220, s.Domain+" ESMTP easyalert")
+
+	for {
+		conn.SetDeadline(time.Now().Add(s.timeout()))
+
+		line, err := sess.text.ReadLine()
+		if lines.tooLong {
+			// the start of a too long line is returned without an
+			// error and its rest cannot be told apart from the next
+			// command, so the connection is closed
+			sess.reply(500, "5.5.6 Line too long")
+			return
+		}
+
+		if err != nil {
+			return
+		}
+
+		verb, arg := line, ""
+		if i := strings.IndexByte(line, ' '); i >= 0 {
+			verb, arg = line[:i], strings.TrimSpace(line[i+1:])
+		}
+
+		switch strings.ToUpper(verb) {
+		case "HELO":
+			sess.reset()
+			sess.reply(250, s.Domain)
+		case "EHLO":
+			sess.reset()
+			sess.reply(250, s.Domain, "SIZE "+strconv.FormatInt(s.maxMessageSize(), 10), "8BITMIME")
+		case "MAIL":
+			sess.mail(arg)
+		case "RCPT":
+			sess.rcpt(arg)
+		case "DATA":
+			sess.data()
+		case "RSET":
+			sess.reset()
+			sess.reply(250, "2.0.0 OK")
+		case "NOOP":
+			sess.reply(250, "2.0.0 OK")
+		case "VRFY":
+			sess.reply(252, "2.5.0 Cannot verify user")
+		case "QUIT":
+			sess.reply(221, "2.0.0 Bye")
+			return
+		default:
+			sess.reply(502, "5.5.2 Command not recognized")
+		}
+	}
+}
+
+// lineReader fails with errLineTooLong once a line exceeds maxLineLength,
+// so a client cannot make the server buffer arbitrarily long lines. The
+// error is kept for all further reads.
+type lineReader struct {
+	r       io.Reader
+	length  int
+	tooLong bool
+}
+
+func (lr *lineReader) Read(p []byte) (int, error) {
+	if lr.tooLong {
+		return 0, errLineTooLong
+	}
+
+	n, err := lr.r.Read(p)
+
+	for i, b := range p[:n] {
+		if b == '\n' {
+			lr.length = 0
+			continue
+		}
+
+		// the LF is not counted, so the CR and content may take all
+		// but one octet
+		lr.length++
+		if lr.length >= maxLineLength {
+			lr.tooLong = true
+			return i, errLineTooLong
+		}
+	}
+
+	return n, err
+}
+
+// reply writes a reply with the given lines, all but the last are marked
+// as continued.
+func (sess *session) reply(code int, lines ...string) {
+	for i, line := range lines {
+		sep := "-"
+		if i == len(lines)-1 {
+			sep = " "
+		}
+
+		sess.text.PrintfLine("%d%s%s", code, sep, line)
+	}
+}
+
+func (sess *session) reset() {
+	sess.from = ""
+	sess.users = nil
+}
+
+func (sess *session) mail(arg string) {
+	if sess.from != "" {
+		sess.reply(503, "5.5.1 Sender already given")
+		return
+	}
+
+	addr, params, ok := parsePath(arg, "FROM:")
+	if !ok {
+		sess.reply(501, "5.5.4 Syntax: MAIL FROM:<address>")
+		return
+	}
+
+	for _, param := range params {
+		if strings.HasPrefix(strings.ToUpper(param), "SIZE=") {
+			size, err := strconv.ParseInt(param[len("SIZE="):], 10, 64)
+			if err == nil && size > sess.server.maxMessageSize() {
+				sess.reply(552, "5.3.4 Message too large")
+				return
+			}
+		}
+	}
+
+	if addr == "" {
+		// bounces have an empty sender
+		addr = "<>"
+	}
+
+	sess.from = addr
+	sess.reply(250, "2.1.0 OK")
+}
+
+func (sess *session) rcpt(arg string) {
+	if sess.from == "" {
+		sess.reply(503, "5.5.1 Need MAIL command")
+		return
+	}
+
+	addr, _, ok := parsePath(arg, "TO:")
+	if !ok {
+		sess.reply(501, "5.5.4 Syntax: RCPT TO:<address>")
+		return
+	}
+
+	at := strings.LastIndexByte(addr, '@')
+	if at < 0 || !strings.EqualFold(addr[at+1:], sess.server.Domain) {
+		sess.reply(550, "5.7.1 Relaying denied")
+		return
+	}
+
+	// inbound tokens are lower case, as mail servers may change the case
+	// of the local part
+	token := strings.ToLower(addr[:at])
+	if token == "" {
+		sess.reply(550, "5.1.1 Unknown recipient")
+		return
+	}
+
+	user, err := sess.server.UserRepo.FindUser("WHERE inbound_token = $1", token)
+	if err != nil {
+		if err == easyalert.ErrRecordDoesNotExist {
+			sess.reply(550, "5.1.1 Unknown recipient")
+			return
+		}
+
+		sess.reply(451, "4.3.0 Temporary failure, try again later")
+		return
+	}
+
+	for _, u := range sess.users {
+		if u.ID == user.ID {
+			sess.reply(250, "2.1.5 OK")
+			return
+		}
+	}
+
+	if len(sess.users) >= maxRecipients {
+		sess.reply(452, "4.5.3 Too many recipients")
+		return
+	}
+
+	sess.users = append(sess.users, user)
+	sess.reply(250, "2.1.5 OK")
+}
+
+func (sess *session) data() {
+	if len(sess.users) == 0 {
+		sess.reply(503, "5.5.1 Need RCPT command")
+		return
+	}
+
+	sess.reply(354, "End data with <CR><LF>.<CR><LF>")
+
+	dot := sess.text.DotReader()
+	max := sess.server.maxMessageSize()
+
+	data, err := ioutil.ReadAll(io.LimitReader(dot, max+1))
+	if err != nil {
+		// too long lines are answered when reading the next command
+		return
+	}
+
+	if int64(len(data)) > max {
+		// the rest of the message has to be read to continue the session
+		io.Copy(ioutil.Discard, dot)
+
+		sess.reply(552, "5.3.4 Message too large")
+		sess.reset()
+		return
+	}
+
+	defer sess.reset()
+
+	msg, err := parseMessage(data)
+	if err != nil {
+		sess.reply(554, "5.6.0 Message could not be parsed")
+		return
+	}
+
+	alerts := make([]easyalert.Alert, len(sess.users))
+	for i, user := range sess.users {
+		alerts[i] = msg.alert(user, sess.from)
+	}
+
+	// the alerts of all recipients are created at once, so that a sender
+	// retrying after a temporary failure does not alert anyone twice
+	alerts, err = sess.server.AlertRepo.CreateAlerts(alerts)
+	if err != nil {
+		log.Println("SMTP server error:", err)
+		sess.reply(451, "4.3.0 Could not create alert, try again later")
+		return
+	}
+
+	for i, user := range sess.users {
+		sess.server.Notifier.Notify(user, easyalert.Notification{
+			Alert:   alerts[i],
+			Message: msg.body,
+			Format:  easyalert.MessageFormatText,
+		})
+	}
+
+	sess.reply(250, "2.0.0 OK: queued")
+}
+
+// parsePath parses arguments like "FROM:<a@example.com> SIZE=100".
+func parsePath(arg, prefix string) (string, []string, bool) {
+	if len(arg) < len(prefix) || !strings.EqualFold(arg[:len(prefix)], prefix) {
+		return "", nil, false
+	}
+
+	fields := strings.Fields(arg[len(prefix):])
+	if len(fields) == 0 {
+		return "", nil, false
+	}
+
+	path := fields[0]
+	if !strings.HasPrefix(path, "<") || !strings.HasSuffix(path, ">") {
+		return "", nil, false
+	}
+
+	return path[1 : len(path)-1], fields[1:], true
+}
@@ -0,0 +1,257 @@
+package smtpd_test
+
+import (
+	"errors"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/mocks"
+	"github.com/bakku/easyalert/smtpd"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func startServer(t *testing.T, s smtpd.Server) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+
+	go s.Serve(l)
+
+	return l.Addr().String()
+}
+
+// requireReply checks the code of a rejected command and the enhanced status
+// code at the start of its message.
+func requireReply(t *testing.T, err error, code int, status string) {
+	e, ok := err.(*textproto.Error)
+	require.True(t, ok, err.Error())
+
+	require.Equal(t, code, e.Code)
+	require.True(t, strings.HasPrefix(e.Msg, status), e.Msg)
+}
+
+func TestServer_ShouldCreateAlertFromEmail(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	user := easyalert.User{ID: 1, InboundToken: "abc123"}
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser("WHERE inbound_token = $1", "abc123").Return(user, nil)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().CreateAlerts([]easyalert.Alert{{
+		Subject:     "UPS on battery",
+		Status:      easyalert.AlertStatusPending,
+		UserID:      1,
+		Occurrences: 1,
+		Severity:    easyalert.AlertSeverityInfo,
+		Labels:      map[string]string{"source": "email", "from": "ups@example.com"},
+	}}).DoAndReturn(func(alerts []easyalert.Alert) ([]easyalert.Alert, error) {
+		alerts[0].ID = 5
+		return alerts, nil
+	})
+
+	delivered := make(chan easyalert.Notification, 1)
+
+	notifier := mocks.NewMockNotifier(mockCtrl)
+	notifier.EXPECT().Notify(user, gomock.Any()).Do(func(user easyalert.User, n easyalert.Notification) {
+		delivered <- n
+	})
+
+	addr := startServer(t, smtpd.Server{
+		Domain:    "alerts.example.com",
+		UserRepo:  userRepo,
+		AlertRepo: alertRepo,
+		Notifier:  notifier,
+	})
+
+	msg := "From: UPS <ups@example.com>\r\n" +
+		"Subject: =?UTF-8?Q?UPS_on_battery?=\r\n" +
+		"Content-Type: multipart/alternative; boundary=b1\r\n" +
+		"\r\n" +
+		"--b1\r\n" +
+		"Content-Type: text/html\r\n" +
+		"\r\n" +
+		"<p>Power failure</p>\r\n" +
+		"--b1\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n" +
+		"Content-Transfer-Encoding: quoted-printable\r\n" +
+		"\r\n" +
+		"Power failure, runtime 42 min=\r\nutes.\r\n" +
+		"--b1--\r\n"
+
+	err := smtp.SendMail(addr, nil, "ups@example.com", []string{"ABC123@alerts.example.com"}, []byte(msg))
+	require.Nil(t, err)
+
+	n := <-delivered
+	require.Equal(t, uint(5), n.Alert.ID)
+	require.Equal(t, "Power failure, runtime 42 minutes.", n.Message)
+	require.Equal(t, easyalert.MessageFormatText, n.Format)
+}
+
+func TestServer_ShouldRejectUnknownTokensAndOtherDomains(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser("WHERE inbound_token = $1", "wrong").Return(easyalert.User{}, easyalert.ErrRecordDoesNotExist)
+
+	addr := startServer(t, smtpd.Server{Domain: "alerts.example.com", UserRepo: userRepo})
+
+	c, err := smtp.Dial(addr)
+	require.Nil(t, err)
+	defer c.Close()
+
+	require.Nil(t, c.Mail("nas@example.com"))
+
+	err = c.Rcpt("wrong@alerts.example.com")
+	require.NotNil(t, err)
+	requireReply(t, err, 550, "5.1.1")
+
+	err = c.Rcpt("someone@example.org")
+	require.NotNil(t, err)
+	requireReply(t, err, 550, "5.7.1")
+
+	_, err = c.Data()
+	require.NotNil(t, err)
+	requireReply(t, err, 503, "")
+}
+
+func TestServer_ShouldRejectTooLargeMessages(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	addr := startServer(t, smtpd.Server{Domain: "alerts.example.com", UserRepo: userRepo, MaxMessageSize: 100})
+
+	c, err := smtp.Dial(addr)
+	require.Nil(t, err)
+	defer c.Close()
+
+	require.Nil(t, c.Mail("nas@example.com"))
+	require.Nil(t, c.Rcpt("abc123@alerts.example.com"))
+
+	w, err := c.Data()
+	require.Nil(t, err)
+
+	_, err = w.Write([]byte("Subject: Disk full\r\n\r\n" + strings.Repeat("x", 200) + "\r\n"))
+	require.Nil(t, err)
+
+	err = w.Close()
+	require.NotNil(t, err)
+	requireReply(t, err, 552, "5.3.4")
+
+	// the session can be used for further emails
+	require.Nil(t, c.Reset())
+}
+
+func TestServer_ShouldNotCreateAnyAlertIfOneRecipientFails(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser("WHERE inbound_token = $1", "abc").Return(easyalert.User{ID: 1}, nil)
+	userRepo.EXPECT().FindUser("WHERE inbound_token = $1", "def").Return(easyalert.User{ID: 2}, nil)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().CreateAlerts(gomock.Any()).DoAndReturn(func(alerts []easyalert.Alert) ([]easyalert.Alert, error) {
+		require.Len(t, alerts, 2)
+		return nil, errors.New("connection lost")
+	})
+
+	// no notification is expected, so the retry of the sender is the only
+	// delivery
+	notifier := mocks.NewMockNotifier(mockCtrl)
+
+	addr := startServer(t, smtpd.Server{
+		Domain:    "alerts.example.com",
+		UserRepo:  userRepo,
+		AlertRepo: alertRepo,
+		Notifier:  notifier,
+	})
+
+	err := smtp.SendMail(addr, nil, "nas@example.com", []string{"abc@alerts.example.com", "def@alerts.example.com"}, []byte("Subject: RAID degraded\r\n\r\nDisk 2 failed.\r\n"))
+	require.NotNil(t, err)
+	requireReply(t, err, 451, "4.3.0")
+}
+
+func TestServer_ShouldRejectTooLongLines(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	addr := startServer(t, smtpd.Server{Domain: "alerts.example.com", UserRepo: userRepo})
+
+	c, err := smtp.Dial(addr)
+	require.Nil(t, err)
+	defer c.Close()
+
+	require.Nil(t, c.Mail("nas@example.com"))
+	require.Nil(t, c.Rcpt("abc123@alerts.example.com"))
+
+	w, err := c.Data()
+	require.Nil(t, err)
+
+	_, err = w.Write([]byte("Subject: Disk full\r\n\r\n" + strings.Repeat("x", 1000) + "\r\n"))
+	require.Nil(t, err)
+
+	err = w.Close()
+	require.NotNil(t, err)
+	requireReply(t, err, 500, "5.5.6")
+
+	// the rest of the line cannot be told apart from commands
+	require.NotNil(t, c.Noop())
+
+	text, err := textproto.Dial("tcp", addr)
+	require.Nil(t, err)
+	defer text.Close()
+
+	_, _, err = text.ReadResponse(220)
+	require.Nil(t, err)
+
+	// 998 octets and the CRLF are allowed
+	require.Nil(t, text.PrintfLine("NOOP %s", strings.Repeat("x", 993)))
+	_, _, err = text.ReadResponse(250)
+	require.Nil(t, err)
+
+	require.Nil(t, text.PrintfLine("NOOP %s", strings.Repeat("x", 994)))
+	_, _, err = text.ReadResponse(250)
+	requireReply(t, err, 500, "5.5.6")
+}
+
+func TestServer_ShouldRejectTooManyConnections(t *testing.T) {
+	addr := startServer(t, smtpd.Server{Domain: "alerts.example.com", MaxConnections: 1})
+
+	c, err := smtp.Dial(addr)
+	require.Nil(t, err)
+	defer c.Close()
+
+	_, err = smtp.Dial(addr)
+	require.NotNil(t, err)
+	requireReply(t, err, 421, "4.7.0")
+
+	require.Nil(t, c.Quit())
+
+	// the connection is released once the first session ends
+	for i := 0; i < 100; i++ {
+		c, err = smtp.Dial(addr)
+		if err == nil {
+			break
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	require.Nil(t, err)
+	c.Close()
+}
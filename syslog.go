@@ -0,0 +1,60 @@
+package easyalert
+
+import "time"
+
+// SyslogVerificationTokenLength is the length of the token which verifies
+// that a syslog source belongs to the user.
+const SyslogVerificationTokenLength = 32
+
+// SyslogSourceRepository wraps all CRUD operations for syslog sources
+type SyslogSourceRepository interface {
+	FindSyslogSource(query string, params ...interface{}) (SyslogSource, error)
+	FindSyslogSources(query string, params ...interface{}) ([]SyslogSource, error)
+	CreateSyslogSource(source SyslogSource) (SyslogSource, error)
+	UpdateSyslogSource(source SyslogSource) (SyslogSource, error)
+	DeleteSyslogSource(source SyslogSource) error
+	// VerifySyslogSource marks the unverified source as verified unless
+	// another source with the same address was verified before. Otherwise
+	// ErrRecordDoesNotExist is returned.
+	VerifySyslogSource(source SyslogSource) (SyslogSource, error)
+}
+
+// SyslogSource is a device of the user which sends its logs via syslog. It is
+// identified by the IP address the logs are sent from. Anyone may claim an
+// address, but only messages of verified sources are received and every
+// address can only be verified by a single source across all users.
+// Otherwise anyone registering the address of another user's device would
+// receive its logs.
+type SyslogSource struct {
+	ID      uint
+	Name    string
+	Address string
+	Rules   []SyslogRule
+	// Verified is set once a message containing the VerificationToken was
+	// received from the address via TCP, which cannot be sent from a
+	// spoofed address.
+	Verified          bool
+	VerificationToken string
+	UserID            uint
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// ResetVerification requires the source to be verified again with a new
+// token, e.g. because its address changed.
+func (s *SyslogSource) ResetVerification(token string) {
+	s.Verified = false
+	s.VerificationToken = token
+}
+
+// SyslogRule decides which log lines of a source become alerts. All given
+// conditions have to match, the first matching rule of a source wins.
+type SyslogRule struct {
+	// Facility is the name of the facility, e.g. "auth", empty matches all.
+	Facility string
+	// Severity is the least urgent severity which matches, e.g. "warning"
+	// matches warning, err, crit, alert and emerg. Empty matches all.
+	Severity string
+	// Pattern is a regular expression the message has to match.
+	Pattern string
+}
@@ -0,0 +1,172 @@
+package syslogd
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Message is a parsed syslog message.
+type Message struct {
+	Facility  int
+	Severity  int
+	Timestamp time.Time
+	Hostname  string
+	AppName   string
+	Text      string
+}
+
+// ErrInvalidMessage is returned if a message does not start with a valid priority.
+var ErrInvalidMessage = errors.New("invalid syslog message")
+
+// facilities are the names of the facilities in the order of their codes.
+var facilities = []string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+	"uucp", "cron", "authpriv", "ftp", "ntp", "security", "console", "solaris-cron",
+	"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+}
+
+// severities are the names of the severities in the order of their codes,
+// the most urgent first.
+var severities = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+// FacilityName returns the name of the facility of the message.
+func (m Message) FacilityName() string {
+	return facilities[m.Facility]
+}
+
+// SeverityName returns the name of the severity of the message.
+func (m Message) SeverityName() string {
+	return severities[m.Severity]
+}
+
+// tag3164 matches the tag of RFC 3164 messages like "sshd[123]:".
+var tag3164 = regexp.MustCompile(`^([^\s\[\]:]+)(\[[^\]]*\])?:$`)
+
+// Parse parses a message in the format of RFC 5424 or, as many devices still
+// use it, RFC 3164. Messages without a timestamp are given now.
+func Parse(data []byte, now time.Time) (Message, error) {
+	line := strings.TrimRight(string(data), "\r\n\x00")
+
+	if !strings.HasPrefix(line, "<") {
+		return Message{}, ErrInvalidMessage
+	}
+
+	end := strings.IndexByte(line, '>')
+	if end < 2 || end > 4 {
+		return Message{}, ErrInvalidMessage
+	}
+
+	pri, err := strconv.Atoi(line[1:end])
+	if err != nil || pri < 0 || pri >= len(facilities)*8 {
+		return Message{}, ErrInvalidMessage
+	}
+
+	m := Message{Facility: pri / 8, Severity: pri % 8, Timestamp: now}
+	rest := line[end+1:]
+
+	if strings.HasPrefix(rest, "1 ") {
+		parse5424(&m, rest[2:])
+	} else {
+		parse3164(&m, rest, now)
+	}
+
+	return m, nil
+}
+
+// parse5424 parses "TIMESTAMP HOSTNAME APP-NAME PROCID MSGID SD MSG".
+func parse5424(m *Message, rest string) {
+	fields := make([]string, 5)
+
+	for i := range fields {
+		rest = strings.TrimLeft(rest, " ")
+
+		end := strings.IndexByte(rest, ' ')
+		if end < 0 {
+			end = len(rest)
+		}
+
+		fields[i], rest = rest[:end], rest[end:]
+
+		if fields[i] == "-" {
+			fields[i] = ""
+		}
+	}
+
+	if t, err := time.Parse(time.RFC3339Nano, fields[0]); err == nil {
+		m.Timestamp = t
+	}
+
+	m.Hostname = fields[1]
+	m.AppName = fields[2]
+
+	rest = strings.TrimLeft(rest, " ")
+
+	if strings.HasPrefix(rest, "-") {
+		rest = rest[1:]
+	} else {
+		rest = skipStructuredData(rest)
+	}
+
+	m.Text = strings.TrimPrefix(strings.TrimLeft(rest, " "), "\ufeff")
+}
+
+// skipStructuredData returns everything after the structured data elements
+// like [id key="value"] at the start of s.
+func skipStructuredData(s string) string {
+	inQuotes := false
+
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && inQuotes:
+			i++
+		case s[i] == '"':
+			inQuotes = !inQuotes
+		case s[i] == ']' && !inQuotes:
+			if i+1 >= len(s) || s[i+1] != '[' {
+				return s[i+1:]
+			}
+		}
+	}
+
+	return ""
+}
+
+// parse3164 parses "Mmm dd hh:mm:ss HOSTNAME TAG: MSG". Devices often leave
+// out the timestamp or hostname, so both are optional.
+func parse3164(m *Message, rest string, now time.Time) {
+	if len(rest) >= 16 && rest[15] == ' ' {
+		t, err := time.ParseInLocation(time.Stamp, rest[:15], now.Location())
+		if err == nil {
+			t = t.AddDate(now.Year(), 0, 0)
+
+			// messages sent around new year
+			if t.Sub(now) > 24*time.Hour {
+				t = t.AddDate(-1, 0, 0)
+			}
+
+			m.Timestamp = t
+			rest = rest[16:]
+
+			fields := strings.SplitN(rest, " ", 2)
+			if len(fields) == 2 && !tag3164.MatchString(fields[0]) {
+				m.Hostname = fields[0]
+				rest = fields[1]
+			}
+		}
+	}
+
+	fields := strings.SplitN(rest, " ", 2)
+	if match := tag3164.FindStringSubmatch(fields[0]); match != nil {
+		m.AppName = match[1]
+		rest = ""
+
+		if len(fields) == 2 {
+			rest = fields[1]
+		}
+	}
+
+	m.Text = strings.TrimSpace(rest)
+}
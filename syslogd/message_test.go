@@ -0,0 +1,71 @@
+package syslogd_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bakku/easyalert/syslogd"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2019, 1, 30, 12, 0, 0, 0, time.UTC)
+
+func TestParse_RFC5424(t *testing.T) {
+	m, err := syslogd.Parse([]byte(`<34>1 2019-01-30T10:15:03.003Z router1 sshd 512 ID47 [exampleSDID@32473 iut="3" eventSource="App\]lication"] `+"\ufeffFailed password for root\n"), now)
+	require.Nil(t, err)
+
+	require.Equal(t, "auth", m.FacilityName())
+	require.Equal(t, "crit", m.SeverityName())
+	require.Equal(t, time.Date(2019, 1, 30, 10, 15, 3, 3000000, time.UTC), m.Timestamp)
+	require.Equal(t, "router1", m.Hostname)
+	require.Equal(t, "sshd", m.AppName)
+	require.Equal(t, "Failed password for root", m.Text)
+}
+
+func TestParse_RFC5424WithoutStructuredData(t *testing.T) {
+	m, err := syslogd.Parse([]byte(`<165>1 - - - - - - Link down`), now)
+	require.Nil(t, err)
+
+	require.Equal(t, "local4", m.FacilityName())
+	require.Equal(t, "notice", m.SeverityName())
+	require.Equal(t, now, m.Timestamp)
+	require.Equal(t, "", m.Hostname)
+	require.Equal(t, "Link down", m.Text)
+}
+
+func TestParse_RFC3164(t *testing.T) {
+	m, err := syslogd.Parse([]byte(`<28>Jan  5 09:01:02 nas01 smartd[881]: Device /dev/sda failing`), now)
+	require.Nil(t, err)
+
+	require.Equal(t, "daemon", m.FacilityName())
+	require.Equal(t, "warning", m.SeverityName())
+	require.Equal(t, time.Date(2019, 1, 5, 9, 1, 2, 0, time.UTC), m.Timestamp)
+	require.Equal(t, "nas01", m.Hostname)
+	require.Equal(t, "smartd", m.AppName)
+	require.Equal(t, "Device /dev/sda failing", m.Text)
+}
+
+func TestParse_RFC3164FromLastYear(t *testing.T) {
+	m, err := syslogd.Parse([]byte(`<28>Dec 31 23:59:59 nas01 kernel: eth0 down`), now)
+	require.Nil(t, err)
+
+	require.Equal(t, 2018, m.Timestamp.Year())
+}
+
+func TestParse_RFC3164WithoutTimestampAndHostname(t *testing.T) {
+	m, err := syslogd.Parse([]byte(`<11>dnsmasq: no servers found`), now)
+	require.Nil(t, err)
+
+	require.Equal(t, "user", m.FacilityName())
+	require.Equal(t, "err", m.SeverityName())
+	require.Equal(t, "", m.Hostname)
+	require.Equal(t, "dnsmasq", m.AppName)
+	require.Equal(t, "no servers found", m.Text)
+}
+
+func TestParse_InvalidPriority(t *testing.T) {
+	for _, line := range []string{"no priority", "<>1 -", "<192>x", "<1a>x"} {
+		_, err := syslogd.Parse([]byte(line), now)
+		require.Equal(t, syslogd.ErrInvalidMessage, err, line)
+	}
+}
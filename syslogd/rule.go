@@ -0,0 +1,122 @@
+package syslogd
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/bakku/easyalert"
+)
+
+// InvalidRuleError is returned if a field of a rule is invalid.
+type InvalidRuleError struct {
+	Field string
+	Err   error
+}
+
+func (e InvalidRuleError) Error() string {
+	return fmt.Sprintf("Invalid %s: %v.", e.Field, e.Err)
+}
+
+// rule is a compiled easyalert.SyslogRule.
+type rule struct {
+	// index is the position of the rule inside its source.
+	index    int
+	facility int
+	severity int
+	pattern  *regexp.Regexp
+}
+
+// ValidateRule checks that the facility and severity of the rule are known
+// and that its pattern is a valid regular expression.
+func ValidateRule(r easyalert.SyslogRule) error {
+	_, err := compileRule(r)
+	return err
+}
+
+func compileRule(r easyalert.SyslogRule) (rule, error) {
+	compiled := rule{facility: -1, severity: len(severities) - 1}
+
+	if r.Facility != "" {
+		compiled.facility = indexOf(facilities, r.Facility)
+
+		if compiled.facility < 0 {
+			return rule{}, InvalidRuleError{"facility", fmt.Errorf("unknown facility %q", r.Facility)}
+		}
+	}
+
+	if r.Severity != "" {
+		compiled.severity = indexOf(severities, r.Severity)
+
+		if compiled.severity < 0 {
+			return rule{}, InvalidRuleError{"severity", fmt.Errorf("unknown severity %q, expected one of emerg, alert, crit, err, warning, notice, info or debug", r.Severity)}
+		}
+	}
+
+	if r.Pattern != "" {
+		pattern, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return rule{}, InvalidRuleError{"pattern", err}
+		}
+
+		compiled.pattern = pattern
+	}
+
+	return compiled, nil
+}
+
+func (r rule) matches(m Message) bool {
+	if r.facility >= 0 && m.Facility != r.facility {
+		return false
+	}
+
+	// lower codes are more urgent
+	if m.Severity > r.severity {
+		return false
+	}
+
+	return r.pattern == nil || r.pattern.MatchString(m.Text)
+}
+
+// Match returns the index of the first rule matching the message.
+func Match(rules []easyalert.SyslogRule, m Message) (int, bool) {
+	return match(compileRules(rules), m)
+}
+
+// compileRules compiles every rule of a source. Invalid rules are left out,
+// the others keep their index.
+func compileRules(rules []easyalert.SyslogRule) []rule {
+	var compiled []rule
+
+	for i, r := range rules {
+		c, err := compileRule(r)
+		if err != nil {
+			continue
+		}
+
+		c.index = i
+		compiled = append(compiled, c)
+	}
+
+	return compiled
+}
+
+// match returns the index of the first compiled rule matching the message.
+func match(rules []rule, m Message) (int, bool) {
+	for _, r := range rules {
+		if r.matches(m) {
+			return r.index, true
+		}
+	}
+
+	return 0, false
+}
+
+func indexOf(list []string, s string) int {
+	for i, item := range list {
+		if item == s {
+			return i
+		}
+	}
+
+	return -1
+}
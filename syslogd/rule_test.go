@@ -0,0 +1,55 @@
+package syslogd_test
+
+import (
+	"testing"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/syslogd"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateRule(t *testing.T) {
+	require.Nil(t, syslogd.ValidateRule(easyalert.SyslogRule{Facility: "auth", Severity: "warning", Pattern: "^Failed"}))
+
+	tests := []struct {
+		rule  easyalert.SyslogRule
+		field string
+	}{
+		{easyalert.SyslogRule{Facility: "web"}, "facility"},
+		{easyalert.SyslogRule{Severity: "error"}, "severity"},
+		{easyalert.SyslogRule{Pattern: "(unclosed"}, "pattern"},
+	}
+
+	for _, test := range tests {
+		err := syslogd.ValidateRule(test.rule)
+		require.NotNil(t, err)
+		require.Equal(t, test.field, err.(syslogd.InvalidRuleError).Field)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	rules := []easyalert.SyslogRule{
+		{Facility: "auth", Pattern: "Failed password"},
+		{Severity: "err"},
+	}
+
+	m, err := syslogd.Parse([]byte("<38>sshd[1]: Failed password for root"), now)
+	require.Nil(t, err)
+
+	i, ok := syslogd.Match(rules, m)
+	require.True(t, ok)
+	require.Equal(t, 0, i)
+
+	m, err = syslogd.Parse([]byte("<3>kernel: disk error"), now)
+	require.Nil(t, err)
+
+	i, ok = syslogd.Match(rules, m)
+	require.True(t, ok)
+	require.Equal(t, 1, i)
+
+	m, err = syslogd.Parse([]byte("<4>kernel: disk slow"), now)
+	require.Nil(t, err)
+
+	_, ok = syslogd.Match(rules, m)
+	require.False(t, ok)
+}
@@ -0,0 +1,339 @@
+// Package syslogd implements a syslog receiver which turns log lines of
+// devices like routers into alerts.
+package syslogd
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bakku/easyalert"
+)
+
+// maxMessageSize limits the size of a single message. Longer UDP datagrams
+// are truncated, longer TCP frames close the connection.
+const maxMessageSize = 8192
+
+// Server receives syslog messages via UDP and TCP on the same address.
+// Messages of addresses which do not belong to a verified source are
+// dropped.
+type Server struct {
+	Addr       string
+	SourceRepo easyalert.SyslogSourceRepository
+	UserRepo   easyalert.UserRepository
+	AlertRepo  easyalert.AlertRepository
+	Notifier   easyalert.Notifier
+
+	// RefreshInterval is how often sources are reloaded, so changed sources
+	// take effect after it. Defaults to 10 seconds.
+	RefreshInterval time.Duration
+
+	// RateLimit is how many messages of a source per minute may create or
+	// fold alerts. Defaults to 60.
+	RateLimit int
+
+	mu       sync.Mutex
+	sources  map[string]cachedSource
+	claims   map[string][]easyalert.SyslogSource
+	loadedAt time.Time
+	windows  map[uint]*rateWindow
+}
+
+// Run listens on the address of the server until stop is closed.
+func (s *Server) Run(stop <-chan struct{}) {
+	packetConn, err := net.ListenPacket("udp", s.Addr)
+	if err != nil {
+		log.Println("Syslog server error:", err)
+		return
+	}
+
+	listener, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		packetConn.Close()
+		log.Println("Syslog server error:", err)
+		return
+	}
+
+	go func() {
+		<-stop
+		packetConn.Close()
+		listener.Close()
+	}()
+
+	go s.ServeUDP(packetConn)
+
+	err = s.ServeTCP(listener)
+
+	select {
+	case <-stop:
+	default:
+		log.Println("Syslog server error:", err)
+	}
+}
+
+// ServeUDP receives a message per datagram until the connection is closed.
+func (s *Server) ServeUDP(conn net.PacketConn) error {
+	buf := make([]byte, maxMessageSize)
+
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			return err
+		}
+
+		s.receive(hostOf(addr), buf[:n], false)
+	}
+}
+
+// ServeTCP accepts connections on the listener and receives their messages
+// until the listener is closed.
+func (s *Server) ServeTCP(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+
+		go s.handleTCP(conn)
+	}
+}
+
+// handleTCP reads messages which are framed by octet counting or, as most
+// devices do, by newlines (RFC 6587).
+func (s *Server) handleTCP(conn net.Conn) {
+	defer conn.Close()
+
+	host := hostOf(conn.RemoteAddr())
+	r := bufio.NewReaderSize(conn, maxMessageSize)
+
+	for {
+		first, err := r.Peek(1)
+		if err != nil {
+			return
+		}
+
+		var data []byte
+
+		if first[0] >= '1' && first[0] <= '9' {
+			length, err := r.ReadString(' ')
+			if err != nil {
+				return
+			}
+
+			n, err := strconv.Atoi(strings.TrimSpace(length))
+			if err != nil || n > maxMessageSize {
+				return
+			}
+
+			data = make([]byte, n)
+
+			_, err = io.ReadFull(r, data)
+			if err != nil {
+				return
+			}
+		} else {
+			data, err = r.ReadSlice('\n')
+			if err != nil && (err != io.EOF || len(data) == 0) {
+				return
+			}
+		}
+
+		s.receive(host, data, true)
+	}
+}
+
+// receive handles a message. Only messages received via TCP may verify a
+// source, since the address of a UDP datagram can be spoofed.
+func (s *Server) receive(host string, data []byte, tcp bool) {
+	now := time.Now().UTC()
+
+	if tcp {
+		verified, err := s.Verify(host, data, now)
+		if err != nil {
+			log.Println("Syslog server error:", err)
+			return
+		}
+
+		if verified {
+			return
+		}
+	}
+
+	err := s.Receive(host, data, now)
+	if err != nil {
+		log.Println("Syslog server error:", err)
+	}
+}
+
+// Verify verifies the unverified source of the address whose verification
+// token the message contains and reports whether a source was verified. The
+// address has to be the real sender of the message.
+func (s *Server) Verify(host string, data []byte, now time.Time) (bool, error) {
+	claims, err := s.unverified(host, now)
+	if err != nil {
+		return false, err
+	}
+
+	for _, source := range claims {
+		if !bytes.Contains(data, []byte(source.VerificationToken)) {
+			continue
+		}
+
+		_, err = s.SourceRepo.VerifySyslogSource(source)
+		if err == easyalert.ErrRecordDoesNotExist {
+			// another source verified the address first
+			return false, nil
+		}
+
+		if err != nil {
+			return false, err
+		}
+
+		s.reload()
+
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// Receive handles a message sent from the given IP address. If the address
+// belongs to a verified source and a rule of the source matches, an alert is created.
+// Repeated messages are folded into a single alert.
+func (s *Server) Receive(host string, data []byte, now time.Time) error {
+	m, err := Parse(data, now)
+	if err != nil {
+		return err
+	}
+
+	cached, ok, err := s.source(host, now)
+	if err != nil || !ok {
+		return err
+	}
+
+	source := cached.source
+
+	i, ok := match(cached.rules, m)
+	if !ok || !s.allow(source, now) {
+		return nil
+	}
+
+	user, err := s.UserRepo.FindUser("WHERE id = $1", source.UserID)
+	if err != nil {
+		return err
+	}
+
+	alert, folded, err := easyalert.CreateOrFoldAlert(s.AlertRepo, alertFor(source, i, m), easyalert.DefaultDedupWindow)
+	if err != nil {
+		return err
+	}
+
+	if !folded {
+		s.Notifier.Notify(user, easyalert.Notification{
+			Alert:   alert,
+			Message: messageBody(source, m),
+			Format:  easyalert.MessageFormatText,
+		})
+	}
+
+	return nil
+}
+
+// alertFor returns the alert for a message matching the rule with the given
+// index. The log line is confidential, so it is only part of the message.
+func alertFor(source easyalert.SyslogSource, rule int, m Message) easyalert.Alert {
+	host := m.Hostname
+	if host == "" {
+		host = source.Name
+	}
+
+	subject := m.SeverityName() + " on " + host
+	if m.AppName != "" {
+		subject = m.AppName + " " + subject
+	}
+
+	labels := map[string]string{
+		"source":        "syslog",
+		"syslog_source": source.Name,
+		"facility":      m.FacilityName(),
+	}
+
+	if m.Hostname != "" {
+		labels["host"] = m.Hostname
+	}
+
+	return easyalert.Alert{
+		Subject:     subject,
+		Status:      easyalert.AlertStatusPending,
+		UserID:      source.UserID,
+		Occurrences: 1,
+		Severity:    severity(m),
+		Labels:      labels,
+		DedupKey:    dedupKey(source, rule, m),
+	}
+}
+
+// digits are replaced inside dedup keys, so that messages only differing in
+// numbers like ports, PIDs or IP addresses are folded.
+var digits = regexp.MustCompile(`[0-9]+`)
+
+// dedupKey identifies repeated messages of the same rule, host and app
+// without storing the message itself.
+func dedupKey(source easyalert.SyslogSource, rule int, m Message) string {
+	text := digits.ReplaceAllString(m.Text, "0")
+	sum := sha1.Sum([]byte(fmt.Sprintf("%d\x00%s\x00%s\x00%s", rule, m.Hostname, m.AppName, text)))
+
+	return fmt.Sprintf("syslog:%d:%s", source.ID, hex.EncodeToString(sum[:8]))
+}
+
+// severity maps emerg, alert, crit and err to critical and warning to warning.
+func severity(m Message) uint {
+	switch {
+	case m.Severity <= 3:
+		return easyalert.AlertSeverityCritical
+	case m.Severity == 4:
+		return easyalert.AlertSeverityWarning
+	default:
+		return easyalert.AlertSeverityInfo
+	}
+}
+
+func messageBody(source easyalert.SyslogSource, m Message) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Source: %s (%s)\n", source.Name, source.Address)
+
+	if m.Hostname != "" {
+		fmt.Fprintf(&b, "Host: %s\n", m.Hostname)
+	}
+
+	if m.AppName != "" {
+		fmt.Fprintf(&b, "App: %s\n", m.AppName)
+	}
+
+	fmt.Fprintf(&b, "Facility: %s\n", m.FacilityName())
+	fmt.Fprintf(&b, "Severity: %s\n", m.SeverityName())
+	fmt.Fprintf(&b, "Time: %s\n\n", m.Timestamp.UTC().Format(time.RFC3339))
+	b.WriteString(m.Text)
+
+	return b.String()
+}
+
+func hostOf(addr net.Addr) string {
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+
+	return host
+}
@@ -0,0 +1,266 @@
+package syslogd_test
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/mocks"
+	"github.com/bakku/easyalert/syslogd"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+var router = easyalert.SyslogSource{
+	ID:       4,
+	Name:     "router",
+	Address:  "192.0.2.1",
+	Rules:    []easyalert.SyslogRule{{Facility: "auth", Severity: "warning", Pattern: "Failed password"}},
+	Verified: true,
+	UserID:   1,
+}
+
+func TestReceive_ShouldIgnoreUnknownAddresses(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	sourceRepo := mocks.NewMockSyslogSourceRepository(mockCtrl)
+	sourceRepo.EXPECT().FindSyslogSources("").Return([]easyalert.SyslogSource{router}, nil)
+
+	s := &syslogd.Server{SourceRepo: sourceRepo}
+
+	err := s.Receive("192.0.2.9", []byte("<34>sshd: Failed password for root"), now)
+	require.Nil(t, err)
+}
+
+func TestReceive_ShouldIgnoreMessagesNotMatchingAnyRule(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	sourceRepo := mocks.NewMockSyslogSourceRepository(mockCtrl)
+	sourceRepo.EXPECT().FindSyslogSources("").Return([]easyalert.SyslogSource{router}, nil)
+
+	s := &syslogd.Server{SourceRepo: sourceRepo}
+
+	err := s.Receive("192.0.2.1", []byte("<38>sshd: Failed password for root"), now)
+	require.Nil(t, err)
+}
+
+func TestReceive_ShouldCreateAndNotifyAlert(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	user := easyalert.User{ID: 1}
+
+	sourceRepo := mocks.NewMockSyslogSourceRepository(mockCtrl)
+	sourceRepo.EXPECT().FindSyslogSources("").Return([]easyalert.SyslogSource{router}, nil)
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser("WHERE id = $1", uint(1)).Return(user, nil)
+
+	var dedupKey string
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().FoldAlert(gomock.Any(), uint(1), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(query string, params ...interface{}) (easyalert.Alert, error) {
+		dedupKey = params[1].(string)
+		return easyalert.Alert{}, easyalert.ErrRecordDoesNotExist
+	})
+	alertRepo.EXPECT().CreateAlert(gomock.Any()).DoAndReturn(func(alert easyalert.Alert) (easyalert.Alert, error) {
+		require.Equal(t, "sshd crit on gw1", alert.Subject)
+		require.Equal(t, uint(easyalert.AlertSeverityCritical), alert.Severity)
+		require.Equal(t, map[string]string{"source": "syslog", "syslog_source": "router", "facility": "auth", "host": "gw1"}, alert.Labels)
+		require.Equal(t, dedupKey, alert.DedupKey)
+		require.Regexp(t, "^syslog:4:[0-9a-f]{16}$", alert.DedupKey)
+
+		alert.ID = 7
+		return alert, nil
+	})
+
+	notifier := mocks.NewMockNotifier(mockCtrl)
+	notifier.EXPECT().Notify(user, gomock.Any()).Do(func(user easyalert.User, n easyalert.Notification) {
+		require.Equal(t, uint(7), n.Alert.ID)
+		require.Equal(t, "Source: router (192.0.2.1)\n"+
+			"Host: gw1\n"+
+			"App: sshd\n"+
+			"Facility: auth\n"+
+			"Severity: crit\n"+
+			"Time: 2019-01-30T11:00:00Z\n\n"+
+			"Failed password for root from 198.51.100.7 port 40022", n.Message)
+	})
+
+	s := &syslogd.Server{SourceRepo: sourceRepo, UserRepo: userRepo, AlertRepo: alertRepo, Notifier: notifier}
+
+	err := s.Receive("192.0.2.1", []byte("<34>Jan 30 11:00:00 gw1 sshd[99]: Failed password for root from 198.51.100.7 port 40022"), now)
+	require.Nil(t, err)
+}
+
+func TestReceive_ShouldFoldMessagesOnlyDifferingInNumbers(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	sourceRepo := mocks.NewMockSyslogSourceRepository(mockCtrl)
+	sourceRepo.EXPECT().FindSyslogSources("").Return([]easyalert.SyslogSource{router}, nil)
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil).Times(2)
+
+	var keys []string
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().FoldAlert(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(query string, params ...interface{}) (easyalert.Alert, error) {
+		keys = append(keys, params[1].(string))
+		return easyalert.Alert{ID: 7, Occurrences: 2}, nil
+	}).Times(2)
+
+	s := &syslogd.Server{SourceRepo: sourceRepo, UserRepo: userRepo, AlertRepo: alertRepo, Notifier: mocks.NewMockNotifier(mockCtrl)}
+
+	require.Nil(t, s.Receive("192.0.2.1", []byte("<34>sshd[99]: Failed password for root from 198.51.100.7 port 40022"), now))
+	require.Nil(t, s.Receive("192.0.2.1", []byte("<34>sshd[100]: Failed password for root from 198.51.100.8 port 51234"), now))
+
+	require.Equal(t, keys[0], keys[1])
+}
+
+func TestServeUDP_ShouldReceiveDatagrams(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	received := make(chan string, 1)
+
+	sourceRepo := mocks.NewMockSyslogSourceRepository(mockCtrl)
+	sourceRepo.EXPECT().FindSyslogSources("").Return([]easyalert.SyslogSource{{ID: 5, Name: "local", Address: "127.0.0.1", Rules: router.Rules, Verified: true, UserID: 1}}, nil)
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).DoAndReturn(func(query string, params ...interface{}) (easyalert.User, error) {
+		received <- "received"
+		return easyalert.User{}, errors.New("Error!!")
+	})
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.Nil(t, err)
+	defer conn.Close()
+
+	s := &syslogd.Server{SourceRepo: sourceRepo, UserRepo: userRepo}
+	go s.ServeUDP(conn)
+
+	client, err := net.Dial("udp", conn.LocalAddr().String())
+	require.Nil(t, err)
+	defer client.Close()
+
+	_, err = client.Write([]byte("<34>sshd: Failed password for root"))
+	require.Nil(t, err)
+
+	require.Equal(t, "received", <-received)
+}
+
+func TestReceive_ShouldIgnoreUnverifiedSources(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	unverified := router
+	unverified.Verified = false
+	unverified.VerificationToken = "abcdef"
+
+	sourceRepo := mocks.NewMockSyslogSourceRepository(mockCtrl)
+	sourceRepo.EXPECT().FindSyslogSources("").Return([]easyalert.SyslogSource{unverified}, nil)
+
+	s := &syslogd.Server{SourceRepo: sourceRepo}
+
+	err := s.Receive("192.0.2.1", []byte("<34>sshd: Failed password for root abcdef"), now)
+	require.Nil(t, err)
+}
+
+func TestServeTCP_ShouldVerifySourceOfTheSendingAddress(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	verified := make(chan uint, 1)
+
+	// both users claim the address, only the token of the second one is sent
+	claims := []easyalert.SyslogSource{
+		{ID: 5, Name: "local", Address: "127.0.0.1", VerificationToken: "abcdef", UserID: 1},
+		{ID: 6, Name: "local", Address: "127.0.0.1", VerificationToken: "ghijkl", UserID: 2},
+	}
+
+	sourceRepo := mocks.NewMockSyslogSourceRepository(mockCtrl)
+	sourceRepo.EXPECT().FindSyslogSources("").Return(claims, nil)
+	sourceRepo.EXPECT().VerifySyslogSource(claims[1]).DoAndReturn(func(source easyalert.SyslogSource) (easyalert.SyslogSource, error) {
+		verified <- source.ID
+		return source, nil
+	})
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	defer l.Close()
+
+	s := &syslogd.Server{SourceRepo: sourceRepo}
+	go s.ServeTCP(l)
+
+	client, err := net.Dial("tcp", l.Addr().String())
+	require.Nil(t, err)
+	defer client.Close()
+
+	_, err = client.Write([]byte("<14>verify ghijkl\n"))
+	require.Nil(t, err)
+
+	require.Equal(t, uint(6), <-verified)
+}
+
+func TestVerify_ShouldIgnoreOtherAddresses(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	sourceRepo := mocks.NewMockSyslogSourceRepository(mockCtrl)
+	sourceRepo.EXPECT().FindSyslogSources("").Return([]easyalert.SyslogSource{
+		{ID: 5, Name: "router", Address: "192.0.2.1", VerificationToken: "abcdef", UserID: 1},
+	}, nil)
+
+	s := &syslogd.Server{SourceRepo: sourceRepo}
+
+	verified, err := s.Verify("192.0.2.9", []byte("<14>verify abcdef"), now)
+	require.Nil(t, err)
+	require.False(t, verified)
+}
+
+func TestReceive_ShouldReloadSourcesAfterRefreshInterval(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	sourceRepo := mocks.NewMockSyslogSourceRepository(mockCtrl)
+	gomock.InOrder(
+		sourceRepo.EXPECT().FindSyslogSources("").Return(nil, nil),
+		sourceRepo.EXPECT().FindSyslogSources("").Return([]easyalert.SyslogSource{router}, nil),
+	)
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{}, errors.New("Error!!"))
+
+	s := &syslogd.Server{SourceRepo: sourceRepo, UserRepo: userRepo, RefreshInterval: time.Minute}
+
+	data := []byte("<34>sshd: Failed password for root")
+
+	require.Nil(t, s.Receive("192.0.2.1", data, now))
+	require.Nil(t, s.Receive("192.0.2.1", data, now.Add(30*time.Second)))
+	require.NotNil(t, s.Receive("192.0.2.1", data, now.Add(time.Minute)))
+}
+
+func TestReceive_ShouldDropMessagesOverTheRateLimit(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	sourceRepo := mocks.NewMockSyslogSourceRepository(mockCtrl)
+	sourceRepo.EXPECT().FindSyslogSources("").Return([]easyalert.SyslogSource{router}, nil).AnyTimes()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{}, errors.New("Error!!")).Times(3)
+
+	s := &syslogd.Server{SourceRepo: sourceRepo, UserRepo: userRepo, RateLimit: 2}
+
+	data := []byte("<34>sshd: Failed password for root")
+
+	require.NotNil(t, s.Receive("192.0.2.1", data, now))
+	require.NotNil(t, s.Receive("192.0.2.1", data, now.Add(time.Second)))
+	require.Nil(t, s.Receive("192.0.2.1", data, now.Add(2*time.Second)))
+	require.NotNil(t, s.Receive("192.0.2.1", data, now.Add(time.Minute)))
+}
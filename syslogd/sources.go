@@ -0,0 +1,128 @@
+package syslogd
+
+import (
+	"log"
+	"time"
+
+	"github.com/bakku/easyalert"
+)
+
+const (
+	defaultRefreshInterval = 10 * time.Second
+	defaultRateLimit       = 60
+)
+
+// cachedSource is a source with its rules compiled.
+type cachedSource struct {
+	source easyalert.SyslogSource
+	rules  []rule
+}
+
+// rateWindow counts the matching messages of a source within a minute.
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+// source returns the verified source of the address. All sources are kept in
+// memory and reloaded once the refresh interval passed, so a message only
+// hits the database if it matches a rule.
+func (s *Server) source(host string, now time.Time) (cachedSource, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.load(now)
+	if err != nil {
+		return cachedSource{}, false, err
+	}
+
+	cached, ok := s.sources[host]
+
+	return cached, ok, nil
+}
+
+// unverified returns the unverified sources claiming the address.
+func (s *Server) unverified(host string, now time.Time) ([]easyalert.SyslogSource, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.load(now)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.claims[host], nil
+}
+
+// load reloads the sources once the refresh interval passed. The mutex has
+// to be held.
+func (s *Server) load(now time.Time) error {
+	interval := s.RefreshInterval
+	if interval == 0 {
+		interval = defaultRefreshInterval
+	}
+
+	if s.sources != nil && now.Sub(s.loadedAt) < interval && !now.Before(s.loadedAt) {
+		return nil
+	}
+
+	sources, err := s.SourceRepo.FindSyslogSources("")
+	if err != nil {
+		return err
+	}
+
+	s.sources = make(map[string]cachedSource, len(sources))
+	s.claims = make(map[string][]easyalert.SyslogSource)
+
+	for _, source := range sources {
+		if source.Verified {
+			s.sources[source.Address] = cachedSource{source, compileRules(source.Rules)}
+		} else if source.VerificationToken != "" {
+			s.claims[source.Address] = append(s.claims[source.Address], source)
+		}
+	}
+
+	s.loadedAt = now
+
+	return nil
+}
+
+// reload makes the next message reload the sources.
+func (s *Server) reload() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sources = nil
+}
+
+// allow reports whether another message of the source may create or fold an
+// alert within the current minute. Messages over the limit are dropped.
+func (s *Server) allow(source easyalert.SyslogSource, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	limit := s.RateLimit
+	if limit == 0 {
+		limit = defaultRateLimit
+	}
+
+	if s.windows == nil {
+		s.windows = make(map[uint]*rateWindow)
+	}
+
+	w, ok := s.windows[source.ID]
+	if !ok || now.Sub(w.start) >= time.Minute || now.Before(w.start) {
+		w = &rateWindow{start: now}
+		s.windows[source.ID] = w
+	}
+
+	w.count++
+
+	// only the first dropped message is logged, so a log storm does not
+	// flood the log of the server as well
+	if w.count == limit+1 {
+		log.Printf("Syslog source %d exceeded %d messages per minute, dropping messages", source.ID, limit)
+	}
+
+	return w.count <= limit
+}
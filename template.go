@@ -0,0 +1,285 @@
+package easyalert
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+)
+
+// TemplateRepository wraps all CRUD operations for templates
+type TemplateRepository interface {
+	FindTemplate(query string, params ...interface{}) (Template, error)
+	FindTemplates(query string, params ...interface{}) ([]Template, error)
+	CreateTemplate(tmpl Template) (Template, error)
+	UpdateTemplate(tmpl Template) (Template, error)
+	DeleteTemplate(tmpl Template) error
+}
+
+// Template describes the subject and message of alerts which are sent from
+// many places. Both are Go text/templates which are rendered with the
+// variables given when creating an alert.
+type Template struct {
+	ID        uint
+	Name      string
+	Subject   string
+	Body      string
+	Format    string
+	UserID    uint
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// templateFuncs are the only functions templates may call next to the
+// builtin comparison and logic functions.
+var templateFuncs = template.FuncMap{
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"trim":  strings.TrimSpace,
+	"truncate": func(n int, s string) string {
+		if n < 0 || len([]rune(s)) <= n {
+			return s
+		}
+
+		return string([]rune(s)[:n])
+	},
+}
+
+var allowedBuiltins = map[string]bool{
+	"and": true, "or": true, "not": true, "len": true,
+	"eq": true, "ne": true, "lt": true, "le": true, "gt": true, "ge": true,
+}
+
+// MissingVariablesError is returned if a template references variables which were not given.
+type MissingVariablesError struct {
+	Names []string
+}
+
+func (e MissingVariablesError) Error() string {
+	return "Missing template variables: " + strings.Join(e.Names, ", ") + "."
+}
+
+// InvalidTemplateError is returned if the subject or body of a template can not be parsed.
+type InvalidTemplateError struct {
+	Field string
+	Err   error
+}
+
+func (e InvalidTemplateError) Error() string {
+	return fmt.Sprintf("Invalid %s template: %v", e.Field, e.Err)
+}
+
+// Validate checks that subject and body are valid templates which only use
+// allowed functions and no loops or nested templates.
+func (t Template) Validate() error {
+	_, err := parseTemplate("subject", t.Subject)
+	if err != nil {
+		return InvalidTemplateError{"subject", err}
+	}
+
+	_, err = parseTemplate("body", t.Body)
+	if err != nil {
+		return InvalidTemplateError{"body", err}
+	}
+
+	return nil
+}
+
+// Render renders subject and body with the given variables. All variables
+// referenced by the templates have to be given.
+func (t Template) Render(vars map[string]string) (subject, body string, err error) {
+	subjectTmpl, err := parseTemplate("subject", t.Subject)
+	if err != nil {
+		return "", "", err
+	}
+
+	bodyTmpl, err := parseTemplate("body", t.Body)
+	if err != nil {
+		return "", "", err
+	}
+
+	var missing []string
+
+	for _, name := range append(templateVariables(subjectTmpl), templateVariables(bodyTmpl)...) {
+		if _, ok := vars[name]; !ok && !containsString(missing, name) {
+			missing = append(missing, name)
+		}
+	}
+
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return "", "", MissingVariablesError{missing}
+	}
+
+	subject, err = execute(subjectTmpl, vars)
+	if err != nil {
+		return "", "", err
+	}
+
+	// a subject spanning multiple lines would break the mail header
+	subject = strings.Join(strings.Fields(subject), " ")
+
+	body, err = execute(bodyTmpl, vars)
+	if err != nil {
+		return "", "", err
+	}
+
+	return subject, body, nil
+}
+
+func parseTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tmpl.Templates()) > 1 {
+		return nil, errors.New("defining templates is not allowed")
+	}
+
+	err = checkNode(tmpl.Tree.Root)
+	if err != nil {
+		return nil, err
+	}
+
+	return tmpl, nil
+}
+
+// checkNode rejects everything which could make rendering expensive, like
+// loops and nested templates, and calls of functions which are not allowed.
+func checkNode(node parse.Node) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+
+		for _, child := range n.Nodes {
+			err := checkNode(child)
+			if err != nil {
+				return err
+			}
+		}
+	case *parse.ActionNode:
+		return checkNode(n.Pipe)
+	case *parse.IfNode:
+		return checkBranch(n.BranchNode)
+	case *parse.WithNode:
+		return checkBranch(n.BranchNode)
+	case *parse.PipeNode:
+		if n == nil {
+			return nil
+		}
+
+		for _, cmd := range n.Cmds {
+			for _, arg := range cmd.Args {
+				err := checkNode(arg)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	case *parse.IdentifierNode:
+		if _, ok := templateFuncs[n.Ident]; !ok && !allowedBuiltins[n.Ident] {
+			return fmt.Errorf("function %q is not allowed", n.Ident)
+		}
+	case *parse.RangeNode:
+		return errors.New("range is not allowed")
+	case *parse.TemplateNode:
+		return errors.New("nested templates are not allowed")
+	case *parse.TextNode, *parse.FieldNode, *parse.VariableNode, *parse.DotNode,
+		*parse.StringNode, *parse.NumberNode, *parse.BoolNode, *parse.NilNode:
+		return nil
+	default:
+		return fmt.Errorf("%s is not allowed", node)
+	}
+
+	return nil
+}
+
+func checkBranch(n parse.BranchNode) error {
+	err := checkNode(n.Pipe)
+	if err != nil {
+		return err
+	}
+
+	err = checkNode(n.List)
+	if err != nil {
+		return err
+	}
+
+	return checkNode(n.ElseList)
+}
+
+// templateVariables returns the names of all variables referenced as .name or $.name.
+func templateVariables(tmpl *template.Template) []string {
+	var names []string
+
+	var walk func(node parse.Node)
+	walk = func(node parse.Node) {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+
+			for _, child := range n.Nodes {
+				walk(child)
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe)
+		case *parse.IfNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.WithNode:
+			// the dot is changed inside with, so only its pipeline refers to variables
+			walk(n.Pipe)
+		case *parse.PipeNode:
+			if n == nil {
+				return
+			}
+
+			for _, cmd := range n.Cmds {
+				for _, arg := range cmd.Args {
+					walk(arg)
+				}
+			}
+		case *parse.FieldNode:
+			names = append(names, n.Ident[0])
+		case *parse.VariableNode:
+			if n.Ident[0] == "$" && len(n.Ident) > 1 {
+				names = append(names, n.Ident[1])
+			}
+		}
+	}
+
+	walk(tmpl.Tree.Root)
+
+	return names
+}
+
+func execute(tmpl *template.Template, vars map[string]string) (string, error) {
+	var buf bytes.Buffer
+
+	err := tmpl.Execute(&buf, vars)
+	if err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,59 @@
+package easyalert_test
+
+import (
+	"testing"
+
+	"github.com/bakku/easyalert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateRender(t *testing.T) {
+	tmpl := easyalert.Template{
+		Subject: "Backup of {{.host}} failed",
+		Body:    "Job {{.job | upper}} exited with {{$.code}}.{{if .details}}\n{{truncate 5 .details}}{{end}}",
+	}
+
+	subject, body, err := tmpl.Render(map[string]string{"host": "db1", "job": "nightly", "code": "12", "details": "disk full"})
+	require.Nil(t, err)
+
+	require.Equal(t, "Backup of db1 failed", subject)
+	require.Equal(t, "Job NIGHTLY exited with 12.\ndisk ", body)
+}
+
+func TestTemplateRender_MissingVariables(t *testing.T) {
+	tmpl := easyalert.Template{
+		Subject: "Backup of {{.host}} failed",
+		Body:    "Job {{.job}} on {{.host}} exited with {{.code}}.",
+	}
+
+	_, _, err := tmpl.Render(map[string]string{"job": "nightly"})
+	require.Equal(t, easyalert.MissingVariablesError{Names: []string{"code", "host"}}, err)
+	require.Equal(t, "Missing template variables: code, host.", err.Error())
+}
+
+func TestTemplateRender_SubjectOnOneLine(t *testing.T) {
+	tmpl := easyalert.Template{Subject: "Backup of {{.host}}\nfailed", Body: "-"}
+
+	subject, _, err := tmpl.Render(map[string]string{"host": "db1\r\nBcc: someone@example.com"})
+	require.Nil(t, err)
+
+	require.Equal(t, "Backup of db1 Bcc: someone@example.com failed", subject)
+}
+
+func TestTemplateValidate(t *testing.T) {
+	valid := easyalert.Template{Subject: "{{.host | lower}}", Body: "{{if eq .code \"0\"}}ok{{else}}{{.code}}{{end}}"}
+	require.Nil(t, valid.Validate())
+
+	invalid := []easyalert.Template{
+		{Subject: "{{.host", Body: ""},
+		{Subject: "", Body: "{{range .hosts}}{{.}}{{end}}"},
+		{Subject: "", Body: "{{printf \"%0999999999d\" 1}}"},
+		{Subject: "", Body: "{{define \"x\"}}{{end}}"},
+		{Subject: "", Body: "{{template \"body\"}}"},
+		{Subject: "{{call .fn}}", Body: ""},
+	}
+
+	for _, tmpl := range invalid {
+		require.NotNil(t, tmpl.Validate(), tmpl)
+	}
+}
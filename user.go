@@ -1,6 +1,7 @@
 package easyalert
 
 import (
+	"regexp"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
@@ -8,6 +9,31 @@ import (
 
 const UserTokenLength = 32
 
+// InboundTokenLength is the length of the token in inbound email addresses.
+const InboundTokenLength = 32
+
+// Phone numbers are verified by sending a code of PhoneCodeLength digits to
+// them which must be entered within PhoneCodeValidity. After
+// MaxPhoneCodeAttempts wrong entries a new code has to be requested. Since
+// every text message costs money, at most MaxPhoneCodesPerDay codes are sent
+// to a user per day and at most one per PhoneCodeResendInterval.
+const (
+	PhoneCodeLength         = 6
+	PhoneCodeValidity       = 10 * time.Minute
+	MaxPhoneCodeAttempts    = 5
+	MaxPhoneCodesPerDay     = 5
+	PhoneCodeResendInterval = time.Minute
+)
+
+// phoneNumberPattern matches phone numbers in the E.164 format.
+var phoneNumberPattern = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
+
+// ValidPhoneNumber returns whether s is a phone number in the E.164 format
+// like +4915112345678.
+func ValidPhoneNumber(s string) bool {
+	return phoneNumberPattern.MatchString(s)
+}
+
 // UserRepository wraps all CRUD operations for users
 type UserRepository interface {
 	FindUser(query string, params ...interface{}) (User, error)
@@ -15,6 +41,19 @@ type UserRepository interface {
 	CreateUser(user User) (User, error)
 	UpdateUser(user User) (User, error)
 	DeleteUser(user User) error
+
+	// SavePhoneCode saves the phone number and code set by SetPhoneNumber
+	// and counts the code towards the daily limit. So that concurrent
+	// requests cannot send more codes, the limits are checked while saving
+	// and ErrPhoneCodeLimit is returned if a code was sent within
+	// PhoneCodeResendInterval or MaxPhoneCodesPerDay codes within a day
+	// before now.
+	SavePhoneCode(user User, now time.Time) (User, error)
+	// CountPhoneCodeAttempt counts an attempt to verify the code of the
+	// user before it is compared, so that concurrent guesses count as
+	// well. ErrPhoneCodeLimit is returned if the code expired, was replaced
+	// or MaxPhoneCodeAttempts were made.
+	CountPhoneCodeAttempt(user User, now time.Time) (User, error)
 }
 
 // User defines all fields of the user model
@@ -25,6 +64,26 @@ type User struct {
 	Token          string
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
+
+	// InboundToken is the local part of the address emails are sent to to
+	// create alerts. Unlike Token it grants no access to the API, since it
+	// ends up in the configuration of appliances and in mail logs. It is
+	// empty until it is generated for the first time.
+	InboundToken string
+
+	// PhoneNumber only receives alerts once PhoneVerified is set. Until
+	// then the digest of the code sent to it is kept.
+	PhoneNumber        string
+	PhoneVerified      bool
+	PhoneCodeDigest    string
+	PhoneCodeExpiresAt *time.Time
+	PhoneCodeAttempts  uint
+
+	// PhoneCodesSent counts the codes sent since PhoneCodesSentSince. It is
+	// kept when the phone number is removed, so removing it does not lift
+	// the daily limit.
+	PhoneCodesSent      uint
+	PhoneCodesSentSince *time.Time
 }
 
 func (u *User) HashPassword(pass string) error {
@@ -41,3 +100,69 @@ func (u *User) HashPassword(pass string) error {
 func (u *User) ValidPassword(pass string) bool {
 	return bcrypt.CompareHashAndPassword([]byte(u.PasswordDigest), []byte(pass)) == nil
 }
+
+// SetPhoneNumber replaces the phone number with an unverified one which is
+// verified by the given code until expiresAt.
+func (u *User) SetPhoneNumber(number, code string, expiresAt time.Time) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	u.PhoneNumber = number
+	u.PhoneVerified = false
+	u.PhoneCodeDigest = string(hash)
+	u.PhoneCodeExpiresAt = &expiresAt
+	u.PhoneCodeAttempts = 0
+
+	return nil
+}
+
+// PhoneCodeSentRecently returns whether the current code was sent less than
+// PhoneCodeResendInterval before now.
+func (u *User) PhoneCodeSentRecently(now time.Time) bool {
+	return u.PhoneCodeExpiresAt != nil && now.Before(u.PhoneCodeExpiresAt.Add(PhoneCodeResendInterval-PhoneCodeValidity))
+}
+
+// PhoneCodeLimitReached returns whether MaxPhoneCodesPerDay codes were sent
+// within the last day before now.
+func (u *User) PhoneCodeLimitReached(now time.Time) bool {
+	if u.PhoneCodesSentSince == nil || !now.Before(u.PhoneCodesSentSince.Add(24*time.Hour)) {
+		return false
+	}
+
+	return u.PhoneCodesSent >= MaxPhoneCodesPerDay
+}
+
+// VerifyPhoneNumber marks the phone number as verified if the code is the
+// one sent to it, did not expire and was not guessed too often. The attempt
+// has to be counted by UserRepository.CountPhoneCodeAttempt before.
+func (u *User) VerifyPhoneNumber(code string, now time.Time) bool {
+	if u.PhoneCodeDigest == "" || u.PhoneCodeExpiresAt == nil || !now.Before(*u.PhoneCodeExpiresAt) {
+		return false
+	}
+
+	if u.PhoneCodeAttempts > MaxPhoneCodeAttempts {
+		return false
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(u.PhoneCodeDigest), []byte(code)) != nil {
+		return false
+	}
+
+	u.PhoneVerified = true
+	u.PhoneCodeDigest = ""
+	u.PhoneCodeExpiresAt = nil
+	u.PhoneCodeAttempts = 0
+
+	return true
+}
+
+// RemovePhoneNumber removes the phone number together with a pending code.
+func (u *User) RemovePhoneNumber() {
+	u.PhoneNumber = ""
+	u.PhoneVerified = false
+	u.PhoneCodeDigest = ""
+	u.PhoneCodeExpiresAt = nil
+	u.PhoneCodeAttempts = 0
+}
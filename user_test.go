@@ -2,6 +2,7 @@ package easyalert_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/bakku/easyalert"
 	"github.com/stretchr/testify/require"
@@ -32,3 +33,83 @@ func TestValidPassword_ReturnsFalseIfInvalid(t *testing.T) {
 
 	require.False(t, u.ValidPassword("test123"))
 }
+
+func TestValidPhoneNumber(t *testing.T) {
+	require.True(t, easyalert.ValidPhoneNumber("+4915112345678"))
+	require.False(t, easyalert.ValidPhoneNumber("015112345678"))
+	require.False(t, easyalert.ValidPhoneNumber("+49 151 12345678"))
+	require.False(t, easyalert.ValidPhoneNumber("+0151123"))
+	require.False(t, easyalert.ValidPhoneNumber("+1234567890123456"))
+}
+
+func TestVerifyPhoneNumber_ReturnsTrueIfCodeIsValid(t *testing.T) {
+	now := time.Date(2019, 2, 13, 18, 0, 0, 0, time.UTC)
+
+	u := easyalert.User{}
+	err := u.SetPhoneNumber("+4915112345678", "123456", now.Add(easyalert.PhoneCodeValidity))
+	require.Nil(t, err)
+	require.False(t, u.PhoneVerified)
+
+	require.True(t, u.VerifyPhoneNumber("123456", now))
+	require.True(t, u.PhoneVerified)
+	require.Equal(t, "", u.PhoneCodeDigest)
+	require.Nil(t, u.PhoneCodeExpiresAt)
+}
+
+func TestVerifyPhoneNumber_ReturnsFalseIfCodeExpired(t *testing.T) {
+	now := time.Date(2019, 2, 13, 18, 0, 0, 0, time.UTC)
+
+	u := easyalert.User{}
+	err := u.SetPhoneNumber("+4915112345678", "123456", now)
+	require.Nil(t, err)
+
+	require.False(t, u.VerifyPhoneNumber("123456", now))
+	require.False(t, u.PhoneVerified)
+}
+
+func TestVerifyPhoneNumber_ReturnsFalseAfterTooManyAttempts(t *testing.T) {
+	now := time.Date(2019, 2, 13, 18, 0, 0, 0, time.UTC)
+
+	u := easyalert.User{}
+	err := u.SetPhoneNumber("+4915112345678", "123456", now.Add(time.Minute))
+	require.Nil(t, err)
+
+	u.PhoneCodeAttempts = easyalert.MaxPhoneCodeAttempts
+	require.True(t, u.VerifyPhoneNumber("123456", now))
+
+	u.PhoneVerified = false
+	err = u.SetPhoneNumber("+4915112345678", "123456", now.Add(time.Minute))
+	require.Nil(t, err)
+
+	u.PhoneCodeAttempts = easyalert.MaxPhoneCodeAttempts + 1
+	require.False(t, u.VerifyPhoneNumber("123456", now))
+	require.False(t, u.PhoneVerified)
+}
+
+func TestPhoneCodeLimitReached_ShouldLimitCodesPerDay(t *testing.T) {
+	now := time.Date(2019, 2, 19, 12, 0, 0, 0, time.UTC)
+
+	var user easyalert.User
+	require.False(t, user.PhoneCodeLimitReached(now))
+
+	user.PhoneCodesSent = easyalert.MaxPhoneCodesPerDay - 1
+	user.PhoneCodesSentSince = &now
+	require.False(t, user.PhoneCodeLimitReached(now.Add(time.Hour)))
+
+	user.PhoneCodesSent = easyalert.MaxPhoneCodesPerDay
+	require.True(t, user.PhoneCodeLimitReached(now.Add(23*time.Hour)))
+	require.False(t, user.PhoneCodeLimitReached(now.Add(24*time.Hour)))
+}
+
+func TestPhoneCodeSentRecently(t *testing.T) {
+	now := time.Date(2019, 2, 19, 12, 0, 0, 0, time.UTC)
+
+	var user easyalert.User
+	require.False(t, user.PhoneCodeSentRecently(now))
+
+	err := user.SetPhoneNumber("+4915112345678", "123456", now.Add(easyalert.PhoneCodeValidity))
+	require.Nil(t, err)
+
+	require.True(t, user.PhoneCodeSentRecently(now.Add(59*time.Second)))
+	require.False(t, user.PhoneCodeSentRecently(now.Add(easyalert.PhoneCodeResendInterval)))
+}
@@ -0,0 +1,95 @@
+package watchdog
+
+import (
+	"log"
+	"time"
+
+	"github.com/bakku/easyalert"
+)
+
+// DefaultInterval is used if a Watchdog is not given an interval.
+const DefaultInterval = time.Minute
+
+// Watchdog periodically checks all heartbeats and creates an alert for
+// every heartbeat which was not pinged in time.
+type Watchdog struct {
+	UserRepo      easyalert.UserRepository
+	HeartbeatRepo easyalert.HeartbeatRepository
+	AlertRepo     easyalert.AlertRepository
+	Notifier      easyalert.Notifier
+	Interval      time.Duration
+}
+
+// Run checks the heartbeats every interval until stop is closed.
+func (w Watchdog) Run(stop <-chan struct{}) {
+	interval := w.Interval
+	if interval == 0 {
+		interval = DefaultInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			err := w.Check(now)
+			if err != nil {
+				log.Println("Watchdog error:", err)
+			}
+		}
+	}
+}
+
+// Check marks all heartbeats which are overdue at the given time as down
+// and creates an alert for each of them. Heartbeats which are already down
+// are skipped so that a missing job only results in a single alert. A
+// heartbeat is marked before its alert is created, so a ping arriving in
+// between or a second watchdog never results in a wrong or duplicate alert.
+// Errors of a single heartbeat are logged and do not stop the others.
+func (w Watchdog) Check(now time.Time) error {
+	heartbeats, err := w.HeartbeatRepo.FindHeartbeats("WHERE status <> $1", easyalert.HeartbeatStatusDown)
+	if err != nil {
+		return err
+	}
+
+	for _, heartbeat := range heartbeats {
+		if !heartbeat.Overdue(now) {
+			continue
+		}
+
+		err := w.markDown(heartbeat)
+		if err != nil {
+			log.Printf("Watchdog error for heartbeat %d: %v", heartbeat.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (w Watchdog) markDown(heartbeat easyalert.Heartbeat) error {
+	heartbeat, err := w.HeartbeatRepo.MarkHeartbeatDown(heartbeat)
+	if err == easyalert.ErrRecordDoesNotExist {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	alert, err := w.AlertRepo.CreateAlert(heartbeat.MissedAlert())
+	if err != nil {
+		return err
+	}
+
+	user, err := w.UserRepo.FindUser("WHERE id = $1", heartbeat.UserID)
+	if err != nil {
+		return err
+	}
+
+	w.Notifier.Notify(user, easyalert.Notification{Alert: alert})
+
+	return nil
+}
@@ -0,0 +1,139 @@
+package watchdog_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/mocks"
+	"github.com/bakku/easyalert/watchdog"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck_ShouldReturnErrorIfFetchingHeartbeatsFails(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	heartbeatRepo := mocks.NewMockHeartbeatRepository(mockCtrl)
+	heartbeatRepo.EXPECT().FindHeartbeats(gomock.Any(), gomock.Any()).Return(nil, errors.New("Error!!"))
+
+	w := watchdog.Watchdog{HeartbeatRepo: heartbeatRepo}
+
+	err := w.Check(time.Now())
+	require.NotNil(t, err)
+}
+
+func TestCheck_ShouldCreateAlertForOverdueHeartbeats(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	now := time.Date(2019, 1, 7, 12, 0, 0, 0, time.UTC)
+	recentPing := now.Add(-30 * time.Minute)
+	oldPing := now.Add(-2 * time.Hour)
+
+	healthy := easyalert.Heartbeat{ID: 1, Name: "cleanup", Interval: time.Hour, Status: easyalert.HeartbeatStatusUp, LastPingAt: &recentPing, UserID: 1}
+	overdue := easyalert.Heartbeat{ID: 2, Name: "backup", Interval: time.Hour, Status: easyalert.HeartbeatStatusUp, LastPingAt: &oldPing, UserID: 1}
+
+	heartbeatRepo := mocks.NewMockHeartbeatRepository(mockCtrl)
+	heartbeatRepo.EXPECT().FindHeartbeats(gomock.Any(), easyalert.HeartbeatStatusDown).Return([]easyalert.Heartbeat{healthy, overdue}, nil)
+	heartbeatRepo.EXPECT().MarkHeartbeatDown(overdue).DoAndReturn(func(h easyalert.Heartbeat) (easyalert.Heartbeat, error) {
+		h.Status = easyalert.HeartbeatStatusDown
+		return h, nil
+	})
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().CreateAlert(overdue.MissedAlert()).Return(easyalert.Alert{ID: 3}, nil)
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser("WHERE id = $1", uint(1)).Return(easyalert.User{ID: 1}, nil)
+
+	notifier := mocks.NewMockNotifier(mockCtrl)
+	notifier.EXPECT().Notify(easyalert.User{ID: 1}, easyalert.Notification{Alert: easyalert.Alert{ID: 3}})
+
+	w := watchdog.Watchdog{UserRepo: userRepo, HeartbeatRepo: heartbeatRepo, AlertRepo: alertRepo, Notifier: notifier}
+
+	err := w.Check(now)
+	require.Nil(t, err)
+}
+
+func TestCheck_ShouldCreateAlertForMissedScheduledRun(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	lastPingAt := time.Date(2019, 1, 9, 2, 3, 0, 0, time.UTC)
+
+	// the run at 02:00 on the 10th did not ping
+	missed := easyalert.Heartbeat{ID: 1, Name: "backup", Schedule: "0 2 * * *", GracePeriod: 30 * time.Minute, Status: easyalert.HeartbeatStatusUp, LastPingAt: &lastPingAt, UserID: 1}
+
+	heartbeatRepo := mocks.NewMockHeartbeatRepository(mockCtrl)
+	heartbeatRepo.EXPECT().FindHeartbeats(gomock.Any(), gomock.Any()).Return([]easyalert.Heartbeat{missed}, nil).Times(2)
+	heartbeatRepo.EXPECT().MarkHeartbeatDown(missed).Return(missed, nil)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().CreateAlert(missed.MissedAlert()).Return(easyalert.Alert{}, nil)
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{}, nil)
+
+	notifier := mocks.NewMockNotifier(mockCtrl)
+	notifier.EXPECT().Notify(gomock.Any(), gomock.Any())
+
+	w := watchdog.Watchdog{UserRepo: userRepo, HeartbeatRepo: heartbeatRepo, AlertRepo: alertRepo, Notifier: notifier}
+
+	err := w.Check(time.Date(2019, 1, 10, 2, 30, 0, 0, time.UTC))
+	require.Nil(t, err)
+
+	err = w.Check(time.Date(2019, 1, 10, 2, 31, 0, 0, time.UTC))
+	require.Nil(t, err)
+}
+
+func TestCheck_ShouldNotCreateAlertIfHeartbeatWasPingedInBetween(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	now := time.Date(2019, 1, 7, 12, 0, 0, 0, time.UTC)
+	oldPing := now.Add(-2 * time.Hour)
+
+	overdue := easyalert.Heartbeat{ID: 2, Name: "backup", Interval: time.Hour, Status: easyalert.HeartbeatStatusUp, LastPingAt: &oldPing, UserID: 1}
+
+	heartbeatRepo := mocks.NewMockHeartbeatRepository(mockCtrl)
+	heartbeatRepo.EXPECT().FindHeartbeats(gomock.Any(), gomock.Any()).Return([]easyalert.Heartbeat{overdue}, nil)
+	heartbeatRepo.EXPECT().MarkHeartbeatDown(overdue).Return(easyalert.Heartbeat{}, easyalert.ErrRecordDoesNotExist)
+
+	w := watchdog.Watchdog{HeartbeatRepo: heartbeatRepo, AlertRepo: mocks.NewMockAlertRepository(mockCtrl)}
+
+	err := w.Check(now)
+	require.Nil(t, err)
+}
+
+func TestCheck_ShouldContinueWithNextHeartbeatOnError(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	now := time.Date(2019, 1, 7, 12, 0, 0, 0, time.UTC)
+	oldPing := now.Add(-2 * time.Hour)
+
+	failing := easyalert.Heartbeat{ID: 1, Name: "cleanup", Interval: time.Hour, Status: easyalert.HeartbeatStatusUp, LastPingAt: &oldPing, UserID: 1}
+	overdue := easyalert.Heartbeat{ID: 2, Name: "backup", Interval: time.Hour, Status: easyalert.HeartbeatStatusUp, LastPingAt: &oldPing, UserID: 1}
+
+	heartbeatRepo := mocks.NewMockHeartbeatRepository(mockCtrl)
+	heartbeatRepo.EXPECT().FindHeartbeats(gomock.Any(), gomock.Any()).Return([]easyalert.Heartbeat{failing, overdue}, nil)
+	heartbeatRepo.EXPECT().MarkHeartbeatDown(failing).Return(easyalert.Heartbeat{}, errors.New("Error!!"))
+	heartbeatRepo.EXPECT().MarkHeartbeatDown(overdue).Return(overdue, nil)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().CreateAlert(overdue.MissedAlert()).Return(easyalert.Alert{ID: 3}, nil)
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	notifier := mocks.NewMockNotifier(mockCtrl)
+	notifier.EXPECT().Notify(gomock.Any(), gomock.Any())
+
+	w := watchdog.Watchdog{UserRepo: userRepo, HeartbeatRepo: heartbeatRepo, AlertRepo: alertRepo, Notifier: notifier}
+
+	err := w.Check(now)
+	require.Nil(t, err)
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/sms"
+	"github.com/gorilla/mux"
+)
+
+// alertPage shows an alert to whoever opens the link of a text message, so
+// it only contains what the text message and the alert listing show anyway.
+var alertPage = template.Must(template.New("alert").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Subject}}</title>
+</head>
+<body>
+<h1>[{{.HumanSeverity}}] {{.Subject}}</h1>
+<p>Status: {{.HumanStatus}}</p>
+<p>Created at: {{.CreatedAt.UTC.Format "2006-01-02 15:04:05 MST"}}</p>
+{{if gt .Occurrences 1}}<p>Seen {{.Occurrences}} times, last at {{.LastSeenAt.UTC.Format "2006-01-02 15:04:05 MST"}}</p>
+{{end}}{{if .Labels}}<ul>
+{{range $key, $value := .Labels}}<li>{{$key}}: {{$value}}</li>
+{{end}}</ul>
+{{end}}</body>
+</html>
+`))
+
+// AlertLinkHandler shows the alert of a short link sent in a text message.
+// The link is signed and expires, so it can be opened without logging in.
+type AlertLinkHandler struct {
+	AlertRepo easyalert.AlertRepository
+	Links     sms.LinkSigner
+}
+
+// ServeHTTP handles the HTTP request.
+func (h AlertLinkHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id, err := h.Links.Verify(mux.Vars(r)["token"], time.Now())
+	if err != nil {
+		writeError(w, http.StatusNotFound, "not_found", "The link is invalid or has expired.")
+		return
+	}
+
+	alert, err := h.AlertRepo.FindAlert("WHERE id = $1", id)
+	if err == easyalert.ErrRecordDoesNotExist {
+		writeError(w, http.StatusNotFound, "not_found", "Alert not found.")
+		return
+	}
+
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not fetch alert")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	alertPage.Execute(w, &alert)
+}
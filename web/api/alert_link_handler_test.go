@@ -0,0 +1,68 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/mocks"
+	"github.com/bakku/easyalert/sms"
+	"github.com/bakku/easyalert/web/api"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+var alertLinks = sms.LinkSigner{Secret: []byte("0123456789abcdef0123456789abcdef")}
+
+func TestAlertLink_ShouldShowAlertWithoutAuthorization(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().FindAlert(gomock.Any(), uint(5)).Return(easyalert.Alert{
+		ID:          5,
+		Subject:     "Backup <db1> failed",
+		Severity:    easyalert.AlertSeverityCritical,
+		Occurrences: 3,
+		LastSeenAt:  time.Date(2019, 2, 20, 3, 4, 5, 0, time.UTC),
+		Labels:      map[string]string{"host": "db1"},
+	}, nil)
+
+	req, err := http.NewRequest("GET", "/a/token", nil)
+	require.Nil(t, err)
+	req = mux.SetURLVars(req, map[string]string{"token": alertLinks.Sign(5, time.Now())})
+
+	rr := httptest.NewRecorder()
+	handler := api.AlertLinkHandler{AlertRepo: alertRepo, Links: alertLinks}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "text/html; charset=UTF-8", rr.Header().Get("Content-Type"))
+	require.Contains(t, rr.Body.String(), "<h1>[critical] Backup &lt;db1&gt; failed</h1>")
+	require.Contains(t, rr.Body.String(), "Seen 3 times, last at 2019-02-20 03:04:05 UTC")
+	require.Contains(t, rr.Body.String(), "<li>host: db1</li>")
+}
+
+func TestAlertLink_ShouldRejectExpiredAndForgedTokens(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	expired := alertLinks.Sign(5, time.Now().Add(-sms.DefaultLinkTTL))
+	forged := sms.LinkSigner{Secret: []byte("guessed")}.Sign(5, time.Now())
+
+	for _, token := range []string{expired, forged, "5"} {
+		req, err := http.NewRequest("GET", "/a/"+token, nil)
+		require.Nil(t, err)
+		req = mux.SetURLVars(req, map[string]string{"token": token})
+
+		rr := httptest.NewRecorder()
+		handler := api.AlertLinkHandler{AlertRepo: mocks.NewMockAlertRepository(mockCtrl), Links: alertLinks}
+		handler.ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusNotFound, rr.Code)
+		requireMatchesSpec(t, loadOpenAPI(t), "GET", "/a/{token}", rr)
+	}
+}
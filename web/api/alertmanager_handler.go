@@ -0,0 +1,114 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/alertmanager"
+)
+
+// maxWebhookRequestSize limits the size of webhook payloads of integrations.
+const maxWebhookRequestSize = 1 << 20
+
+// AlertmanagerHandler receives webhook notifications of the Prometheus
+// Alertmanager and creates an alert for every firing or resolved alert.
+type AlertmanagerHandler struct {
+	UserRepo  easyalert.UserRepository
+	AlertRepo easyalert.AlertRepository
+	Notifier  easyalert.Notifier
+}
+
+type alertmanagerResponseBody struct {
+	Alerts []alertResponseBody `json:"alerts"`
+}
+
+// ServeHTTP handles the HTTP request.
+func (h AlertmanagerHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeIntegration(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	bytes, ok := readWebhookBody(w, r)
+	if !ok {
+		return
+	}
+
+	var payload alertmanager.Payload
+
+	err := json.Unmarshal(bytes, &payload)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "invalid_json", "invalid json")
+		return
+	}
+
+	err = payload.Validate()
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "invalid_payload", err.Error())
+		return
+	}
+
+	responseBody := alertmanagerResponseBody{Alerts: []alertResponseBody{}}
+
+	for _, notification := range payload.Notifications(user) {
+		alert, folded, err := easyalert.CreateOrFoldAlert(h.AlertRepo, notification.Alert, alertmanager.DedupWindow)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal_error", "could not create alert")
+			return
+		}
+
+		if !folded {
+			notification.Alert = alert
+			h.Notifier.Notify(user, notification)
+		}
+
+		responseBody.Alerts = append(responseBody.Alerts, convertAlertToResponseBody(alert))
+	}
+
+	writeJSON(w, http.StatusOK, responseBody)
+}
+
+// authorizeIntegration works like authorizeUser, but also accepts the
+// inbound token of the user as token query parameter for tools which can not
+// set headers. The API token is not accepted there, since query strings end
+// up in configuration files and access logs and the API token can do
+// anything, like deleting the user.
+func authorizeIntegration(w http.ResponseWriter, r *http.Request, userRepo easyalert.UserRepository) (easyalert.User, bool) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		return authorizeUser(w, r, userRepo)
+	}
+
+	user, err := userRepo.FindUser("WHERE inbound_token = $1", token)
+	if err != nil {
+		if err == easyalert.ErrRecordDoesNotExist {
+			writeError(w, http.StatusUnauthorized, "invalid_token", "Invalid inbound token, the API token is only accepted in the Authorization header.")
+			return easyalert.User{}, false
+		}
+
+		writeError(w, http.StatusInternalServerError, "internal_error", "an unknown error occured")
+		return easyalert.User{}, false
+	}
+
+	return user, true
+}
+
+// readWebhookBody reads the payload of a webhook. If it is too large an error
+// is written and false is returned.
+func readWebhookBody(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	bytes, err := ioutil.ReadAll(io.LimitReader(r.Body, maxWebhookRequestSize+1))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not read http body")
+		return nil, false
+	}
+
+	if len(bytes) > maxWebhookRequestSize {
+		writeError(w, http.StatusRequestEntityTooLarge, "request_too_large", "Request body too large.")
+		return nil, false
+	}
+
+	return bytes, true
+}
@@ -0,0 +1,143 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/mocks"
+	"github.com/bakku/easyalert/web/api"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+const alertmanagerPayload = `{
+	"version": "4",
+	"groupKey": "{}:{alertname=\"HighLatency\"}",
+	"status": "firing",
+	"receiver": "easyalert",
+	"alerts": [
+		{
+			"status": "firing",
+			"labels": {"alertname": "HighLatency", "severity": "critical"},
+			"annotations": {"summary": "Latency is above 500ms"},
+			"startsAt": "2019-01-25T10:00:00Z",
+			"fingerprint": "abc123"
+		}
+	]
+}`
+
+func TestPOSTAlertmanager_ShouldOnlyAcceptInboundTokenInQuery(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	// the API token of the user is not looked up
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser("WHERE inbound_token = $1", "12345").Return(easyalert.User{}, easyalert.ErrRecordDoesNotExist)
+
+	req, err := http.NewRequest("POST", "/api/integrations/alertmanager?token=12345", strings.NewReader(alertmanagerPayload))
+	require.Nil(t, err)
+
+	rr := httptest.NewRecorder()
+	handler := api.AlertmanagerHandler{UserRepo: userRepo}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+	requireProblem(t, rr, "invalid_token", "Invalid inbound token, the API token is only accepted in the Authorization header.")
+}
+
+func TestPOSTAlertmanager_ShouldReturnErrorIfVersionIsNotSupported(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	req, err := http.NewRequest("POST", "/api/integrations/alertmanager", strings.NewReader(`{"version": "3", "alerts": []}`))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.AlertmanagerHandler{UserRepo: userRepo}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	requireProblem(t, rr, "invalid_payload", `Unsupported payload version "3", expected 4.`)
+}
+
+func TestPOSTAlertmanager_ShouldCreateAndNotifyAlert(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	user := easyalert.User{ID: 1, Email: "test@example.com"}
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser("WHERE inbound_token = $1", "abcdef").Return(user, nil)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().FoldAlert(gomock.Any(), uint(1), "alertmanager:abc123:1548410400:firing", gomock.Any(), gomock.Any()).Return(easyalert.Alert{}, easyalert.ErrRecordDoesNotExist)
+	alertRepo.EXPECT().CreateAlert(gomock.Any()).DoAndReturn(func(alert easyalert.Alert) (easyalert.Alert, error) {
+		require.Equal(t, "[FIRING] HighLatency: Latency is above 500ms", alert.Subject)
+		require.Equal(t, uint(easyalert.AlertSeverityCritical), alert.Severity)
+
+		alert.ID = 7
+		return alert, nil
+	})
+
+	notifier := mocks.NewMockNotifier(mockCtrl)
+	notifier.EXPECT().Notify(user, gomock.Any()).Do(func(user easyalert.User, n easyalert.Notification) {
+		require.Equal(t, uint(7), n.Alert.ID)
+		require.Contains(t, n.Message, "alertname = HighLatency")
+	})
+
+	req, err := http.NewRequest("POST", "/api/integrations/alertmanager?token=abcdef", strings.NewReader(alertmanagerPayload))
+	require.Nil(t, err)
+
+	rr := httptest.NewRecorder()
+	handler := api.AlertmanagerHandler{
+		UserRepo:  userRepo,
+		AlertRepo: alertRepo,
+		Notifier:  notifier,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var body struct {
+		Alerts []struct {
+			ID uint `json:"id"`
+		} `json:"alerts"`
+	}
+
+	require.Nil(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	require.Len(t, body.Alerts, 1)
+	require.Equal(t, uint(7), body.Alerts[0].ID)
+}
+
+func TestPOSTAlertmanager_ShouldNotNotifyFoldedAlert(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().FoldAlert(gomock.Any(), uint(1), "alertmanager:abc123:1548410400:firing", gomock.Any(), gomock.Any()).Return(easyalert.Alert{ID: 5, Occurrences: 2}, nil)
+
+	req, err := http.NewRequest("POST", "/api/integrations/alertmanager?token=abcdef", strings.NewReader(alertmanagerPayload))
+	require.Nil(t, err)
+
+	rr := httptest.NewRecorder()
+	handler := api.AlertmanagerHandler{
+		UserRepo:  userRepo,
+		AlertRepo: alertRepo,
+		Notifier:  mocks.NewMockNotifier(mockCtrl),
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+}
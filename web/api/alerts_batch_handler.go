@@ -0,0 +1,233 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/bakku/easyalert"
+)
+
+const (
+	maxBatchSize = 100
+
+	// maxBatchRequestSize is enough for 100 alerts with long messages,
+	// batches can not carry attachments.
+	maxBatchRequestSize = 5 << 20
+)
+
+// CreateAlertsBatchHandler should accept a JSON array of alerts and create
+// all of them at once.
+type CreateAlertsBatchHandler struct {
+	UserRepo        easyalert.UserRepository
+	AlertRepo       easyalert.AlertRepository
+	TemplateRepo    easyalert.TemplateRepository
+	IdempotencyRepo easyalert.IdempotencyKeyRepository
+	Notifier        easyalert.Notifier
+}
+
+type batchItemResult struct {
+	Index  int                `json:"index"`
+	Status int                `json:"status"`
+	Code   string             `json:"code,omitempty"`
+	Error  string             `json:"error,omitempty"`
+	Errors []fieldError       `json:"errors,omitempty"`
+	Alert  *alertResponseBody `json:"alert,omitempty"`
+}
+
+// fail marks the item as failed with the given error.
+func (result *batchItemResult) fail(err apiError) {
+	result.Status = err.Status
+	result.Code = err.Code
+	result.Error = err.Message
+	result.Errors = err.Fields
+}
+
+type batchResponseBody struct {
+	Results []batchItemResult `json:"results"`
+}
+
+// ServeHTTP handles the HTTP request.
+func (h CreateAlertsBatchHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	bytes, err := ioutil.ReadAll(io.LimitReader(r.Body, maxBatchRequestSize+1))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not read http body")
+		return
+	}
+
+	if len(bytes) > maxBatchRequestSize {
+		writeError(w, http.StatusRequestEntityTooLarge, "request_too_large", "Request body too large.")
+		return
+	}
+
+	// items are unmarshalled one by one so that invalid JSON only fails its item
+	var items []json.RawMessage
+
+	err = json.Unmarshal(bytes, &items)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "invalid_json", "invalid json")
+		return
+	}
+
+	serveIdempotent(w, r, h.IdempotencyRepo, user, items, func(w http.ResponseWriter) {
+		h.createAlerts(w, r, user, items)
+	})
+}
+
+func (h CreateAlertsBatchHandler) createAlerts(w http.ResponseWriter, r *http.Request, user easyalert.User, items []json.RawMessage) {
+	mode := r.URL.Query().Get("mode")
+	if mode == "" {
+		mode = "atomic"
+	}
+
+	if mode != "atomic" && mode != "partial" {
+		writeError(w, http.StatusUnprocessableEntity, "invalid_mode", "Invalid mode, expected atomic or partial.")
+		return
+	}
+
+	if len(items) == 0 {
+		writeError(w, http.StatusUnprocessableEntity, "empty_batch", "No alerts given.")
+		return
+	}
+
+	if len(items) > maxBatchSize {
+		writeError(w, http.StatusUnprocessableEntity, "batch_too_large", fmt.Sprintf("A batch must not contain more than %d alerts.", maxBatchSize))
+		return
+	}
+
+	now := time.Now()
+	results := make([]batchItemResult, len(items))
+
+	var (
+		alerts  []easyalert.Alert
+		windows []time.Duration
+		bodies  []createAlertRequestBody
+		indexes []int
+		invalid int
+	)
+
+	for i, item := range items {
+		results[i].Index = i
+
+		var alertBody createAlertRequestBody
+
+		err := json.Unmarshal(item, &alertBody)
+		if err != nil {
+			results[i].fail(invalidRequest("invalid_json", "invalid json"))
+			invalid++
+			continue
+		}
+
+		err = alertBody.applyTemplate(h.TemplateRepo, user)
+		if err != nil {
+			e, ok := err.(apiError)
+			if !ok || e.Status >= http.StatusInternalServerError {
+				writeAPIError(w, err)
+				return
+			}
+
+			results[i].fail(e)
+			invalid++
+			continue
+		}
+
+		alert, window, err := alertBody.toAlert(user, now)
+		if err != nil {
+			results[i].fail(err.(apiError))
+			invalid++
+			continue
+		}
+
+		alerts = append(alerts, alert)
+		windows = append(windows, window)
+		bodies = append(bodies, alertBody)
+		indexes = append(indexes, i)
+	}
+
+	if invalid > 0 && mode == "atomic" {
+		for _, i := range indexes {
+			results[i].fail(apiError{
+				Status:  http.StatusFailedDependency,
+				Code:    "batch_invalid",
+				Message: "Not created because other alerts of the batch are invalid.",
+			})
+		}
+
+		writeJSON(w, http.StatusUnprocessableEntity, batchResponseBody{results})
+		return
+	}
+
+	var (
+		plain     []easyalert.Alert
+		positions []int
+		deduped   []int
+	)
+
+	for n, alert := range alerts {
+		if alert.DedupKey == "" {
+			plain = append(plain, alert)
+			positions = append(positions, n)
+		} else {
+			deduped = append(deduped, n)
+		}
+	}
+
+	if len(plain) > 0 {
+		created, err := h.AlertRepo.CreateAlerts(plain)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal_error", "could not create alerts")
+			return
+		}
+
+		for k, n := range positions {
+			alerts[n] = created[k]
+		}
+	}
+
+	// alerts with a dedup key are created or folded one after another like
+	// single alerts, so that items of the batch with the same key fold into
+	// each other
+	folded := make([]bool, len(alerts))
+
+	for _, n := range deduped {
+		var err error
+
+		alerts[n], folded[n], err = easyalert.CreateOrFoldAlert(h.AlertRepo, alerts[n], windows[n])
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal_error", "could not create alerts")
+			return
+		}
+	}
+
+	for n, i := range indexes {
+		results[i].Status = http.StatusCreated
+
+		if folded[n] {
+			results[i].Status = http.StatusOK
+		} else if alerts[n].Status == easyalert.AlertStatusPending {
+			h.Notifier.Notify(user, easyalert.Notification{
+				Alert:   alerts[n],
+				Message: bodies[n].Message,
+				Format:  bodies[n].Format,
+			})
+		}
+
+		alertBody := convertAlertToResponseBody(alerts[n])
+		results[i].Alert = &alertBody
+	}
+
+	status := http.StatusCreated
+	if invalid > 0 {
+		status = http.StatusMultiStatus
+	}
+
+	writeJSON(w, status, batchResponseBody{results})
+}
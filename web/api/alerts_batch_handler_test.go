@@ -0,0 +1,331 @@
+package api_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/mocks"
+	"github.com/bakku/easyalert/web/api"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPOSTAlertsBatch_ShouldReturnErrorIfBatchIsEmpty(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	req, err := http.NewRequest("POST", "/api/alerts/batch", strings.NewReader("[]"))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateAlertsBatchHandler{
+		UserRepo: userRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	requireProblem(t, rr, "empty_batch", "No alerts given.")
+}
+
+func TestPOSTAlertsBatch_ShouldReturnErrorIfRequestBodyIsTooLarge(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	payload := `[{"subject": "Hi", "message": "` + strings.Repeat("a", 6<<20) + `"}]`
+
+	req, err := http.NewRequest("POST", "/api/alerts/batch", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateAlertsBatchHandler{
+		UserRepo: userRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+	requireProblem(t, rr, "request_too_large", "Request body too large.")
+	requireMatchesSpec(t, loadOpenAPI(t), "POST", "/api/alerts/batch", rr)
+}
+
+func TestPOSTAlertsBatch_ShouldReturnErrorIfModeIsInvalid(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	req, err := http.NewRequest("POST", "/api/alerts/batch?mode=some", strings.NewReader("[]"))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateAlertsBatchHandler{
+		UserRepo: userRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	requireProblem(t, rr, "invalid_mode", "Invalid mode, expected atomic or partial.")
+}
+
+func TestPOSTAlertsBatch_ShouldCreateNothingInAtomicModeIfOneAlertIsInvalid(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	payload := `[
+		{"subject": "Hi", "message": "Hi"},
+		{"subject": "Hi", "message": "Hi", "severity": "fatal"},
+		{"subject": "Hi", "message": "Hi", "dedup_window": "1h"}
+	]`
+
+	req, err := http.NewRequest("POST", "/api/alerts/batch", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateAlertsBatchHandler{
+		UserRepo: userRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+
+	expectedJsonResp := "{\n" +
+		"  \"results\": [\n" +
+		"    {\n" +
+		"      \"index\": 0,\n" +
+		"      \"status\": 424,\n" +
+		"      \"code\": \"batch_invalid\",\n" +
+		"      \"error\": \"Not created because other alerts of the batch are invalid.\"\n" +
+		"    },\n" +
+		"    {\n" +
+		"      \"index\": 1,\n" +
+		"      \"status\": 422,\n" +
+		"      \"code\": \"invalid_severity\",\n" +
+		"      \"error\": \"Invalid severity.\",\n" +
+		"      \"errors\": [\n" +
+		"        {\n" +
+		"          \"field\": \"severity\",\n" +
+		"          \"code\": \"invalid_severity\",\n" +
+		"          \"message\": \"Invalid severity.\"\n" +
+		"        }\n" +
+		"      ]\n" +
+		"    },\n" +
+		"    {\n" +
+		"      \"index\": 2,\n" +
+		"      \"status\": 422,\n" +
+		"      \"code\": \"missing_dedup_key\",\n" +
+		"      \"error\": \"Dedup window given without dedup key.\",\n" +
+		"      \"errors\": [\n" +
+		"        {\n" +
+		"          \"field\": \"dedup_window\",\n" +
+		"          \"code\": \"missing_dedup_key\",\n" +
+		"          \"message\": \"Dedup window given without dedup key.\"\n" +
+		"        }\n" +
+		"      ]\n" +
+		"    }\n" +
+		"  ]\n" +
+		"}"
+
+	require.Equal(t, expectedJsonResp, rr.Body.String())
+}
+
+func TestPOSTAlertsBatch_ShouldCreateValidAlertsInPartialMode(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	createdAt := time.Date(2019, 1, 18, 10, 0, 0, 0, time.UTC)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().CreateAlerts(gomock.Any()).DoAndReturn(func(alerts []easyalert.Alert) ([]easyalert.Alert, error) {
+		require.Len(t, alerts, 1)
+		require.Equal(t, uint(1), alerts[0].UserID)
+
+		alerts[0].ID = 3
+		alerts[0].LastSeenAt = createdAt
+		alerts[0].CreatedAt = createdAt
+		return alerts, nil
+	})
+
+	payload := `[
+		{"subject": "Hi"},
+		{"subject": "Disk full", "message": "Hi"}
+	]`
+
+	req, err := http.NewRequest("POST", "/api/alerts/batch?mode=partial", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	notifier := mocks.NewMockNotifier(mockCtrl)
+	notifier.EXPECT().Notify(easyalert.User{ID: 1}, gomock.Any()).Do(func(user easyalert.User, n easyalert.Notification) {
+		require.Equal(t, uint(3), n.Alert.ID)
+		require.Equal(t, "Hi", n.Message)
+	})
+
+	handler := api.CreateAlertsBatchHandler{
+		Notifier:  notifier,
+		UserRepo:  userRepo,
+		AlertRepo: alertRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusMultiStatus, rr.Code)
+
+	expectedJsonResp := "{\n" +
+		"  \"results\": [\n" +
+		"    {\n" +
+		"      \"index\": 0,\n" +
+		"      \"status\": 422,\n" +
+		"      \"code\": \"missing_field\",\n" +
+		"      \"error\": \"Subject or message not given.\",\n" +
+		"      \"errors\": [\n" +
+		"        {\n" +
+		"          \"field\": \"message\",\n" +
+		"          \"code\": \"missing_field\",\n" +
+		"          \"message\": \"Message must be given.\"\n" +
+		"        }\n" +
+		"      ]\n" +
+		"    },\n" +
+		"    {\n" +
+		"      \"index\": 1,\n" +
+		"      \"status\": 201,\n" +
+		"      \"alert\": {\n" +
+		"        \"id\": 3,\n" +
+		"        \"subject\": \"Disk full\",\n" +
+		"        \"status\": \"pending\",\n" +
+		"        \"severity\": \"info\",\n" +
+		"        \"occurrences\": 1,\n" +
+		"        \"last_seen_at\": \"2019-01-18T10:00:00Z\",\n" +
+		"        \"created_at\": \"2019-01-18T10:00:00Z\"\n" +
+		"      }\n" +
+		"    }\n" +
+		"  ]\n" +
+		"}"
+
+	require.Equal(t, expectedJsonResp, rr.Body.String())
+}
+
+func TestPOSTAlertsBatch_ShouldFoldAlertsWithDedupKey(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	gomock.InOrder(
+		alertRepo.EXPECT().CreateAlerts(gomock.Any()).DoAndReturn(func(alerts []easyalert.Alert) ([]easyalert.Alert, error) {
+			require.Len(t, alerts, 1)
+			require.Equal(t, "Disk full", alerts[0].Subject)
+
+			alerts[0].ID = 3
+			return alerts, nil
+		}),
+		// the first alert with the key is created, the second one folds into it
+		alertRepo.EXPECT().FoldAlert(gomock.Any(), uint(1), "backup", gomock.Any(), gomock.Any()).Return(easyalert.Alert{}, easyalert.ErrRecordDoesNotExist),
+		alertRepo.EXPECT().CreateAlert(gomock.Any()).DoAndReturn(func(alert easyalert.Alert) (easyalert.Alert, error) {
+			require.Equal(t, "backup", alert.DedupKey)
+
+			alert.ID = 4
+			return alert, nil
+		}),
+		alertRepo.EXPECT().FoldAlert(gomock.Any(), uint(1), "backup", gomock.Any(), gomock.Any()).Return(easyalert.Alert{ID: 4, DedupKey: "backup", Occurrences: 2}, nil),
+	)
+
+	notifier := mocks.NewMockNotifier(mockCtrl)
+	notifier.EXPECT().Notify(easyalert.User{ID: 1}, gomock.Any()).Times(2)
+
+	payload := `[
+		{"subject": "Backup failed", "message": "Hi", "dedup_key": "backup"},
+		{"subject": "Disk full", "message": "Hi"},
+		{"subject": "Backup failed", "message": "Hi", "dedup_key": "backup"}
+	]`
+
+	req, err := http.NewRequest("POST", "/api/alerts/batch", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateAlertsBatchHandler{
+		Notifier:  notifier,
+		UserRepo:  userRepo,
+		AlertRepo: alertRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusCreated, rr.Code)
+	requireMatchesSpec(t, loadOpenAPI(t), "POST", "/api/alerts/batch", rr)
+
+	var body struct {
+		Results []struct {
+			Status int
+			Alert  struct {
+				ID          uint
+				Occurrences uint
+			}
+		}
+	}
+	require.Nil(t, json.Unmarshal(rr.Body.Bytes(), &body))
+
+	require.Equal(t, http.StatusCreated, body.Results[0].Status)
+	require.Equal(t, uint(4), body.Results[0].Alert.ID)
+	require.Equal(t, http.StatusCreated, body.Results[1].Status)
+	require.Equal(t, uint(3), body.Results[1].Alert.ID)
+	require.Equal(t, http.StatusOK, body.Results[2].Status)
+	require.Equal(t, uint(2), body.Results[2].Alert.Occurrences)
+}
+
+func TestPOSTAlertsBatch_ShouldReturnErrorIfAlertsCouldNotBeCreated(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().CreateAlerts(gomock.Any()).Return(nil, errors.New("Error!!"))
+
+	payload := `[{"subject": "Hi", "message": "Hi"}, {"subject": "Hi", "message": "Hi"}]`
+
+	req, err := http.NewRequest("POST", "/api/alerts/batch", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateAlertsBatchHandler{
+		UserRepo:  userRepo,
+		AlertRepo: alertRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusInternalServerError, rr.Code)
+	requireProblem(t, rr, "internal_error", "could not create alerts")
+}
@@ -2,75 +2,381 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/delivery"
+	"github.com/bakku/easyalert/markdown"
 )
 
-// CreateAlertsHandler should accept a JSON object and create an alert from it.
+// CreateAlertsHandler should accept a JSON object, form data or plain text and create an alert from it.
 type CreateAlertsHandler struct {
-	UserRepo  easyalert.UserRepository
-	AlertRepo easyalert.AlertRepository
+	UserRepo        easyalert.UserRepository
+	AlertRepo       easyalert.AlertRepository
+	TemplateRepo    easyalert.TemplateRepository
+	IdempotencyRepo easyalert.IdempotencyKeyRepository
+	Notifier        easyalert.Notifier
 }
 
+const (
+	// maxUserAttachmentSize is the size of attachments a user may send
+	// inside attachmentQuotaPeriod.
+	maxUserAttachmentSize = 100 << 20
+	attachmentQuotaPeriod = 24 * time.Hour
+)
+
 type createAlertRequestBody struct {
-	Subject string `json:"subject"`
-	Message string `json:"message"`
+	Subject     string            `json:"subject"`
+	Message     string            `json:"message"`
+	Format      string            `json:"format"`
+	DedupKey    string            `json:"dedup_key"`
+	DedupWindow string            `json:"dedup_window"`
+	Severity    string            `json:"severity"`
+	Labels      map[string]string `json:"labels"`
+	SendAt      string            `json:"send_at"`
+	Delay       string            `json:"delay"`
+	Template    string            `json:"template"`
+	Vars        map[string]string `json:"vars"`
 }
 
 // ServeHTTP handles the HTTP request.
 func (h CreateAlertsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	token, ok := getUserToken(r)
+	user, ok := authorizeUser(w, r, h.UserRepo)
 	if !ok {
-		writeError(w, http.StatusUnauthorized, "Missing or invalid Authorization header.")
 		return
 	}
 
-	user, err := h.UserRepo.FindUser("WHERE token = $1", token)
+	bytes, err := ioutil.ReadAll(io.LimitReader(r.Body, maxAlertRequestSize+1))
 	if err != nil {
-		if err == easyalert.ErrRecordDoesNotExist {
-			writeError(w, http.StatusUnauthorized, "Invalid token.")
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not read http body")
+		return
+	}
+
+	if len(bytes) > maxAlertRequestSize {
+		writeError(w, http.StatusRequestEntityTooLarge, "request_too_large", "Request body too large.")
+		return
+	}
+
+	alertBody, attachments, err := decodeAlertRequest(r, bytes)
+	if err != nil {
+		writeAPIError(w, err)
+		return
+	}
+
+	request := struct {
+		Alert       createAlertRequestBody
+		Attachments []easyalert.Attachment
+	}{alertBody, attachments}
+
+	serveIdempotent(w, r, h.IdempotencyRepo, user, request, func(w http.ResponseWriter) {
+		h.createAlert(w, user, alertBody, attachments)
+	})
+}
+
+func (h CreateAlertsHandler) createAlert(w http.ResponseWriter, user easyalert.User, alertBody createAlertRequestBody, attachments []easyalert.Attachment) {
+	err := alertBody.applyTemplate(h.TemplateRepo, user)
+	if err != nil {
+		writeAPIError(w, err)
+		return
+	}
+
+	alert, window, err := alertBody.toAlert(user, time.Now())
+	if err != nil {
+		writeAPIError(w, err)
+		return
+	}
+
+	notification := easyalert.Notification{
+		Message:     alertBody.Message,
+		Format:      alertBody.Format,
+		Attachments: attachments,
+	}
+
+	if len(attachments) > 0 {
+		// attachments are never stored, so they can only be sent right away
+		if alert.Status == easyalert.AlertStatusScheduled {
+			writeError(w, http.StatusUnprocessableEntity, "attachments_not_allowed", "Attachments can not be sent with scheduled alerts.")
 			return
 		}
 
-		writeError(w, http.StatusInternalServerError, "an unknown error occured")
+		alert.AttachmentSize = notification.AttachmentSize()
+
+		if alert.AttachmentSize > maxAttachmentSize {
+			writeError(w, http.StatusRequestEntityTooLarge, "attachments_too_large", "Attachments must not be larger than 10 MB in total.")
+			return
+		}
+
+		used, err := h.usedAttachmentSize(user)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal_error", "could not fetch alerts")
+			return
+		}
+
+		if used+alert.AttachmentSize > maxUserAttachmentSize {
+			writeError(w, http.StatusTooManyRequests, "attachment_quota_exceeded", "Attachment limit of 100 MB per day exceeded.")
+			return
+		}
+	}
+
+	alert, folded, err := easyalert.CreateOrFoldAlert(h.AlertRepo, alert, window)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not create alert")
+		return
+	}
+
+	if folded {
+		w.Header().Set("Location", alertURL(alert))
+		writeJSON(w, http.StatusOK, convertAlertToResponseBody(alert))
 		return
 	}
 
-	bytes, err := ioutil.ReadAll(r.Body)
+	if alert.Status == easyalert.AlertStatusPending {
+		notification.Alert = alert
+		h.Notifier.Notify(user, notification)
+	}
+
+	w.Header().Set("Location", alertURL(alert))
+	writeJSON(w, http.StatusCreated, convertAlertToResponseBody(alert))
+}
+
+// usedAttachmentSize returns the size of all attachments the user sent
+// inside the current quota period.
+func (h CreateAlertsHandler) usedAttachmentSize(user easyalert.User) (int64, error) {
+	alerts, err := h.AlertRepo.FindAlerts(`
+		WHERE user_id = $1 AND attachment_size > 0
+		AND created_at > NOW() - $2 * INTERVAL '1 second'
+	`, user.ID, int64(attachmentQuotaPeriod.Seconds()))
+
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "could not read http body")
+		return 0, err
+	}
+
+	var used int64
+
+	for _, alert := range alerts {
+		used += alert.AttachmentSize
+	}
+
+	return used, nil
+}
+
+// toAlert validates the request body and converts it into an alert of the
+// user. If the alert should be deduplicated the dedup window is returned as well.
+func (body createAlertRequestBody) toAlert(user easyalert.User, now time.Time) (easyalert.Alert, time.Duration, error) {
+	if body.Subject == "" || body.Message == "" {
+		e := invalidRequest("missing_field", "Subject or message not given.")
+
+		if body.Subject == "" {
+			e.Fields = append(e.Fields, fieldError{"subject", "missing_field", "Subject must be given."})
+		}
+
+		if body.Message == "" {
+			e.Fields = append(e.Fields, fieldError{"message", "missing_field", "Message must be given."})
+		}
+
+		return easyalert.Alert{}, 0, e
+	}
+
+	if body.Format != "" && body.Format != easyalert.MessageFormatText && body.Format != easyalert.MessageFormatMarkdown {
+		return easyalert.Alert{}, 0, invalidField("format", "invalid_format", "Invalid format, expected text or markdown.")
+	}
+
+	severity, err := easyalert.ParseSeverity(body.Severity)
+	if err != nil {
+		return easyalert.Alert{}, 0, invalidField("severity", "invalid_severity", "Invalid severity.")
+	}
+
+	for key := range body.Labels {
+		if key == "" || strings.Contains(key, ":") {
+			return easyalert.Alert{}, 0, invalidField("labels", "invalid_label", "Label keys must not be empty or contain a colon.")
+		}
+	}
+
+	var status uint = easyalert.AlertStatusPending
+
+	sendAt, err := parseSendAt(body.SendAt, body.Delay, now)
+	if err != nil {
+		return easyalert.Alert{}, 0, err
+	}
+
+	if sendAt != nil {
+		status = easyalert.AlertStatusScheduled
+	}
+
+	if body.DedupKey == "" && body.DedupWindow != "" {
+		return easyalert.Alert{}, 0, invalidField("dedup_window", "missing_dedup_key", "Dedup window given without dedup key.")
+	}
+
+	var window time.Duration
+
+	if body.DedupKey != "" {
+		window = easyalert.DefaultDedupWindow
+
+		if body.DedupWindow != "" {
+			window, err = time.ParseDuration(body.DedupWindow)
+			if err != nil || window <= 0 {
+				return easyalert.Alert{}, 0, invalidField("dedup_window", "invalid_dedup_window", "Invalid dedup window.")
+			}
+		}
+	}
+
+	alert := easyalert.Alert{
+		Subject:     body.Subject,
+		Status:      status,
+		UserID:      user.ID,
+		DedupKey:    body.DedupKey,
+		Occurrences: 1,
+		Severity:    severity,
+		Labels:      body.Labels,
+		SendAt:      sendAt,
+	}
+
+	// the message of a scheduled alert is stored until it is released
+	if sendAt != nil {
+		alert.Message = body.Message
+		alert.Format = body.Format
+	}
+
+	return alert, window, nil
+}
+
+// PreviewAlertHandler renders the message of an alert the way it would be
+// sent without creating or sending the alert.
+type PreviewAlertHandler struct {
+	UserRepo     easyalert.UserRepository
+	TemplateRepo easyalert.TemplateRepository
+}
+
+type previewAlertResponseBody struct {
+	Subject string `json:"subject"`
+	HTML    string `json:"html"`
+}
+
+// ServeHTTP handles the HTTP request.
+func (h PreviewAlertHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	bytes, err := ioutil.ReadAll(io.LimitReader(r.Body, maxAlertRequestSize+1))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not read http body")
 		return
 	}
 
-	var alertBody createAlertRequestBody
+	if len(bytes) > maxAlertRequestSize {
+		writeError(w, http.StatusRequestEntityTooLarge, "request_too_large", "Request body too large.")
+		return
+	}
 
-	err = json.Unmarshal(bytes, &alertBody)
+	alertBody, _, err := decodeAlertRequest(r, bytes)
 	if err != nil {
-		writeError(w, http.StatusUnprocessableEntity, "invalid json")
+		writeAPIError(w, err)
 		return
 	}
 
-	if alertBody.Subject == "" || alertBody.Message == "" {
-		writeError(w, http.StatusUnprocessableEntity, "Subject or message not given.")
+	err = alertBody.applyTemplate(h.TemplateRepo, user)
+	if err != nil {
+		writeAPIError(w, err)
 		return
 	}
 
-	alert := easyalert.Alert{
+	_, _, err = alertBody.toAlert(user, time.Now())
+	if err != nil {
+		writeAPIError(w, err)
+		return
+	}
+
+	if alertBody.Format != easyalert.MessageFormatMarkdown {
+		writeError(w, http.StatusUnprocessableEntity, "unsupported_format", "Only markdown messages can be previewed.")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, previewAlertResponseBody{
 		Subject: alertBody.Subject,
-		Status:  0,
-		UserID:  user.ID,
+		HTML:    delivery.HTMLDocument(alertBody.Subject, markdown.ToHTML(alertBody.Message)),
+	})
+}
+
+// parseSendAt returns when an alert should be sent, given either as an
+// RFC 3339 timestamp or as a delay. It returns nil if the alert should be
+// sent right away.
+func parseSendAt(sendAt, delay string, now time.Time) (*time.Time, error) {
+	if sendAt != "" && delay != "" {
+		return nil, invalidRequest("conflicting_fields", "Only one of send_at and delay can be given.")
+	}
+
+	if sendAt != "" {
+		t, err := time.Parse(time.RFC3339, sendAt)
+		if err != nil {
+			return nil, invalidField("send_at", "invalid_timestamp", "Invalid send_at, expected RFC 3339 timestamp.")
+		}
+
+		if !t.After(now) {
+			return nil, invalidField("send_at", "send_at_in_past", "send_at must be in the future.")
+		}
+
+		t = t.UTC()
+		return &t, nil
+	}
+
+	if delay != "" {
+		d, err := time.ParseDuration(delay)
+		if err != nil || d <= 0 {
+			return nil, invalidField("delay", "invalid_delay", "Invalid delay.")
+		}
+
+		t := now.Add(d).UTC()
+		return &t, nil
+	}
+
+	return nil, nil
+}
+
+// CancelAlertHandler cancels a scheduled alert of the user before it is sent.
+type CancelAlertHandler struct {
+	UserRepo  easyalert.UserRepository
+	AlertRepo easyalert.AlertRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h CancelAlertHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	alert, ok := findUserAlert(w, r, h.AlertRepo, user)
+	if !ok {
+		return
+	}
+
+	if alert.Status != easyalert.AlertStatusScheduled {
+		writeError(w, http.StatusConflict, "alert_not_scheduled", "Only scheduled alerts can be canceled.")
+		return
+	}
+
+	alert.Status = easyalert.AlertStatusCanceled
+
+	// the dispatcher may have released the alert since it was fetched
+	alert, err := h.AlertRepo.UpdateAlertStatus(alert, easyalert.AlertStatusScheduled)
+	if err == easyalert.ErrRecordDoesNotExist {
+		writeError(w, http.StatusConflict, "alert_not_scheduled", "Only scheduled alerts can be canceled.")
+		return
 	}
 
-	_, err = h.AlertRepo.CreateAlert(alert)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "could not create alert")
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not cancel alert")
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, http.StatusOK, convertAlertToResponseBody(alert))
 }
 
 // GetAlertsHandler should return all alerts of the user.
@@ -79,73 +385,241 @@ type GetAlertsHandler struct {
 	AlertRepo easyalert.AlertRepository
 }
 
-type getAlertsResponseBody struct {
-	Subject   string `json:"subject"`
-	Status    string `json:"status"`
-	SentAt    string `json:"sent_at,omitempty"`
-	CreatedAt string `json:"created_at"`
+type alertResponseBody struct {
+	ID          uint              `json:"id"`
+	Subject     string            `json:"subject"`
+	Status      string            `json:"status"`
+	Severity    string            `json:"severity"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	SendAt      string            `json:"send_at,omitempty"`
+	SentAt      string            `json:"sent_at,omitempty"`
+	DedupKey    string            `json:"dedup_key,omitempty"`
+	Occurrences uint              `json:"occurrences"`
+	LastSeenAt  string            `json:"last_seen_at"`
+	CreatedAt   string            `json:"created_at"`
 }
 
 // ServeHTTP handles the HTTP request.
 func (h GetAlertsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	token, ok := getUserToken(r)
+	user, ok := authorizeUser(w, r, h.UserRepo)
 	if !ok {
-		writeError(w, http.StatusUnauthorized, "Missing or invalid Authorization header.")
 		return
 	}
 
-	user, err := h.UserRepo.FindUser("WHERE token = $1", token)
+	query, err := newAlertsQuery(user.ID, r.URL.Query())
 	if err != nil {
-		if err == easyalert.ErrRecordDoesNotExist {
-			writeError(w, http.StatusUnauthorized, "Invalid token.")
-			return
-		}
-
-		writeError(w, http.StatusInternalServerError, "an unknown error occured")
+		writeAPIError(w, err)
 		return
 	}
 
-	alerts, err := h.AlertRepo.FindAlerts("WHERE user_id = $1", user.ID)
+	alerts, err := h.AlertRepo.FindAlerts(query.sql, query.params...)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "could not fetch alerts")
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not fetch alerts")
 		return
 	}
 
+	alerts, links := query.paginate(alerts, r.URL)
+	if links != "" {
+		w.Header().Set("Link", links)
+	}
+
 	responseBody := convertAlertsToResponseBody(alerts)
 
-	responseBodyBytes, err := json.Marshal(responseBody)
-	if err != nil {
-		writeError(w, http.StatusInternalServerError, "could not marshal response body")
+	writeJSON(w, http.StatusOK, responseBody)
+}
+
+func convertAlertsToResponseBody(alerts []easyalert.Alert) []alertResponseBody {
+	responseBodyArray := make([]alertResponseBody, len(alerts))
+
+	for i, alert := range alerts {
+		responseBodyArray[i] = convertAlertToResponseBody(alert)
+	}
+
+	return responseBodyArray
+}
+
+func convertAlertToResponseBody(alert easyalert.Alert) alertResponseBody {
+	responseAlert := alertResponseBody{
+		ID:          alert.ID,
+		Subject:     alert.Subject,
+		Status:      alert.HumanStatus(),
+		Severity:    alert.HumanSeverity(),
+		Labels:      alert.Labels,
+		DedupKey:    alert.DedupKey,
+		Occurrences: alert.Occurrences,
+		LastSeenAt:  alert.LastSeenAt.Format(time.RFC3339),
+		CreatedAt:   alert.CreatedAt.Format(time.RFC3339),
+	}
+
+	if alert.SendAt != nil {
+		responseAlert.SendAt = alert.SendAt.Format(time.RFC3339)
+	}
+
+	if alert.SentAt != nil {
+		responseAlert.SentAt = alert.SentAt.Format(time.RFC3339)
+	}
+
+	return responseAlert
+}
+
+func alertURL(alert easyalert.Alert) string {
+	return fmt.Sprintf("/api/alerts/%d", alert.ID)
+}
+
+// GetAlertHandler should return a single alert of the user.
+type GetAlertHandler struct {
+	UserRepo  easyalert.UserRepository
+	AlertRepo easyalert.AlertRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h GetAlertHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
 		return
 	}
 
-	body, err := prettifyJSON(string(responseBodyBytes))
+	alert, ok := findUserAlert(w, r, h.AlertRepo, user)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, convertAlertToResponseBody(alert))
+}
+
+// DeleteAlertHandler should delete a single alert of the user.
+type DeleteAlertHandler struct {
+	UserRepo  easyalert.UserRepository
+	AlertRepo easyalert.AlertRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h DeleteAlertHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	alert, ok := findUserAlert(w, r, h.AlertRepo, user)
+	if !ok {
+		return
+	}
+
+	err := h.AlertRepo.DeleteAlert(alert)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "could not prettify json response")
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not delete alert")
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(body))
+	w.WriteHeader(http.StatusNoContent)
 }
 
-func convertAlertsToResponseBody(alerts []easyalert.Alert) []getAlertsResponseBody {
-	responseBodyArray := make([]getAlertsResponseBody, len(alerts))
+// ResendAlertHandler queues an already delivered, failed or canceled alert
+// of the user for delivery again.
+type ResendAlertHandler struct {
+	UserRepo  easyalert.UserRepository
+	AlertRepo easyalert.AlertRepository
+	Notifier  easyalert.Notifier
+}
 
-	for i, alert := range alerts {
-		responseAlert := getAlertsResponseBody{
-			Subject:   alert.Subject,
-			Status:    alert.HumanStatus(),
-			CreatedAt: alert.CreatedAt.Format(time.RFC3339),
+type resendAlertRequestBody struct {
+	Message string `json:"message"`
+	Format  string `json:"format"`
+}
+
+// ServeHTTP handles the HTTP request.
+func (h ResendAlertHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	alert, ok := findUserAlert(w, r, h.AlertRepo, user)
+	if !ok {
+		return
+	}
+
+	if alert.Status == easyalert.AlertStatusPending || alert.Status == easyalert.AlertStatusScheduled {
+		writeError(w, http.StatusConflict, "alert_not_sent", "Alert was not sent yet.")
+		return
+	}
+
+	bytes, err := ioutil.ReadAll(io.LimitReader(r.Body, maxAlertRequestSize+1))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not read http body")
+		return
+	}
+
+	if len(bytes) > maxAlertRequestSize {
+		writeError(w, http.StatusRequestEntityTooLarge, "request_too_large", "Request body too large.")
+		return
+	}
+
+	var body resendAlertRequestBody
+
+	if len(bytes) > 0 {
+		err = json.Unmarshal(bytes, &body)
+		if err != nil {
+			writeError(w, http.StatusUnprocessableEntity, "invalid_json", "invalid json")
+			return
 		}
+	}
+
+	if body.Format != "" && body.Format != easyalert.MessageFormatText && body.Format != easyalert.MessageFormatMarkdown {
+		writeAPIError(w, invalidField("format", "invalid_format", "Invalid format, expected text or markdown."))
+		return
+	}
 
-		if alert.SentAt != nil {
-			responseAlert.SentAt = alert.SentAt.Format(time.RFC3339)
+	// only alerts canceled before they were released still have their
+	// message, otherwise it has to be given again
+	notification := easyalert.Notification{Message: alert.Message, Format: alert.Format}
+	if body.Message != "" {
+		notification.Message = body.Message
+		notification.Format = body.Format
+	}
+
+	if notification.Message == "" {
+		writeAPIError(w, invalidField("message", "missing_field", "Message must be given, it is not stored once an alert was sent."))
+		return
+	}
+
+	alert.Status = easyalert.AlertStatusPending
+	alert.SendAt = nil
+	alert.SentAt = nil
+	alert.Message = ""
+	alert.Format = ""
+
+	alert, err = h.AlertRepo.UpdateAlert(alert)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not resend alert")
+		return
+	}
+
+	notification.Alert = alert
+	h.Notifier.Notify(user, notification)
+
+	writeJSON(w, http.StatusOK, convertAlertToResponseBody(alert))
+}
+
+// findUserAlert returns the alert given by the id route variable if it
+// belongs to the user. Otherwise an error is written and false is returned.
+func findUserAlert(w http.ResponseWriter, r *http.Request, repo easyalert.AlertRepository, user easyalert.User) (easyalert.Alert, bool) {
+	id, ok := getURLID(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "Alert not found.")
+		return easyalert.Alert{}, false
+	}
+
+	alert, err := repo.FindAlert("WHERE id = $1 AND user_id = $2", id, user.ID)
+	if err != nil {
+		if err == easyalert.ErrRecordDoesNotExist {
+			writeError(w, http.StatusNotFound, "not_found", "Alert not found.")
+			return easyalert.Alert{}, false
 		}
 
-		responseBodyArray[i] = responseAlert
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not fetch alert")
+		return easyalert.Alert{}, false
 	}
 
-	return responseBodyArray
+	return alert, true
 }
@@ -1,7 +1,9 @@
 package api_test
 
 import (
+	"encoding/base64"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -12,6 +14,7 @@ import (
 	"github.com/bakku/easyalert/mocks"
 	"github.com/bakku/easyalert/web/api"
 	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/require"
 )
 
@@ -24,8 +27,7 @@ func TestPOSTAlerts_ShouldReturnUnauthorizedIfAuthorizationHeaderIsNotPresent(t
 	handler.ServeHTTP(rr, req)
 
 	require.Equal(t, http.StatusUnauthorized, rr.Code)
-	require.Equal(t, "application/json; charset=UTF-8", rr.Header().Get("Content-Type"))
-	require.Equal(t, "{\n  \"error\": \"Missing or invalid Authorization header.\"\n}", rr.Body.String())
+	requireProblem(t, rr, "missing_token", "Missing or invalid Authorization header.")
 }
 
 func TestPOSTAlerts_ShouldReturnUnauthorizedIfNoUserExistsForToken(t *testing.T) {
@@ -47,8 +49,7 @@ func TestPOSTAlerts_ShouldReturnUnauthorizedIfNoUserExistsForToken(t *testing.T)
 	handler.ServeHTTP(rr, req)
 
 	require.Equal(t, http.StatusUnauthorized, rr.Code)
-	require.Equal(t, "application/json; charset=UTF-8", rr.Header().Get("Content-Type"))
-	require.Equal(t, "{\n  \"error\": \"Invalid token.\"\n}", rr.Body.String())
+	requireProblem(t, rr, "invalid_token", "Invalid token.")
 }
 
 func TestPOSTAlerts_ShouldReturnErrorIfAlertWasGivenIncorrectly(t *testing.T) {
@@ -72,8 +73,7 @@ func TestPOSTAlerts_ShouldReturnErrorIfAlertWasGivenIncorrectly(t *testing.T) {
 	handler.ServeHTTP(rr, req)
 
 	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
-	require.Equal(t, "application/json; charset=UTF-8", rr.Header().Get("Content-Type"))
-	require.Equal(t, "{\n  \"error\": \"invalid json\"\n}", rr.Body.String())
+	requireProblem(t, rr, "invalid_json", "invalid json")
 }
 
 func TestPOSTAlerts_ShouldReturnErrorIfSubjectOrMessageAreNotGiven(t *testing.T) {
@@ -100,8 +100,7 @@ func TestPOSTAlerts_ShouldReturnErrorIfSubjectOrMessageAreNotGiven(t *testing.T)
 	handler.ServeHTTP(rr, req)
 
 	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
-	require.Equal(t, "application/json; charset=UTF-8", rr.Header().Get("Content-Type"))
-	require.Equal(t, "{\n  \"error\": \"Subject or message not given.\"\n}", rr.Body.String())
+	requireProblem(t, rr, "missing_field", "Subject or message not given.")
 }
 
 func TestPOSTAlerts_ShouldReturnErrorIfCreationWasUnsuccessful(t *testing.T) {
@@ -132,8 +131,7 @@ func TestPOSTAlerts_ShouldReturnErrorIfCreationWasUnsuccessful(t *testing.T) {
 	handler.ServeHTTP(rr, req)
 
 	require.Equal(t, http.StatusInternalServerError, rr.Code)
-	require.Equal(t, "application/json; charset=UTF-8", rr.Header().Get("Content-Type"))
-	require.Equal(t, "{\n  \"error\": \"could not create alert\"\n}", rr.Body.String())
+	requireProblem(t, rr, "internal_error", "could not create alert")
 }
 
 func TestPOSTAlerts_ShouldCreateAlert(t *testing.T) {
@@ -143,8 +141,15 @@ func TestPOSTAlerts_ShouldCreateAlert(t *testing.T) {
 	userRepo := mocks.NewMockUserRepository(mockCtrl)
 	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{}, nil)
 
+	createdAt := time.Date(2019, 1, 17, 8, 50, 0, 0, time.UTC)
+
 	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
-	alertRepo.EXPECT().CreateAlert(gomock.Any()).Return(easyalert.Alert{}, nil)
+	alertRepo.EXPECT().CreateAlert(gomock.Any()).DoAndReturn(func(alert easyalert.Alert) (easyalert.Alert, error) {
+		alert.ID = 7
+		alert.LastSeenAt = createdAt
+		alert.CreatedAt = createdAt
+		return alert, nil
+	})
 
 	payload := `{
 		"subject": "Hi",
@@ -157,136 +162,2058 @@ func TestPOSTAlerts_ShouldCreateAlert(t *testing.T) {
 	req.Header.Set("Authorization", "Bearer 12345")
 
 	rr := httptest.NewRecorder()
+	notifier := mocks.NewMockNotifier(mockCtrl)
+	notifier.EXPECT().Notify(easyalert.User{}, gomock.Any()).Do(func(user easyalert.User, n easyalert.Notification) {
+		require.Equal(t, uint(7), n.Alert.ID)
+		require.Equal(t, "Hi", n.Message)
+		require.Empty(t, n.Attachments)
+	})
+
 	handler := api.CreateAlertsHandler{
+		Notifier:  notifier,
 		UserRepo:  userRepo,
 		AlertRepo: alertRepo,
 	}
 	handler.ServeHTTP(rr, req)
 
 	require.Equal(t, http.StatusCreated, rr.Code)
+	require.Equal(t, "application/json; charset=UTF-8", rr.Header().Get("Content-Type"))
+	require.Equal(t, "/api/alerts/7", rr.Header().Get("Location"))
+
+	expectedJsonResp := "{\n" +
+		"  \"id\": 7,\n" +
+		"  \"subject\": \"Hi\",\n" +
+		"  \"status\": \"pending\",\n" +
+		"  \"severity\": \"info\",\n" +
+		"  \"occurrences\": 1,\n" +
+		"  \"last_seen_at\": \"2019-01-17T08:50:00Z\",\n" +
+		"  \"created_at\": \"2019-01-17T08:50:00Z\"\n" +
+		"}"
+
+	require.Equal(t, expectedJsonResp, rr.Body.String())
 }
 
-func TestGETAlerts_ShouldReturnUnauthorizedIfAuthorizationHeaderIsNotPresent(t *testing.T) {
-	req, err := http.NewRequest("GET", "/api/alerts", nil)
+func TestPOSTAlerts_ShouldReturnErrorIfDedupWindowIsGivenWithoutKey(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{}, nil)
+
+	payload := `{
+		"subject": "Hi",
+		"message": "Hi",
+		"dedup_window": "10m"
+	}`
+
+	req, err := http.NewRequest("POST", "/api/alerts", strings.NewReader(payload))
 	require.Nil(t, err)
 
+	req.Header.Set("Authorization", "Bearer 12345")
+
 	rr := httptest.NewRecorder()
-	handler := api.GetAlertsHandler{}
+	handler := api.CreateAlertsHandler{
+		UserRepo: userRepo,
+	}
 	handler.ServeHTTP(rr, req)
 
-	require.Equal(t, http.StatusUnauthorized, rr.Code)
-	require.Equal(t, "application/json; charset=UTF-8", rr.Header().Get("Content-Type"))
-	require.Equal(t, "{\n  \"error\": \"Missing or invalid Authorization header.\"\n}", rr.Body.String())
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	requireProblem(t, rr, "missing_dedup_key", "Dedup window given without dedup key.")
 }
 
-func TestGETAlerts_ShouldReturnUnauthorizedIfNoUserExistsForToken(t *testing.T) {
+func TestPOSTAlerts_ShouldReturnErrorIfDedupWindowIsInvalid(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
 	userRepo := mocks.NewMockUserRepository(mockCtrl)
-	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{}, easyalert.ErrRecordDoesNotExist)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{}, nil)
 
-	req, err := http.NewRequest("GET", "/api/alerts", nil)
+	payload := `{
+		"subject": "Hi",
+		"message": "Hi",
+		"dedup_key": "backup",
+		"dedup_window": "ten minutes"
+	}`
+
+	req, err := http.NewRequest("POST", "/api/alerts", strings.NewReader(payload))
 	require.Nil(t, err)
 
 	req.Header.Set("Authorization", "Bearer 12345")
 
 	rr := httptest.NewRecorder()
-	handler := api.GetAlertsHandler{
+	handler := api.CreateAlertsHandler{
 		UserRepo: userRepo,
 	}
 	handler.ServeHTTP(rr, req)
 
-	require.Equal(t, http.StatusUnauthorized, rr.Code)
-	require.Equal(t, "application/json; charset=UTF-8", rr.Header().Get("Content-Type"))
-	require.Equal(t, "{\n  \"error\": \"Invalid token.\"\n}", rr.Body.String())
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	requireProblem(t, rr, "invalid_dedup_window", "Invalid dedup window.")
 }
 
-func TestGETAlerts_ShouldReturnErrorIfGettingAlertsReturnsError(t *testing.T) {
+func TestPOSTAlerts_ShouldFoldAlertIntoExistingAlertWithSameDedupKey(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
 	userRepo := mocks.NewMockUserRepository(mockCtrl)
 	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
 
+	folded := easyalert.Alert{ID: 5, Subject: "Hi", UserID: 1, DedupKey: "backup", Occurrences: 3}
+
 	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
-	alertRepo.EXPECT().FindAlerts(gomock.Any(), gomock.Any()).Return(nil, errors.New("Error!!"))
+	alertRepo.EXPECT().FoldAlert(gomock.Any(), uint(1), "backup", int64(600), easyalert.AlertStatusCanceled).Return(folded, nil)
 
-	req, err := http.NewRequest("GET", "/api/alerts", nil)
+	payload := `{
+		"subject": "Hi",
+		"message": "Hi",
+		"dedup_key": "backup",
+		"dedup_window": "10m"
+	}`
+
+	req, err := http.NewRequest("POST", "/api/alerts", strings.NewReader(payload))
 	require.Nil(t, err)
 
 	req.Header.Set("Authorization", "Bearer 12345")
 
 	rr := httptest.NewRecorder()
-	handler := api.GetAlertsHandler{
+	handler := api.CreateAlertsHandler{
 		UserRepo:  userRepo,
 		AlertRepo: alertRepo,
 	}
 	handler.ServeHTTP(rr, req)
 
-	require.Equal(t, http.StatusInternalServerError, rr.Code)
-	require.Equal(t, "application/json; charset=UTF-8", rr.Header().Get("Content-Type"))
-	require.Equal(t, "{\n  \"error\": \"could not fetch alerts\"\n}", rr.Body.String())
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "/api/alerts/5", rr.Header().Get("Location"))
+	require.Contains(t, rr.Body.String(), "\"occurrences\": 3")
 }
 
-func TestGETAlerts_ShouldReturnAllAlerts(t *testing.T) {
+func TestPOSTAlerts_ShouldCreateAlertIfNoAlertWithSameDedupKeyExists(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
 	userRepo := mocks.NewMockUserRepository(mockCtrl)
 	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
 
-	createdAt := time.Date(2018, 5, 10, 8, 50, 0, 0, time.UTC)
-	sentAt := time.Date(2018, 5, 10, 8, 53, 0, 0, time.UTC)
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().FoldAlert(gomock.Any(), uint(1), "backup", int64(3600), easyalert.AlertStatusCanceled).Return(easyalert.Alert{}, easyalert.ErrRecordDoesNotExist)
+	alertRepo.EXPECT().CreateAlert(gomock.Any()).DoAndReturn(func(alert easyalert.Alert) (easyalert.Alert, error) {
+		require.Equal(t, "backup", alert.DedupKey)
+		require.Equal(t, uint(1), alert.Occurrences)
 
-	expected := []easyalert.Alert{
-		{
-			ID:        1,
-			Subject:   "Test #1",
-			Status:    0,
-			SentAt:    nil,
-			CreatedAt: createdAt,
-		},
-		{
-			ID:        2,
-			Subject:   "Test #2",
-			Status:    1,
-			SentAt:    &sentAt,
-			CreatedAt: createdAt,
-		},
+		return alert, nil
+	})
+
+	payload := `{
+		"subject": "Hi",
+		"message": "Hi",
+		"dedup_key": "backup"
+	}`
+
+	req, err := http.NewRequest("POST", "/api/alerts", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	notifier := mocks.NewMockNotifier(mockCtrl)
+	notifier.EXPECT().Notify(gomock.Any(), gomock.Any())
+
+	handler := api.CreateAlertsHandler{
+		Notifier:  notifier,
+		UserRepo:  userRepo,
+		AlertRepo: alertRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusCreated, rr.Code)
+}
+
+func TestPOSTAlerts_ShouldReturnErrorIfSeverityIsInvalid(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{}, nil)
+
+	payload := `{
+		"subject": "Hi",
+		"message": "Hi",
+		"severity": "fatal"
+	}`
+
+	req, err := http.NewRequest("POST", "/api/alerts", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateAlertsHandler{
+		UserRepo: userRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	p := requireProblem(t, rr, "invalid_severity", "Invalid severity.")
+	require.Len(t, p.Errors, 1)
+	require.Equal(t, "severity", p.Errors[0].Field)
+}
+
+func TestPOSTAlerts_ShouldReturnErrorIfLabelKeyIsInvalid(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{}, nil)
+
+	payload := `{
+		"subject": "Hi",
+		"message": "Hi",
+		"labels": {"env:prod": "yes"}
+	}`
+
+	req, err := http.NewRequest("POST", "/api/alerts", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateAlertsHandler{
+		UserRepo: userRepo,
 	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	requireProblem(t, rr, "invalid_label", "Label keys must not be empty or contain a colon.")
+}
+
+func TestPOSTAlerts_ShouldCreateAlertWithSeverityAndLabels(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
 
 	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
-	alertRepo.EXPECT().FindAlerts(gomock.Any(), gomock.Any()).Return(expected, nil)
+	alertRepo.EXPECT().CreateAlert(gomock.Any()).DoAndReturn(func(alert easyalert.Alert) (easyalert.Alert, error) {
+		require.Equal(t, uint(easyalert.AlertSeverityCritical), alert.Severity)
+		require.Equal(t, map[string]string{"env": "prod"}, alert.Labels)
 
-	req, err := http.NewRequest("GET", "/api/alerts", nil)
+		return alert, nil
+	})
+
+	payload := `{
+		"subject": "Hi",
+		"message": "Hi",
+		"severity": "critical",
+		"labels": {"env": "prod"}
+	}`
+
+	req, err := http.NewRequest("POST", "/api/alerts", strings.NewReader(payload))
 	require.Nil(t, err)
 
 	req.Header.Set("Authorization", "Bearer 12345")
 
 	rr := httptest.NewRecorder()
-	handler := api.GetAlertsHandler{
+	notifier := mocks.NewMockNotifier(mockCtrl)
+	notifier.EXPECT().Notify(gomock.Any(), gomock.Any())
+
+	handler := api.CreateAlertsHandler{
+		Notifier:  notifier,
 		UserRepo:  userRepo,
 		AlertRepo: alertRepo,
 	}
 	handler.ServeHTTP(rr, req)
 
-	require.Equal(t, http.StatusOK, rr.Code)
-	require.Equal(t, "application/json; charset=UTF-8", rr.Header().Get("Content-Type"))
+	require.Equal(t, http.StatusCreated, rr.Code)
+}
 
-	expectedJsonResp := "[\n" +
-		"  {\n" +
-		"    \"subject\": \"Test #1\",\n" +
-		"    \"status\": \"pending\",\n" +
-		"    \"created_at\": \"2018-05-10T08:50:00Z\"\n" +
-		"  },\n" +
-		"  {\n" +
-		"    \"subject\": \"Test #2\",\n" +
-		"    \"status\": \"sent\",\n" +
-		"    \"sent_at\": \"2018-05-10T08:53:00Z\",\n" +
-		"    \"created_at\": \"2018-05-10T08:50:00Z\"\n" +
-		"  }\n" +
-		"]"
+func TestPOSTAlerts_ShouldReturnErrorIfSendAtAndDelayAreGiven(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
 
-	require.Equal(t, expectedJsonResp, rr.Body.String())
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{}, nil)
+
+	payload := `{
+		"subject": "Hi",
+		"message": "Hi",
+		"send_at": "2030-01-01T09:00:00+01:00",
+		"delay": "2h"
+	}`
+
+	req, err := http.NewRequest("POST", "/api/alerts", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateAlertsHandler{
+		UserRepo: userRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	requireProblem(t, rr, "conflicting_fields", "Only one of send_at and delay can be given.")
+}
+
+func TestPOSTAlerts_ShouldReturnErrorIfSendAtIsInThePast(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{}, nil)
+
+	payload := `{
+		"subject": "Hi",
+		"message": "Hi",
+		"send_at": "2018-01-01T09:00:00+01:00"
+	}`
+
+	req, err := http.NewRequest("POST", "/api/alerts", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateAlertsHandler{
+		UserRepo: userRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	requireProblem(t, rr, "send_at_in_past", "send_at must be in the future.")
+}
+
+func TestPOSTAlerts_ShouldScheduleAlertWithSendAt(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{}, nil)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().CreateAlert(gomock.Any()).DoAndReturn(func(alert easyalert.Alert) (easyalert.Alert, error) {
+		require.Equal(t, "scheduled", alert.HumanStatus())
+		require.Equal(t, time.Date(2030, 1, 1, 8, 0, 0, 0, time.UTC), *alert.SendAt)
+		require.Equal(t, "Hi **there**", alert.Message)
+		require.Equal(t, easyalert.MessageFormatMarkdown, alert.Format)
+
+		return alert, nil
+	})
+
+	payload := `{
+		"subject": "Hi",
+		"message": "Hi **there**",
+		"format": "markdown",
+		"send_at": "2030-01-01T09:00:00+01:00"
+	}`
+
+	req, err := http.NewRequest("POST", "/api/alerts", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateAlertsHandler{
+		UserRepo:  userRepo,
+		AlertRepo: alertRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusCreated, rr.Code)
+}
+
+func TestPOSTAlerts_ShouldScheduleAlertWithDelay(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{}, nil)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().CreateAlert(gomock.Any()).DoAndReturn(func(alert easyalert.Alert) (easyalert.Alert, error) {
+		require.Equal(t, "scheduled", alert.HumanStatus())
+		require.WithinDuration(t, time.Now().Add(2*time.Hour), *alert.SendAt, time.Minute)
+
+		return alert, nil
+	})
+
+	payload := `{
+		"subject": "Hi",
+		"message": "Hi",
+		"delay": "2h"
+	}`
+
+	req, err := http.NewRequest("POST", "/api/alerts", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateAlertsHandler{
+		UserRepo:  userRepo,
+		AlertRepo: alertRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusCreated, rr.Code)
+}
+
+func TestCancelAlert_ShouldReturnUnauthorizedIfAuthorizationHeaderIsNotPresent(t *testing.T) {
+	req, err := http.NewRequest("POST", "/api/alerts/1/cancel", nil)
+	require.Nil(t, err)
+
+	rr := httptest.NewRecorder()
+	handler := api.CancelAlertHandler{}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+	requireProblem(t, rr, "missing_token", "Missing or invalid Authorization header.")
+}
+
+func TestCancelAlert_ShouldReturnNotFoundIfAlertDoesNotBelongToUser(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().FindAlert(gomock.Any(), uint64(5), uint(1)).Return(easyalert.Alert{}, easyalert.ErrRecordDoesNotExist)
+
+	req, err := http.NewRequest("POST", "/api/alerts/5/cancel", nil)
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+	req = mux.SetURLVars(req, map[string]string{"id": "5"})
+
+	rr := httptest.NewRecorder()
+	handler := api.CancelAlertHandler{
+		UserRepo:  userRepo,
+		AlertRepo: alertRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusNotFound, rr.Code)
+	requireProblem(t, rr, "not_found", "Alert not found.")
+}
+
+func TestCancelAlert_ShouldReturnConflictIfAlertIsNotScheduled(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().FindAlert(gomock.Any(), gomock.Any(), gomock.Any()).Return(easyalert.Alert{ID: 5, Status: easyalert.AlertStatusSent}, nil)
+
+	req, err := http.NewRequest("POST", "/api/alerts/5/cancel", nil)
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+	req = mux.SetURLVars(req, map[string]string{"id": "5"})
+
+	rr := httptest.NewRecorder()
+	handler := api.CancelAlertHandler{
+		UserRepo:  userRepo,
+		AlertRepo: alertRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusConflict, rr.Code)
+	requireProblem(t, rr, "alert_not_scheduled", "Only scheduled alerts can be canceled.")
+}
+
+func TestCancelAlert_ShouldCancelScheduledAlert(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().FindAlert(gomock.Any(), gomock.Any(), gomock.Any()).Return(easyalert.Alert{ID: 5, Status: easyalert.AlertStatusScheduled, Occurrences: 1}, nil)
+	alertRepo.EXPECT().UpdateAlertStatus(easyalert.Alert{ID: 5, Status: easyalert.AlertStatusCanceled, Occurrences: 1}, uint(easyalert.AlertStatusScheduled)).DoAndReturn(func(alert easyalert.Alert, from uint) (easyalert.Alert, error) {
+		return alert, nil
+	})
+
+	req, err := http.NewRequest("POST", "/api/alerts/5/cancel", nil)
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+	req = mux.SetURLVars(req, map[string]string{"id": "5"})
+
+	rr := httptest.NewRecorder()
+	handler := api.CancelAlertHandler{
+		UserRepo:  userRepo,
+		AlertRepo: alertRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), "\"status\": \"canceled\"")
+	requireMatchesSpec(t, loadOpenAPI(t), "POST", "/api/alerts/{id}/cancel", rr)
+}
+
+func TestCancelAlert_ShouldReturnConflictIfAlertWasReleasedMeanwhile(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().FindAlert(gomock.Any(), gomock.Any(), gomock.Any()).Return(easyalert.Alert{ID: 5, Status: easyalert.AlertStatusScheduled}, nil)
+	alertRepo.EXPECT().UpdateAlertStatus(gomock.Any(), gomock.Any()).Return(easyalert.Alert{}, easyalert.ErrRecordDoesNotExist)
+
+	req, err := http.NewRequest("POST", "/api/alerts/5/cancel", nil)
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+	req = mux.SetURLVars(req, map[string]string{"id": "5"})
+
+	rr := httptest.NewRecorder()
+	handler := api.CancelAlertHandler{
+		UserRepo:  userRepo,
+		AlertRepo: alertRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusConflict, rr.Code)
+	requireProblem(t, rr, "alert_not_scheduled", "Only scheduled alerts can be canceled.")
+}
+
+func TestGETAlerts_ShouldReturnUnauthorizedIfAuthorizationHeaderIsNotPresent(t *testing.T) {
+	req, err := http.NewRequest("GET", "/api/alerts", nil)
+	require.Nil(t, err)
+
+	rr := httptest.NewRecorder()
+	handler := api.GetAlertsHandler{}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+	requireProblem(t, rr, "missing_token", "Missing or invalid Authorization header.")
+}
+
+func TestGETAlerts_ShouldReturnUnauthorizedIfNoUserExistsForToken(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{}, easyalert.ErrRecordDoesNotExist)
+
+	req, err := http.NewRequest("GET", "/api/alerts", nil)
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.GetAlertsHandler{
+		UserRepo: userRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+	requireProblem(t, rr, "invalid_token", "Invalid token.")
+}
+
+func TestGETAlerts_ShouldReturnErrorIfGettingAlertsReturnsError(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().FindAlerts(gomock.Any(), gomock.Any()).Return(nil, errors.New("Error!!"))
+
+	req, err := http.NewRequest("GET", "/api/alerts", nil)
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.GetAlertsHandler{
+		UserRepo:  userRepo,
+		AlertRepo: alertRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusInternalServerError, rr.Code)
+	requireProblem(t, rr, "internal_error", "could not fetch alerts")
+}
+
+func TestGETAlerts_ShouldReturnAllAlerts(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	createdAt := time.Date(2018, 5, 10, 8, 50, 0, 0, time.UTC)
+	sentAt := time.Date(2018, 5, 10, 8, 53, 0, 0, time.UTC)
+
+	expected := []easyalert.Alert{
+		{
+			ID:          1,
+			Subject:     "Test #1",
+			Status:      0,
+			SentAt:      nil,
+			Occurrences: 1,
+			LastSeenAt:  createdAt,
+			CreatedAt:   createdAt,
+		},
+		{
+			ID:          2,
+			Subject:     "Test #2",
+			Status:      1,
+			SentAt:      &sentAt,
+			DedupKey:    "backup",
+			Occurrences: 3,
+			LastSeenAt:  sentAt,
+			Severity:    2,
+			Labels:      map[string]string{"host": "db1"},
+			CreatedAt:   createdAt,
+		},
+	}
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().FindAlerts(gomock.Any(), gomock.Any()).Return(expected, nil)
+
+	req, err := http.NewRequest("GET", "/api/alerts", nil)
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.GetAlertsHandler{
+		UserRepo:  userRepo,
+		AlertRepo: alertRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "application/json; charset=UTF-8", rr.Header().Get("Content-Type"))
+
+	expectedJsonResp := "[\n" +
+		"  {\n" +
+		"    \"id\": 1,\n" +
+		"    \"subject\": \"Test #1\",\n" +
+		"    \"status\": \"pending\",\n" +
+		"    \"severity\": \"info\",\n" +
+		"    \"occurrences\": 1,\n" +
+		"    \"last_seen_at\": \"2018-05-10T08:50:00Z\",\n" +
+		"    \"created_at\": \"2018-05-10T08:50:00Z\"\n" +
+		"  },\n" +
+		"  {\n" +
+		"    \"id\": 2,\n" +
+		"    \"subject\": \"Test #2\",\n" +
+		"    \"status\": \"sent\",\n" +
+		"    \"severity\": \"critical\",\n" +
+		"    \"labels\": {\n" +
+		"      \"host\": \"db1\"\n" +
+		"    },\n" +
+		"    \"sent_at\": \"2018-05-10T08:53:00Z\",\n" +
+		"    \"dedup_key\": \"backup\",\n" +
+		"    \"occurrences\": 3,\n" +
+		"    \"last_seen_at\": \"2018-05-10T08:53:00Z\",\n" +
+		"    \"created_at\": \"2018-05-10T08:50:00Z\"\n" +
+		"  }\n" +
+		"]"
+
+	require.Equal(t, expectedJsonResp, rr.Body.String())
+}
+
+func TestGETAlerts_ShouldReturnErrorIfFilterIsInvalid(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	req, err := http.NewRequest("GET", "/api/alerts?label=env", nil)
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.GetAlertsHandler{
+		UserRepo: userRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	requireProblem(t, rr, "invalid_label", "Invalid label filter.")
+}
+
+func TestGETAlerts_ShouldFilterBySeverityAndLabels(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().FindAlerts(
+		"WHERE user_id = $1 AND severity = $2 AND labels @> $3 ORDER BY created_at DESC, id DESC LIMIT $4",
+		uint(1), uint(easyalert.AlertSeverityCritical), `{"env":"prod"}`, 51,
+	).Return(nil, nil)
+
+	req, err := http.NewRequest("GET", "/api/alerts?severity=critical&label=env:prod", nil)
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.GetAlertsHandler{
+		UserRepo:  userRepo,
+		AlertRepo: alertRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "[]", rr.Body.String())
+}
+
+func TestGETAlerts_ShouldFilterByStatusTimeAndSubject(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().FindAlerts(
+		"WHERE user_id = $1 AND status = $2 AND created_at >= $3 AND created_at < $4"+
+			" AND strpos(lower(subject), lower($5)) > 0 ORDER BY created_at DESC, id DESC LIMIT $6",
+		uint(1), uint(easyalert.AlertStatusFailed),
+		time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2019, 1, 2, 0, 0, 0, 0, time.UTC),
+		"backup", 11,
+	).Return(nil, nil)
+
+	req, err := http.NewRequest("GET", "/api/alerts?status=failed&since=2019-01-01T01:00:00%2B01:00&until=2019-01-02T00:00:00Z&q=backup&limit=10", nil)
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.GetAlertsHandler{
+		UserRepo:  userRepo,
+		AlertRepo: alertRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "", rr.Header().Get("Link"))
+}
+
+func TestGETAlerts_ShouldReturnErrorIfLimitIsInvalid(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	req, err := http.NewRequest("GET", "/api/alerts?limit=1000", nil)
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.GetAlertsHandler{
+		UserRepo: userRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	requireProblem(t, rr, "invalid_limit", "Limit must be between 1 and 100.")
+}
+
+func TestGETAlerts_ShouldReturnErrorIfCursorIsInvalid(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	req, err := http.NewRequest("GET", "/api/alerts?cursor=invalid", nil)
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.GetAlertsHandler{
+		UserRepo: userRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	requireProblem(t, rr, "invalid_cursor", "Invalid cursor.")
+}
+
+func testCursor(createdAt string, id uint, backwards bool) string {
+	raw := fmt.Sprintf(`{"t":"%s","id":%d`, createdAt, id)
+	if backwards {
+		raw += `,"b":true`
+	}
+
+	return base64.RawURLEncoding.EncodeToString([]byte(raw + "}"))
+}
+
+func TestGETAlerts_ShouldReturnNextLinkIfMoreAlertsExist(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	createdAt := time.Date(2019, 1, 14, 8, 0, 0, 0, time.UTC)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().FindAlerts(gomock.Any(), uint(1), 3).Return([]easyalert.Alert{
+		{ID: 3, Subject: "Test #3", CreatedAt: createdAt},
+		{ID: 2, Subject: "Test #2", CreatedAt: createdAt},
+		{ID: 1, Subject: "Test #1", CreatedAt: createdAt},
+	}, nil)
+
+	req, err := http.NewRequest("GET", "/api/alerts?limit=2", nil)
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.GetAlertsHandler{
+		UserRepo:  userRepo,
+		AlertRepo: alertRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.NotContains(t, rr.Body.String(), "Test #1")
+
+	next := testCursor("2019-01-14T08:00:00Z", 2, false)
+	require.Equal(t, `</api/alerts?cursor=`+next+`&limit=2>; rel="next"`, rr.Header().Get("Link"))
+}
+
+func TestGETAlerts_ShouldContinueAfterCursor(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	createdAt := time.Date(2019, 1, 14, 8, 0, 0, 0, time.UTC)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().FindAlerts(
+		"WHERE user_id = $1 AND (created_at, id) < ($2, $3) ORDER BY created_at DESC, id DESC LIMIT $4",
+		uint(1), createdAt, uint(2), 3,
+	).Return([]easyalert.Alert{
+		{ID: 1, Subject: "Test #1", CreatedAt: createdAt},
+	}, nil)
+
+	cursor := testCursor("2019-01-14T08:00:00Z", 2, false)
+
+	req, err := http.NewRequest("GET", "/api/alerts?limit=2&cursor="+cursor, nil)
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.GetAlertsHandler{
+		UserRepo:  userRepo,
+		AlertRepo: alertRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	prev := testCursor("2019-01-14T08:00:00Z", 1, true)
+	require.Equal(t, `</api/alerts?cursor=`+prev+`&limit=2>; rel="prev"`, rr.Header().Get("Link"))
+}
+
+func TestGETAlerts_ShouldGoBackBeforeCursor(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	createdAt := time.Date(2019, 1, 14, 8, 0, 0, 0, time.UTC)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().FindAlerts(
+		"WHERE user_id = $1 AND (created_at, id) > ($2, $3) ORDER BY created_at ASC, id ASC LIMIT $4",
+		uint(1), createdAt, uint(1), 2,
+	).Return([]easyalert.Alert{
+		{ID: 2, Subject: "Test #2", CreatedAt: createdAt},
+		{ID: 3, Subject: "Test #3", CreatedAt: createdAt},
+	}, nil)
+
+	cursor := testCursor("2019-01-14T08:00:00Z", 1, true)
+
+	req, err := http.NewRequest("GET", "/api/alerts?limit=1&cursor="+cursor, nil)
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.GetAlertsHandler{
+		UserRepo:  userRepo,
+		AlertRepo: alertRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), "Test #2")
+	require.NotContains(t, rr.Body.String(), "Test #3")
+
+	next := testCursor("2019-01-14T08:00:00Z", 2, false)
+	prev := testCursor("2019-01-14T08:00:00Z", 2, true)
+	require.Equal(t, `</api/alerts?cursor=`+next+`&limit=1>; rel="next", </api/alerts?cursor=`+prev+`&limit=1>; rel="prev"`, rr.Header().Get("Link"))
+}
+
+func TestGETAlert_ShouldReturnUnauthorizedIfNoUserExistsForToken(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{}, easyalert.ErrRecordDoesNotExist)
+
+	req, err := http.NewRequest("GET", "/api/alerts/5", nil)
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+	req = mux.SetURLVars(req, map[string]string{"id": "5"})
+
+	rr := httptest.NewRecorder()
+	handler := api.GetAlertHandler{
+		UserRepo: userRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+	requireProblem(t, rr, "invalid_token", "Invalid token.")
+}
+
+func TestGETAlert_ShouldReturnNotFoundIfAlertDoesNotBelongToUser(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().FindAlert("WHERE id = $1 AND user_id = $2", uint64(5), uint(1)).Return(easyalert.Alert{}, easyalert.ErrRecordDoesNotExist)
+
+	req, err := http.NewRequest("GET", "/api/alerts/5", nil)
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+	req = mux.SetURLVars(req, map[string]string{"id": "5"})
+
+	rr := httptest.NewRecorder()
+	handler := api.GetAlertHandler{
+		UserRepo:  userRepo,
+		AlertRepo: alertRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusNotFound, rr.Code)
+	requireProblem(t, rr, "not_found", "Alert not found.")
+}
+
+func TestGETAlert_ShouldReturnAlert(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	createdAt := time.Date(2019, 1, 17, 8, 50, 0, 0, time.UTC)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().FindAlert(gomock.Any(), uint64(5), uint(1)).Return(easyalert.Alert{
+		ID:          5,
+		Subject:     "Test",
+		Status:      easyalert.AlertStatusFailed,
+		Occurrences: 1,
+		LastSeenAt:  createdAt,
+		CreatedAt:   createdAt,
+	}, nil)
+
+	req, err := http.NewRequest("GET", "/api/alerts/5", nil)
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+	req = mux.SetURLVars(req, map[string]string{"id": "5"})
+
+	rr := httptest.NewRecorder()
+	handler := api.GetAlertHandler{
+		UserRepo:  userRepo,
+		AlertRepo: alertRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "application/json; charset=UTF-8", rr.Header().Get("Content-Type"))
+
+	expectedJsonResp := "{\n" +
+		"  \"id\": 5,\n" +
+		"  \"subject\": \"Test\",\n" +
+		"  \"status\": \"failed\",\n" +
+		"  \"severity\": \"info\",\n" +
+		"  \"occurrences\": 1,\n" +
+		"  \"last_seen_at\": \"2019-01-17T08:50:00Z\",\n" +
+		"  \"created_at\": \"2019-01-17T08:50:00Z\"\n" +
+		"}"
+
+	require.Equal(t, expectedJsonResp, rr.Body.String())
+}
+
+func TestDELETEAlert_ShouldReturnErrorIfDeletionFails(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().FindAlert(gomock.Any(), uint64(5), uint(1)).Return(easyalert.Alert{ID: 5}, nil)
+	alertRepo.EXPECT().DeleteAlert(easyalert.Alert{ID: 5}).Return(errors.New("Error!!"))
+
+	req, err := http.NewRequest("DELETE", "/api/alerts/5", nil)
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+	req = mux.SetURLVars(req, map[string]string{"id": "5"})
+
+	rr := httptest.NewRecorder()
+	handler := api.DeleteAlertHandler{
+		UserRepo:  userRepo,
+		AlertRepo: alertRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusInternalServerError, rr.Code)
+	requireProblem(t, rr, "internal_error", "could not delete alert")
+}
+
+func TestDELETEAlert_ShouldDeleteAlert(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().FindAlert(gomock.Any(), uint64(5), uint(1)).Return(easyalert.Alert{ID: 5}, nil)
+	alertRepo.EXPECT().DeleteAlert(easyalert.Alert{ID: 5}).Return(nil)
+
+	req, err := http.NewRequest("DELETE", "/api/alerts/5", nil)
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+	req = mux.SetURLVars(req, map[string]string{"id": "5"})
+
+	rr := httptest.NewRecorder()
+	handler := api.DeleteAlertHandler{
+		UserRepo:  userRepo,
+		AlertRepo: alertRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusNoContent, rr.Code)
+}
+
+func TestResendAlert_ShouldReturnConflictIfAlertWasNotSentYet(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().FindAlert(gomock.Any(), uint64(5), uint(1)).Return(easyalert.Alert{ID: 5, Status: easyalert.AlertStatusPending}, nil)
+
+	req, err := http.NewRequest("POST", "/api/alerts/5/resend", nil)
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+	req = mux.SetURLVars(req, map[string]string{"id": "5"})
+
+	rr := httptest.NewRecorder()
+	handler := api.ResendAlertHandler{
+		UserRepo:  userRepo,
+		AlertRepo: alertRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusConflict, rr.Code)
+	requireProblem(t, rr, "alert_not_sent", "Alert was not sent yet.")
+}
+
+func TestResendAlert_ShouldQueueAlertAgain(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	sentAt := time.Date(2019, 1, 17, 8, 50, 0, 0, time.UTC)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().FindAlert(gomock.Any(), uint64(5), uint(1)).Return(easyalert.Alert{ID: 5, Status: easyalert.AlertStatusFailed, SentAt: &sentAt}, nil)
+	alertRepo.EXPECT().UpdateAlert(easyalert.Alert{ID: 5, Status: easyalert.AlertStatusPending}).DoAndReturn(func(alert easyalert.Alert) (easyalert.Alert, error) {
+		return alert, nil
+	})
+
+	req, err := http.NewRequest("POST", "/api/alerts/5/resend", strings.NewReader(`{"message": "Disk is still full"}`))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+	req = mux.SetURLVars(req, map[string]string{"id": "5"})
+
+	rr := httptest.NewRecorder()
+	notifier := mocks.NewMockNotifier(mockCtrl)
+	notifier.EXPECT().Notify(easyalert.User{ID: 1}, easyalert.Notification{
+		Alert:   easyalert.Alert{ID: 5, Status: easyalert.AlertStatusPending},
+		Message: "Disk is still full",
+	})
+
+	handler := api.ResendAlertHandler{
+		Notifier:  notifier,
+		UserRepo:  userRepo,
+		AlertRepo: alertRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), "\"status\": \"pending\"")
+}
+
+func TestResendAlert_ShouldRequireMessageOfSentAlerts(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().FindAlert(gomock.Any(), uint64(5), uint(1)).Return(easyalert.Alert{ID: 5, Status: easyalert.AlertStatusSent}, nil)
+
+	req, err := http.NewRequest("POST", "/api/alerts/5/resend", http.NoBody)
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+	req = mux.SetURLVars(req, map[string]string{"id": "5"})
+
+	rr := httptest.NewRecorder()
+	handler := api.ResendAlertHandler{
+		UserRepo:  userRepo,
+		AlertRepo: alertRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	requireMatchesSpec(t, loadOpenAPI(t), "POST", "/api/alerts/{id}/resend", rr)
+
+	p := requireProblem(t, rr, "missing_field", "Message must be given, it is not stored once an alert was sent.")
+	require.Equal(t, "message", p.Errors[0].Field)
+}
+
+func TestResendAlert_ShouldSendStoredMessageOfCanceledAlerts(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	sendAt := time.Date(2030, 1, 1, 8, 0, 0, 0, time.UTC)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().FindAlert(gomock.Any(), uint64(5), uint(1)).Return(easyalert.Alert{
+		ID:      5,
+		Status:  easyalert.AlertStatusCanceled,
+		SendAt:  &sendAt,
+		Message: "Maintenance *tonight*",
+		Format:  easyalert.MessageFormatMarkdown,
+	}, nil)
+	alertRepo.EXPECT().UpdateAlert(easyalert.Alert{ID: 5, Status: easyalert.AlertStatusPending}).DoAndReturn(func(alert easyalert.Alert) (easyalert.Alert, error) {
+		return alert, nil
+	})
+
+	notifier := mocks.NewMockNotifier(mockCtrl)
+	notifier.EXPECT().Notify(easyalert.User{ID: 1}, easyalert.Notification{
+		Alert:   easyalert.Alert{ID: 5, Status: easyalert.AlertStatusPending},
+		Message: "Maintenance *tonight*",
+		Format:  easyalert.MessageFormatMarkdown,
+	})
+
+	req, err := http.NewRequest("POST", "/api/alerts/5/resend", http.NoBody)
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+	req = mux.SetURLVars(req, map[string]string{"id": "5"})
+
+	rr := httptest.NewRecorder()
+	handler := api.ResendAlertHandler{
+		Notifier:  notifier,
+		UserRepo:  userRepo,
+		AlertRepo: alertRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestPOSTAlerts_ShouldReplayResponseForIdempotencyKey(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil).Times(2)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().CreateAlert(gomock.Any()).DoAndReturn(func(alert easyalert.Alert) (easyalert.Alert, error) {
+		alert.ID = 7
+		return alert, nil
+	}).Times(1)
+
+	var stored easyalert.IdempotencyKey
+
+	idempotencyRepo := mocks.NewMockIdempotencyKeyRepository(mockCtrl)
+	gomock.InOrder(
+		idempotencyRepo.EXPECT().CreateIdempotencyKey(gomock.Any()).DoAndReturn(func(key easyalert.IdempotencyKey) (easyalert.IdempotencyKey, error) {
+			require.Equal(t, 0, key.StatusCode)
+			key.ID = 3
+			return key, nil
+		}),
+		idempotencyRepo.EXPECT().UpdateIdempotencyKey(gomock.Any()).DoAndReturn(func(key easyalert.IdempotencyKey) (easyalert.IdempotencyKey, error) {
+			stored = key
+			return key, nil
+		}),
+		idempotencyRepo.EXPECT().CreateIdempotencyKey(gomock.Any()).Return(easyalert.IdempotencyKey{}, easyalert.ErrRecordAlreadyExists),
+		idempotencyRepo.EXPECT().FindIdempotencyKey(gomock.Any(), uint(1), "abc").DoAndReturn(func(query string, params ...interface{}) (easyalert.IdempotencyKey, error) {
+			return stored, nil
+		}),
+	)
+
+	notifier := mocks.NewMockNotifier(mockCtrl)
+	notifier.EXPECT().Notify(gomock.Any(), gomock.Any())
+
+	handler := api.CreateAlertsHandler{
+		Notifier:        notifier,
+		UserRepo:        userRepo,
+		AlertRepo:       alertRepo,
+		IdempotencyRepo: idempotencyRepo,
+	}
+
+	payload := `{"subject": "Hi", "message": "Hi"}`
+
+	var responses []*httptest.ResponseRecorder
+
+	for i := 0; i < 2; i++ {
+		req, err := http.NewRequest("POST", "/api/alerts", strings.NewReader(payload))
+		require.Nil(t, err)
+
+		req.Header.Set("Authorization", "Bearer 12345")
+		req.Header.Set("Idempotency-Key", "abc")
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		responses = append(responses, rr)
+	}
+
+	require.Equal(t, uint(3), stored.ID)
+	require.Equal(t, "abc", stored.Key)
+	require.Equal(t, uint(1), stored.UserID)
+	require.Equal(t, http.StatusCreated, stored.StatusCode)
+	require.Equal(t, "/api/alerts/7", stored.Location)
+
+	require.Equal(t, http.StatusCreated, responses[1].Code)
+	require.Equal(t, "/api/alerts/7", responses[1].Header().Get("Location"))
+	require.Equal(t, "true", responses[1].Header().Get("Idempotent-Replayed"))
+	require.Equal(t, responses[0].Body.String(), responses[1].Body.String())
+}
+
+func TestPOSTAlerts_ShouldReplayMultipartRequestWithAnotherBoundary(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil).Times(2)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().FindAlerts(gomock.Any(), uint(1), gomock.Any()).Return(nil, nil)
+	alertRepo.EXPECT().CreateAlert(gomock.Any()).DoAndReturn(func(alert easyalert.Alert) (easyalert.Alert, error) {
+		alert.ID = 7
+		return alert, nil
+	}).Times(1)
+
+	var stored easyalert.IdempotencyKey
+
+	idempotencyRepo := mocks.NewMockIdempotencyKeyRepository(mockCtrl)
+	gomock.InOrder(
+		idempotencyRepo.EXPECT().CreateIdempotencyKey(gomock.Any()).DoAndReturn(func(key easyalert.IdempotencyKey) (easyalert.IdempotencyKey, error) {
+			return key, nil
+		}),
+		idempotencyRepo.EXPECT().UpdateIdempotencyKey(gomock.Any()).DoAndReturn(func(key easyalert.IdempotencyKey) (easyalert.IdempotencyKey, error) {
+			stored = key
+			return key, nil
+		}),
+		idempotencyRepo.EXPECT().CreateIdempotencyKey(gomock.Any()).Return(easyalert.IdempotencyKey{}, easyalert.ErrRecordAlreadyExists),
+		idempotencyRepo.EXPECT().FindIdempotencyKey(gomock.Any(), uint(1), "abc").DoAndReturn(func(query string, params ...interface{}) (easyalert.IdempotencyKey, error) {
+			return stored, nil
+		}),
+	)
+
+	notifier := mocks.NewMockNotifier(mockCtrl)
+	notifier.EXPECT().Notify(gomock.Any(), gomock.Any())
+
+	handler := api.CreateAlertsHandler{
+		Notifier:        notifier,
+		UserRepo:        userRepo,
+		AlertRepo:       alertRepo,
+		IdempotencyRepo: idempotencyRepo,
+	}
+
+	var responses []*httptest.ResponseRecorder
+
+	for _, boundary := range []string{"XYZ", "ABC"} {
+		payload := strings.Replace(multipartAlertWithAttachment("rsync error: code 12", ""), "XYZ", boundary, -1)
+
+		req, err := http.NewRequest("POST", "/api/alerts", strings.NewReader(payload))
+		require.Nil(t, err)
+
+		req.Header.Set("Authorization", "Bearer 12345")
+		req.Header.Set("Content-Type", "multipart/form-data; boundary="+boundary)
+		req.Header.Set("Idempotency-Key", "abc")
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		responses = append(responses, rr)
+	}
+
+	require.Equal(t, http.StatusCreated, responses[1].Code)
+	require.Equal(t, "true", responses[1].Header().Get("Idempotent-Replayed"))
+}
+
+func TestPOSTAlerts_ShouldReturnConflictIfIdempotencyKeyIsReusedWithDifferentPayload(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	idempotencyRepo := mocks.NewMockIdempotencyKeyRepository(mockCtrl)
+	idempotencyRepo.EXPECT().CreateIdempotencyKey(gomock.Any()).Return(easyalert.IdempotencyKey{}, easyalert.ErrRecordAlreadyExists)
+	idempotencyRepo.EXPECT().FindIdempotencyKey(gomock.Any(), uint(1), "abc").Return(easyalert.IdempotencyKey{
+		Key:         "abc",
+		RequestHash: "1234",
+		StatusCode:  http.StatusCreated,
+		Body:        "{}",
+		UserID:      1,
+	}, nil)
+
+	payload := `{"subject": "Hi", "message": "Hi"}`
+
+	req, err := http.NewRequest("POST", "/api/alerts", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+	req.Header.Set("Idempotency-Key", "abc")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateAlertsHandler{
+		UserRepo:        userRepo,
+		IdempotencyRepo: idempotencyRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusConflict, rr.Code)
+	requireProblem(t, rr, "idempotency_key_reused", "Idempotency key was already used for a different request.")
+}
+
+func TestPOSTAlerts_ShouldReturnConflictIfIdempotentRequestIsInProgress(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	var requestHash string
+
+	idempotencyRepo := mocks.NewMockIdempotencyKeyRepository(mockCtrl)
+	idempotencyRepo.EXPECT().CreateIdempotencyKey(gomock.Any()).DoAndReturn(func(key easyalert.IdempotencyKey) (easyalert.IdempotencyKey, error) {
+		requestHash = key.RequestHash
+		return easyalert.IdempotencyKey{}, easyalert.ErrRecordAlreadyExists
+	})
+	idempotencyRepo.EXPECT().FindIdempotencyKey(gomock.Any(), uint(1), "abc").DoAndReturn(func(query string, params ...interface{}) (easyalert.IdempotencyKey, error) {
+		return easyalert.IdempotencyKey{Key: "abc", RequestHash: requestHash, UserID: 1}, nil
+	})
+
+	payload := `{"subject": "Hi", "message": "Hi"}`
+
+	req, err := http.NewRequest("POST", "/api/alerts", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+	req.Header.Set("Idempotency-Key", "abc")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateAlertsHandler{
+		UserRepo:        userRepo,
+		IdempotencyRepo: idempotencyRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusConflict, rr.Code)
+	requireProblem(t, rr, "idempotency_key_in_progress", "A request with this idempotency key is still in progress.")
+}
+
+func TestPOSTAlerts_ShouldNotStoreServerErrorsForIdempotencyKey(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().CreateAlert(gomock.Any()).Return(easyalert.Alert{}, errors.New("Error!!"))
+
+	idempotencyRepo := mocks.NewMockIdempotencyKeyRepository(mockCtrl)
+	idempotencyRepo.EXPECT().CreateIdempotencyKey(gomock.Any()).DoAndReturn(func(key easyalert.IdempotencyKey) (easyalert.IdempotencyKey, error) {
+		key.ID = 3
+		return key, nil
+	})
+	idempotencyRepo.EXPECT().DeleteIdempotencyKey(gomock.Any()).DoAndReturn(func(key easyalert.IdempotencyKey) error {
+		require.Equal(t, uint(3), key.ID)
+		return nil
+	})
+
+	payload := `{"subject": "Hi", "message": "Hi"}`
+
+	req, err := http.NewRequest("POST", "/api/alerts", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+	req.Header.Set("Idempotency-Key", "abc")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateAlertsHandler{
+		UserRepo:        userRepo,
+		AlertRepo:       alertRepo,
+		IdempotencyRepo: idempotencyRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusInternalServerError, rr.Code)
+	requireProblem(t, rr, "internal_error", "could not create alert")
+}
+
+func TestPOSTAlerts_ShouldAcceptFormData(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().CreateAlert(gomock.Any()).DoAndReturn(func(alert easyalert.Alert) (easyalert.Alert, error) {
+		require.Equal(t, "Backup failed", alert.Subject)
+		require.Equal(t, uint(easyalert.AlertSeverityCritical), alert.Severity)
+		require.Equal(t, map[string]string{"env": "prod", "host": "db:1"}, alert.Labels)
+		return alert, nil
+	})
+
+	payload := "subject=Backup+failed&message=Disk+full&severity=critical&label=env:prod&label=host:db:1"
+
+	req, err := http.NewRequest("POST", "/api/alerts", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	rr := httptest.NewRecorder()
+	notifier := mocks.NewMockNotifier(mockCtrl)
+	notifier.EXPECT().Notify(gomock.Any(), gomock.Any())
+
+	handler := api.CreateAlertsHandler{
+		Notifier:  notifier,
+		UserRepo:  userRepo,
+		AlertRepo: alertRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusCreated, rr.Code)
+}
+
+func TestPOSTAlerts_ShouldAcceptMultipartFormData(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().CreateAlert(gomock.Any()).DoAndReturn(func(alert easyalert.Alert) (easyalert.Alert, error) {
+		require.Equal(t, "Backup failed", alert.Subject)
+		return alert, nil
+	})
+
+	payload := "--XYZ\r\n" +
+		"Content-Disposition: form-data; name=\"subject\"\r\n\r\n" +
+		"Backup failed\r\n" +
+		"--XYZ\r\n" +
+		"Content-Disposition: form-data; name=\"message\"\r\n\r\n" +
+		"Disk full\r\n" +
+		"--XYZ--\r\n"
+
+	req, err := http.NewRequest("POST", "/api/alerts", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+	req.Header.Set("Content-Type", "multipart/form-data; boundary=XYZ")
+
+	rr := httptest.NewRecorder()
+	notifier := mocks.NewMockNotifier(mockCtrl)
+	notifier.EXPECT().Notify(gomock.Any(), gomock.Any())
+
+	handler := api.CreateAlertsHandler{
+		Notifier:  notifier,
+		UserRepo:  userRepo,
+		AlertRepo: alertRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusCreated, rr.Code)
+}
+
+func TestPOSTAlerts_ShouldAcceptPlainText(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().CreateAlert(gomock.Any()).DoAndReturn(func(alert easyalert.Alert) (easyalert.Alert, error) {
+		require.Equal(t, "Backup failed", alert.Subject)
+		require.Equal(t, uint(easyalert.AlertSeverityWarning), alert.Severity)
+		return alert, nil
+	})
+
+	payload := "Backup failed\nrsync: connection unexpectedly closed\nrsync error: code 12\n"
+
+	req, err := http.NewRequest("POST", "/api/alerts?severity=warning", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+
+	rr := httptest.NewRecorder()
+	notifier := mocks.NewMockNotifier(mockCtrl)
+	notifier.EXPECT().Notify(gomock.Any(), gomock.Any()).Do(func(user easyalert.User, n easyalert.Notification) {
+		require.Equal(t, "rsync: connection unexpectedly closed\nrsync error: code 12", n.Message)
+	})
+
+	handler := api.CreateAlertsHandler{
+		Notifier:  notifier,
+		UserRepo:  userRepo,
+		AlertRepo: alertRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusCreated, rr.Code)
+}
+
+func TestPOSTAlerts_ShouldAcceptQueryParameters(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().CreateAlert(gomock.Any()).DoAndReturn(func(alert easyalert.Alert) (easyalert.Alert, error) {
+		require.Equal(t, "Backup failed", alert.Subject)
+		return alert, nil
+	})
+
+	req, err := http.NewRequest("POST", "/api/alerts?subject=Backup+failed&message=Disk+full", strings.NewReader(""))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	notifier := mocks.NewMockNotifier(mockCtrl)
+	notifier.EXPECT().Notify(gomock.Any(), gomock.Any())
+
+	handler := api.CreateAlertsHandler{
+		Notifier:  notifier,
+		UserRepo:  userRepo,
+		AlertRepo: alertRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusCreated, rr.Code)
+}
+
+func TestPOSTAlerts_ShouldReturnErrorIfLabelIsNotKeyValue(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	payload := "subject=Hi&message=Hi&label=prod"
+
+	req, err := http.NewRequest("POST", "/api/alerts", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateAlertsHandler{
+		UserRepo: userRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	requireProblem(t, rr, "invalid_label", "Labels must be given as key:value.")
+}
+
+func TestPOSTAlerts_ShouldReturnErrorIfContentTypeIsNotSupported(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	req, err := http.NewRequest("POST", "/api/alerts", strings.NewReader("<alert/>"))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+	req.Header.Set("Content-Type", "application/xml")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateAlertsHandler{
+		UserRepo: userRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnsupportedMediaType, rr.Code)
+	requireProblem(t, rr, "unsupported_media_type", "Unsupported content type, expected JSON, form data or plain text.")
+}
+
+func multipartAlertWithAttachment(data string, fields string) string {
+	return "--XYZ\r\n" +
+		"Content-Disposition: form-data; name=\"subject\"\r\n\r\n" +
+		"Backup failed\r\n" +
+		"--XYZ\r\n" +
+		"Content-Disposition: form-data; name=\"message\"\r\n\r\n" +
+		"See log\r\n" +
+		fields +
+		"--XYZ\r\n" +
+		"Content-Disposition: form-data; name=\"attachment\"; filename=\"/var/log/backup.log\"\r\n" +
+		"Content-Type: text/plain\r\n\r\n" +
+		data + "\r\n" +
+		"--XYZ--\r\n"
+}
+
+func TestPOSTAlerts_ShouldPassAttachmentsToNotifier(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().FindAlerts(gomock.Any(), uint(1), gomock.Any()).Return([]easyalert.Alert{{AttachmentSize: 100}}, nil)
+	alertRepo.EXPECT().CreateAlert(gomock.Any()).DoAndReturn(func(alert easyalert.Alert) (easyalert.Alert, error) {
+		require.Equal(t, int64(20), alert.AttachmentSize)
+		return alert, nil
+	})
+
+	notifier := mocks.NewMockNotifier(mockCtrl)
+	notifier.EXPECT().Notify(gomock.Any(), gomock.Any()).Do(func(user easyalert.User, n easyalert.Notification) {
+		require.Equal(t, "See log", n.Message)
+		require.Equal(t, []easyalert.Attachment{
+			{Filename: "backup.log", ContentType: "text/plain", Data: []byte("rsync error: code 12")},
+		}, n.Attachments)
+	})
+
+	req, err := http.NewRequest("POST", "/api/alerts", strings.NewReader(multipartAlertWithAttachment("rsync error: code 12", "")))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+	req.Header.Set("Content-Type", "multipart/form-data; boundary=XYZ")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateAlertsHandler{
+		UserRepo:  userRepo,
+		AlertRepo: alertRepo,
+		Notifier:  notifier,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusCreated, rr.Code)
+}
+
+func TestPOSTAlerts_ShouldReturnErrorIfAttachmentsAreGivenForScheduledAlert(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	delay := "--XYZ\r\n" +
+		"Content-Disposition: form-data; name=\"delay\"\r\n\r\n" +
+		"2h\r\n"
+
+	req, err := http.NewRequest("POST", "/api/alerts", strings.NewReader(multipartAlertWithAttachment("rsync error: code 12", delay)))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+	req.Header.Set("Content-Type", "multipart/form-data; boundary=XYZ")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateAlertsHandler{
+		UserRepo: userRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	requireProblem(t, rr, "attachments_not_allowed", "Attachments can not be sent with scheduled alerts.")
+}
+
+func TestPOSTAlerts_ShouldReturnErrorIfAttachmentsAreTooLarge(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	data := strings.Repeat("a", 10<<20+1)
+
+	req, err := http.NewRequest("POST", "/api/alerts", strings.NewReader(multipartAlertWithAttachment(data, "")))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+	req.Header.Set("Content-Type", "multipart/form-data; boundary=XYZ")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateAlertsHandler{
+		UserRepo: userRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+	requireProblem(t, rr, "attachments_too_large", "Attachments must not be larger than 10 MB in total.")
+}
+
+func TestPOSTAlerts_ShouldReturnErrorIfRequestBodyIsTooLarge(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	data := strings.Repeat("a", 12<<20)
+
+	req, err := http.NewRequest("POST", "/api/alerts", strings.NewReader(multipartAlertWithAttachment(data, "")))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+	req.Header.Set("Content-Type", "multipart/form-data; boundary=XYZ")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateAlertsHandler{
+		UserRepo: userRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+	requireProblem(t, rr, "request_too_large", "Request body too large.")
+}
+
+func TestPOSTAlerts_ShouldReturnErrorIfUserExceedsAttachmentLimit(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().FindAlerts(gomock.Any(), uint(1), gomock.Any()).Return([]easyalert.Alert{
+		{AttachmentSize: 60 << 20},
+		{AttachmentSize: 40 << 20},
+	}, nil)
+
+	req, err := http.NewRequest("POST", "/api/alerts", strings.NewReader(multipartAlertWithAttachment("rsync error: code 12", "")))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+	req.Header.Set("Content-Type", "multipart/form-data; boundary=XYZ")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateAlertsHandler{
+		UserRepo:  userRepo,
+		AlertRepo: alertRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusTooManyRequests, rr.Code)
+	requireProblem(t, rr, "attachment_quota_exceeded", "Attachment limit of 100 MB per day exceeded.")
+}
+
+func TestPOSTAlerts_ShouldReturnErrorIfFormatIsInvalid(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	payload := `{"subject": "Hi", "message": "Hi", "format": "html"}`
+
+	req, err := http.NewRequest("POST", "/api/alerts", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateAlertsHandler{
+		UserRepo: userRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	requireProblem(t, rr, "invalid_format", "Invalid format, expected text or markdown.")
+}
+
+func TestPOSTAlerts_ShouldPassFormatToNotifier(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().CreateAlert(gomock.Any()).DoAndReturn(func(alert easyalert.Alert) (easyalert.Alert, error) {
+		return alert, nil
+	})
+
+	notifier := mocks.NewMockNotifier(mockCtrl)
+	notifier.EXPECT().Notify(gomock.Any(), gomock.Any()).Do(func(user easyalert.User, n easyalert.Notification) {
+		require.Equal(t, easyalert.MessageFormatMarkdown, n.Format)
+	})
+
+	payload := `{"subject": "Hi", "message": "**Hi**", "format": "markdown"}`
+
+	req, err := http.NewRequest("POST", "/api/alerts", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateAlertsHandler{
+		UserRepo:  userRepo,
+		AlertRepo: alertRepo,
+		Notifier:  notifier,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusCreated, rr.Code)
+}
+
+func TestPreviewAlert_ShouldReturnErrorIfFormatIsNotMarkdown(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	payload := `{"subject": "Hi", "message": "Hi"}`
+
+	req, err := http.NewRequest("POST", "/api/alerts/preview", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.PreviewAlertHandler{
+		UserRepo: userRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	requireProblem(t, rr, "unsupported_format", "Only markdown messages can be previewed.")
+}
+
+func TestPreviewAlert_ShouldReturnRenderedHTML(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	payload := `{"subject": "Backup failed", "message": "Disk **full** <script>alert(1)</script>", "format": "markdown"}`
+
+	req, err := http.NewRequest("POST", "/api/alerts/preview", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.PreviewAlertHandler{
+		UserRepo: userRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	expectedJsonResp := "{\n" +
+		"  \"subject\": \"Backup failed\",\n" +
+		"  \"html\": \"\\u003c!DOCTYPE html\\u003e\\n\\u003chtml\\u003e\\n\\u003chead\\u003e\\n\\u003cmeta charset=\\\"UTF-8\\\"\\u003e\\n" +
+		"\\u003ctitle\\u003eBackup failed\\u003c/title\\u003e\\n\\u003c/head\\u003e\\n\\u003cbody\\u003e\\n" +
+		"\\u003cp\\u003eDisk \\u003cstrong\\u003efull\\u003c/strong\\u003e alert(1)\\u003c/p\\u003e\\n" +
+		"\\u003c/body\\u003e\\n\\u003c/html\\u003e\\n\"\n" +
+		"}"
+
+	require.Equal(t, expectedJsonResp, rr.Body.String())
+}
+
+func TestPOSTAlerts_ShouldRenderTemplate(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	templateRepo := mocks.NewMockTemplateRepository(mockCtrl)
+	templateRepo.EXPECT().FindTemplate("WHERE user_id = $1 AND name = $2", uint(1), "backup").Return(easyalert.Template{
+		Subject: "Backup of {{.host}} failed",
+		Body:    "Exit code **{{.code}}**",
+		Format:  "markdown",
+	}, nil)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().CreateAlert(gomock.Any()).DoAndReturn(func(alert easyalert.Alert) (easyalert.Alert, error) {
+		require.Equal(t, "Backup of db1 failed", alert.Subject)
+		return alert, nil
+	})
+
+	payload := "template=backup&var=host:db1&var=code:2"
+
+	req, err := http.NewRequest("POST", "/api/alerts", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	rr := httptest.NewRecorder()
+	notifier := mocks.NewMockNotifier(mockCtrl)
+	notifier.EXPECT().Notify(gomock.Any(), gomock.Any()).Do(func(user easyalert.User, n easyalert.Notification) {
+		require.Equal(t, "Exit code **2**", n.Message)
+		require.Equal(t, "markdown", n.Format)
+	})
+
+	handler := api.CreateAlertsHandler{
+		Notifier:     notifier,
+		UserRepo:     userRepo,
+		AlertRepo:    alertRepo,
+		TemplateRepo: templateRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusCreated, rr.Code)
+}
+
+func TestPOSTAlerts_ShouldReturnErrorIfTemplateVariablesAreMissing(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	templateRepo := mocks.NewMockTemplateRepository(mockCtrl)
+	templateRepo.EXPECT().FindTemplate(gomock.Any(), gomock.Any(), gomock.Any()).Return(easyalert.Template{
+		Subject: "Backup of {{.host}} failed",
+		Body:    "{{.log}} ({{.code}})",
+	}, nil)
+
+	payload := `{"template": "backup", "vars": {"host": "db1"}}`
+
+	req, err := http.NewRequest("POST", "/api/alerts", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateAlertsHandler{
+		UserRepo:     userRepo,
+		TemplateRepo: templateRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	p := requireProblem(t, rr, "missing_variables", "Missing template variables: code, log.")
+	require.Len(t, p.Errors, 2)
+	require.Equal(t, "vars.code", p.Errors[0].Field)
+	require.Equal(t, "missing_variable", p.Errors[0].Code)
+	require.Equal(t, "vars.log", p.Errors[1].Field)
+}
+
+func TestPOSTAlerts_ShouldReturnErrorIfTemplateDoesNotExist(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	templateRepo := mocks.NewMockTemplateRepository(mockCtrl)
+	templateRepo.EXPECT().FindTemplate(gomock.Any(), gomock.Any(), gomock.Any()).Return(easyalert.Template{}, easyalert.ErrRecordDoesNotExist)
+
+	req, err := http.NewRequest("POST", "/api/alerts?template=backup", strings.NewReader(""))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateAlertsHandler{
+		UserRepo:     userRepo,
+		TemplateRepo: templateRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	requireProblem(t, rr, "unknown_template", "Template not found.")
+}
+
+func TestPOSTAlerts_ShouldReturnErrorIfSubjectIsGivenWithTemplate(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	payload := `{"template": "backup", "subject": "Hi"}`
+
+	req, err := http.NewRequest("POST", "/api/alerts", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateAlertsHandler{
+		UserRepo: userRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	requireProblem(t, rr, "conflicting_fields", "Subject, message and format can not be given together with a template.")
 }
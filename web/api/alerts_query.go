@@ -0,0 +1,215 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bakku/easyalert"
+)
+
+const (
+	defaultAlertsLimit = 50
+	maxAlertsLimit     = 100
+)
+
+// alertsCursor points to the position of an alert inside the listing,
+// which is sorted by created_at and id. It is handed to clients base64
+// encoded so they treat it as opaque.
+type alertsCursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uint      `json:"id"`
+	// Backwards is set for cursors pointing to the previous page
+	Backwards bool `json:"b,omitempty"`
+}
+
+func (c alertsCursor) encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeAlertsCursor(s string) (alertsCursor, error) {
+	var c alertsCursor
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, err
+	}
+
+	err = json.Unmarshal(b, &c)
+
+	return c, err
+}
+
+// alertsQuery is the query used to list one page of the alerts of a user.
+type alertsQuery struct {
+	sql    string
+	params []interface{}
+	limit  int
+	cursor *alertsCursor
+}
+
+// newAlertsQuery builds the query used to list the alerts of a user from
+// the filters, cursor and limit given as query parameters. Alerts are
+// always sorted by newest first.
+func newAlertsQuery(userID uint, values url.Values) (alertsQuery, error) {
+	q := alertsQuery{
+		sql:    "WHERE user_id = $1",
+		params: []interface{}{userID},
+		limit:  defaultAlertsLimit,
+	}
+
+	if values.Get("status") != "" {
+		status, err := easyalert.ParseStatus(values.Get("status"))
+		if err != nil {
+			return q, invalidField("status", "invalid_status", "Invalid status.")
+		}
+
+		q.where("status = $%d", status)
+	}
+
+	if values.Get("severity") != "" {
+		severity, err := easyalert.ParseSeverity(values.Get("severity"))
+		if err != nil {
+			return q, invalidField("severity", "invalid_severity", "Invalid severity.")
+		}
+
+		q.where("severity = $%d", severity)
+	}
+
+	if len(values["label"]) > 0 {
+		labels := make(map[string]string)
+
+		// labels are given as key:value, e.g. ?label=env:prod&label=host:db1
+		for _, label := range values["label"] {
+			parts := strings.SplitN(label, ":", 2)
+			if len(parts) != 2 || parts[0] == "" {
+				return q, invalidField("label", "invalid_label", "Invalid label filter.")
+			}
+
+			labels[parts[0]] = parts[1]
+		}
+
+		labelsJSON, err := json.Marshal(labels)
+		if err != nil {
+			return q, err
+		}
+
+		q.where("labels @> $%d", string(labelsJSON))
+	}
+
+	if values.Get("since") != "" {
+		since, err := time.Parse(time.RFC3339, values.Get("since"))
+		if err != nil {
+			return q, invalidField("since", "invalid_timestamp", "Invalid since, expected RFC 3339 timestamp.")
+		}
+
+		q.where("created_at >= $%d", since.UTC())
+	}
+
+	if values.Get("until") != "" {
+		until, err := time.Parse(time.RFC3339, values.Get("until"))
+		if err != nil {
+			return q, invalidField("until", "invalid_timestamp", "Invalid until, expected RFC 3339 timestamp.")
+		}
+
+		q.where("created_at < $%d", until.UTC())
+	}
+
+	if values.Get("q") != "" {
+		q.where("strpos(lower(subject), lower($%d)) > 0", values.Get("q"))
+	}
+
+	if values.Get("limit") != "" {
+		limit, err := strconv.Atoi(values.Get("limit"))
+		if err != nil || limit < 1 || limit > maxAlertsLimit {
+			return q, invalidField("limit", "invalid_limit", fmt.Sprintf("Limit must be between 1 and %d.", maxAlertsLimit))
+		}
+
+		q.limit = limit
+	}
+
+	order := "DESC"
+
+	if values.Get("cursor") != "" {
+		cursor, err := decodeAlertsCursor(values.Get("cursor"))
+		if err != nil {
+			return q, invalidField("cursor", "invalid_cursor", "Invalid cursor.")
+		}
+
+		q.cursor = &cursor
+		q.params = append(q.params, cursor.CreatedAt, cursor.ID)
+
+		comparison := "<"
+		if cursor.Backwards {
+			// the previous page is fetched in reverse and flipped afterwards
+			comparison = ">"
+			order = "ASC"
+		}
+
+		q.sql += fmt.Sprintf(" AND (created_at, id) %s ($%d, $%d)", comparison, len(q.params)-1, len(q.params))
+	}
+
+	// one more alert than requested is fetched to know whether another page exists
+	q.params = append(q.params, q.limit+1)
+	q.sql += fmt.Sprintf(" ORDER BY created_at %s, id %s LIMIT $%d", order, order, len(q.params))
+
+	return q, nil
+}
+
+// where adds a condition with a single parameter. The placeholder inside
+// the condition is given as $%d.
+func (q *alertsQuery) where(condition string, param interface{}) {
+	q.params = append(q.params, param)
+	q.sql += " AND " + fmt.Sprintf(condition, len(q.params))
+}
+
+// paginate trims the alerts fetched with the query to one page and returns
+// them in listing order together with the value of the Link header.
+func (q alertsQuery) paginate(alerts []easyalert.Alert, u *url.URL) ([]easyalert.Alert, string) {
+	hasMore := len(alerts) > q.limit
+	if hasMore {
+		alerts = alerts[:q.limit]
+	}
+
+	backwards := q.cursor != nil && q.cursor.Backwards
+
+	if backwards {
+		for i, j := 0, len(alerts)-1; i < j; i, j = i+1, j-1 {
+			alerts[i], alerts[j] = alerts[j], alerts[i]
+		}
+	}
+
+	if len(alerts) == 0 {
+		return alerts, ""
+	}
+
+	first := alerts[0]
+	last := alerts[len(alerts)-1]
+
+	var links []string
+
+	if (!backwards && hasMore) || backwards {
+		next := alertsCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, cursorURL(u, next)))
+	}
+
+	if (backwards && hasMore) || (!backwards && q.cursor != nil) {
+		prev := alertsCursor{CreatedAt: first.CreatedAt, ID: first.ID, Backwards: true}
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, cursorURL(u, prev)))
+	}
+
+	return alerts, strings.Join(links, ", ")
+}
+
+// cursorURL returns the given URL with its cursor replaced.
+func cursorURL(u *url.URL, cursor alertsCursor) string {
+	values := u.Query()
+	values.Set("cursor", cursor.encode())
+
+	return u.Path + "?" + values.Encode()
+}
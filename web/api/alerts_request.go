@@ -0,0 +1,254 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/bakku/easyalert"
+)
+
+const (
+	// maxAttachmentSize is the size all attachments of an alert may have in total.
+	maxAttachmentSize = 10 << 20
+
+	// maxAlertRequestSize leaves room for the other fields next to the attachments.
+	maxAlertRequestSize = maxAttachmentSize + 1<<20
+)
+
+var (
+	errUnsupportedContentType = apiError{
+		Status:  http.StatusUnsupportedMediaType,
+		Code:    "unsupported_media_type",
+		Message: "Unsupported content type, expected JSON, form data or plain text.",
+	}
+
+	errTemplateLookup = apiError{
+		Status:  http.StatusInternalServerError,
+		Code:    "internal_error",
+		Message: "could not fetch template",
+	}
+)
+
+// decodeAlertRequest reads the alert from the request body depending on its
+// Content-Type. Requests without a body and Content-Type are read from the
+// query parameters so that alerts can be sent with a plain URL.
+// Attachments can only be given as multipart/form-data files named attachment.
+func decodeAlertRequest(r *http.Request, body []byte) (createAlertRequestBody, []easyalert.Attachment, error) {
+	contentType := r.Header.Get("Content-Type")
+
+	if contentType == "" {
+		if len(body) == 0 {
+			alertBody, err := alertRequestFromValues(r.URL.Query())
+			return alertBody, nil, err
+		}
+
+		contentType = "application/json"
+	}
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return createAlertRequestBody{}, nil, errUnsupportedContentType
+	}
+
+	switch mediaType {
+	case "application/json":
+		var alertBody createAlertRequestBody
+
+		err = json.Unmarshal(body, &alertBody)
+		if err != nil {
+			return createAlertRequestBody{}, nil, invalidRequest("invalid_json", "invalid json")
+		}
+
+		return alertBody, nil, nil
+	case "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return createAlertRequestBody{}, nil, invalidRequest("invalid_form_data", "invalid form data")
+		}
+
+		alertBody, err := alertRequestFromValues(values)
+		return alertBody, nil, err
+	case "multipart/form-data":
+		// the whole body fits into memory so attachments are never written to disk
+		form, err := multipart.NewReader(bytes.NewReader(body), params["boundary"]).ReadForm(maxAlertRequestSize)
+		if err != nil {
+			return createAlertRequestBody{}, nil, invalidRequest("invalid_form_data", "invalid form data")
+		}
+		defer form.RemoveAll()
+
+		alertBody, err := alertRequestFromValues(form.Value)
+		if err != nil {
+			return createAlertRequestBody{}, nil, err
+		}
+
+		attachments, err := readAttachments(form.File["attachment"])
+		if err != nil {
+			return createAlertRequestBody{}, nil, invalidRequest("invalid_form_data", "invalid form data")
+		}
+
+		return alertBody, attachments, nil
+	case "text/plain":
+		alertBody, err := alertRequestFromValues(r.URL.Query())
+		if err != nil {
+			return createAlertRequestBody{}, nil, err
+		}
+
+		// the first line is the subject, everything after it the message
+		lines := strings.SplitN(string(body), "\n", 2)
+
+		alertBody.Subject = strings.TrimSpace(lines[0])
+		alertBody.Message = ""
+
+		if len(lines) == 2 {
+			alertBody.Message = strings.Trim(lines[1], "\r\n")
+		}
+
+		return alertBody, nil, nil
+	}
+
+	return createAlertRequestBody{}, nil, errUnsupportedContentType
+}
+
+// readAttachments reads the uploaded files into memory.
+func readAttachments(files []*multipart.FileHeader) ([]easyalert.Attachment, error) {
+	var attachments []easyalert.Attachment
+
+	for _, fh := range files {
+		f, err := fh.Open()
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := ioutil.ReadAll(f)
+		f.Close()
+
+		if err != nil {
+			return nil, err
+		}
+
+		contentType := fh.Header.Get("Content-Type")
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+
+		attachments = append(attachments, easyalert.Attachment{
+			Filename:    filepath.Base(fh.Filename),
+			ContentType: contentType,
+			Data:        data,
+		})
+	}
+
+	return attachments, nil
+}
+
+// alertRequestFromValues reads an alert from form values or query parameters.
+// Labels are given as repeated label parameters in the form key:value.
+func alertRequestFromValues(values map[string][]string) (createAlertRequestBody, error) {
+	get := func(key string) string {
+		if len(values[key]) == 0 {
+			return ""
+		}
+
+		return values[key][0]
+	}
+
+	alertBody := createAlertRequestBody{
+		Subject:     get("subject"),
+		Message:     get("message"),
+		Format:      get("format"),
+		DedupKey:    get("dedup_key"),
+		DedupWindow: get("dedup_window"),
+		Severity:    get("severity"),
+		SendAt:      get("send_at"),
+		Delay:       get("delay"),
+		Template:    get("template"),
+	}
+
+	for _, label := range values["label"] {
+		parts := strings.SplitN(label, ":", 2)
+		if len(parts) != 2 {
+			return createAlertRequestBody{}, invalidField("label", "invalid_label", "Labels must be given as key:value.")
+		}
+
+		if alertBody.Labels == nil {
+			alertBody.Labels = map[string]string{}
+		}
+
+		alertBody.Labels[parts[0]] = parts[1]
+	}
+
+	for _, v := range values["var"] {
+		parts := strings.SplitN(v, ":", 2)
+		if len(parts) != 2 {
+			return createAlertRequestBody{}, invalidField("var", "invalid_variable", "Variables must be given as key:value.")
+		}
+
+		if alertBody.Vars == nil {
+			alertBody.Vars = map[string]string{}
+		}
+
+		alertBody.Vars[parts[0]] = parts[1]
+	}
+
+	return alertBody, nil
+}
+
+// missingVariables lists every missing variable as invalid field.
+func missingVariables(err easyalert.MissingVariablesError) apiError {
+	e := invalidRequest("missing_variables", err.Error())
+
+	for _, name := range err.Names {
+		e.Fields = append(e.Fields, fieldError{"vars." + name, "missing_variable", "Variable " + name + " is missing."})
+	}
+
+	return e
+}
+
+// applyTemplate renders the template referenced by the request body into its
+// subject, message and format. Bodies without a template are left untouched.
+// If the template could not be fetched errTemplateLookup is returned.
+func (body *createAlertRequestBody) applyTemplate(repo easyalert.TemplateRepository, user easyalert.User) error {
+	if body.Template == "" {
+		if len(body.Vars) > 0 {
+			return invalidField("vars", "missing_template", "Variables given without template.")
+		}
+
+		return nil
+	}
+
+	if body.Subject != "" || body.Message != "" || body.Format != "" {
+		return invalidRequest("conflicting_fields", "Subject, message and format can not be given together with a template.")
+	}
+
+	tmpl, err := repo.FindTemplate("WHERE user_id = $1 AND name = $2", user.ID, body.Template)
+	if err != nil {
+		if err == easyalert.ErrRecordDoesNotExist {
+			return invalidField("template", "unknown_template", "Template not found.")
+		}
+
+		return errTemplateLookup
+	}
+
+	subject, message, err := tmpl.Render(body.Vars)
+	if err != nil {
+		if missing, ok := err.(easyalert.MissingVariablesError); ok {
+			return missingVariables(missing)
+		}
+
+		return invalidField("template", "template_render_failed", fmt.Sprintf("Could not render template: %v.", err))
+	}
+
+	body.Subject = subject
+	body.Message = message
+	body.Format = tmpl.Format
+
+	return nil
+}
@@ -29,7 +29,7 @@ type authResponseBody struct {
 func (h AuthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	bytes, err := ioutil.ReadAll(r.Body)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "could not read http body")
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not read http body")
 		return
 	}
 
@@ -37,48 +37,34 @@ func (h AuthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	err = json.Unmarshal(bytes, &authBody)
 	if err != nil {
-		writeError(w, http.StatusUnprocessableEntity, "invalid json")
+		writeError(w, http.StatusUnprocessableEntity, "invalid_json", "invalid json")
 		return
 	}
 
 	if authBody.Email == "" || authBody.Password == "" {
-		writeError(w, http.StatusBadRequest, "Empty email or password.")
+		writeError(w, http.StatusBadRequest, "missing_credentials", "Empty email or password.")
 		return
 	}
 
 	user, err := h.UserRepo.FindUser("WHERE email = $1", authBody.Email)
 	if err != nil {
 		if err == easyalert.ErrRecordDoesNotExist {
-			writeError(w, http.StatusUnauthorized, "Invalid credentials.")
+			writeError(w, http.StatusUnauthorized, "invalid_credentials", "Invalid credentials.")
 			return
 		}
 
-		writeError(w, http.StatusInternalServerError, "an unknown error occured")
+		writeError(w, http.StatusInternalServerError, "internal_error", "an unknown error occured")
 		return
 	}
 
 	if !user.ValidPassword(authBody.Password) {
-		writeError(w, http.StatusUnauthorized, "Invalid credentials.")
+		writeError(w, http.StatusUnauthorized, "invalid_credentials", "Invalid credentials.")
 		return
 	}
 
 	responseBody := authResponseBody{user.Token}
 
-	responseBodyBytes, err := json.Marshal(responseBody)
-	if err != nil {
-		writeError(w, http.StatusInternalServerError, "could not marshal response body")
-		return
-	}
-
-	body, err := prettifyJSON(string(responseBodyBytes))
-	if err != nil {
-		writeError(w, http.StatusInternalServerError, "could not prettify json response")
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(body))
+	writeJSON(w, http.StatusOK, responseBody)
 }
 
 // AuthRefreshHandler requires the token of the user in the Authorization
@@ -89,26 +75,14 @@ type AuthRefreshHandler struct {
 
 // ServeHTTP handles the HTTP request.
 func (h AuthRefreshHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	token, ok := getUserToken(r)
+	user, ok := authorizeUser(w, r, h.UserRepo)
 	if !ok {
-		writeError(w, http.StatusUnauthorized, "Missing or invalid Authorization header.")
-		return
-	}
-
-	user, err := h.UserRepo.FindUser("WHERE token = $1", token)
-	if err != nil {
-		if err == easyalert.ErrRecordDoesNotExist {
-			writeError(w, http.StatusUnauthorized, "Invalid token.")
-			return
-		}
-
-		writeError(w, http.StatusInternalServerError, "an unknown error occured")
 		return
 	}
 
 	newToken, err := random.String(easyalert.UserTokenLength)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "could not generate token")
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not generate token")
 		return
 	}
 
@@ -116,26 +90,12 @@ func (h AuthRefreshHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	user, err = h.UserRepo.UpdateUser(user)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "could not update token")
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not update token")
 		return
 	}
 
 	var responseBody authResponseBody
 	responseBody.Token = user.Token
 
-	responseBodyBytes, err := json.Marshal(responseBody)
-	if err != nil {
-		writeError(w, http.StatusInternalServerError, "could not marshal response body")
-		return
-	}
-
-	body, err := prettifyJSON(string(responseBodyBytes))
-	if err != nil {
-		writeError(w, http.StatusInternalServerError, "could not prettify json response")
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(body))
+	writeJSON(w, http.StatusOK, responseBody)
 }
@@ -25,8 +25,7 @@ func TestPOSTAuth_ShouldReturnErrorIfUserWasGivenIncorrectly(t *testing.T) {
 	handler.ServeHTTP(rr, req)
 
 	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
-	require.Equal(t, "application/json; charset=UTF-8", rr.Header().Get("Content-Type"))
-	require.Equal(t, "{\n  \"error\": \"invalid json\"\n}", rr.Body.String())
+	requireProblem(t, rr, "invalid_json", "invalid json")
 }
 
 func TestPOSTAuth_ShouldReturnErrorIfEmailOrPasswordAreEmpty(t *testing.T) {
@@ -46,8 +45,7 @@ func TestPOSTAuth_ShouldReturnErrorIfEmailOrPasswordAreEmpty(t *testing.T) {
 	handler.ServeHTTP(rr, req)
 
 	require.Equal(t, http.StatusBadRequest, rr.Code)
-	require.Equal(t, "application/json; charset=UTF-8", rr.Header().Get("Content-Type"))
-	require.Equal(t, "{\n  \"error\": \"Empty email or password.\"\n}", rr.Body.String())
+	requireProblem(t, rr, "missing_credentials", "Empty email or password.")
 }
 
 func TestPOSTAuth_ShouldReturnErrorIfUserCouldNotBeFound(t *testing.T) {
@@ -74,8 +72,7 @@ func TestPOSTAuth_ShouldReturnErrorIfUserCouldNotBeFound(t *testing.T) {
 	handler.ServeHTTP(rr, req)
 
 	require.Equal(t, http.StatusUnauthorized, rr.Code)
-	require.Equal(t, "application/json; charset=UTF-8", rr.Header().Get("Content-Type"))
-	require.Equal(t, "{\n  \"error\": \"Invalid credentials.\"\n}", rr.Body.String())
+	requireProblem(t, rr, "invalid_credentials", "Invalid credentials.")
 }
 
 func TestPOSTAuth_ShouldReturnErrorIfPasswordWasIncorrect(t *testing.T) {
@@ -111,8 +108,7 @@ func TestPOSTAuth_ShouldReturnErrorIfPasswordWasIncorrect(t *testing.T) {
 	handler.ServeHTTP(rr, req)
 
 	require.Equal(t, http.StatusUnauthorized, rr.Code)
-	require.Equal(t, "application/json; charset=UTF-8", rr.Header().Get("Content-Type"))
-	require.Equal(t, "{\n  \"error\": \"Invalid credentials.\"\n}", rr.Body.String())
+	requireProblem(t, rr, "invalid_credentials", "Invalid credentials.")
 }
 
 func TestPOSTAuth_ShouldReturnTokenIfPasswordWasCorrect(t *testing.T) {
@@ -161,8 +157,7 @@ func TestPUTAuthRefresh_ShouldReturnUnauthorizedIfAuthorizationHeaderIsNotPresen
 	handler.ServeHTTP(rr, req)
 
 	require.Equal(t, http.StatusUnauthorized, rr.Code)
-	require.Equal(t, "application/json; charset=UTF-8", rr.Header().Get("Content-Type"))
-	require.Equal(t, "{\n  \"error\": \"Missing or invalid Authorization header.\"\n}", rr.Body.String())
+	requireProblem(t, rr, "missing_token", "Missing or invalid Authorization header.")
 }
 
 func TestPUTAuthRefresh_ShouldReturnUnauthorizedIfAuthorizationHeaderIsInvalid(t *testing.T) {
@@ -176,8 +171,7 @@ func TestPUTAuthRefresh_ShouldReturnUnauthorizedIfAuthorizationHeaderIsInvalid(t
 	handler.ServeHTTP(rr, req)
 
 	require.Equal(t, http.StatusUnauthorized, rr.Code)
-	require.Equal(t, "application/json; charset=UTF-8", rr.Header().Get("Content-Type"))
-	require.Equal(t, "{\n  \"error\": \"Missing or invalid Authorization header.\"\n}", rr.Body.String())
+	requireProblem(t, rr, "missing_token", "Missing or invalid Authorization header.")
 }
 
 func TestPUTAuthRefresh_ShouldReturnUnauthorizedIfNoUserExistsForToken(t *testing.T) {
@@ -199,8 +193,7 @@ func TestPUTAuthRefresh_ShouldReturnUnauthorizedIfNoUserExistsForToken(t *testin
 	handler.ServeHTTP(rr, req)
 
 	require.Equal(t, http.StatusUnauthorized, rr.Code)
-	require.Equal(t, "application/json; charset=UTF-8", rr.Header().Get("Content-Type"))
-	require.Equal(t, "{\n  \"error\": \"Invalid token.\"\n}", rr.Body.String())
+	requireProblem(t, rr, "invalid_token", "Invalid token.")
 }
 
 func TestPUTAuthRefresh_ShouldRefreshTheToken(t *testing.T) {
@@ -0,0 +1,240 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/chat"
+)
+
+// The URL is never returned since it contains the credentials of the
+// webhook, only its host is returned to tell channels apart.
+type chatChannelResponseBody struct {
+	ID        uint   `json:"id"`
+	Name      string `json:"name"`
+	Kind      string `json:"kind"`
+	Host      string `json:"host"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+func convertChatChannelToResponseBody(channel easyalert.ChatChannel) chatChannelResponseBody {
+	body := chatChannelResponseBody{
+		ID:        channel.ID,
+		Name:      channel.Name,
+		Kind:      channel.Kind,
+		CreatedAt: channel.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: channel.UpdatedAt.Format(time.RFC3339),
+	}
+
+	if u, err := url.Parse(channel.URL); err == nil {
+		body.Host = u.Host
+	}
+
+	return body
+}
+
+type chatChannelRequestBody struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	URL  string `json:"url"`
+}
+
+// GetChatChannelsHandler should return all chat channels of the user.
+type GetChatChannelsHandler struct {
+	UserRepo    easyalert.UserRepository
+	ChannelRepo easyalert.ChatChannelRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h GetChatChannelsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	channels, err := h.ChannelRepo.FindChatChannels("WHERE user_id = $1 ORDER BY name", user.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not fetch chat channels")
+		return
+	}
+
+	responseBody := make([]chatChannelResponseBody, len(channels))
+	for i, channel := range channels {
+		responseBody[i] = convertChatChannelToResponseBody(channel)
+	}
+
+	writeJSON(w, http.StatusOK, responseBody)
+}
+
+// CreateChatChannelsHandler should accept a JSON object and create a chat channel from it.
+type CreateChatChannelsHandler struct {
+	UserRepo    easyalert.UserRepository
+	ChannelRepo easyalert.ChatChannelRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h CreateChatChannelsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	channel, ok := readChatChannel(w, r, h.ChannelRepo, user, easyalert.ChatChannel{UserID: user.ID})
+	if !ok {
+		return
+	}
+
+	channel, err := h.ChannelRepo.CreateChatChannel(channel)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not create chat channel")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, convertChatChannelToResponseBody(channel))
+}
+
+// GetChatChannelHandler should return a single chat channel of the user.
+type GetChatChannelHandler struct {
+	UserRepo    easyalert.UserRepository
+	ChannelRepo easyalert.ChatChannelRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h GetChatChannelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	channel, ok := findUserChatChannel(w, r, h.ChannelRepo, user)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, convertChatChannelToResponseBody(channel))
+}
+
+// UpdateChatChannelHandler should accept a JSON object and replace a chat channel of the user with it.
+type UpdateChatChannelHandler struct {
+	UserRepo    easyalert.UserRepository
+	ChannelRepo easyalert.ChatChannelRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h UpdateChatChannelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	channel, ok := findUserChatChannel(w, r, h.ChannelRepo, user)
+	if !ok {
+		return
+	}
+
+	channel, ok = readChatChannel(w, r, h.ChannelRepo, user, channel)
+	if !ok {
+		return
+	}
+
+	channel, err := h.ChannelRepo.UpdateChatChannel(channel)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not update chat channel")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, convertChatChannelToResponseBody(channel))
+}
+
+// DeleteChatChannelHandler should delete a chat channel of the user.
+type DeleteChatChannelHandler struct {
+	UserRepo    easyalert.UserRepository
+	ChannelRepo easyalert.ChatChannelRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h DeleteChatChannelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	channel, ok := findUserChatChannel(w, r, h.ChannelRepo, user)
+	if !ok {
+		return
+	}
+
+	err := h.ChannelRepo.DeleteChatChannel(channel)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not delete chat channel")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// readChatChannel reads the chat channel from the request body into channel
+// and validates it. If the channel is invalid an error is written and false
+// is returned.
+func readChatChannel(w http.ResponseWriter, r *http.Request, repo easyalert.ChatChannelRepository, user easyalert.User, channel easyalert.ChatChannel) (easyalert.ChatChannel, bool) {
+	var body chatChannelRequestBody
+
+	if !readJSONBody(w, r, &body) {
+		return easyalert.ChatChannel{}, false
+	}
+
+	if body.Name == "" || body.Kind == "" || (body.URL == "" && channel.URL == "") {
+		writeError(w, http.StatusUnprocessableEntity, "missing_field", "Name, kind and URL must be given.")
+		return easyalert.ChatChannel{}, false
+	}
+
+	if !checkName(w, body.Name) {
+		return easyalert.ChatChannel{}, false
+	}
+
+	if !chat.ValidKind(body.Kind) {
+		writeAPIError(w, invalidField("kind", "invalid_kind", "Kind must be one of slack, discord, mattermost or teams."))
+		return easyalert.ChatChannel{}, false
+	}
+
+	// the URL is kept if it is omitted on update, since it is not returned
+	if body.URL != "" {
+		channelURL, ok := parseHTTPURL(body.URL)
+		if !ok {
+			writeAPIError(w, invalidField("url", "invalid_url", "URL must be an absolute http or https URL of a public host."))
+			return easyalert.ChatChannel{}, false
+		}
+
+		channel.URL = channelURL
+	}
+
+	channel.Name = body.Name
+	channel.Kind = body.Kind
+
+	ok := checkUniqueName(w, channel.ID, "A chat channel with this name already exists.", func() (uint, error) {
+		existing, err := repo.FindChatChannel("WHERE user_id = $1 AND name = $2", user.ID, channel.Name)
+		return existing.ID, err
+	})
+	if !ok {
+		return easyalert.ChatChannel{}, false
+	}
+
+	return channel, true
+}
+
+// findUserChatChannel returns the chat channel given by the id route variable
+// if it belongs to the user. Otherwise an error is written and false is
+// returned.
+func findUserChatChannel(w http.ResponseWriter, r *http.Request, repo easyalert.ChatChannelRepository, user easyalert.User) (easyalert.ChatChannel, bool) {
+	var channel easyalert.ChatChannel
+
+	found := findUserResource(w, r, "chat channel", func(id uint64) (err error) {
+		channel, err = repo.FindChatChannel("WHERE id = $1 AND user_id = $2", id, user.ID)
+		return err
+	})
+
+	return channel, found
+}
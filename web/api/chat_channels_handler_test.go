@@ -0,0 +1,148 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/mocks"
+	"github.com/bakku/easyalert/web/api"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPOSTChatChannels_ShouldReturnErrorIfKindIsUnknown(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	payload := `{"name": "ops", "kind": "irc", "url": "https://example.com/hook"}`
+
+	req, err := http.NewRequest("POST", "/api/chat-channels", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateChatChannelsHandler{UserRepo: userRepo}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+
+	p := requireProblem(t, rr, "invalid_kind", "Kind must be one of slack, discord, mattermost or teams.")
+	require.Equal(t, "kind", p.Errors[0].Field)
+}
+
+func TestPOSTChatChannels_ShouldCreateChatChannel(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	createdAt := time.Date(2019, 2, 6, 19, 0, 0, 0, time.UTC)
+
+	channelRepo := mocks.NewMockChatChannelRepository(mockCtrl)
+	channelRepo.EXPECT().FindChatChannel(gomock.Any(), gomock.Any()).Return(easyalert.ChatChannel{}, easyalert.ErrRecordDoesNotExist)
+	channelRepo.EXPECT().CreateChatChannel(easyalert.ChatChannel{
+		Name:   "ops",
+		Kind:   "discord",
+		URL:    "https://discord.com/api/webhooks/1/x",
+		UserID: 1,
+	}).DoAndReturn(func(channel easyalert.ChatChannel) (easyalert.ChatChannel, error) {
+		channel.ID = 2
+		channel.CreatedAt = createdAt
+		channel.UpdatedAt = createdAt
+		return channel, nil
+	})
+
+	payload := `{"name": "ops", "kind": "discord", "url": "https://discord.com/api/webhooks/1/x"}`
+
+	req, err := http.NewRequest("POST", "/api/chat-channels", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateChatChannelsHandler{UserRepo: userRepo, ChannelRepo: channelRepo}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusCreated, rr.Code)
+	requireMatchesSpec(t, loadOpenAPI(t), "POST", "/api/chat-channels", rr)
+
+	expectedJsonResp := "{\n" +
+		"  \"id\": 2,\n" +
+		"  \"name\": \"ops\",\n" +
+		"  \"kind\": \"discord\",\n" +
+		"  \"host\": \"discord.com\",\n" +
+		"  \"created_at\": \"2019-02-06T19:00:00Z\",\n" +
+		"  \"updated_at\": \"2019-02-06T19:00:00Z\"\n" +
+		"}"
+
+	require.Equal(t, expectedJsonResp, rr.Body.String())
+}
+
+func TestPOSTChatChannels_ShouldRequireURL(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	payload := `{"name": "ops", "kind": "slack"}`
+
+	req, err := http.NewRequest("POST", "/api/chat-channels", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateChatChannelsHandler{UserRepo: userRepo}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	requireProblem(t, rr, "missing_field", "Name, kind and URL must be given.")
+}
+
+func TestPUTChatChannel_ShouldKeepURLIfOmitted(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	channel := easyalert.ChatChannel{ID: 2, Name: "ops", Kind: "slack", URL: "https://hooks.slack.com/services/x", UserID: 1}
+
+	channelRepo := mocks.NewMockChatChannelRepository(mockCtrl)
+	channelRepo.EXPECT().FindChatChannel("WHERE id = $1 AND user_id = $2", uint64(2), uint(1)).Return(channel, nil)
+	channelRepo.EXPECT().FindChatChannel("WHERE user_id = $1 AND name = $2", uint(1), "alerts").Return(easyalert.ChatChannel{}, easyalert.ErrRecordDoesNotExist)
+	channelRepo.EXPECT().UpdateChatChannel(easyalert.ChatChannel{
+		ID:     2,
+		Name:   "alerts",
+		Kind:   "slack",
+		URL:    "https://hooks.slack.com/services/x",
+		UserID: 1,
+	}).DoAndReturn(func(channel easyalert.ChatChannel) (easyalert.ChatChannel, error) {
+		return channel, nil
+	})
+
+	req, err := http.NewRequest("PUT", "/api/chat-channels/2", strings.NewReader(`{"name": "alerts", "kind": "slack"}`))
+	require.Nil(t, err)
+	req = mux.SetURLVars(req, map[string]string{"id": "2"})
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.UpdateChatChannelHandler{UserRepo: userRepo, ChannelRepo: channelRepo}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	requireMatchesSpec(t, loadOpenAPI(t), "PUT", "/api/chat-channels/{id}", rr)
+	require.NotContains(t, rr.Body.String(), "services/x")
+}
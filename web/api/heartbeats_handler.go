@@ -0,0 +1,280 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/cron"
+)
+
+type heartbeatResponseBody struct {
+	ID            uint   `json:"id"`
+	Name          string `json:"name"`
+	Interval      string `json:"interval,omitempty"`
+	Schedule      string `json:"schedule,omitempty"`
+	Timezone      string `json:"timezone,omitempty"`
+	GracePeriod   string `json:"grace_period"`
+	Status        string `json:"status"`
+	LastPingAt    string `json:"last_ping_at,omitempty"`
+	NextPingDueAt string `json:"next_ping_due_at"`
+	DeadlineAt    string `json:"deadline_at"`
+	PingURL       string `json:"ping_url"`
+	CreatedAt     string `json:"created_at"`
+}
+
+func convertHeartbeatToResponseBody(heartbeat easyalert.Heartbeat) heartbeatResponseBody {
+	responseHeartbeat := heartbeatResponseBody{
+		ID:            heartbeat.ID,
+		Name:          heartbeat.Name,
+		GracePeriod:   heartbeat.GracePeriod.String(),
+		Status:        heartbeat.HumanStatus(),
+		NextPingDueAt: heartbeat.NextPingDueAt().Format(time.RFC3339),
+		DeadlineAt:    heartbeat.DeadlineAt().Format(time.RFC3339),
+		PingURL:       fmt.Sprintf("/api/heartbeats/%d/ping", heartbeat.ID),
+		CreatedAt:     heartbeat.CreatedAt.Format(time.RFC3339),
+	}
+
+	if heartbeat.Schedule != "" {
+		responseHeartbeat.Schedule = heartbeat.Schedule
+		responseHeartbeat.Timezone = heartbeat.Timezone
+	} else {
+		responseHeartbeat.Interval = heartbeat.Interval.String()
+	}
+
+	if heartbeat.LastPingAt != nil {
+		responseHeartbeat.LastPingAt = heartbeat.LastPingAt.Format(time.RFC3339)
+	}
+
+	return responseHeartbeat
+}
+
+// CreateHeartbeatsHandler should accept a JSON object and create a heartbeat from it.
+type CreateHeartbeatsHandler struct {
+	UserRepo      easyalert.UserRepository
+	HeartbeatRepo easyalert.HeartbeatRepository
+}
+
+type createHeartbeatRequestBody struct {
+	Name        string `json:"name"`
+	Interval    string `json:"interval"`
+	Schedule    string `json:"schedule"`
+	Timezone    string `json:"timezone"`
+	GracePeriod string `json:"grace_period"`
+}
+
+// ServeHTTP handles the HTTP request.
+func (h CreateHeartbeatsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	bytes, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not read http body")
+		return
+	}
+
+	var heartbeatBody createHeartbeatRequestBody
+
+	err = json.Unmarshal(bytes, &heartbeatBody)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "invalid_json", "invalid json")
+		return
+	}
+
+	if heartbeatBody.Name == "" || (heartbeatBody.Interval == "") == (heartbeatBody.Schedule == "") {
+		writeError(w, http.StatusUnprocessableEntity, "missing_field", "Name and either interval or schedule must be given.")
+		return
+	}
+
+	var interval time.Duration
+
+	if heartbeatBody.Interval != "" {
+		interval, err = time.ParseDuration(heartbeatBody.Interval)
+		if err != nil || interval < time.Minute {
+			writeAPIError(w, invalidField("interval", "invalid_interval", "Interval must be a duration of at least one minute."))
+			return
+		}
+	}
+
+	if heartbeatBody.Timezone != "" && heartbeatBody.Schedule == "" {
+		writeAPIError(w, invalidField("timezone", "conflicting_fields", "Timezone can only be given with a schedule."))
+		return
+	}
+
+	loc := time.UTC
+
+	if heartbeatBody.Timezone != "" {
+		loc, err = time.LoadLocation(heartbeatBody.Timezone)
+		if err != nil {
+			writeAPIError(w, invalidField("timezone", "invalid_timezone", "Invalid timezone."))
+			return
+		}
+	}
+
+	if heartbeatBody.Schedule != "" {
+		schedule, err := cron.Parse(heartbeatBody.Schedule)
+		if err != nil {
+			writeAPIError(w, invalidField("schedule", "invalid_schedule", "Invalid schedule: "+err.Error()+"."))
+			return
+		}
+
+		// a heartbeat which is never expected would never become late
+		if schedule.Next(time.Now().In(loc)).IsZero() {
+			writeAPIError(w, invalidField("schedule", "invalid_schedule", "Invalid schedule: it never fires."))
+			return
+		}
+	}
+
+	var gracePeriod time.Duration
+
+	if heartbeatBody.GracePeriod != "" {
+		gracePeriod, err = time.ParseDuration(heartbeatBody.GracePeriod)
+		if err != nil || gracePeriod < 0 {
+			writeAPIError(w, invalidField("grace_period", "invalid_grace_period", "Invalid grace period."))
+			return
+		}
+	}
+
+	heartbeat := easyalert.Heartbeat{
+		Name:        heartbeatBody.Name,
+		Interval:    interval,
+		Schedule:    heartbeatBody.Schedule,
+		Timezone:    heartbeatBody.Timezone,
+		GracePeriod: gracePeriod,
+		Status:      easyalert.HeartbeatStatusNew,
+		UserID:      user.ID,
+	}
+
+	heartbeat, err = h.HeartbeatRepo.CreateHeartbeat(heartbeat)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not create heartbeat")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, convertHeartbeatToResponseBody(heartbeat))
+}
+
+// GetHeartbeatsHandler should return all heartbeats of the user.
+type GetHeartbeatsHandler struct {
+	UserRepo      easyalert.UserRepository
+	HeartbeatRepo easyalert.HeartbeatRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h GetHeartbeatsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	heartbeats, err := h.HeartbeatRepo.FindHeartbeats("WHERE user_id = $1 ORDER BY id", user.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not fetch heartbeats")
+		return
+	}
+
+	responseBody := make([]heartbeatResponseBody, len(heartbeats))
+	for i, heartbeat := range heartbeats {
+		responseBody[i] = convertHeartbeatToResponseBody(heartbeat)
+	}
+
+	writeJSON(w, http.StatusOK, responseBody)
+}
+
+// DeleteHeartbeatHandler should delete a heartbeat of the user.
+type DeleteHeartbeatHandler struct {
+	UserRepo      easyalert.UserRepository
+	HeartbeatRepo easyalert.HeartbeatRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h DeleteHeartbeatHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	heartbeat, ok := findUserHeartbeat(w, r, h.HeartbeatRepo, user)
+	if !ok {
+		return
+	}
+
+	err := h.HeartbeatRepo.DeleteHeartbeat(heartbeat)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not delete heartbeat")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PingHeartbeatHandler records a ping of the job watched by the heartbeat.
+// If the heartbeat was down before, a recovery alert is created.
+type PingHeartbeatHandler struct {
+	UserRepo      easyalert.UserRepository
+	HeartbeatRepo easyalert.HeartbeatRepository
+	AlertRepo     easyalert.AlertRepository
+	Notifier      easyalert.Notifier
+}
+
+// ServeHTTP handles the HTTP request.
+func (h PingHeartbeatHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	heartbeat, ok := findUserHeartbeat(w, r, h.HeartbeatRepo, user)
+	if !ok {
+		return
+	}
+
+	recovered := heartbeat.Ping(time.Now().UTC())
+
+	heartbeat, err := h.HeartbeatRepo.UpdateHeartbeat(heartbeat)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not update heartbeat")
+		return
+	}
+
+	if recovered {
+		alert, err := h.AlertRepo.CreateAlert(heartbeat.RecoveredAlert())
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal_error", "could not create alert")
+			return
+		}
+
+		h.Notifier.Notify(user, easyalert.Notification{Alert: alert})
+	}
+
+	writeJSON(w, http.StatusOK, convertHeartbeatToResponseBody(heartbeat))
+}
+
+// findUserHeartbeat returns the heartbeat given by the id route variable if
+// it belongs to the user. Otherwise an error is written and false is returned.
+func findUserHeartbeat(w http.ResponseWriter, r *http.Request, repo easyalert.HeartbeatRepository, user easyalert.User) (easyalert.Heartbeat, bool) {
+	id, ok := getURLID(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "Heartbeat not found.")
+		return easyalert.Heartbeat{}, false
+	}
+
+	heartbeat, err := repo.FindHeartbeat("WHERE id = $1 AND user_id = $2", id, user.ID)
+	if err != nil {
+		if err == easyalert.ErrRecordDoesNotExist {
+			writeError(w, http.StatusNotFound, "not_found", "Heartbeat not found.")
+			return easyalert.Heartbeat{}, false
+		}
+
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not fetch heartbeat")
+		return easyalert.Heartbeat{}, false
+	}
+
+	return heartbeat, true
+}
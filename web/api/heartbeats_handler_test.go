@@ -0,0 +1,519 @@
+package api_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/mocks"
+	"github.com/bakku/easyalert/web/api"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPOSTHeartbeats_ShouldReturnUnauthorizedIfAuthorizationHeaderIsNotPresent(t *testing.T) {
+	req, err := http.NewRequest("POST", "/api/heartbeats", nil)
+	require.Nil(t, err)
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateHeartbeatsHandler{}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+	requireProblem(t, rr, "missing_token", "Missing or invalid Authorization header.")
+}
+
+func TestPOSTHeartbeats_ShouldReturnErrorIfIntervalIsTooShort(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{}, nil)
+
+	payload := `{
+		"name": "backup",
+		"interval": "10s"
+	}`
+
+	req, err := http.NewRequest("POST", "/api/heartbeats", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateHeartbeatsHandler{
+		UserRepo: userRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	requireProblem(t, rr, "invalid_interval", "Interval must be a duration of at least one minute.")
+}
+
+func TestPOSTHeartbeats_ShouldCreateHeartbeat(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	createdAt := time.Date(2019, 1, 7, 10, 0, 0, 0, time.UTC)
+
+	heartbeatRepo := mocks.NewMockHeartbeatRepository(mockCtrl)
+	heartbeatRepo.EXPECT().CreateHeartbeat(easyalert.Heartbeat{
+		Name:        "backup",
+		Interval:    24 * time.Hour,
+		GracePeriod: time.Hour,
+		UserID:      1,
+	}).DoAndReturn(func(h easyalert.Heartbeat) (easyalert.Heartbeat, error) {
+		h.ID = 3
+		h.CreatedAt = createdAt
+		return h, nil
+	})
+
+	payload := `{
+		"name": "backup",
+		"interval": "24h",
+		"grace_period": "1h"
+	}`
+
+	req, err := http.NewRequest("POST", "/api/heartbeats", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateHeartbeatsHandler{
+		UserRepo:      userRepo,
+		HeartbeatRepo: heartbeatRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusCreated, rr.Code)
+	require.Equal(t, "application/json; charset=UTF-8", rr.Header().Get("Content-Type"))
+
+	expectedJsonResp := "{\n" +
+		"  \"id\": 3,\n" +
+		"  \"name\": \"backup\",\n" +
+		"  \"interval\": \"24h0m0s\",\n" +
+		"  \"grace_period\": \"1h0m0s\",\n" +
+		"  \"status\": \"new\",\n" +
+		"  \"next_ping_due_at\": \"2019-01-08T10:00:00Z\",\n" +
+		"  \"deadline_at\": \"2019-01-08T11:00:00Z\",\n" +
+		"  \"ping_url\": \"/api/heartbeats/3/ping\",\n" +
+		"  \"created_at\": \"2019-01-07T10:00:00Z\"\n" +
+		"}"
+
+	require.Equal(t, expectedJsonResp, rr.Body.String())
+}
+
+func TestPOSTHeartbeats_ShouldReturnErrorIfIntervalAndScheduleAreGiven(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{}, nil)
+
+	payload := `{
+		"name": "backup",
+		"interval": "1h",
+		"schedule": "0 2 * * *"
+	}`
+
+	req, err := http.NewRequest("POST", "/api/heartbeats", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateHeartbeatsHandler{
+		UserRepo: userRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	requireProblem(t, rr, "missing_field", "Name and either interval or schedule must be given.")
+}
+
+func TestPOSTHeartbeats_ShouldReturnErrorIfScheduleIsInvalid(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{}, nil)
+
+	payload := `{
+		"name": "backup",
+		"schedule": "0 25 * * *"
+	}`
+
+	req, err := http.NewRequest("POST", "/api/heartbeats", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateHeartbeatsHandler{
+		UserRepo: userRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	requireProblem(t, rr, "invalid_schedule", "Invalid schedule: hour: value 25 out of range 0-23.")
+}
+
+func TestPOSTHeartbeats_ShouldReturnErrorIfScheduleNeverFires(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{}, nil)
+
+	payload := `{
+		"name": "backup",
+		"schedule": "0 0 30 2 *"
+	}`
+
+	req, err := http.NewRequest("POST", "/api/heartbeats", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateHeartbeatsHandler{
+		UserRepo: userRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+
+	p := requireProblem(t, rr, "invalid_schedule", "Invalid schedule: it never fires.")
+	require.Equal(t, "schedule", p.Errors[0].Field)
+}
+
+func TestPOSTHeartbeats_ShouldReturnErrorIfTimezoneIsGivenWithInterval(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{}, nil)
+
+	payload := `{
+		"name": "backup",
+		"interval": "1h",
+		"timezone": "Europe/Berlin"
+	}`
+
+	req, err := http.NewRequest("POST", "/api/heartbeats", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateHeartbeatsHandler{
+		UserRepo: userRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+
+	p := requireProblem(t, rr, "conflicting_fields", "Timezone can only be given with a schedule.")
+	require.Equal(t, "timezone", p.Errors[0].Field)
+}
+
+func TestPOSTHeartbeats_ShouldReturnErrorIfTimezoneIsInvalid(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{}, nil)
+
+	payload := `{
+		"name": "backup",
+		"schedule": "0 2 * * *",
+		"timezone": "Mars/Olympus"
+	}`
+
+	req, err := http.NewRequest("POST", "/api/heartbeats", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateHeartbeatsHandler{
+		UserRepo: userRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	requireProblem(t, rr, "invalid_timezone", "Invalid timezone.")
+}
+
+func TestPOSTHeartbeats_ShouldCreateHeartbeatWithSchedule(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	createdAt := time.Date(2019, 1, 10, 10, 0, 0, 0, time.UTC)
+
+	heartbeatRepo := mocks.NewMockHeartbeatRepository(mockCtrl)
+	heartbeatRepo.EXPECT().CreateHeartbeat(easyalert.Heartbeat{
+		Name:        "backup",
+		Schedule:    "0 2 * * *",
+		Timezone:    "Europe/Berlin",
+		GracePeriod: 30 * time.Minute,
+		UserID:      1,
+	}).DoAndReturn(func(h easyalert.Heartbeat) (easyalert.Heartbeat, error) {
+		h.ID = 4
+		h.CreatedAt = createdAt
+		return h, nil
+	})
+
+	payload := `{
+		"name": "backup",
+		"schedule": "0 2 * * *",
+		"timezone": "Europe/Berlin",
+		"grace_period": "30m"
+	}`
+
+	req, err := http.NewRequest("POST", "/api/heartbeats", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateHeartbeatsHandler{
+		UserRepo:      userRepo,
+		HeartbeatRepo: heartbeatRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusCreated, rr.Code)
+
+	expectedJsonResp := "{\n" +
+		"  \"id\": 4,\n" +
+		"  \"name\": \"backup\",\n" +
+		"  \"schedule\": \"0 2 * * *\",\n" +
+		"  \"timezone\": \"Europe/Berlin\",\n" +
+		"  \"grace_period\": \"30m0s\",\n" +
+		"  \"status\": \"new\",\n" +
+		"  \"next_ping_due_at\": \"2019-01-11T02:00:00+01:00\",\n" +
+		"  \"deadline_at\": \"2019-01-11T02:30:00+01:00\",\n" +
+		"  \"ping_url\": \"/api/heartbeats/4/ping\",\n" +
+		"  \"created_at\": \"2019-01-10T10:00:00Z\"\n" +
+		"}"
+
+	require.Equal(t, expectedJsonResp, rr.Body.String())
+}
+
+func TestGETHeartbeats_ShouldReturnErrorIfGettingHeartbeatsReturnsError(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	heartbeatRepo := mocks.NewMockHeartbeatRepository(mockCtrl)
+	heartbeatRepo.EXPECT().FindHeartbeats(gomock.Any(), uint(1)).Return(nil, errors.New("Error!!"))
+
+	req, err := http.NewRequest("GET", "/api/heartbeats", nil)
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.GetHeartbeatsHandler{
+		UserRepo:      userRepo,
+		HeartbeatRepo: heartbeatRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusInternalServerError, rr.Code)
+	requireProblem(t, rr, "internal_error", "could not fetch heartbeats")
+}
+
+func TestGETHeartbeats_ShouldReturnAllHeartbeats(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	createdAt := time.Date(2019, 1, 7, 10, 0, 0, 0, time.UTC)
+	lastPingAt := time.Date(2019, 1, 7, 11, 0, 0, 0, time.UTC)
+
+	heartbeatRepo := mocks.NewMockHeartbeatRepository(mockCtrl)
+	heartbeatRepo.EXPECT().FindHeartbeats(gomock.Any(), uint(1)).Return([]easyalert.Heartbeat{
+		{
+			ID:         1,
+			Name:       "backup",
+			Interval:   time.Hour,
+			Status:     easyalert.HeartbeatStatusUp,
+			LastPingAt: &lastPingAt,
+			CreatedAt:  createdAt,
+		},
+	}, nil)
+
+	req, err := http.NewRequest("GET", "/api/heartbeats", nil)
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.GetHeartbeatsHandler{
+		UserRepo:      userRepo,
+		HeartbeatRepo: heartbeatRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	expectedJsonResp := "[\n" +
+		"  {\n" +
+		"    \"id\": 1,\n" +
+		"    \"name\": \"backup\",\n" +
+		"    \"interval\": \"1h0m0s\",\n" +
+		"    \"grace_period\": \"0s\",\n" +
+		"    \"status\": \"up\",\n" +
+		"    \"last_ping_at\": \"2019-01-07T11:00:00Z\",\n" +
+		"    \"next_ping_due_at\": \"2019-01-07T12:00:00Z\",\n" +
+		"    \"deadline_at\": \"2019-01-07T12:00:00Z\",\n" +
+		"    \"ping_url\": \"/api/heartbeats/1/ping\",\n" +
+		"    \"created_at\": \"2019-01-07T10:00:00Z\"\n" +
+		"  }\n" +
+		"]"
+
+	require.Equal(t, expectedJsonResp, rr.Body.String())
+}
+
+func TestDELETEHeartbeat_ShouldReturnNotFoundIfHeartbeatDoesNotBelongToUser(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	heartbeatRepo := mocks.NewMockHeartbeatRepository(mockCtrl)
+	heartbeatRepo.EXPECT().FindHeartbeat(gomock.Any(), uint64(2), uint(1)).Return(easyalert.Heartbeat{}, easyalert.ErrRecordDoesNotExist)
+
+	req, err := http.NewRequest("DELETE", "/api/heartbeats/2", nil)
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+	req = mux.SetURLVars(req, map[string]string{"id": "2"})
+
+	rr := httptest.NewRecorder()
+	handler := api.DeleteHeartbeatHandler{
+		UserRepo:      userRepo,
+		HeartbeatRepo: heartbeatRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusNotFound, rr.Code)
+	requireProblem(t, rr, "not_found", "Heartbeat not found.")
+}
+
+func TestDELETEHeartbeat_ShouldDeleteHeartbeat(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	heartbeatRepo := mocks.NewMockHeartbeatRepository(mockCtrl)
+	heartbeatRepo.EXPECT().FindHeartbeat(gomock.Any(), uint64(2), uint(1)).Return(easyalert.Heartbeat{ID: 2}, nil)
+	heartbeatRepo.EXPECT().DeleteHeartbeat(easyalert.Heartbeat{ID: 2}).Return(nil)
+
+	req, err := http.NewRequest("DELETE", "/api/heartbeats/2", nil)
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+	req = mux.SetURLVars(req, map[string]string{"id": "2"})
+
+	rr := httptest.NewRecorder()
+	handler := api.DeleteHeartbeatHandler{
+		UserRepo:      userRepo,
+		HeartbeatRepo: heartbeatRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusNoContent, rr.Code)
+}
+
+func TestPingHeartbeat_ShouldMarkHeartbeatAsUp(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	heartbeatRepo := mocks.NewMockHeartbeatRepository(mockCtrl)
+	heartbeatRepo.EXPECT().FindHeartbeat(gomock.Any(), uint64(2), uint(1)).Return(easyalert.Heartbeat{ID: 2, Status: easyalert.HeartbeatStatusNew}, nil)
+	heartbeatRepo.EXPECT().UpdateHeartbeat(gomock.Any()).DoAndReturn(func(h easyalert.Heartbeat) (easyalert.Heartbeat, error) {
+		require.Equal(t, "up", h.HumanStatus())
+		require.NotNil(t, h.LastPingAt)
+
+		return h, nil
+	})
+
+	req, err := http.NewRequest("POST", "/api/heartbeats/2/ping", nil)
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+	req = mux.SetURLVars(req, map[string]string{"id": "2"})
+
+	rr := httptest.NewRecorder()
+	handler := api.PingHeartbeatHandler{
+		UserRepo:      userRepo,
+		HeartbeatRepo: heartbeatRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestPingHeartbeat_ShouldCreateRecoveryAlertIfHeartbeatWasDown(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	heartbeat := easyalert.Heartbeat{ID: 2, Name: "backup", Status: easyalert.HeartbeatStatusDown, UserID: 1}
+
+	heartbeatRepo := mocks.NewMockHeartbeatRepository(mockCtrl)
+	heartbeatRepo.EXPECT().FindHeartbeat(gomock.Any(), uint64(2), uint(1)).Return(heartbeat, nil)
+	heartbeatRepo.EXPECT().UpdateHeartbeat(gomock.Any()).DoAndReturn(func(h easyalert.Heartbeat) (easyalert.Heartbeat, error) {
+		return h, nil
+	})
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().CreateAlert(heartbeat.RecoveredAlert()).Return(easyalert.Alert{}, nil)
+
+	req, err := http.NewRequest("POST", "/api/heartbeats/2/ping", nil)
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+	req = mux.SetURLVars(req, map[string]string{"id": "2"})
+
+	rr := httptest.NewRecorder()
+	notifier := mocks.NewMockNotifier(mockCtrl)
+	notifier.EXPECT().Notify(gomock.Any(), gomock.Any())
+
+	handler := api.PingHeartbeatHandler{
+		Notifier:      notifier,
+		UserRepo:      userRepo,
+		HeartbeatRepo: heartbeatRepo,
+		AlertRepo:     alertRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+}
@@ -4,12 +4,66 @@ import (
 	"net/http"
 )
 
-// HomeHandler just returns a simple welcome message
+// HomeHandler returns a welcome message together with links to all resources
+// and examples how to use the API from the command line.
 type HomeHandler struct{}
 
+type homeResponseBody struct {
+	Easyalert string            `json:"easyalert"`
+	Links     map[string]string `json:"links"`
+	Examples  []homeExample     `json:"examples"`
+}
+
+type homeExample struct {
+	Description string `json:"description"`
+	Command     string `json:"command"`
+}
+
 // ServeHTTP handles the HTTP request
 func (h HomeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(`{"easyalert":"Alerting made easy"}`))
+	base := baseURL(r)
+	auth := `-H "Authorization: Bearer $TOKEN" `
+
+	writeJSON(w, http.StatusOK, homeResponseBody{
+		Easyalert: "Alerting made easy",
+		Links: map[string]string{
+			"openapi":        base + "/api/openapi.json",
+			"users":          base + "/api/users",
+			"auth":           base + "/api/auth",
+			"alerts":         base + "/api/alerts",
+			"heartbeats":     base + "/api/heartbeats",
+			"templates":      base + "/api/templates",
+			"integrations":   base + "/api/integrations",
+			"syslog_sources": base + "/api/syslog-sources",
+			"webhooks":       base + "/api/webhooks",
+			"chat_channels":  base + "/api/chat-channels",
+			"push_channels":  base + "/api/push-channels",
+			"matrix_rooms":   base + "/api/matrix-rooms",
+			"routing":        base + "/api/routing",
+		},
+		Examples: []homeExample{
+			{"Create an account", `curl -d '{"email":"you@example.com","password":"secret"}' ` + base + "/api/users"},
+			{"Fetch the token of your account", `curl -d '{"email":"you@example.com","password":"secret"}' ` + base + "/api/auth"},
+			{"Send an alert", "curl " + auth + `-d subject="Backup failed" -d message="Disk full" ` + base + "/api/alerts"},
+			{"Send the end of a log file", "tail -n 50 backup.log | curl " + auth + `-H "Content-Type: text/plain" --data-binary @- ` + base + "/api/alerts"},
+			{"List failed alerts", "curl " + auth + `"` + base + `/api/alerts?status=failed"`},
+			{"Watch a nightly job", "curl " + auth + `-d '{"name":"backup","schedule":"0 2 * * *"}' ` + base + "/api/heartbeats"},
+		},
+	})
+}
+
+// baseURL returns the scheme and host the request was sent to, so that the
+// examples can be copied as they are.
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+
+	host := r.Host
+	if host == "" {
+		host = "localhost"
+	}
+
+	return scheme + "://" + host
 }
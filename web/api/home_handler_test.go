@@ -1,6 +1,7 @@
 package api_test
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -14,6 +15,9 @@ func TestHomeHandler_Success(t *testing.T) {
 	req, err := http.NewRequest("GET", "/api", nil)
 	require.Nil(t, err)
 
+	req.Host = "easyalert.example.com"
+	req.Header.Set("X-Forwarded-Proto", "https")
+
 	rr := httptest.NewRecorder()
 	handler := api.HomeHandler{}
 
@@ -21,5 +25,23 @@ func TestHomeHandler_Success(t *testing.T) {
 
 	require.Equal(t, http.StatusOK, rr.Code)
 	require.Equal(t, "application/json; charset=UTF-8", rr.Header().Get("Content-Type"))
-	require.Equal(t, `{"easyalert":"Alerting made easy"}`, rr.Body.String())
+
+	var body struct {
+		Easyalert string            `json:"easyalert"`
+		Links     map[string]string `json:"links"`
+		Examples  []struct {
+			Description string `json:"description"`
+			Command     string `json:"command"`
+		} `json:"examples"`
+	}
+
+	err = json.Unmarshal(rr.Body.Bytes(), &body)
+	require.Nil(t, err)
+
+	require.Equal(t, "Alerting made easy", body.Easyalert)
+	require.Equal(t, "https://easyalert.example.com/api/openapi.json", body.Links["openapi"])
+	require.Equal(t, "https://easyalert.example.com/api/alerts", body.Links["alerts"])
+
+	require.Equal(t, "Send an alert", body.Examples[2].Description)
+	require.Equal(t, `curl -H "Authorization: Bearer $TOKEN" -d subject="Backup failed" -d message="Disk full" https://easyalert.example.com/api/alerts`, body.Examples[2].Command)
 }
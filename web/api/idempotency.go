@@ -0,0 +1,159 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+
+	"github.com/bakku/easyalert"
+)
+
+const maxIdempotencyKeyLength = 255
+
+// bufferedResponse is a http.ResponseWriter which keeps the response in memory
+// so that it can be stored before it is sent to the client.
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+
+	return b.body.Write(p)
+}
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	if b.status == 0 {
+		b.status = status
+	}
+}
+
+// hashRequest identifies a request by its method, URL and decoded body so
+// that a reused idempotency key can be told apart from a retry. The decoded
+// body is hashed since retries may encode it differently, e.g. with another
+// multipart boundary.
+func hashRequest(r *http.Request, request interface{}) (string, error) {
+	encoded, err := json.Marshal(request)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	hash.Write(encoded)
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// serveIdempotent calls serve directly if the request has no Idempotency-Key
+// header. Otherwise the key is claimed before serve is called and the
+// response of serve is stored for later retries. Retries replay the stored
+// response, or are rejected while the first request is still in progress.
+// Server errors are not stored so that the request can be retried.
+func serveIdempotent(w http.ResponseWriter, r *http.Request, repo easyalert.IdempotencyKeyRepository, user easyalert.User, request interface{}, serve func(w http.ResponseWriter)) {
+	key := r.Header.Get("Idempotency-Key")
+	if key == "" {
+		serve(w)
+		return
+	}
+
+	if len(key) > maxIdempotencyKeyLength {
+		writeError(w, http.StatusUnprocessableEntity, "invalid_idempotency_key", "Idempotency key must not be longer than 255 characters.")
+		return
+	}
+
+	requestHash, err := hashRequest(r, request)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not hash request")
+		return
+	}
+
+	claimed, err := repo.CreateIdempotencyKey(easyalert.IdempotencyKey{
+		Key:         key,
+		RequestHash: requestHash,
+		UserID:      user.ID,
+	})
+
+	if err == easyalert.ErrRecordAlreadyExists {
+		replayIdempotent(w, repo, user, key, requestHash)
+		return
+	}
+
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not create idempotency key")
+		return
+	}
+
+	resp := &bufferedResponse{header: w.Header()}
+	serve(resp)
+
+	if resp.status == 0 {
+		resp.status = http.StatusOK
+	}
+
+	// the response is sent even if it could not be stored, since failing
+	// here would make the client retry a request which already succeeded
+	if resp.status < 500 {
+		claimed.StatusCode = resp.status
+		claimed.Location = resp.header.Get("Location")
+		claimed.Body = resp.body.String()
+
+		repo.UpdateIdempotencyKey(claimed)
+	} else {
+		repo.DeleteIdempotencyKey(claimed)
+	}
+
+	w.WriteHeader(resp.status)
+	w.Write(resp.body.Bytes())
+}
+
+// replayIdempotent sends the stored response of an idempotency key which
+// was already claimed by another request.
+func replayIdempotent(w http.ResponseWriter, repo easyalert.IdempotencyKeyRepository, user easyalert.User, key, requestHash string) {
+	stored, err := repo.FindIdempotencyKey("WHERE user_id = $1 AND key = $2", user.ID, key)
+
+	// the key was deleted in between because the first request failed
+	if err == easyalert.ErrRecordDoesNotExist {
+		writeError(w, http.StatusConflict, "idempotency_key_in_progress", "A request with this idempotency key is still in progress.")
+		return
+	}
+
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not fetch idempotency key")
+		return
+	}
+
+	if stored.RequestHash != requestHash {
+		writeError(w, http.StatusConflict, "idempotency_key_reused", "Idempotency key was already used for a different request.")
+		return
+	}
+
+	if stored.StatusCode == 0 {
+		writeError(w, http.StatusConflict, "idempotency_key_in_progress", "A request with this idempotency key is still in progress.")
+		return
+	}
+
+	if stored.Location != "" {
+		w.Header().Set("Location", stored.Location)
+	}
+
+	contentType := "application/json; charset=UTF-8"
+	if stored.StatusCode >= http.StatusBadRequest {
+		contentType = problemContentType
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Idempotent-Replayed", "true")
+	w.WriteHeader(stored.StatusCode)
+	w.Write([]byte(stored.Body))
+}
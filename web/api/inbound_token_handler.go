@@ -0,0 +1,90 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/random"
+)
+
+type inboundTokenResponseBody struct {
+	InboundToken string `json:"inbound_token"`
+}
+
+// generateInboundToken returns a new inbound token. It is lower case, since
+// mail servers may change the case of the local part of an address.
+func generateInboundToken() (string, error) {
+	token, err := random.String(easyalert.InboundTokenLength)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.ToLower(token), nil
+}
+
+// GetInboundTokenHandler should return the token of the inbound email
+// address of the user. Users created before inbound tokens existed get one
+// generated on the first request.
+type GetInboundTokenHandler struct {
+	UserRepo easyalert.UserRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h GetInboundTokenHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	if user.InboundToken == "" {
+		user, ok = saveInboundToken(w, h.UserRepo, user)
+		if !ok {
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusOK, inboundTokenResponseBody{user.InboundToken})
+}
+
+// RotateInboundTokenHandler should replace the inbound token of the user,
+// e.g. after the address leaked. Emails to the old address are rejected
+// from then on.
+type RotateInboundTokenHandler struct {
+	UserRepo easyalert.UserRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h RotateInboundTokenHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	user, ok = saveInboundToken(w, h.UserRepo, user)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, inboundTokenResponseBody{user.InboundToken})
+}
+
+// saveInboundToken generates a new inbound token for the user and saves it.
+// If that fails an error is written and false is returned.
+func saveInboundToken(w http.ResponseWriter, repo easyalert.UserRepository, user easyalert.User) (easyalert.User, bool) {
+	token, err := generateInboundToken()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not generate token")
+		return easyalert.User{}, false
+	}
+
+	user.InboundToken = token
+
+	user, err = repo.UpdateUser(user)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not update user")
+		return easyalert.User{}, false
+	}
+
+	return user, true
+}
@@ -0,0 +1,92 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/mocks"
+	"github.com/bakku/easyalert/web/api"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGETInboundToken_ShouldReturnExistingToken(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1, Token: "12345", InboundToken: "abcdef"}, nil)
+
+	req, err := http.NewRequest("GET", "/api/users/me/inbound-token", nil)
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.GetInboundTokenHandler{UserRepo: userRepo}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	requireMatchesSpec(t, loadOpenAPI(t), "GET", "/api/users/me/inbound-token", rr)
+	require.JSONEq(t, `{"inbound_token": "abcdef"}`, rr.Body.String())
+}
+
+func TestGETInboundToken_ShouldGenerateMissingToken(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1, Token: "12345"}, nil)
+
+	var updated easyalert.User
+
+	userRepo.EXPECT().UpdateUser(gomock.Any()).DoAndReturn(func(user easyalert.User) (easyalert.User, error) {
+		updated = user
+		return user, nil
+	})
+
+	req, err := http.NewRequest("GET", "/api/users/me/inbound-token", nil)
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.GetInboundTokenHandler{UserRepo: userRepo}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var body map[string]string
+	require.Nil(t, json.Unmarshal(rr.Body.Bytes(), &body))
+
+	require.Regexp(t, "^[a-z]{32}$", updated.InboundToken)
+	require.Equal(t, updated.InboundToken, body["inbound_token"])
+	require.Equal(t, "12345", updated.Token)
+}
+
+func TestPOSTInboundToken_ShouldReplaceToken(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1, InboundToken: "leaked"}, nil)
+	userRepo.EXPECT().UpdateUser(gomock.Any()).DoAndReturn(func(user easyalert.User) (easyalert.User, error) {
+		require.NotEqual(t, "leaked", user.InboundToken)
+		return user, nil
+	})
+
+	req, err := http.NewRequest("POST", "/api/users/me/inbound-token", nil)
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.RotateInboundTokenHandler{UserRepo: userRepo}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	requireMatchesSpec(t, loadOpenAPI(t), "POST", "/api/users/me/inbound-token", rr)
+}
@@ -0,0 +1,337 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/webhook"
+)
+
+type fieldMappingBody struct {
+	Subject  string `json:"subject"`
+	Message  string `json:"message"`
+	Severity string `json:"severity"`
+	DedupKey string `json:"dedup_key"`
+}
+
+type integrationResponseBody struct {
+	ID         uint             `json:"id"`
+	Name       string           `json:"name"`
+	Preset     string           `json:"preset,omitempty"`
+	Mapping    fieldMappingBody `json:"mapping"`
+	WebhookURL string           `json:"webhook_url"`
+	CreatedAt  string           `json:"created_at"`
+	UpdatedAt  string           `json:"updated_at"`
+}
+
+func convertIntegrationToResponseBody(integration easyalert.Integration) integrationResponseBody {
+	return integrationResponseBody{
+		ID:     integration.ID,
+		Name:   integration.Name,
+		Preset: integration.Preset,
+		Mapping: fieldMappingBody{
+			Subject:  integration.Mapping.Subject,
+			Message:  integration.Mapping.Message,
+			Severity: integration.Mapping.Severity,
+			DedupKey: integration.Mapping.DedupKey,
+		},
+		WebhookURL: fmt.Sprintf("/api/integrations/%d/webhook", integration.ID),
+		CreatedAt:  integration.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:  integration.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+type integrationRequestBody struct {
+	Name    string           `json:"name"`
+	Preset  string           `json:"preset"`
+	Mapping fieldMappingBody `json:"mapping"`
+}
+
+// GetIntegrationsHandler should return all integrations of the user.
+type GetIntegrationsHandler struct {
+	UserRepo        easyalert.UserRepository
+	IntegrationRepo easyalert.IntegrationRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h GetIntegrationsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	integrations, err := h.IntegrationRepo.FindIntegrations("WHERE user_id = $1 ORDER BY name", user.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not fetch integrations")
+		return
+	}
+
+	responseBody := make([]integrationResponseBody, len(integrations))
+	for i, integration := range integrations {
+		responseBody[i] = convertIntegrationToResponseBody(integration)
+	}
+
+	writeJSON(w, http.StatusOK, responseBody)
+}
+
+// CreateIntegrationsHandler should accept a JSON object and create an integration from it.
+type CreateIntegrationsHandler struct {
+	UserRepo        easyalert.UserRepository
+	IntegrationRepo easyalert.IntegrationRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h CreateIntegrationsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	integration, ok := readIntegration(w, r, h.IntegrationRepo, user, easyalert.Integration{UserID: user.ID})
+	if !ok {
+		return
+	}
+
+	integration, err := h.IntegrationRepo.CreateIntegration(integration)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not create integration")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, convertIntegrationToResponseBody(integration))
+}
+
+// GetIntegrationHandler should return a single integration of the user.
+type GetIntegrationHandler struct {
+	UserRepo        easyalert.UserRepository
+	IntegrationRepo easyalert.IntegrationRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h GetIntegrationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	integration, ok := findUserIntegration(w, r, h.IntegrationRepo, user)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, convertIntegrationToResponseBody(integration))
+}
+
+// UpdateIntegrationHandler should accept a JSON object and replace an integration of the user with it.
+type UpdateIntegrationHandler struct {
+	UserRepo        easyalert.UserRepository
+	IntegrationRepo easyalert.IntegrationRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h UpdateIntegrationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	integration, ok := findUserIntegration(w, r, h.IntegrationRepo, user)
+	if !ok {
+		return
+	}
+
+	integration, ok = readIntegration(w, r, h.IntegrationRepo, user, integration)
+	if !ok {
+		return
+	}
+
+	integration, err := h.IntegrationRepo.UpdateIntegration(integration)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not update integration")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, convertIntegrationToResponseBody(integration))
+}
+
+// DeleteIntegrationHandler should delete an integration of the user.
+type DeleteIntegrationHandler struct {
+	UserRepo        easyalert.UserRepository
+	IntegrationRepo easyalert.IntegrationRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h DeleteIntegrationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	integration, ok := findUserIntegration(w, r, h.IntegrationRepo, user)
+	if !ok {
+		return
+	}
+
+	err := h.IntegrationRepo.DeleteIntegration(integration)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not delete integration")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// IntegrationWebhookHandler receives the JSON webhook of an integration and
+// creates an alert from it using the mapping of the integration.
+type IntegrationWebhookHandler struct {
+	UserRepo        easyalert.UserRepository
+	IntegrationRepo easyalert.IntegrationRepository
+	AlertRepo       easyalert.AlertRepository
+	Notifier        easyalert.Notifier
+}
+
+// ServeHTTP handles the HTTP request.
+func (h IntegrationWebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeIntegration(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	integration, ok := findUserIntegration(w, r, h.IntegrationRepo, user)
+	if !ok {
+		return
+	}
+
+	bytes, ok := readWebhookBody(w, r)
+	if !ok {
+		return
+	}
+
+	notification, err := webhook.Notification(integration.Mapping, user, bytes)
+	if err != nil {
+		switch err {
+		case webhook.ErrInvalidJSON:
+			writeError(w, http.StatusUnprocessableEntity, "invalid_json", "invalid json")
+		case webhook.ErrNoSubject:
+			writeAPIError(w, invalidField("mapping.subject", "unmapped_subject", err.Error()))
+		default:
+			writeError(w, http.StatusInternalServerError, "internal_error", "could not map payload")
+		}
+
+		return
+	}
+
+	notification.Alert.Labels = map[string]string{"integration": integration.Name}
+
+	alert, folded, err := easyalert.CreateOrFoldAlert(h.AlertRepo, notification.Alert, easyalert.DefaultDedupWindow)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not create alert")
+		return
+	}
+
+	if !folded {
+		notification.Alert = alert
+		h.Notifier.Notify(user, notification)
+	}
+
+	writeJSON(w, http.StatusOK, convertAlertToResponseBody(alert))
+}
+
+// readIntegration reads the integration from the request body into
+// integration and validates it. Expressions which are not given are taken
+// from the preset. If the integration is invalid an error is written and
+// false is returned.
+func readIntegration(w http.ResponseWriter, r *http.Request, repo easyalert.IntegrationRepository, user easyalert.User, integration easyalert.Integration) (easyalert.Integration, bool) {
+	var body integrationRequestBody
+
+	if !readJSONBody(w, r, &body) {
+		return easyalert.Integration{}, false
+	}
+
+	if body.Name == "" {
+		writeAPIError(w, invalidField("name", "missing_field", "Name must be given."))
+		return easyalert.Integration{}, false
+	}
+
+	if !checkName(w, body.Name) {
+		return easyalert.Integration{}, false
+	}
+
+	mapping := easyalert.FieldMapping{
+		Subject:  body.Mapping.Subject,
+		Message:  body.Mapping.Message,
+		Severity: body.Mapping.Severity,
+		DedupKey: body.Mapping.DedupKey,
+	}
+
+	if body.Preset != "" {
+		preset, ok := webhook.Presets[body.Preset]
+		if !ok {
+			writeAPIError(w, invalidField("preset", "invalid_preset", "Invalid preset, expected grafana."))
+			return easyalert.Integration{}, false
+		}
+
+		mapping = withDefaults(mapping, preset)
+	}
+
+	err := webhook.Validate(mapping)
+	if err != nil {
+		field := "mapping"
+		if e, ok := err.(webhook.InvalidExpressionError); ok {
+			field = "mapping." + e.Field
+		}
+
+		writeAPIError(w, invalidField(field, "invalid_expression", err.Error()))
+		return easyalert.Integration{}, false
+	}
+
+	integration.Name = body.Name
+	integration.Preset = body.Preset
+	integration.Mapping = mapping
+
+	ok := checkUniqueName(w, integration.ID, "An integration with this name already exists.", func() (uint, error) {
+		existing, err := repo.FindIntegration("WHERE user_id = $1 AND name = $2", user.ID, integration.Name)
+		return existing.ID, err
+	})
+	if !ok {
+		return easyalert.Integration{}, false
+	}
+
+	return integration, true
+}
+
+// withDefaults fills every expression which is not given from defaults.
+func withDefaults(mapping, defaults easyalert.FieldMapping) easyalert.FieldMapping {
+	if mapping.Subject == "" {
+		mapping.Subject = defaults.Subject
+	}
+
+	if mapping.Message == "" {
+		mapping.Message = defaults.Message
+	}
+
+	if mapping.Severity == "" {
+		mapping.Severity = defaults.Severity
+	}
+
+	if mapping.DedupKey == "" {
+		mapping.DedupKey = defaults.DedupKey
+	}
+
+	return mapping
+}
+
+// findUserIntegration returns the integration given by the id route variable
+// if it belongs to the user. Otherwise an error is written and false is returned.
+func findUserIntegration(w http.ResponseWriter, r *http.Request, repo easyalert.IntegrationRepository, user easyalert.User) (easyalert.Integration, bool) {
+	var integration easyalert.Integration
+
+	found := findUserResource(w, r, "integration", func(id uint64) (err error) {
+		integration, err = repo.FindIntegration("WHERE id = $1 AND user_id = $2", id, user.ID)
+		return err
+	})
+
+	return integration, found
+}
@@ -0,0 +1,273 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/mocks"
+	"github.com/bakku/easyalert/web/api"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPOSTIntegrations_ShouldReturnErrorIfPresetIsUnknown(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	req, err := http.NewRequest("POST", "/api/integrations", strings.NewReader(`{"name": "grafana", "preset": "kibana"}`))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateIntegrationsHandler{UserRepo: userRepo}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	requireProblem(t, rr, "invalid_preset", "Invalid preset, expected grafana.")
+}
+
+func TestPOSTIntegrations_ShouldReturnErrorIfExpressionIsInvalid(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	payload := `{"name": "ci", "mapping": {"subject": "$.job", "severity": "$.levels[first]"}}`
+
+	req, err := http.NewRequest("POST", "/api/integrations", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateIntegrationsHandler{UserRepo: userRepo}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+
+	p := requireProblem(t, rr, "invalid_expression", `Invalid severity expression: invalid index "first" in path "$.levels[first]".`)
+	require.Equal(t, "mapping.severity", p.Errors[0].Field)
+}
+
+func TestPOSTIntegrations_ShouldCreateIntegrationFromPreset(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	createdAt := time.Date(2019, 1, 28, 18, 0, 0, 0, time.UTC)
+
+	integrationRepo := mocks.NewMockIntegrationRepository(mockCtrl)
+	integrationRepo.EXPECT().FindIntegration(gomock.Any(), gomock.Any(), gomock.Any()).Return(easyalert.Integration{}, easyalert.ErrRecordDoesNotExist)
+	integrationRepo.EXPECT().CreateIntegration(easyalert.Integration{
+		Name:   "grafana",
+		Preset: "grafana",
+		Mapping: easyalert.FieldMapping{
+			Subject:  "$.title",
+			Message:  "$.message",
+			Severity: "$.commonLabels.priority",
+			DedupKey: "grafana:{$.groupKey}:{$.alerts[0].startsAt}:{$.status}",
+		},
+		UserID: 1,
+	}).DoAndReturn(func(integration easyalert.Integration) (easyalert.Integration, error) {
+		integration.ID = 2
+		integration.CreatedAt = createdAt
+		integration.UpdatedAt = createdAt
+		return integration, nil
+	})
+
+	payload := `{"name": "grafana", "preset": "grafana", "mapping": {"severity": "$.commonLabels.priority"}}`
+
+	req, err := http.NewRequest("POST", "/api/integrations", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateIntegrationsHandler{
+		UserRepo:        userRepo,
+		IntegrationRepo: integrationRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusCreated, rr.Code)
+	requireMatchesSpec(t, loadOpenAPI(t), "POST", "/api/integrations", rr)
+
+	expectedJsonResp := "{\n" +
+		"  \"id\": 2,\n" +
+		"  \"name\": \"grafana\",\n" +
+		"  \"preset\": \"grafana\",\n" +
+		"  \"mapping\": {\n" +
+		"    \"subject\": \"$.title\",\n" +
+		"    \"message\": \"$.message\",\n" +
+		"    \"severity\": \"$.commonLabels.priority\",\n" +
+		"    \"dedup_key\": \"grafana:{$.groupKey}:{$.alerts[0].startsAt}:{$.status}\"\n" +
+		"  },\n" +
+		"  \"webhook_url\": \"/api/integrations/2/webhook\",\n" +
+		"  \"created_at\": \"2019-01-28T18:00:00Z\",\n" +
+		"  \"updated_at\": \"2019-01-28T18:00:00Z\"\n" +
+		"}"
+
+	require.Equal(t, expectedJsonResp, rr.Body.String())
+}
+
+func TestGETIntegration_ShouldReturnNotFoundIfIntegrationDoesNotBelongToUser(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	integrationRepo := mocks.NewMockIntegrationRepository(mockCtrl)
+	integrationRepo.EXPECT().FindIntegration("WHERE id = $1 AND user_id = $2", uint64(2), uint(1)).Return(easyalert.Integration{}, easyalert.ErrRecordDoesNotExist)
+
+	req, err := http.NewRequest("GET", "/api/integrations/2", nil)
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+	req = mux.SetURLVars(req, map[string]string{"id": "2"})
+
+	rr := httptest.NewRecorder()
+	handler := api.GetIntegrationHandler{
+		UserRepo:        userRepo,
+		IntegrationRepo: integrationRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusNotFound, rr.Code)
+	requireProblem(t, rr, "not_found", "Integration not found.")
+}
+
+func TestPUTIntegration_ShouldReturnConflictIfNameIsTakenByAnotherIntegration(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	integrationRepo := mocks.NewMockIntegrationRepository(mockCtrl)
+	integrationRepo.EXPECT().FindIntegration("WHERE id = $1 AND user_id = $2", uint64(2), uint(1)).Return(easyalert.Integration{ID: 2, Name: "grafana", UserID: 1}, nil)
+	integrationRepo.EXPECT().FindIntegration("WHERE user_id = $1 AND name = $2", uint(1), "prometheus").Return(easyalert.Integration{ID: 3, Name: "prometheus", UserID: 1}, nil)
+
+	payload := `{"name": "prometheus", "preset": "grafana"}`
+
+	req, err := http.NewRequest("PUT", "/api/integrations/2", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+	req = mux.SetURLVars(req, map[string]string{"id": "2"})
+
+	rr := httptest.NewRecorder()
+	handler := api.UpdateIntegrationHandler{
+		UserRepo:        userRepo,
+		IntegrationRepo: integrationRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusConflict, rr.Code)
+	requireProblem(t, rr, "name_taken", "An integration with this name already exists.")
+}
+
+func TestPOSTIntegrationWebhook_ShouldReturnErrorIfSubjectIsNotMapped(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	integrationRepo := mocks.NewMockIntegrationRepository(mockCtrl)
+	integrationRepo.EXPECT().FindIntegration(gomock.Any(), gomock.Any(), gomock.Any()).Return(easyalert.Integration{
+		ID:      2,
+		Name:    "ci",
+		Mapping: easyalert.FieldMapping{Subject: "$.job"},
+	}, nil)
+
+	req, err := http.NewRequest("POST", "/api/integrations/2/webhook?token=abcdef", strings.NewReader(`{"name": "deploy"}`))
+	require.Nil(t, err)
+
+	req = mux.SetURLVars(req, map[string]string{"id": "2"})
+
+	rr := httptest.NewRecorder()
+	handler := api.IntegrationWebhookHandler{
+		UserRepo:        userRepo,
+		IntegrationRepo: integrationRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	requireProblem(t, rr, "unmapped_subject", "Subject expression did not match the payload.")
+}
+
+func TestPOSTIntegrationWebhook_ShouldCreateAndNotifyAlert(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	user := easyalert.User{ID: 1}
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(user, nil)
+
+	integrationRepo := mocks.NewMockIntegrationRepository(mockCtrl)
+	integrationRepo.EXPECT().FindIntegration(gomock.Any(), gomock.Any(), gomock.Any()).Return(easyalert.Integration{
+		ID:   2,
+		Name: "ci",
+		Mapping: easyalert.FieldMapping{
+			Subject:  "Pipeline {$.pipeline.name} {$.status}",
+			Message:  "$.log",
+			Severity: "$.level",
+		},
+	}, nil)
+
+	createdAt := time.Date(2019, 1, 28, 18, 0, 0, 0, time.UTC)
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().CreateAlert(easyalert.Alert{
+		Subject:     "Pipeline deploy failed",
+		Status:      easyalert.AlertStatusPending,
+		UserID:      1,
+		Occurrences: 1,
+		Severity:    easyalert.AlertSeverityCritical,
+		Labels:      map[string]string{"integration": "ci"},
+	}).DoAndReturn(func(alert easyalert.Alert) (easyalert.Alert, error) {
+		alert.ID = 9
+		alert.CreatedAt = createdAt
+		return alert, nil
+	})
+
+	notifier := mocks.NewMockNotifier(mockCtrl)
+	notifier.EXPECT().Notify(user, gomock.Any()).Do(func(user easyalert.User, n easyalert.Notification) {
+		require.Equal(t, uint(9), n.Alert.ID)
+		require.Equal(t, "exit code 1", n.Message)
+	})
+
+	payload := `{"pipeline": {"name": "deploy"}, "status": "failed", "level": "error", "log": "exit code 1"}`
+
+	req, err := http.NewRequest("POST", "/api/integrations/2/webhook", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+	req = mux.SetURLVars(req, map[string]string{"id": "2"})
+
+	rr := httptest.NewRecorder()
+	handler := api.IntegrationWebhookHandler{
+		UserRepo:        userRepo,
+		IntegrationRepo: integrationRepo,
+		AlertRepo:       alertRepo,
+		Notifier:        notifier,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	requireMatchesSpec(t, loadOpenAPI(t), "POST", "/api/integrations/{id}/webhook", rr)
+}
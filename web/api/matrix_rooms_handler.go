@@ -0,0 +1,241 @@
+package api
+
+import (
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/bakku/easyalert"
+)
+
+// The access token is never returned since it grants full access to the
+// Matrix account it belongs to.
+type matrixRoomResponseBody struct {
+	ID            uint   `json:"id"`
+	Name          string `json:"name"`
+	HomeserverURL string `json:"homeserver_url"`
+	RoomID        string `json:"room_id"`
+	CreatedAt     string `json:"created_at"`
+	UpdatedAt     string `json:"updated_at"`
+}
+
+func convertMatrixRoomToResponseBody(room easyalert.MatrixRoom) matrixRoomResponseBody {
+	return matrixRoomResponseBody{
+		ID:            room.ID,
+		Name:          room.Name,
+		HomeserverURL: room.HomeserverURL,
+		RoomID:        room.RoomID,
+		CreatedAt:     room.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:     room.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+type matrixRoomRequestBody struct {
+	Name          string `json:"name"`
+	HomeserverURL string `json:"homeserver_url"`
+	RoomID        string `json:"room_id"`
+	AccessToken   string `json:"access_token"`
+}
+
+// matrixRoomIDPattern matches room IDs like !abc123:example.org. Room
+// aliases starting with # are not accepted since messages can only be sent
+// to room IDs.
+var matrixRoomIDPattern = regexp.MustCompile(`^![^:\s]+:\S+$`)
+
+// GetMatrixRoomsHandler should return all Matrix rooms of the user.
+type GetMatrixRoomsHandler struct {
+	UserRepo easyalert.UserRepository
+	RoomRepo easyalert.MatrixRoomRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h GetMatrixRoomsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	rooms, err := h.RoomRepo.FindMatrixRooms("WHERE user_id = $1 ORDER BY name", user.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not fetch matrix rooms")
+		return
+	}
+
+	responseBody := make([]matrixRoomResponseBody, len(rooms))
+	for i, room := range rooms {
+		responseBody[i] = convertMatrixRoomToResponseBody(room)
+	}
+
+	writeJSON(w, http.StatusOK, responseBody)
+}
+
+// CreateMatrixRoomsHandler should accept a JSON object and create a Matrix room from it.
+type CreateMatrixRoomsHandler struct {
+	UserRepo easyalert.UserRepository
+	RoomRepo easyalert.MatrixRoomRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h CreateMatrixRoomsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	room, ok := readMatrixRoom(w, r, h.RoomRepo, user, easyalert.MatrixRoom{UserID: user.ID})
+	if !ok {
+		return
+	}
+
+	room, err := h.RoomRepo.CreateMatrixRoom(room)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not create matrix room")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, convertMatrixRoomToResponseBody(room))
+}
+
+// GetMatrixRoomHandler should return a single Matrix room of the user.
+type GetMatrixRoomHandler struct {
+	UserRepo easyalert.UserRepository
+	RoomRepo easyalert.MatrixRoomRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h GetMatrixRoomHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	room, ok := findUserMatrixRoom(w, r, h.RoomRepo, user)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, convertMatrixRoomToResponseBody(room))
+}
+
+// UpdateMatrixRoomHandler should accept a JSON object and replace a Matrix room of the user with it.
+// The access token may be omitted to keep the current one.
+type UpdateMatrixRoomHandler struct {
+	UserRepo easyalert.UserRepository
+	RoomRepo easyalert.MatrixRoomRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h UpdateMatrixRoomHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	room, ok := findUserMatrixRoom(w, r, h.RoomRepo, user)
+	if !ok {
+		return
+	}
+
+	room, ok = readMatrixRoom(w, r, h.RoomRepo, user, room)
+	if !ok {
+		return
+	}
+
+	room, err := h.RoomRepo.UpdateMatrixRoom(room)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not update matrix room")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, convertMatrixRoomToResponseBody(room))
+}
+
+// DeleteMatrixRoomHandler should delete a Matrix room of the user.
+type DeleteMatrixRoomHandler struct {
+	UserRepo easyalert.UserRepository
+	RoomRepo easyalert.MatrixRoomRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h DeleteMatrixRoomHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	room, ok := findUserMatrixRoom(w, r, h.RoomRepo, user)
+	if !ok {
+		return
+	}
+
+	err := h.RoomRepo.DeleteMatrixRoom(room)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not delete matrix room")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// readMatrixRoom reads the Matrix room from the request body into room and
+// validates it. An empty access token keeps the one of room. If the room is
+// invalid an error is written and false is returned.
+func readMatrixRoom(w http.ResponseWriter, r *http.Request, repo easyalert.MatrixRoomRepository, user easyalert.User, room easyalert.MatrixRoom) (easyalert.MatrixRoom, bool) {
+	var body matrixRoomRequestBody
+
+	if !readJSONBody(w, r, &body) {
+		return easyalert.MatrixRoom{}, false
+	}
+
+	if body.Name == "" || body.HomeserverURL == "" || body.RoomID == "" || (body.AccessToken == "" && room.AccessToken == "") {
+		writeError(w, http.StatusUnprocessableEntity, "missing_field", "Name, homeserver URL, room ID and access token must be given.")
+		return easyalert.MatrixRoom{}, false
+	}
+
+	if !checkName(w, body.Name) {
+		return easyalert.MatrixRoom{}, false
+	}
+
+	homeserverURL, ok := parseHTTPURL(body.HomeserverURL)
+	if !ok {
+		writeAPIError(w, invalidField("homeserver_url", "invalid_url", "Homeserver URL must be an absolute http or https URL of a public host."))
+		return easyalert.MatrixRoom{}, false
+	}
+
+	if !matrixRoomIDPattern.MatchString(body.RoomID) {
+		writeAPIError(w, invalidField("room_id", "invalid_room_id", "Room ID must look like !abc123:example.org."))
+		return easyalert.MatrixRoom{}, false
+	}
+
+	room.Name = body.Name
+	room.HomeserverURL = homeserverURL
+	room.RoomID = body.RoomID
+
+	if body.AccessToken != "" {
+		room.AccessToken = body.AccessToken
+	}
+
+	ok = checkUniqueName(w, room.ID, "A matrix room with this name already exists.", func() (uint, error) {
+		existing, err := repo.FindMatrixRoom("WHERE user_id = $1 AND name = $2", user.ID, room.Name)
+		return existing.ID, err
+	})
+	if !ok {
+		return easyalert.MatrixRoom{}, false
+	}
+
+	return room, true
+}
+
+// findUserMatrixRoom returns the Matrix room given by the id route variable
+// if it belongs to the user. Otherwise an error is written and false is
+// returned.
+func findUserMatrixRoom(w http.ResponseWriter, r *http.Request, repo easyalert.MatrixRoomRepository, user easyalert.User) (easyalert.MatrixRoom, bool) {
+	var room easyalert.MatrixRoom
+
+	found := findUserResource(w, r, "matrix room", func(id uint64) (err error) {
+		room, err = repo.FindMatrixRoom("WHERE id = $1 AND user_id = $2", id, user.ID)
+		return err
+	})
+
+	return room, found
+}
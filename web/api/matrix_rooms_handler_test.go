@@ -0,0 +1,131 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/mocks"
+	"github.com/bakku/easyalert/web/api"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPOSTMatrixRooms_ShouldReturnErrorIfRoomIDIsInvalid(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	payload := `{"name": "ops", "homeserver_url": "https://matrix.example.org", "room_id": "#ops:example.org", "access_token": "syt_secret"}`
+
+	req, err := http.NewRequest("POST", "/api/matrix-rooms", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateMatrixRoomsHandler{UserRepo: userRepo}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+
+	p := requireProblem(t, rr, "invalid_room_id", "Room ID must look like !abc123:example.org.")
+	require.Equal(t, "room_id", p.Errors[0].Field)
+}
+
+func TestPOSTMatrixRooms_ShouldCreateMatrixRoomWithoutReturningAccessToken(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	createdAt := time.Date(2019, 2, 11, 19, 0, 0, 0, time.UTC)
+
+	roomRepo := mocks.NewMockMatrixRoomRepository(mockCtrl)
+	roomRepo.EXPECT().FindMatrixRoom("WHERE user_id = $1 AND name = $2", uint(1), "ops").Return(easyalert.MatrixRoom{}, easyalert.ErrRecordDoesNotExist)
+	roomRepo.EXPECT().CreateMatrixRoom(easyalert.MatrixRoom{
+		Name:          "ops",
+		HomeserverURL: "https://matrix.example.org",
+		RoomID:        "!abc:example.org",
+		AccessToken:   "syt_secret",
+		UserID:        1,
+	}).DoAndReturn(func(room easyalert.MatrixRoom) (easyalert.MatrixRoom, error) {
+		room.ID = 2
+		room.CreatedAt = createdAt
+		room.UpdatedAt = createdAt
+		return room, nil
+	})
+
+	payload := `{"name": "ops", "homeserver_url": "https://matrix.example.org", "room_id": "!abc:example.org", "access_token": "syt_secret"}`
+
+	req, err := http.NewRequest("POST", "/api/matrix-rooms", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateMatrixRoomsHandler{UserRepo: userRepo, RoomRepo: roomRepo}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusCreated, rr.Code)
+	requireMatchesSpec(t, loadOpenAPI(t), "POST", "/api/matrix-rooms", rr)
+
+	expectedJsonResp := "{\n" +
+		"  \"id\": 2,\n" +
+		"  \"name\": \"ops\",\n" +
+		"  \"homeserver_url\": \"https://matrix.example.org\",\n" +
+		"  \"room_id\": \"!abc:example.org\",\n" +
+		"  \"created_at\": \"2019-02-11T19:00:00Z\",\n" +
+		"  \"updated_at\": \"2019-02-11T19:00:00Z\"\n" +
+		"}"
+
+	require.Equal(t, expectedJsonResp, rr.Body.String())
+}
+
+func TestPUTMatrixRoom_ShouldKeepAccessTokenIfOmitted(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	room := easyalert.MatrixRoom{
+		ID:            2,
+		Name:          "ops",
+		HomeserverURL: "https://matrix.example.org",
+		RoomID:        "!abc:example.org",
+		AccessToken:   "syt_secret",
+		UserID:        1,
+	}
+
+	roomRepo := mocks.NewMockMatrixRoomRepository(mockCtrl)
+	roomRepo.EXPECT().FindMatrixRoom("WHERE id = $1 AND user_id = $2", uint64(2), uint(1)).Return(room, nil)
+	roomRepo.EXPECT().FindMatrixRoom("WHERE user_id = $1 AND name = $2", uint(1), "ops").Return(room, nil)
+
+	updated := room
+	updated.RoomID = "!def:example.org"
+
+	roomRepo.EXPECT().UpdateMatrixRoom(updated).Return(updated, nil)
+
+	payload := `{"name": "ops", "homeserver_url": "https://matrix.example.org", "room_id": "!def:example.org"}`
+
+	req, err := http.NewRequest("PUT", "/api/matrix-rooms/2", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+	req = mux.SetURLVars(req, map[string]string{"id": "2"})
+
+	rr := httptest.NewRecorder()
+	handler := api.UpdateMatrixRoomHandler{UserRepo: userRepo, RoomRepo: roomRepo}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.NotContains(t, rr.Body.String(), "syt_secret")
+}
@@ -0,0 +1,15 @@
+package api
+
+import (
+	"net/http"
+)
+
+// OpenAPIHandler returns the OpenAPI document of the API.
+type OpenAPIHandler struct{}
+
+// ServeHTTP handles the HTTP request.
+func (h OpenAPIHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(openAPISpec))
+}
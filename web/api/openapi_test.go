@@ -0,0 +1,389 @@
+package api_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/mocks"
+	"github.com/bakku/easyalert/web/api"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+// loadOpenAPI returns the OpenAPI document served by the API.
+func loadOpenAPI(t *testing.T) map[string]interface{} {
+	req, err := http.NewRequest("GET", "/api/openapi.json", nil)
+	require.Nil(t, err)
+
+	rr := httptest.NewRecorder()
+	api.OpenAPIHandler{}.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var spec map[string]interface{}
+
+	err = json.Unmarshal(rr.Body.Bytes(), &spec)
+	require.Nil(t, err)
+
+	return spec
+}
+
+// requireMatchesSpec checks that the recorded response is documented for the
+// operation and that its body matches the documented schema.
+func requireMatchesSpec(t *testing.T, spec map[string]interface{}, method, path string, rr *httptest.ResponseRecorder) {
+	operation, ok := lookup(spec, "paths", path, strings.ToLower(method)).(map[string]interface{})
+	require.True(t, ok, "%s %s is not documented", method, path)
+
+	response, ok := lookup(operation, "responses", strconv.Itoa(rr.Code)).(map[string]interface{})
+	require.True(t, ok, "status %d of %s %s is not documented", rr.Code, method, path)
+
+	response = resolve(spec, response)
+
+	content, ok := response["content"].(map[string]interface{})
+	if !ok {
+		require.Empty(t, rr.Body.String(), "%s %s should not return a body", method, path)
+		return
+	}
+
+	mediaType, _, err := mime.ParseMediaType(rr.Header().Get("Content-Type"))
+	require.Nil(t, err)
+
+	schema, ok := lookup(content, mediaType, "schema").(map[string]interface{})
+	require.True(t, ok, "%s is not documented for status %d of %s %s", mediaType, rr.Code, method, path)
+
+	var body interface{}
+
+	err = json.Unmarshal(rr.Body.Bytes(), &body)
+	require.Nil(t, err)
+
+	errs := validateSchema(spec, schema, body, "body")
+	require.Empty(t, errs, "response of %s %s does not match the schema", method, path)
+}
+
+func lookup(v interface{}, keys ...string) interface{} {
+	for _, key := range keys {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+
+		v = m[key]
+	}
+
+	return v
+}
+
+// resolve follows local references like #/components/schemas/Alert.
+func resolve(spec, v map[string]interface{}) map[string]interface{} {
+	ref, ok := v["$ref"].(string)
+	if !ok {
+		return v
+	}
+
+	resolved, _ := lookup(spec, strings.Split(strings.TrimPrefix(ref, "#/"), "/")...).(map[string]interface{})
+
+	return resolve(spec, resolved)
+}
+
+// validateSchema validates value against the subset of JSON schema used by
+// the OpenAPI document and returns all violations.
+func validateSchema(spec, schema map[string]interface{}, value interface{}, at string) []string {
+	schema = resolve(spec, schema)
+	if schema == nil {
+		return []string{at + ": unresolvable schema"}
+	}
+
+	var errs []string
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+
+		for _, e := range enum {
+			if e == value {
+				found = true
+			}
+		}
+
+		if !found {
+			errs = append(errs, fmt.Sprintf("%s: %v is not one of %v", at, value, enum))
+		}
+	}
+
+	switch schema["type"] {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return append(errs, at+": expected object")
+		}
+
+		required, _ := schema["required"].([]interface{})
+		for _, name := range required {
+			if _, ok := obj[name.(string)]; !ok {
+				errs = append(errs, fmt.Sprintf("%s: missing property %s", at, name))
+			}
+		}
+
+		properties, _ := schema["properties"].(map[string]interface{})
+
+		names := make([]string, 0, len(obj))
+		for name := range obj {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if property, ok := properties[name].(map[string]interface{}); ok {
+				errs = append(errs, validateSchema(spec, property, obj[name], at+"."+name)...)
+				continue
+			}
+
+			switch additional := schema["additionalProperties"].(type) {
+			case bool:
+				if !additional {
+					errs = append(errs, fmt.Sprintf("%s: unknown property %s", at, name))
+				}
+			case map[string]interface{}:
+				errs = append(errs, validateSchema(spec, additional, obj[name], at+"."+name)...)
+			}
+		}
+	case "array":
+		arr, ok := value.([]interface{})
+		if !ok {
+			return append(errs, at+": expected array")
+		}
+
+		items, _ := schema["items"].(map[string]interface{})
+		for i, item := range arr {
+			errs = append(errs, validateSchema(spec, items, item, fmt.Sprintf("%s[%d]", at, i))...)
+		}
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return append(errs, at+": expected string")
+		}
+
+		if schema["format"] == "date-time" {
+			if _, err := time.Parse(time.RFC3339, s); err != nil {
+				errs = append(errs, at+": expected RFC 3339 timestamp")
+			}
+		}
+
+		if pattern, ok := schema["pattern"].(string); ok && !regexp.MustCompile(pattern).MatchString(s) {
+			errs = append(errs, fmt.Sprintf("%s: does not match %s", at, pattern))
+		}
+	case "integer":
+		n, ok := value.(float64)
+		if !ok || n != float64(int64(n)) {
+			return append(errs, at+": expected integer")
+		}
+
+		if min, ok := schema["minimum"].(float64); ok && n < min {
+			errs = append(errs, fmt.Sprintf("%s: must be at least %v", at, min))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			errs = append(errs, at+": expected boolean")
+		}
+	}
+
+	return errs
+}
+
+func TestOpenAPI_ShouldBeValidDocument(t *testing.T) {
+	spec := loadOpenAPI(t)
+
+	require.Equal(t, "3.0.3", spec["openapi"])
+
+	// every reference has to point to an existing component
+	refs := regexp.MustCompile(`"\$ref":\s*"([^"]+)"`).FindAllStringSubmatch(openAPIJSON(t), -1)
+	require.NotEmpty(t, refs)
+
+	for _, ref := range refs {
+		require.NotNil(t, lookup(spec, strings.Split(strings.TrimPrefix(ref[1], "#/"), "/")...), ref[1])
+	}
+}
+
+func openAPIJSON(t *testing.T) string {
+	req, err := http.NewRequest("GET", "/api/openapi.json", nil)
+	require.Nil(t, err)
+
+	rr := httptest.NewRecorder()
+	api.OpenAPIHandler{}.ServeHTTP(rr, req)
+
+	return rr.Body.String()
+}
+
+func TestOpenAPI_HomeMatchesSchema(t *testing.T) {
+	spec := loadOpenAPI(t)
+
+	req, err := http.NewRequest("GET", "/api", nil)
+	require.Nil(t, err)
+
+	rr := httptest.NewRecorder()
+	api.HomeHandler{}.ServeHTTP(rr, req)
+
+	requireMatchesSpec(t, spec, "GET", "/api", rr)
+}
+
+func TestOpenAPI_ProblemsMatchSchema(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	spec := loadOpenAPI(t)
+
+	req, err := http.NewRequest("POST", "/api/alerts", strings.NewReader(`{"subject": "Hi"}`))
+	require.Nil(t, err)
+
+	rr := httptest.NewRecorder()
+	api.RequestID(api.CreateAlertsHandler{}).ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+	requireMatchesSpec(t, spec, "POST", "/api/alerts", rr)
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	req, err = http.NewRequest("POST", "/api/alerts", strings.NewReader(`{"subject": "Hi"}`))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr = httptest.NewRecorder()
+	api.RequestID(api.CreateAlertsHandler{UserRepo: userRepo}).ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	requireMatchesSpec(t, spec, "POST", "/api/alerts", rr)
+}
+
+func TestOpenAPI_AlertsMatchSchema(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	spec := loadOpenAPI(t)
+
+	now := time.Date(2019, 1, 25, 10, 0, 0, 0, time.UTC)
+	alert := easyalert.Alert{
+		ID:          3,
+		Subject:     "Backup failed",
+		Status:      easyalert.AlertStatusSent,
+		Severity:    easyalert.AlertSeverityCritical,
+		Labels:      map[string]string{"env": "prod"},
+		DedupKey:    "backup",
+		Occurrences: 2,
+		SentAt:      &now,
+		LastSeenAt:  now,
+		CreatedAt:   now,
+	}
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil).AnyTimes()
+
+	alertRepo := mocks.NewMockAlertRepository(mockCtrl)
+	alertRepo.EXPECT().FindAlerts(gomock.Any(), gomock.Any()).Return([]easyalert.Alert{alert}, nil)
+	alertRepo.EXPECT().FindAlert(gomock.Any(), gomock.Any()).Return(alert, nil)
+	alertRepo.EXPECT().CreateAlert(gomock.Any()).Return(alert, nil)
+	alertRepo.EXPECT().CreateAlerts(gomock.Any()).Return([]easyalert.Alert{alert}, nil)
+
+	notifier := mocks.NewMockNotifier(mockCtrl)
+
+	req, err := http.NewRequest("GET", "/api/alerts", nil)
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	api.GetAlertsHandler{UserRepo: userRepo, AlertRepo: alertRepo}.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	requireMatchesSpec(t, spec, "GET", "/api/alerts", rr)
+
+	req, err = http.NewRequest("GET", "/api/alerts/3", nil)
+	require.Nil(t, err)
+
+	req = mux.SetURLVars(req, map[string]string{"id": "3"})
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr = httptest.NewRecorder()
+	api.GetAlertHandler{UserRepo: userRepo, AlertRepo: alertRepo}.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	requireMatchesSpec(t, spec, "GET", "/api/alerts/{id}", rr)
+
+	req, err = http.NewRequest("POST", "/api/alerts", strings.NewReader(`{"subject": "Backup failed", "message": "Disk full"}`))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr = httptest.NewRecorder()
+	api.CreateAlertsHandler{UserRepo: userRepo, AlertRepo: alertRepo, Notifier: notifier}.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusCreated, rr.Code)
+	requireMatchesSpec(t, spec, "POST", "/api/alerts", rr)
+
+	req, err = http.NewRequest("POST", "/api/alerts/batch?mode=partial", strings.NewReader(`[{"subject": "Hi"}, {"subject": "Backup failed", "message": "Disk full"}]`))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr = httptest.NewRecorder()
+	api.CreateAlertsBatchHandler{UserRepo: userRepo, AlertRepo: alertRepo, Notifier: notifier}.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusMultiStatus, rr.Code)
+	requireMatchesSpec(t, spec, "POST", "/api/alerts/batch", rr)
+}
+
+func TestOpenAPI_HeartbeatsAndTemplatesMatchSchema(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	spec := loadOpenAPI(t)
+
+	now := time.Date(2019, 1, 25, 10, 0, 0, 0, time.UTC)
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil).AnyTimes()
+
+	heartbeatRepo := mocks.NewMockHeartbeatRepository(mockCtrl)
+	heartbeatRepo.EXPECT().FindHeartbeats(gomock.Any(), gomock.Any()).Return([]easyalert.Heartbeat{
+		{ID: 1, Name: "backup", Schedule: "0 2 * * *", Timezone: "Europe/Berlin", LastPingAt: &now, CreatedAt: now},
+		{ID: 2, Name: "cleanup", Interval: time.Hour, CreatedAt: now},
+	}, nil)
+
+	templateRepo := mocks.NewMockTemplateRepository(mockCtrl)
+	templateRepo.EXPECT().FindTemplates(gomock.Any(), gomock.Any()).Return([]easyalert.Template{
+		{ID: 1, Name: "backup", Subject: "Backup of {{.host}} failed", Body: "{{.log}}", Format: "text", CreatedAt: now, UpdatedAt: now},
+	}, nil)
+
+	req, err := http.NewRequest("GET", "/api/heartbeats", nil)
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	api.GetHeartbeatsHandler{UserRepo: userRepo, HeartbeatRepo: heartbeatRepo}.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	requireMatchesSpec(t, spec, "GET", "/api/heartbeats", rr)
+
+	req, err = http.NewRequest("GET", "/api/templates", nil)
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr = httptest.NewRecorder()
+	api.GetTemplatesHandler{UserRepo: userRepo, TemplateRepo: templateRepo}.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	requireMatchesSpec(t, spec, "GET", "/api/templates", rr)
+}
@@ -0,0 +1,228 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/random"
+)
+
+type phoneResponseBody struct {
+	PhoneNumber   string `json:"phone_number"`
+	Verified      bool   `json:"verified"`
+	CodeExpiresAt string `json:"code_expires_at,omitempty"`
+}
+
+func convertPhoneToResponseBody(user easyalert.User) phoneResponseBody {
+	body := phoneResponseBody{
+		PhoneNumber: user.PhoneNumber,
+		Verified:    user.PhoneVerified,
+	}
+
+	if user.PhoneCodeExpiresAt != nil {
+		body.CodeExpiresAt = user.PhoneCodeExpiresAt.Format(time.RFC3339)
+	}
+
+	return body
+}
+
+type updatePhoneRequestBody struct {
+	PhoneNumber string `json:"phone_number"`
+}
+
+type verifyPhoneRequestBody struct {
+	Code string `json:"code"`
+}
+
+// GetPhoneHandler should return the phone number of the user.
+type GetPhoneHandler struct {
+	UserRepo easyalert.UserRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h GetPhoneHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, convertPhoneToResponseBody(user))
+}
+
+// UpdatePhoneHandler should accept a JSON object with a phone number and
+// send a code verifying it.
+type UpdatePhoneHandler struct {
+	UserRepo easyalert.UserRepository
+	Sender   easyalert.SMSSender
+}
+
+// ServeHTTP handles the HTTP request.
+func (h UpdatePhoneHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	if h.Sender == nil {
+		writeError(w, http.StatusServiceUnavailable, "sms_unavailable", "This server is not configured to send text messages.")
+		return
+	}
+
+	bytes, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not read http body")
+		return
+	}
+
+	var body updatePhoneRequestBody
+
+	err = json.Unmarshal(bytes, &body)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "invalid_json", "invalid json")
+		return
+	}
+
+	if body.PhoneNumber == "" {
+		writeError(w, http.StatusUnprocessableEntity, "missing_field", "Phone number must be given.")
+		return
+	}
+
+	if !easyalert.ValidPhoneNumber(body.PhoneNumber) {
+		writeAPIError(w, invalidField("phone_number", "invalid_phone_number", "Phone number must be in the E.164 format like +4915112345678."))
+		return
+	}
+
+	if user.PhoneVerified && user.PhoneNumber == body.PhoneNumber {
+		writeJSON(w, http.StatusOK, convertPhoneToResponseBody(user))
+		return
+	}
+
+	now := time.Now().UTC()
+
+	if user.PhoneCodeSentRecently(now) {
+		writeError(w, http.StatusTooManyRequests, "code_recently_sent", "A code was sent less than a minute ago.")
+		return
+	}
+
+	if user.PhoneCodeLimitReached(now) {
+		writeError(w, http.StatusTooManyRequests, "code_limit_reached", "No more than 5 codes can be sent per day.")
+		return
+	}
+
+	code, err := random.Digits(easyalert.PhoneCodeLength)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not generate code")
+		return
+	}
+
+	err = user.SetPhoneNumber(body.PhoneNumber, code, now.Add(easyalert.PhoneCodeValidity))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not hash code")
+		return
+	}
+
+	// the code is saved first, so that a code is never sent which can not
+	// be verified and every sent code counts towards the limit. The limits
+	// are checked again while saving, in case another request sent a code
+	// in the meantime.
+	user, err = h.UserRepo.SavePhoneCode(user, now)
+	if err == easyalert.ErrPhoneCodeLimit {
+		writeError(w, http.StatusTooManyRequests, "code_recently_sent", "A code was sent less than a minute ago.")
+		return
+	}
+
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not update user")
+		return
+	}
+
+	err = h.Sender.SendSMS(user.PhoneNumber, "Your easyalert verification code is "+code)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, "sms_failed", "The code could not be sent to the phone number.")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, convertPhoneToResponseBody(user))
+}
+
+// VerifyPhoneHandler should accept a JSON object with the code sent to the
+// phone number of the user and verify the phone number with it.
+type VerifyPhoneHandler struct {
+	UserRepo easyalert.UserRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h VerifyPhoneHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	bytes, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not read http body")
+		return
+	}
+
+	var body verifyPhoneRequestBody
+
+	err = json.Unmarshal(bytes, &body)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "invalid_json", "invalid json")
+		return
+	}
+
+	if body.Code == "" {
+		writeError(w, http.StatusUnprocessableEntity, "missing_field", "Code must be given.")
+		return
+	}
+
+	now := time.Now().UTC()
+
+	// the attempt is counted before the code is compared, so that
+	// concurrent requests cannot guess more often
+	user, err = h.UserRepo.CountPhoneCodeAttempt(user, now)
+	if err != nil && err != easyalert.ErrPhoneCodeLimit {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not update user")
+		return
+	}
+
+	if err == easyalert.ErrPhoneCodeLimit || !user.VerifyPhoneNumber(body.Code, now) {
+		writeAPIError(w, invalidField("code", "invalid_code", "Code is wrong or expired. A new code must be requested after 5 wrong attempts."))
+		return
+	}
+
+	user, err = h.UserRepo.UpdateUser(user)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not update user")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, convertPhoneToResponseBody(user))
+}
+
+// DeletePhoneHandler should remove the phone number of the user.
+type DeletePhoneHandler struct {
+	UserRepo easyalert.UserRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h DeletePhoneHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	user.RemovePhoneNumber()
+
+	_, err := h.UserRepo.UpdateUser(user)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not update user")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
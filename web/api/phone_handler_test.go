@@ -0,0 +1,264 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/mocks"
+	"github.com/bakku/easyalert/web/api"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPUTPhone_ShouldReturnErrorIfPhoneNumberIsInvalid(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	req, err := http.NewRequest("PUT", "/api/users/me/phone", strings.NewReader(`{"phone_number": "0151 12345678"}`))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.UpdatePhoneHandler{UserRepo: userRepo, Sender: mocks.NewMockSMSSender(mockCtrl)}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+
+	p := requireProblem(t, rr, "invalid_phone_number", "Phone number must be in the E.164 format like +4915112345678.")
+	require.Equal(t, "phone_number", p.Errors[0].Field)
+}
+
+func TestPUTPhone_ShouldReturnErrorIfSMSIsNotConfigured(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	req, err := http.NewRequest("PUT", "/api/users/me/phone", strings.NewReader(`{"phone_number": "+4915112345678"}`))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.UpdatePhoneHandler{UserRepo: userRepo}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	requireProblem(t, rr, "sms_unavailable", "This server is not configured to send text messages.")
+}
+
+func TestPUTPhone_ShouldSendVerificationCode(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	var (
+		text    string
+		updated easyalert.User
+	)
+
+	sender := mocks.NewMockSMSSender(mockCtrl)
+
+	gomock.InOrder(
+		userRepo.EXPECT().SavePhoneCode(gomock.Any(), gomock.Any()).DoAndReturn(func(user easyalert.User, now time.Time) (easyalert.User, error) {
+			user.PhoneCodesSent = 1
+			updated = user
+			return user, nil
+		}),
+		sender.EXPECT().SendSMS("+4915112345678", gomock.Any()).DoAndReturn(func(to, t string) error {
+			text = t
+			return nil
+		}),
+	)
+
+	req, err := http.NewRequest("PUT", "/api/users/me/phone", strings.NewReader(`{"phone_number": "+4915112345678"}`))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.UpdatePhoneHandler{UserRepo: userRepo, Sender: sender}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	requireMatchesSpec(t, loadOpenAPI(t), "PUT", "/api/users/me/phone", rr)
+
+	require.Regexp(t, "^Your easyalert verification code is [0-9]{6}$", text)
+	require.Equal(t, "+4915112345678", updated.PhoneNumber)
+	require.False(t, updated.PhoneVerified)
+	require.Equal(t, uint(1), updated.PhoneCodesSent)
+
+	code := text[len(text)-6:]
+	require.True(t, updated.VerifyPhoneNumber(code, time.Now()))
+}
+
+func TestPUTPhone_ShouldReturnErrorIfCodeWasSentRecently(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	expiresAt := time.Now().Add(easyalert.PhoneCodeValidity - 10*time.Second)
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1, PhoneNumber: "+4915112345678", PhoneCodeExpiresAt: &expiresAt}, nil)
+
+	req, err := http.NewRequest("PUT", "/api/users/me/phone", strings.NewReader(`{"phone_number": "+4915112345679"}`))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.UpdatePhoneHandler{UserRepo: userRepo, Sender: mocks.NewMockSMSSender(mockCtrl)}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusTooManyRequests, rr.Code)
+	requireProblem(t, rr, "code_recently_sent", "A code was sent less than a minute ago.")
+}
+
+func TestPUTPhone_ShouldNotSendCodeIfAnotherRequestSentOne(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+	userRepo.EXPECT().SavePhoneCode(gomock.Any(), gomock.Any()).Return(easyalert.User{}, easyalert.ErrPhoneCodeLimit)
+
+	req, err := http.NewRequest("PUT", "/api/users/me/phone", strings.NewReader(`{"phone_number": "+4915112345678"}`))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.UpdatePhoneHandler{UserRepo: userRepo, Sender: mocks.NewMockSMSSender(mockCtrl)}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusTooManyRequests, rr.Code)
+	requireProblem(t, rr, "code_recently_sent", "A code was sent less than a minute ago.")
+}
+
+func TestPUTPhone_ShouldReturnErrorIfDailyCodeLimitIsReached(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	sentSince := time.Now().Add(-time.Hour)
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1, PhoneCodesSent: easyalert.MaxPhoneCodesPerDay, PhoneCodesSentSince: &sentSince}, nil)
+
+	req, err := http.NewRequest("PUT", "/api/users/me/phone", strings.NewReader(`{"phone_number": "+4915112345678"}`))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.UpdatePhoneHandler{UserRepo: userRepo, Sender: mocks.NewMockSMSSender(mockCtrl)}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusTooManyRequests, rr.Code)
+	requireProblem(t, rr, "code_limit_reached", "No more than 5 codes can be sent per day.")
+}
+
+func TestPOSTVerifyPhone_ShouldCountWrongCodes(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	user := easyalert.User{ID: 1}
+	err := user.SetPhoneNumber("+4915112345678", "123456", time.Now().Add(time.Minute))
+	require.Nil(t, err)
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(user, nil)
+	userRepo.EXPECT().CountPhoneCodeAttempt(user, gomock.Any()).DoAndReturn(func(user easyalert.User, now time.Time) (easyalert.User, error) {
+		user.PhoneCodeAttempts++
+		return user, nil
+	})
+
+	req, err := http.NewRequest("POST", "/api/users/me/phone/verify", strings.NewReader(`{"code": "654321"}`))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.VerifyPhoneHandler{UserRepo: userRepo}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+
+	p := requireProblem(t, rr, "invalid_code", "Code is wrong or expired. A new code must be requested after 5 wrong attempts.")
+	require.Equal(t, "code", p.Errors[0].Field)
+}
+
+func TestPOSTVerifyPhone_ShouldRejectCodeWithoutAttemptsLeft(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	user := easyalert.User{ID: 1}
+	err := user.SetPhoneNumber("+4915112345678", "123456", time.Now().Add(time.Minute))
+	require.Nil(t, err)
+
+	// another request made the last attempt in the meantime
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(user, nil)
+	userRepo.EXPECT().CountPhoneCodeAttempt(user, gomock.Any()).Return(easyalert.User{}, easyalert.ErrPhoneCodeLimit)
+
+	req, err := http.NewRequest("POST", "/api/users/me/phone/verify", strings.NewReader(`{"code": "123456"}`))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.VerifyPhoneHandler{UserRepo: userRepo}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	requireProblem(t, rr, "invalid_code", "Code is wrong or expired. A new code must be requested after 5 wrong attempts.")
+}
+
+func TestPOSTVerifyPhone_ShouldVerifyPhoneNumber(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	user := easyalert.User{ID: 1}
+	err := user.SetPhoneNumber("+4915112345678", "123456", time.Now().Add(time.Minute))
+	require.Nil(t, err)
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(user, nil)
+	userRepo.EXPECT().CountPhoneCodeAttempt(user, gomock.Any()).DoAndReturn(func(user easyalert.User, now time.Time) (easyalert.User, error) {
+		user.PhoneCodeAttempts++
+		return user, nil
+	})
+	userRepo.EXPECT().UpdateUser(gomock.Any()).DoAndReturn(func(user easyalert.User) (easyalert.User, error) {
+		require.True(t, user.PhoneVerified)
+		return user, nil
+	})
+
+	req, err := http.NewRequest("POST", "/api/users/me/phone/verify", strings.NewReader(`{"code": "123456"}`))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.VerifyPhoneHandler{UserRepo: userRepo}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	requireMatchesSpec(t, loadOpenAPI(t), "POST", "/api/users/me/phone/verify", rr)
+
+	expectedJsonResp := "{\n" +
+		"  \"phone_number\": \"+4915112345678\",\n" +
+		"  \"verified\": true\n" +
+		"}"
+
+	require.Equal(t, expectedJsonResp, rr.Body.String())
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// problemContentType is the media type of error responses as defined by RFC 7807.
+const problemContentType = "application/problem+json; charset=UTF-8"
+
+// apiError is an error which knows how it is presented to the client. Code is
+// a stable identifier clients can rely on, while Message is meant for humans
+// and may change at any time.
+type apiError struct {
+	Status  int
+	Code    string
+	Message string
+	Fields  []fieldError
+}
+
+func (e apiError) Error() string {
+	return e.Message
+}
+
+// fieldError describes why a single field of the request is invalid.
+type fieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type problemResponseBody struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail"`
+	Code      string       `json:"code"`
+	Errors    []fieldError `json:"errors,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+}
+
+// invalidField returns a validation error for a single field of the request.
+func invalidField(field, code, message string) apiError {
+	return apiError{
+		Status:  http.StatusUnprocessableEntity,
+		Code:    code,
+		Message: message,
+		Fields:  []fieldError{{field, code, message}},
+	}
+}
+
+// invalidRequest returns a validation error which does not belong to a single field.
+func invalidRequest(code, message string) apiError {
+	return apiError{Status: http.StatusUnprocessableEntity, Code: code, Message: message}
+}
+
+// writeError writes an error response with the given status and error code.
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeAPIError(w, apiError{Status: status, Code: code, Message: message})
+}
+
+// writeAPIError writes err as problem details. Errors which are not an
+// apiError are not shown to the client as they might contain internals.
+func writeAPIError(w http.ResponseWriter, err error) {
+	e, ok := err.(apiError)
+	if !ok {
+		e = apiError{Status: http.StatusInternalServerError, Code: "internal_error", Message: "an unknown error occured"}
+	}
+
+	problem := problemResponseBody{
+		Type:      "about:blank",
+		Title:     http.StatusText(e.Status),
+		Status:    e.Status,
+		Detail:    e.Message,
+		Code:      e.Code,
+		Errors:    e.Fields,
+		RequestID: w.Header().Get(requestIDHeader),
+	}
+
+	// marshalling can not fail for the problem, so no error has to be written
+	bytes, _ := json.Marshal(problem)
+	body, _ := prettifyJSON(string(bytes))
+
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(e.Status)
+	w.Write([]byte(body))
+}
+
+// NotFoundHandler answers requests to unknown routes with problem details.
+type NotFoundHandler struct{}
+
+// ServeHTTP handles the HTTP request.
+func (h NotFoundHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	writeError(w, http.StatusNotFound, "route_not_found", "Route not found.")
+}
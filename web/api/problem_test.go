@@ -0,0 +1,97 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bakku/easyalert/web/api"
+	"github.com/stretchr/testify/require"
+)
+
+type problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail"`
+	Code      string `json:"code"`
+	RequestID string `json:"request_id"`
+	Errors    []struct {
+		Field   string `json:"field"`
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// requireProblem checks that the response contains problem details with the
+// given error code and detail and returns them for further checks.
+func requireProblem(t *testing.T, rr *httptest.ResponseRecorder, code, detail string) problem {
+	require.Equal(t, "application/problem+json; charset=UTF-8", rr.Header().Get("Content-Type"))
+
+	var p problem
+
+	err := json.Unmarshal(rr.Body.Bytes(), &p)
+	require.Nil(t, err)
+
+	require.Equal(t, "about:blank", p.Type)
+	require.Equal(t, http.StatusText(rr.Code), p.Title)
+	require.Equal(t, rr.Code, p.Status)
+	require.Equal(t, code, p.Code)
+	require.Equal(t, detail, p.Detail)
+
+	return p
+}
+
+func TestRequestID_ShouldGenerateRequestID(t *testing.T) {
+	req, err := http.NewRequest("GET", "/api/alerts", nil)
+	require.Nil(t, err)
+
+	rr := httptest.NewRecorder()
+	handler := api.RequestID(api.GetAlertsHandler{})
+	handler.ServeHTTP(rr, req)
+
+	id := rr.Header().Get("X-Request-ID")
+	require.Len(t, id, 20)
+
+	p := requireProblem(t, rr, "missing_token", "Missing or invalid Authorization header.")
+	require.Equal(t, id, p.RequestID)
+}
+
+func TestRequestID_ShouldKeepRequestIDOfClient(t *testing.T) {
+	req, err := http.NewRequest("GET", "/api/alerts", nil)
+	require.Nil(t, err)
+
+	req.Header.Set("X-Request-ID", "trace-123")
+
+	rr := httptest.NewRecorder()
+	handler := api.RequestID(api.GetAlertsHandler{})
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, "trace-123", rr.Header().Get("X-Request-ID"))
+}
+
+func TestRequestID_ShouldReplaceInvalidRequestID(t *testing.T) {
+	req, err := http.NewRequest("GET", "/api/alerts", nil)
+	require.Nil(t, err)
+
+	req.Header.Set("X-Request-ID", "<script>")
+
+	rr := httptest.NewRecorder()
+	handler := api.RequestID(api.GetAlertsHandler{})
+	handler.ServeHTTP(rr, req)
+
+	require.Len(t, rr.Header().Get("X-Request-ID"), 20)
+}
+
+func TestNotFoundHandler_ShouldReturnProblem(t *testing.T) {
+	req, err := http.NewRequest("GET", "/api/unknown", nil)
+	require.Nil(t, err)
+
+	rr := httptest.NewRecorder()
+	handler := api.NotFoundHandler{}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusNotFound, rr.Code)
+	requireProblem(t, rr, "route_not_found", "Route not found.")
+}
@@ -0,0 +1,268 @@
+package api
+
+import (
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/push"
+)
+
+// The token is never returned since it grants access to the ntfy topic or
+// Gotify application, only whether one is set.
+type pushChannelResponseBody struct {
+	ID        uint   `json:"id"`
+	Name      string `json:"name"`
+	Kind      string `json:"kind"`
+	ServerURL string `json:"server_url"`
+	Topic     string `json:"topic,omitempty"`
+	HasToken  bool   `json:"has_token"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+func convertPushChannelToResponseBody(channel easyalert.PushChannel) pushChannelResponseBody {
+	return pushChannelResponseBody{
+		ID:        channel.ID,
+		Name:      channel.Name,
+		Kind:      channel.Kind,
+		ServerURL: channel.ServerURL,
+		Topic:     channel.Topic,
+		HasToken:  channel.Token != "",
+		CreatedAt: channel.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: channel.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+type pushChannelRequestBody struct {
+	Name      string  `json:"name"`
+	Kind      string  `json:"kind"`
+	ServerURL string  `json:"server_url"`
+	Topic     string  `json:"topic"`
+	Token     *string `json:"token"`
+}
+
+// ntfyTopicPattern matches the topic names ntfy accepts.
+var ntfyTopicPattern = regexp.MustCompile(`^[-_A-Za-z0-9]{1,64}$`)
+
+// GetPushChannelsHandler should return all push channels of the user.
+type GetPushChannelsHandler struct {
+	UserRepo    easyalert.UserRepository
+	ChannelRepo easyalert.PushChannelRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h GetPushChannelsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	channels, err := h.ChannelRepo.FindPushChannels("WHERE user_id = $1 ORDER BY name", user.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not fetch push channels")
+		return
+	}
+
+	responseBody := make([]pushChannelResponseBody, len(channels))
+	for i, channel := range channels {
+		responseBody[i] = convertPushChannelToResponseBody(channel)
+	}
+
+	writeJSON(w, http.StatusOK, responseBody)
+}
+
+// CreatePushChannelsHandler should accept a JSON object and create a push channel from it.
+type CreatePushChannelsHandler struct {
+	UserRepo    easyalert.UserRepository
+	ChannelRepo easyalert.PushChannelRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h CreatePushChannelsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	channel, ok := readPushChannel(w, r, h.ChannelRepo, user, easyalert.PushChannel{UserID: user.ID})
+	if !ok {
+		return
+	}
+
+	channel, err := h.ChannelRepo.CreatePushChannel(channel)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not create push channel")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, convertPushChannelToResponseBody(channel))
+}
+
+// GetPushChannelHandler should return a single push channel of the user.
+type GetPushChannelHandler struct {
+	UserRepo    easyalert.UserRepository
+	ChannelRepo easyalert.PushChannelRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h GetPushChannelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	channel, ok := findUserPushChannel(w, r, h.ChannelRepo, user)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, convertPushChannelToResponseBody(channel))
+}
+
+// UpdatePushChannelHandler should accept a JSON object and replace a push channel of the user with it.
+type UpdatePushChannelHandler struct {
+	UserRepo    easyalert.UserRepository
+	ChannelRepo easyalert.PushChannelRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h UpdatePushChannelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	channel, ok := findUserPushChannel(w, r, h.ChannelRepo, user)
+	if !ok {
+		return
+	}
+
+	channel, ok = readPushChannel(w, r, h.ChannelRepo, user, channel)
+	if !ok {
+		return
+	}
+
+	channel, err := h.ChannelRepo.UpdatePushChannel(channel)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not update push channel")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, convertPushChannelToResponseBody(channel))
+}
+
+// DeletePushChannelHandler should delete a push channel of the user.
+type DeletePushChannelHandler struct {
+	UserRepo    easyalert.UserRepository
+	ChannelRepo easyalert.PushChannelRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h DeletePushChannelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	channel, ok := findUserPushChannel(w, r, h.ChannelRepo, user)
+	if !ok {
+		return
+	}
+
+	err := h.ChannelRepo.DeletePushChannel(channel)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not delete push channel")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// readPushChannel reads the push channel from the request body into channel
+// and validates it. If the channel is invalid an error is written and false
+// is returned.
+func readPushChannel(w http.ResponseWriter, r *http.Request, repo easyalert.PushChannelRepository, user easyalert.User, channel easyalert.PushChannel) (easyalert.PushChannel, bool) {
+	var body pushChannelRequestBody
+
+	if !readJSONBody(w, r, &body) {
+		return easyalert.PushChannel{}, false
+	}
+
+	if body.Name == "" || body.Kind == "" || body.ServerURL == "" {
+		writeError(w, http.StatusUnprocessableEntity, "missing_field", "Name, kind and server URL must be given.")
+		return easyalert.PushChannel{}, false
+	}
+
+	if !checkName(w, body.Name) {
+		return easyalert.PushChannel{}, false
+	}
+
+	if !push.ValidKind(body.Kind) {
+		writeAPIError(w, invalidField("kind", "invalid_kind", "Kind must be either ntfy or gotify."))
+		return easyalert.PushChannel{}, false
+	}
+
+	serverURL, ok := parseHTTPURL(body.ServerURL)
+	if !ok {
+		writeAPIError(w, invalidField("server_url", "invalid_url", "Server URL must be an absolute http or https URL of a public host."))
+		return easyalert.PushChannel{}, false
+	}
+
+	// an omitted token is kept unless it would be sent to another server
+	token := ""
+	if body.Token != nil {
+		token = *body.Token
+	} else if channel.Kind == body.Kind && channel.ServerURL == serverURL {
+		token = channel.Token
+	}
+
+	switch body.Kind {
+	case easyalert.PushKindNtfy:
+		if !ntfyTopicPattern.MatchString(body.Topic) {
+			writeAPIError(w, invalidField("topic", "invalid_topic", "Topic must consist of 1 to 64 letters, digits, dashes and underscores."))
+			return easyalert.PushChannel{}, false
+		}
+	case easyalert.PushKindGotify:
+		if token == "" {
+			writeAPIError(w, invalidField("token", "missing_field", "The token of a Gotify application must be given."))
+			return easyalert.PushChannel{}, false
+		}
+
+		if body.Topic != "" {
+			writeAPIError(w, invalidField("topic", "conflicting_fields", "Gotify channels do not have a topic."))
+			return easyalert.PushChannel{}, false
+		}
+	}
+
+	channel.Name = body.Name
+	channel.Kind = body.Kind
+	channel.ServerURL = serverURL
+	channel.Topic = body.Topic
+	channel.Token = token
+
+	ok = checkUniqueName(w, channel.ID, "A push channel with this name already exists.", func() (uint, error) {
+		existing, err := repo.FindPushChannel("WHERE user_id = $1 AND name = $2", user.ID, channel.Name)
+		return existing.ID, err
+	})
+	if !ok {
+		return easyalert.PushChannel{}, false
+	}
+
+	return channel, true
+}
+
+// findUserPushChannel returns the push channel given by the id route variable
+// if it belongs to the user. Otherwise an error is written and false is
+// returned.
+func findUserPushChannel(w http.ResponseWriter, r *http.Request, repo easyalert.PushChannelRepository, user easyalert.User) (easyalert.PushChannel, bool) {
+	var channel easyalert.PushChannel
+
+	found := findUserResource(w, r, "push channel", func(id uint64) (err error) {
+		channel, err = repo.FindPushChannel("WHERE id = $1 AND user_id = $2", id, user.ID)
+		return err
+	})
+
+	return channel, found
+}
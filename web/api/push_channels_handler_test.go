@@ -0,0 +1,191 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/mocks"
+	"github.com/bakku/easyalert/web/api"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPOSTPushChannels_ShouldReturnErrorIfNtfyTopicIsInvalid(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	payload := `{"name": "phone", "kind": "ntfy", "server_url": "https://ntfy.sh", "topic": "my alerts"}`
+
+	req, err := http.NewRequest("POST", "/api/push-channels", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreatePushChannelsHandler{UserRepo: userRepo}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+
+	p := requireProblem(t, rr, "invalid_topic", "Topic must consist of 1 to 64 letters, digits, dashes and underscores.")
+	require.Equal(t, "topic", p.Errors[0].Field)
+}
+
+func TestPOSTPushChannels_ShouldReturnErrorIfGotifyTokenIsMissing(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	payload := `{"name": "phone", "kind": "gotify", "server_url": "https://gotify.example.com"}`
+
+	req, err := http.NewRequest("POST", "/api/push-channels", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreatePushChannelsHandler{UserRepo: userRepo}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+
+	p := requireProblem(t, rr, "missing_field", "The token of a Gotify application must be given.")
+	require.Equal(t, "token", p.Errors[0].Field)
+}
+
+func TestPOSTPushChannels_ShouldCreatePushChannel(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	createdAt := time.Date(2019, 2, 8, 18, 0, 0, 0, time.UTC)
+
+	channelRepo := mocks.NewMockPushChannelRepository(mockCtrl)
+	channelRepo.EXPECT().FindPushChannel("WHERE user_id = $1 AND name = $2", uint(1), "phone").Return(easyalert.PushChannel{}, easyalert.ErrRecordDoesNotExist)
+	channelRepo.EXPECT().CreatePushChannel(easyalert.PushChannel{
+		Name:      "phone",
+		Kind:      "ntfy",
+		ServerURL: "https://ntfy.example.com",
+		Topic:     "alerts",
+		UserID:    1,
+	}).DoAndReturn(func(channel easyalert.PushChannel) (easyalert.PushChannel, error) {
+		channel.ID = 2
+		channel.CreatedAt = createdAt
+		channel.UpdatedAt = createdAt
+		return channel, nil
+	})
+
+	payload := `{"name": "phone", "kind": "ntfy", "server_url": "https://ntfy.example.com", "topic": "alerts"}`
+
+	req, err := http.NewRequest("POST", "/api/push-channels", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreatePushChannelsHandler{UserRepo: userRepo, ChannelRepo: channelRepo}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusCreated, rr.Code)
+	requireMatchesSpec(t, loadOpenAPI(t), "POST", "/api/push-channels", rr)
+
+	expectedJsonResp := "{\n" +
+		"  \"id\": 2,\n" +
+		"  \"name\": \"phone\",\n" +
+		"  \"kind\": \"ntfy\",\n" +
+		"  \"server_url\": \"https://ntfy.example.com\",\n" +
+		"  \"topic\": \"alerts\",\n" +
+		"  \"has_token\": false,\n" +
+		"  \"created_at\": \"2019-02-08T18:00:00Z\",\n" +
+		"  \"updated_at\": \"2019-02-08T18:00:00Z\"\n" +
+		"}"
+
+	require.Equal(t, expectedJsonResp, rr.Body.String())
+}
+
+func TestPUTPushChannel_ShouldKeepTokenIfOmittedWithoutReturningIt(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	channel := easyalert.PushChannel{
+		ID:        2,
+		Name:      "phone",
+		Kind:      "gotify",
+		ServerURL: "https://gotify.example.com",
+		Token:     "AbC.123",
+		UserID:    1,
+	}
+
+	channelRepo := mocks.NewMockPushChannelRepository(mockCtrl)
+	channelRepo.EXPECT().FindPushChannel("WHERE id = $1 AND user_id = $2", uint64(2), uint(1)).Return(channel, nil)
+	channelRepo.EXPECT().FindPushChannel("WHERE user_id = $1 AND name = $2", uint(1), "mobile").Return(easyalert.PushChannel{}, easyalert.ErrRecordDoesNotExist)
+
+	updated := channel
+	updated.Name = "mobile"
+
+	channelRepo.EXPECT().UpdatePushChannel(updated).Return(updated, nil)
+
+	payload := `{"name": "mobile", "kind": "gotify", "server_url": "https://gotify.example.com"}`
+
+	req, err := http.NewRequest("PUT", "/api/push-channels/2", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+	req = mux.SetURLVars(req, map[string]string{"id": "2"})
+
+	rr := httptest.NewRecorder()
+	handler := api.UpdatePushChannelHandler{UserRepo: userRepo, ChannelRepo: channelRepo}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), "\"has_token\": true")
+	require.NotContains(t, rr.Body.String(), "AbC.123")
+}
+
+func TestPUTPushChannel_ShouldRequireTokenIfServerChanges(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	channelRepo := mocks.NewMockPushChannelRepository(mockCtrl)
+	channelRepo.EXPECT().FindPushChannel("WHERE id = $1 AND user_id = $2", uint64(2), uint(1)).Return(easyalert.PushChannel{
+		ID:        2,
+		Name:      "phone",
+		Kind:      "gotify",
+		ServerURL: "https://gotify.example.com",
+		Token:     "AbC.123",
+		UserID:    1,
+	}, nil)
+
+	payload := `{"name": "phone", "kind": "gotify", "server_url": "https://gotify.example.org"}`
+
+	req, err := http.NewRequest("PUT", "/api/push-channels/2", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+	req = mux.SetURLVars(req, map[string]string{"id": "2"})
+
+	rr := httptest.NewRecorder()
+	handler := api.UpdatePushChannelHandler{UserRepo: userRepo, ChannelRepo: channelRepo}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	requireProblem(t, rr, "missing_field", "The token of a Gotify application must be given.")
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"net/http"
+	"regexp"
+
+	"github.com/bakku/easyalert/random"
+)
+
+const (
+	requestIDHeader = "X-Request-ID"
+	requestIDLength = 20
+)
+
+// requestIDPattern restricts request IDs given by clients so they can be
+// logged and returned without escaping.
+var requestIDPattern = regexp.MustCompile(`^[a-zA-Z0-9._-]{1,128}$`)
+
+// RequestID makes sure every response carries an X-Request-ID header, which
+// is also part of every error response. IDs sent by the client are kept so
+// that requests can be traced across services.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+
+		if !requestIDPattern.MatchString(id) {
+			var err error
+
+			id, err = random.String(requestIDLength)
+			if err != nil {
+				writeError(w, http.StatusInternalServerError, "internal_error", "could not generate request id")
+				return
+			}
+		}
+
+		w.Header().Set(requestIDHeader, id)
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,388 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/routing"
+)
+
+// maxRoutingRules limits the rules of a routing as every rule is evaluated
+// for every delivered alert.
+const maxRoutingRules = 50
+
+// allChannels is the selection shown in dry runs if all channels are used.
+var allChannels = []string{
+	easyalert.ChannelKindEmail,
+	easyalert.ChannelKindWebhook,
+	easyalert.ChannelKindChat,
+	easyalert.ChannelKindPush,
+	easyalert.ChannelKindMatrix,
+	easyalert.ChannelKindSMS,
+}
+
+type routingRuleBody struct {
+	Name     string            `json:"name"`
+	Subject  string            `json:"subject,omitempty"`
+	Severity string            `json:"severity,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	From     string            `json:"from,omitempty"`
+	Until    string            `json:"until,omitempty"`
+	Timezone string            `json:"timezone,omitempty"`
+	Channels []string          `json:"channels"`
+}
+
+type routingResponseBody struct {
+	Rules           []routingRuleBody `json:"rules"`
+	DefaultChannels []string          `json:"default_channels"`
+	CreatedAt       string            `json:"created_at,omitempty"`
+	UpdatedAt       string            `json:"updated_at,omitempty"`
+}
+
+func convertRoutingToResponseBody(r easyalert.Routing) routingResponseBody {
+	rules := make([]routingRuleBody, len(r.Rules))
+	for i, rule := range r.Rules {
+		rules[i] = routingRuleBody{
+			Name:     rule.Name,
+			Subject:  rule.Subject,
+			Severity: rule.Severity,
+			Labels:   rule.Labels,
+			From:     rule.From,
+			Until:    rule.Until,
+			Timezone: rule.Timezone,
+			Channels: rule.Channels,
+		}
+	}
+
+	responseBody := routingResponseBody{
+		Rules:           rules,
+		DefaultChannels: append([]string{}, r.DefaultChannels...),
+	}
+
+	// users without routing get the empty one which was never saved
+	if r.ID != 0 {
+		responseBody.CreatedAt = r.CreatedAt.Format(time.RFC3339)
+		responseBody.UpdatedAt = r.UpdatedAt.Format(time.RFC3339)
+	}
+
+	return responseBody
+}
+
+type routingRequestBody struct {
+	Rules           []routingRuleBody `json:"rules"`
+	DefaultChannels []string          `json:"default_channels"`
+}
+
+type dryRunRoutingRequestBody struct {
+	Subject  string            `json:"subject"`
+	Severity string            `json:"severity"`
+	Labels   map[string]string `json:"labels"`
+	Time     string            `json:"time"`
+}
+
+type routingDestinationBody struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+type dryRunRoutingResponseBody struct {
+	Rule         *string                  `json:"rule"`
+	Channels     []string                 `json:"channels"`
+	Destinations []routingDestinationBody `json:"destinations"`
+}
+
+// GetRoutingHandler should return the routing of the user.
+type GetRoutingHandler struct {
+	UserRepo    easyalert.UserRepository
+	RoutingRepo easyalert.RoutingRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h GetRoutingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	routing, ok := findUserRouting(w, h.RoutingRepo, user)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, convertRoutingToResponseBody(routing))
+}
+
+// UpdateRoutingHandler should accept a JSON object and replace the routing of the user with it.
+type UpdateRoutingHandler struct {
+	UserRepo    easyalert.UserRepository
+	RoutingRepo easyalert.RoutingRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h UpdateRoutingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	existing, ok := findUserRouting(w, h.RoutingRepo, user)
+	if !ok {
+		return
+	}
+
+	bytes, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not read http body")
+		return
+	}
+
+	var body routingRequestBody
+
+	err = json.Unmarshal(bytes, &body)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "invalid_json", "invalid json")
+		return
+	}
+
+	if len(body.Rules) > maxRoutingRules {
+		writeAPIError(w, invalidField("rules", "too_many_rules", fmt.Sprintf("At most %d rules may be given.", maxRoutingRules)))
+		return
+	}
+
+	existing.Rules = make([]easyalert.RoutingRule, len(body.Rules))
+	for i, rule := range body.Rules {
+		existing.Rules[i] = easyalert.RoutingRule{
+			Name:     rule.Name,
+			Subject:  rule.Subject,
+			Severity: rule.Severity,
+			Labels:   rule.Labels,
+			From:     rule.From,
+			Until:    rule.Until,
+			Timezone: rule.Timezone,
+			Channels: rule.Channels,
+		}
+	}
+
+	existing.DefaultChannels = body.DefaultChannels
+
+	err = routing.Validate(existing)
+	if err != nil {
+		if e, ok := err.(routing.InvalidRuleError); ok {
+			writeAPIError(w, invalidField(fmt.Sprintf("rules[%d].%s", e.Rule, e.Field), "invalid_rule", err.Error()))
+			return
+		}
+
+		writeAPIError(w, invalidField("default_channels", "invalid_channels", fmt.Sprintf("Invalid default channels: %v.", err)))
+		return
+	}
+
+	var saved easyalert.Routing
+
+	if existing.ID == 0 {
+		existing.UserID = user.ID
+		saved, err = h.RoutingRepo.CreateRouting(existing)
+	} else {
+		saved, err = h.RoutingRepo.UpdateRouting(existing)
+	}
+
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not save routing")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, convertRoutingToResponseBody(saved))
+}
+
+// DeleteRoutingHandler should delete the routing of the user, so that
+// alerts are delivered through all channels again.
+type DeleteRoutingHandler struct {
+	UserRepo    easyalert.UserRepository
+	RoutingRepo easyalert.RoutingRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h DeleteRoutingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	routing, ok := findUserRouting(w, h.RoutingRepo, user)
+	if !ok {
+		return
+	}
+
+	if routing.ID == 0 {
+		writeError(w, http.StatusNotFound, "not_found", "Routing not found.")
+		return
+	}
+
+	err := h.RoutingRepo.DeleteRouting(routing)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not delete routing")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DryRunRoutingHandler should accept a sample alert and return the rule
+// and the channels the routing of the user selects for it, without
+// delivering anything.
+type DryRunRoutingHandler struct {
+	UserRepo            easyalert.UserRepository
+	RoutingRepo         easyalert.RoutingRepository
+	WebhookEndpointRepo easyalert.WebhookEndpointRepository
+	ChatChannelRepo     easyalert.ChatChannelRepository
+	PushChannelRepo     easyalert.PushChannelRepository
+	MatrixRoomRepo      easyalert.MatrixRoomRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h DryRunRoutingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	bytes, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not read http body")
+		return
+	}
+
+	var body dryRunRoutingRequestBody
+
+	err = json.Unmarshal(bytes, &body)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "invalid_json", "invalid json")
+		return
+	}
+
+	if body.Subject == "" {
+		writeAPIError(w, invalidField("subject", "missing_field", "Subject must be given."))
+		return
+	}
+
+	severity, err := easyalert.ParseSeverity(body.Severity)
+	if err != nil {
+		writeAPIError(w, invalidField("severity", "invalid_severity", "Invalid severity."))
+		return
+	}
+
+	now := time.Now()
+
+	if body.Time != "" {
+		now, err = time.Parse(time.RFC3339, body.Time)
+		if err != nil {
+			writeAPIError(w, invalidField("time", "invalid_time", "Time must be given in RFC 3339 format."))
+			return
+		}
+	}
+
+	userRouting, ok := findUserRouting(w, h.RoutingRepo, user)
+	if !ok {
+		return
+	}
+
+	alert := easyalert.Alert{Subject: body.Subject, Severity: severity, Labels: body.Labels}
+	result := routing.Route(userRouting, alert, now)
+
+	destinations, err := h.destinations(user, alert, result.Channels)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not fetch channels")
+		return
+	}
+
+	responseBody := dryRunRoutingResponseBody{
+		Channels:     result.Channels,
+		Destinations: destinations,
+	}
+
+	if result.Rule != "" {
+		responseBody.Rule = &result.Rule
+	}
+
+	if result.Channels == nil {
+		responseBody.Channels = allChannels
+	}
+
+	writeJSON(w, http.StatusOK, responseBody)
+}
+
+// destinations returns every configured channel of the user which is part
+// of the selection, in the order the notifier delivers through them.
+func (h DryRunRoutingHandler) destinations(user easyalert.User, alert easyalert.Alert, selection easyalert.Selection) ([]routingDestinationBody, error) {
+	destinations := []routingDestinationBody{}
+
+	add := func(kind, name string) {
+		if selection.Selects(kind, name) {
+			destinations = append(destinations, routingDestinationBody{kind, name})
+		}
+	}
+
+	add(easyalert.ChannelKindEmail, user.Email)
+
+	endpoints, err := h.WebhookEndpointRepo.FindWebhookEndpoints("WHERE user_id = $1 ORDER BY name", user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, endpoint := range endpoints {
+		add(easyalert.ChannelKindWebhook, endpoint.Name)
+	}
+
+	chatChannels, err := h.ChatChannelRepo.FindChatChannels("WHERE user_id = $1 ORDER BY name", user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, channel := range chatChannels {
+		add(easyalert.ChannelKindChat, channel.Name)
+	}
+
+	pushChannels, err := h.PushChannelRepo.FindPushChannels("WHERE user_id = $1 ORDER BY name", user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, channel := range pushChannels {
+		add(easyalert.ChannelKindPush, channel.Name)
+	}
+
+	rooms, err := h.MatrixRoomRepo.FindMatrixRooms("WHERE user_id = $1 ORDER BY name", user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, room := range rooms {
+		add(easyalert.ChannelKindMatrix, room.Name)
+	}
+
+	if user.PhoneVerified && selection.SendsSMS(alert) {
+		destinations = append(destinations, routingDestinationBody{easyalert.ChannelKindSMS, user.PhoneNumber})
+	}
+
+	return destinations, nil
+}
+
+// findUserRouting returns the routing of the user or an empty routing if
+// the user has none. If it can not be fetched an error is written and false
+// is returned.
+func findUserRouting(w http.ResponseWriter, repo easyalert.RoutingRepository, user easyalert.User) (easyalert.Routing, bool) {
+	routing, err := repo.FindRouting("WHERE user_id = $1", user.ID)
+	if err != nil {
+		if err == easyalert.ErrRecordDoesNotExist {
+			return easyalert.Routing{}, true
+		}
+
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not fetch routing")
+		return easyalert.Routing{}, false
+	}
+
+	return routing, true
+}
@@ -0,0 +1,279 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/mocks"
+	"github.com/bakku/easyalert/web/api"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGETRouting_ShouldReturnEmptyRoutingIfUserHasNone(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	routingRepo := mocks.NewMockRoutingRepository(mockCtrl)
+	routingRepo.EXPECT().FindRouting("WHERE user_id = $1", uint(1)).Return(easyalert.Routing{}, easyalert.ErrRecordDoesNotExist)
+
+	req, err := http.NewRequest("GET", "/api/routing", nil)
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.GetRoutingHandler{UserRepo: userRepo, RoutingRepo: routingRepo}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	requireMatchesSpec(t, loadOpenAPI(t), "GET", "/api/routing", rr)
+	require.JSONEq(t, `{"rules": [], "default_channels": []}`, rr.Body.String())
+}
+
+func TestPUTRouting_ShouldReturnErrorIfRuleIsInvalid(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	routingRepo := mocks.NewMockRoutingRepository(mockCtrl)
+	routingRepo.EXPECT().FindRouting(gomock.Any(), gomock.Any()).Return(easyalert.Routing{}, easyalert.ErrRecordDoesNotExist)
+
+	payload := `{"rules": [{"name": "night", "channels": ["sms"]}, {"name": "db", "subject": "(unclosed", "channels": ["email"]}]}`
+
+	req, err := http.NewRequest("PUT", "/api/routing", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.UpdateRoutingHandler{UserRepo: userRepo, RoutingRepo: routingRepo}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+
+	p := requireProblem(t, rr, "invalid_rule", "Invalid subject: error parsing regexp: missing closing ): `(unclosed`.")
+	require.Equal(t, "rules[1].subject", p.Errors[0].Field)
+}
+
+func TestPUTRouting_ShouldReturnErrorIfDefaultChannelIsUnknown(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	routingRepo := mocks.NewMockRoutingRepository(mockCtrl)
+	routingRepo.EXPECT().FindRouting(gomock.Any(), gomock.Any()).Return(easyalert.Routing{}, easyalert.ErrRecordDoesNotExist)
+
+	req, err := http.NewRequest("PUT", "/api/routing", strings.NewReader(`{"default_channels": ["pager"]}`))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.UpdateRoutingHandler{UserRepo: userRepo, RoutingRepo: routingRepo}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+
+	p := requireProblem(t, rr, "invalid_channels", `Invalid default channels: unknown channel "pager", expected one of email, webhook, chat, push, matrix or sms.`)
+	require.Equal(t, "default_channels", p.Errors[0].Field)
+}
+
+func TestPUTRouting_ShouldCreateRoutingIfUserHasNone(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	routingRepo := mocks.NewMockRoutingRepository(mockCtrl)
+	routingRepo.EXPECT().FindRouting(gomock.Any(), gomock.Any()).Return(easyalert.Routing{}, easyalert.ErrRecordDoesNotExist)
+
+	var created easyalert.Routing
+
+	routingRepo.EXPECT().CreateRouting(gomock.Any()).DoAndReturn(func(r easyalert.Routing) (easyalert.Routing, error) {
+		created = r
+		r.ID = 3
+		r.CreatedAt = time.Now()
+		r.UpdatedAt = time.Now()
+		return r, nil
+	})
+
+	payload := `{"rules": [{"name": "night", "severity": "critical", "from": "22:00", "until": "07:00", "timezone": "Europe/Berlin", "channels": ["sms", "chat:ops"]}], "default_channels": ["email"]}`
+
+	req, err := http.NewRequest("PUT", "/api/routing", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.UpdateRoutingHandler{UserRepo: userRepo, RoutingRepo: routingRepo}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	requireMatchesSpec(t, loadOpenAPI(t), "PUT", "/api/routing", rr)
+
+	require.Equal(t, uint(1), created.UserID)
+	require.Equal(t, easyalert.Selection{"email"}, created.DefaultChannels)
+	require.Len(t, created.Rules, 1)
+	require.Equal(t, easyalert.RoutingRule{
+		Name:     "night",
+		Severity: "critical",
+		From:     "22:00",
+		Until:    "07:00",
+		Timezone: "Europe/Berlin",
+		Channels: easyalert.Selection{"sms", "chat:ops"},
+	}, created.Rules[0])
+}
+
+func TestDELETERouting_ShouldReturnNotFoundIfUserHasNone(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	routingRepo := mocks.NewMockRoutingRepository(mockCtrl)
+	routingRepo.EXPECT().FindRouting(gomock.Any(), gomock.Any()).Return(easyalert.Routing{}, easyalert.ErrRecordDoesNotExist)
+
+	req, err := http.NewRequest("DELETE", "/api/routing", nil)
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.DeleteRoutingHandler{UserRepo: userRepo, RoutingRepo: routingRepo}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusNotFound, rr.Code)
+	requireProblem(t, rr, "not_found", "Routing not found.")
+}
+
+func TestPOSTRoutingDryRun_ShouldReturnSelectedDestinations(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	user := easyalert.User{ID: 1, Email: "test@example.com", PhoneNumber: "+4915112345678", PhoneVerified: true}
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(user, nil)
+
+	routingRepo := mocks.NewMockRoutingRepository(mockCtrl)
+	routingRepo.EXPECT().FindRouting("WHERE user_id = $1", uint(1)).Return(easyalert.Routing{
+		ID: 3,
+		Rules: []easyalert.RoutingRule{
+			{Name: "night", Severity: "critical", From: "22:00", Until: "07:00", Channels: easyalert.Selection{"sms", "chat:ops"}},
+		},
+		DefaultChannels: easyalert.Selection{"email"},
+		UserID:          1,
+	}, nil)
+
+	endpointRepo := mocks.NewMockWebhookEndpointRepository(mockCtrl)
+	endpointRepo.EXPECT().FindWebhookEndpoints(gomock.Any(), gomock.Any()).Return([]easyalert.WebhookEndpoint{{Name: "ci"}}, nil)
+
+	chatChannelRepo := mocks.NewMockChatChannelRepository(mockCtrl)
+	chatChannelRepo.EXPECT().FindChatChannels(gomock.Any(), gomock.Any()).Return([]easyalert.ChatChannel{{Name: "dev"}, {Name: "ops"}}, nil)
+
+	pushChannelRepo := mocks.NewMockPushChannelRepository(mockCtrl)
+	pushChannelRepo.EXPECT().FindPushChannels(gomock.Any(), gomock.Any()).Return(nil, nil)
+
+	matrixRoomRepo := mocks.NewMockMatrixRoomRepository(mockCtrl)
+	matrixRoomRepo.EXPECT().FindMatrixRooms(gomock.Any(), gomock.Any()).Return(nil, nil)
+
+	payload := `{"subject": "Database down", "severity": "critical", "time": "2019-02-15T23:30:00Z"}`
+
+	req, err := http.NewRequest("POST", "/api/routing/dry-run", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.DryRunRoutingHandler{
+		UserRepo:            userRepo,
+		RoutingRepo:         routingRepo,
+		WebhookEndpointRepo: endpointRepo,
+		ChatChannelRepo:     chatChannelRepo,
+		PushChannelRepo:     pushChannelRepo,
+		MatrixRoomRepo:      matrixRoomRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	requireMatchesSpec(t, loadOpenAPI(t), "POST", "/api/routing/dry-run", rr)
+
+	require.JSONEq(t, `{
+		"rule": "night",
+		"channels": ["sms", "chat:ops"],
+		"destinations": [
+			{"kind": "chat", "name": "ops"},
+			{"kind": "sms", "name": "+4915112345678"}
+		]
+	}`, rr.Body.String())
+}
+
+func TestPOSTRoutingDryRun_ShouldSelectAllChannelsWithoutRouting(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1, Email: "test@example.com", PhoneNumber: "+4915112345678", PhoneVerified: true}, nil)
+
+	routingRepo := mocks.NewMockRoutingRepository(mockCtrl)
+	routingRepo.EXPECT().FindRouting(gomock.Any(), gomock.Any()).Return(easyalert.Routing{}, easyalert.ErrRecordDoesNotExist)
+
+	endpointRepo := mocks.NewMockWebhookEndpointRepository(mockCtrl)
+	endpointRepo.EXPECT().FindWebhookEndpoints(gomock.Any(), gomock.Any()).Return([]easyalert.WebhookEndpoint{{Name: "ci"}}, nil)
+
+	chatChannelRepo := mocks.NewMockChatChannelRepository(mockCtrl)
+	chatChannelRepo.EXPECT().FindChatChannels(gomock.Any(), gomock.Any()).Return(nil, nil)
+
+	pushChannelRepo := mocks.NewMockPushChannelRepository(mockCtrl)
+	pushChannelRepo.EXPECT().FindPushChannels(gomock.Any(), gomock.Any()).Return(nil, nil)
+
+	matrixRoomRepo := mocks.NewMockMatrixRoomRepository(mockCtrl)
+	matrixRoomRepo.EXPECT().FindMatrixRooms(gomock.Any(), gomock.Any()).Return(nil, nil)
+
+	req, err := http.NewRequest("POST", "/api/routing/dry-run", strings.NewReader(`{"subject": "Backup failed"}`))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.DryRunRoutingHandler{
+		UserRepo:            userRepo,
+		RoutingRepo:         routingRepo,
+		WebhookEndpointRepo: endpointRepo,
+		ChatChannelRepo:     chatChannelRepo,
+		PushChannelRepo:     pushChannelRepo,
+		MatrixRoomRepo:      matrixRoomRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var body struct {
+		Rule         *string
+		Channels     []string
+		Destinations []struct{ Kind, Name string }
+	}
+
+	err = json.Unmarshal(rr.Body.Bytes(), &body)
+	require.Nil(t, err)
+
+	require.Nil(t, body.Rule)
+	require.Equal(t, []string{"email", "webhook", "chat", "push", "matrix", "sms"}, body.Channels)
+	// only critical alerts are sent as SMS unless a rule selects SMS
+	require.Equal(t, []struct{ Kind, Name string }{{"email", "test@example.com"}, {"webhook", "ci"}}, body.Destinations)
+}
@@ -0,0 +1,279 @@
+package api
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/random"
+	"github.com/bakku/easyalert/syslogd"
+)
+
+// maxSyslogRules limits the rules of a source as every rule is evaluated for
+// every received message.
+const maxSyslogRules = 20
+
+type syslogRuleBody struct {
+	Facility string `json:"facility,omitempty"`
+	Severity string `json:"severity,omitempty"`
+	Pattern  string `json:"pattern,omitempty"`
+}
+
+type syslogSourceResponseBody struct {
+	ID                uint             `json:"id"`
+	Name              string           `json:"name"`
+	Address           string           `json:"address"`
+	Rules             []syslogRuleBody `json:"rules"`
+	Verified          bool             `json:"verified"`
+	VerificationToken string           `json:"verification_token,omitempty"`
+	CreatedAt         string           `json:"created_at"`
+	UpdatedAt         string           `json:"updated_at"`
+}
+
+func convertSyslogSourceToResponseBody(source easyalert.SyslogSource) syslogSourceResponseBody {
+	rules := make([]syslogRuleBody, len(source.Rules))
+	for i, r := range source.Rules {
+		rules[i] = syslogRuleBody{Facility: r.Facility, Severity: r.Severity, Pattern: r.Pattern}
+	}
+
+	return syslogSourceResponseBody{
+		ID:                source.ID,
+		Name:              source.Name,
+		Address:           source.Address,
+		Rules:             rules,
+		Verified:          source.Verified,
+		VerificationToken: source.VerificationToken,
+		CreatedAt:         source.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:         source.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+type syslogSourceRequestBody struct {
+	Name    string           `json:"name"`
+	Address string           `json:"address"`
+	Rules   []syslogRuleBody `json:"rules"`
+}
+
+// GetSyslogSourcesHandler should return all syslog sources of the user.
+type GetSyslogSourcesHandler struct {
+	UserRepo   easyalert.UserRepository
+	SourceRepo easyalert.SyslogSourceRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h GetSyslogSourcesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	sources, err := h.SourceRepo.FindSyslogSources("WHERE user_id = $1 ORDER BY name", user.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not fetch syslog sources")
+		return
+	}
+
+	responseBody := make([]syslogSourceResponseBody, len(sources))
+	for i, source := range sources {
+		responseBody[i] = convertSyslogSourceToResponseBody(source)
+	}
+
+	writeJSON(w, http.StatusOK, responseBody)
+}
+
+// CreateSyslogSourcesHandler should accept a JSON object and create a syslog source from it.
+type CreateSyslogSourcesHandler struct {
+	UserRepo   easyalert.UserRepository
+	SourceRepo easyalert.SyslogSourceRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h CreateSyslogSourcesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	source, ok := readSyslogSource(w, r, h.SourceRepo, user, easyalert.SyslogSource{UserID: user.ID})
+	if !ok {
+		return
+	}
+
+	source, err := h.SourceRepo.CreateSyslogSource(source)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not create syslog source")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, convertSyslogSourceToResponseBody(source))
+}
+
+// GetSyslogSourceHandler should return a single syslog source of the user.
+type GetSyslogSourceHandler struct {
+	UserRepo   easyalert.UserRepository
+	SourceRepo easyalert.SyslogSourceRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h GetSyslogSourceHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	source, ok := findUserSyslogSource(w, r, h.SourceRepo, user)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, convertSyslogSourceToResponseBody(source))
+}
+
+// UpdateSyslogSourceHandler should accept a JSON object and replace a syslog source of the user with it.
+type UpdateSyslogSourceHandler struct {
+	UserRepo   easyalert.UserRepository
+	SourceRepo easyalert.SyslogSourceRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h UpdateSyslogSourceHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	source, ok := findUserSyslogSource(w, r, h.SourceRepo, user)
+	if !ok {
+		return
+	}
+
+	source, ok = readSyslogSource(w, r, h.SourceRepo, user, source)
+	if !ok {
+		return
+	}
+
+	source, err := h.SourceRepo.UpdateSyslogSource(source)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not update syslog source")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, convertSyslogSourceToResponseBody(source))
+}
+
+// DeleteSyslogSourceHandler should delete a syslog source of the user.
+type DeleteSyslogSourceHandler struct {
+	UserRepo   easyalert.UserRepository
+	SourceRepo easyalert.SyslogSourceRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h DeleteSyslogSourceHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	source, ok := findUserSyslogSource(w, r, h.SourceRepo, user)
+	if !ok {
+		return
+	}
+
+	err := h.SourceRepo.DeleteSyslogSource(source)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not delete syslog source")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// readSyslogSource reads the syslog source from the request body into source
+// and validates it. Names have to be unique for every user. Addresses are
+// not checked against the sources of other users, which would reveal the
+// addresses they registered. Instead new addresses have to be verified. If
+// the source is invalid an error is written and false is returned.
+func readSyslogSource(w http.ResponseWriter, r *http.Request, repo easyalert.SyslogSourceRepository, user easyalert.User, source easyalert.SyslogSource) (easyalert.SyslogSource, bool) {
+	var body syslogSourceRequestBody
+
+	if !readJSONBody(w, r, &body) {
+		return easyalert.SyslogSource{}, false
+	}
+
+	if body.Name == "" || body.Address == "" || len(body.Rules) == 0 {
+		writeError(w, http.StatusUnprocessableEntity, "missing_field", "Name, address and at least one rule must be given.")
+		return easyalert.SyslogSource{}, false
+	}
+
+	if !checkName(w, body.Name) {
+		return easyalert.SyslogSource{}, false
+	}
+
+	ip := net.ParseIP(body.Address)
+	if ip == nil {
+		writeAPIError(w, invalidField("address", "invalid_address", "Address must be an IP address."))
+		return easyalert.SyslogSource{}, false
+	}
+
+	if len(body.Rules) > maxSyslogRules {
+		writeAPIError(w, invalidField("rules", "too_many_rules", fmt.Sprintf("At most %d rules may be given.", maxSyslogRules)))
+		return easyalert.SyslogSource{}, false
+	}
+
+	rules := make([]easyalert.SyslogRule, len(body.Rules))
+
+	for i, r := range body.Rules {
+		rules[i] = easyalert.SyslogRule{Facility: r.Facility, Severity: r.Severity, Pattern: r.Pattern}
+
+		err := syslogd.ValidateRule(rules[i])
+		if err != nil {
+			field := fmt.Sprintf("rules[%d]", i)
+			if e, ok := err.(syslogd.InvalidRuleError); ok {
+				field += "." + e.Field
+			}
+
+			writeAPIError(w, invalidField(field, "invalid_rule", err.Error()))
+			return easyalert.SyslogSource{}, false
+		}
+	}
+
+	if source.ID == 0 || source.Address != ip.String() {
+		token, err := random.String(easyalert.SyslogVerificationTokenLength)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal_error", "could not generate verification token")
+			return easyalert.SyslogSource{}, false
+		}
+
+		source.ResetVerification(token)
+	}
+
+	source.Name = body.Name
+	source.Address = ip.String()
+	source.Rules = rules
+
+	ok := checkUniqueName(w, source.ID, "A syslog source with this name already exists.", func() (uint, error) {
+		existing, err := repo.FindSyslogSource("WHERE user_id = $1 AND name = $2", user.ID, source.Name)
+		return existing.ID, err
+	})
+	if !ok {
+		return easyalert.SyslogSource{}, false
+	}
+
+	return source, true
+}
+
+// findUserSyslogSource returns the syslog source given by the id route
+// variable if it belongs to the user. Otherwise an error is written and false
+// is returned.
+func findUserSyslogSource(w http.ResponseWriter, r *http.Request, repo easyalert.SyslogSourceRepository, user easyalert.User) (easyalert.SyslogSource, bool) {
+	var source easyalert.SyslogSource
+
+	found := findUserResource(w, r, "syslog source", func(id uint64) (err error) {
+		source, err = repo.FindSyslogSource("WHERE id = $1 AND user_id = $2", id, user.ID)
+		return err
+	})
+
+	return source, found
+}
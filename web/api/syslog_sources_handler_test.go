@@ -0,0 +1,197 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/mocks"
+	"github.com/bakku/easyalert/web/api"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPOSTSyslogSources_ShouldReturnErrorIfAddressIsInvalid(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	payload := `{"name": "router", "address": "router.local", "rules": [{"severity": "err"}]}`
+
+	req, err := http.NewRequest("POST", "/api/syslog-sources", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateSyslogSourcesHandler{UserRepo: userRepo}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	requireProblem(t, rr, "invalid_address", "Address must be an IP address.")
+}
+
+func TestPOSTSyslogSources_ShouldReturnErrorIfRuleIsInvalid(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	payload := `{"name": "router", "address": "192.0.2.1", "rules": [{"severity": "err"}, {"pattern": "(unclosed"}]}`
+
+	req, err := http.NewRequest("POST", "/api/syslog-sources", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateSyslogSourcesHandler{UserRepo: userRepo}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+
+	p := requireProblem(t, rr, "invalid_rule", "Invalid pattern: error parsing regexp: missing closing ): `(unclosed`.")
+	require.Equal(t, "rules[1].pattern", p.Errors[0].Field)
+}
+
+func TestPOSTSyslogSources_ShouldCreateSyslogSource(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	createdAt := time.Date(2019, 1, 30, 19, 0, 0, 0, time.UTC)
+
+	sourceRepo := mocks.NewMockSyslogSourceRepository(mockCtrl)
+	var token string
+
+	// the address is not looked up, so it does not matter whether other
+	// users claimed it
+	sourceRepo.EXPECT().FindSyslogSource("WHERE user_id = $1 AND name = $2", uint(1), "router").Return(easyalert.SyslogSource{}, easyalert.ErrRecordDoesNotExist)
+	sourceRepo.EXPECT().CreateSyslogSource(gomock.Any()).DoAndReturn(func(source easyalert.SyslogSource) (easyalert.SyslogSource, error) {
+		require.Equal(t, "2001:db8::1", source.Address)
+		require.Equal(t, []easyalert.SyslogRule{{Facility: "auth", Severity: "warning", Pattern: "Failed password"}}, source.Rules)
+		require.False(t, source.Verified)
+		require.Len(t, source.VerificationToken, easyalert.SyslogVerificationTokenLength)
+
+		token = source.VerificationToken
+		source.ID = 3
+		source.CreatedAt = createdAt
+		source.UpdatedAt = createdAt
+		return source, nil
+	})
+
+	payload := `{"name": "router", "address": "2001:DB8:0::1", "rules": [{"facility": "auth", "severity": "warning", "pattern": "Failed password"}]}`
+
+	req, err := http.NewRequest("POST", "/api/syslog-sources", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateSyslogSourcesHandler{
+		UserRepo:   userRepo,
+		SourceRepo: sourceRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusCreated, rr.Code)
+	requireMatchesSpec(t, loadOpenAPI(t), "POST", "/api/syslog-sources", rr)
+
+	expectedJsonResp := "{\n" +
+		"  \"id\": 3,\n" +
+		"  \"name\": \"router\",\n" +
+		"  \"address\": \"2001:db8::1\",\n" +
+		"  \"rules\": [\n" +
+		"    {\n" +
+		"      \"facility\": \"auth\",\n" +
+		"      \"severity\": \"warning\",\n" +
+		"      \"pattern\": \"Failed password\"\n" +
+		"    }\n" +
+		"  ],\n" +
+		"  \"verified\": false,\n" +
+		"  \"verification_token\": \"" + token + "\",\n" +
+		"  \"created_at\": \"2019-01-30T19:00:00Z\",\n" +
+		"  \"updated_at\": \"2019-01-30T19:00:00Z\"\n" +
+		"}"
+
+	require.Equal(t, expectedJsonResp, rr.Body.String())
+}
+
+func TestPUTSyslogSource_ShouldOnlyResetVerificationIfAddressChanged(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil).Times(2)
+
+	source := easyalert.SyslogSource{ID: 3, Name: "router", Address: "192.0.2.1", Verified: true, UserID: 1}
+
+	sourceRepo := mocks.NewMockSyslogSourceRepository(mockCtrl)
+	sourceRepo.EXPECT().FindSyslogSource("WHERE id = $1 AND user_id = $2", uint64(3), uint(1)).Return(source, nil).Times(2)
+	sourceRepo.EXPECT().FindSyslogSource("WHERE user_id = $1 AND name = $2", uint(1), "router").Return(source, nil).Times(2)
+	gomock.InOrder(
+		sourceRepo.EXPECT().UpdateSyslogSource(gomock.Any()).DoAndReturn(func(source easyalert.SyslogSource) (easyalert.SyslogSource, error) {
+			require.True(t, source.Verified)
+			require.Equal(t, "", source.VerificationToken)
+			return source, nil
+		}),
+		sourceRepo.EXPECT().UpdateSyslogSource(gomock.Any()).DoAndReturn(func(source easyalert.SyslogSource) (easyalert.SyslogSource, error) {
+			require.Equal(t, "192.0.2.2", source.Address)
+			require.False(t, source.Verified)
+			require.Len(t, source.VerificationToken, easyalert.SyslogVerificationTokenLength)
+			return source, nil
+		}),
+	)
+
+	for _, address := range []string{"192.0.2.1", "192.0.2.2"} {
+		payload := `{"name": "router", "address": "` + address + `", "rules": [{"severity": "err"}]}`
+
+		req, err := http.NewRequest("PUT", "/api/syslog-sources/3", strings.NewReader(payload))
+		require.Nil(t, err)
+
+		req = mux.SetURLVars(req, map[string]string{"id": "3"})
+		req.Header.Set("Authorization", "Bearer 12345")
+
+		rr := httptest.NewRecorder()
+		handler := api.UpdateSyslogSourceHandler{
+			UserRepo:   userRepo,
+			SourceRepo: sourceRepo,
+		}
+		handler.ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		requireMatchesSpec(t, loadOpenAPI(t), "PUT", "/api/syslog-sources/{id}", rr)
+	}
+}
+
+func TestPOSTSyslogSources_ShouldReturnErrorIfTooManyRulesAreGiven(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	rules := strings.Repeat(`{"severity": "err"},`, 21)
+	payload := `{"name": "router", "address": "192.0.2.1", "rules": [` + strings.TrimSuffix(rules, ",") + `]}`
+
+	req, err := http.NewRequest("POST", "/api/syslog-sources", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateSyslogSourcesHandler{UserRepo: userRepo}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	requireProblem(t, rr, "too_many_rules", "At most 20 rules may be given.")
+}
@@ -0,0 +1,262 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/bakku/easyalert"
+)
+
+type templateResponseBody struct {
+	ID        uint   `json:"id"`
+	Name      string `json:"name"`
+	Subject   string `json:"subject"`
+	Body      string `json:"body"`
+	Format    string `json:"format"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+func convertTemplateToResponseBody(tmpl easyalert.Template) templateResponseBody {
+	return templateResponseBody{
+		ID:        tmpl.ID,
+		Name:      tmpl.Name,
+		Subject:   tmpl.Subject,
+		Body:      tmpl.Body,
+		Format:    tmpl.Format,
+		CreatedAt: tmpl.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: tmpl.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+type templateRequestBody struct {
+	Name    string `json:"name"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+	Format  string `json:"format"`
+}
+
+// GetTemplatesHandler should return all templates of the user.
+type GetTemplatesHandler struct {
+	UserRepo     easyalert.UserRepository
+	TemplateRepo easyalert.TemplateRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h GetTemplatesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	templates, err := h.TemplateRepo.FindTemplates("WHERE user_id = $1 ORDER BY name", user.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not fetch templates")
+		return
+	}
+
+	responseBody := make([]templateResponseBody, len(templates))
+	for i, tmpl := range templates {
+		responseBody[i] = convertTemplateToResponseBody(tmpl)
+	}
+
+	writeJSON(w, http.StatusOK, responseBody)
+}
+
+// CreateTemplatesHandler should accept a JSON object and create a template from it.
+type CreateTemplatesHandler struct {
+	UserRepo     easyalert.UserRepository
+	TemplateRepo easyalert.TemplateRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h CreateTemplatesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	tmpl, ok := readTemplate(w, r, h.TemplateRepo, user, easyalert.Template{UserID: user.ID})
+	if !ok {
+		return
+	}
+
+	tmpl, err := h.TemplateRepo.CreateTemplate(tmpl)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not create template")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, convertTemplateToResponseBody(tmpl))
+}
+
+// GetTemplateHandler should return a single template of the user.
+type GetTemplateHandler struct {
+	UserRepo     easyalert.UserRepository
+	TemplateRepo easyalert.TemplateRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h GetTemplateHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	tmpl, ok := findUserTemplate(w, r, h.TemplateRepo, user)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, convertTemplateToResponseBody(tmpl))
+}
+
+// UpdateTemplateHandler should accept a JSON object and replace a template of the user with it.
+type UpdateTemplateHandler struct {
+	UserRepo     easyalert.UserRepository
+	TemplateRepo easyalert.TemplateRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h UpdateTemplateHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	tmpl, ok := findUserTemplate(w, r, h.TemplateRepo, user)
+	if !ok {
+		return
+	}
+
+	tmpl, ok = readTemplate(w, r, h.TemplateRepo, user, tmpl)
+	if !ok {
+		return
+	}
+
+	tmpl, err := h.TemplateRepo.UpdateTemplate(tmpl)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not update template")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, convertTemplateToResponseBody(tmpl))
+}
+
+// DeleteTemplateHandler should delete a template of the user.
+type DeleteTemplateHandler struct {
+	UserRepo     easyalert.UserRepository
+	TemplateRepo easyalert.TemplateRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h DeleteTemplateHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	tmpl, ok := findUserTemplate(w, r, h.TemplateRepo, user)
+	if !ok {
+		return
+	}
+
+	err := h.TemplateRepo.DeleteTemplate(tmpl)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not delete template")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// readTemplate reads the template from the request body into tmpl and
+// validates it. Names have to be unique for every user. If the template is
+// invalid an error is written and false is returned.
+func readTemplate(w http.ResponseWriter, r *http.Request, repo easyalert.TemplateRepository, user easyalert.User, tmpl easyalert.Template) (easyalert.Template, bool) {
+	bytes, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not read http body")
+		return easyalert.Template{}, false
+	}
+
+	var templateBody templateRequestBody
+
+	err = json.Unmarshal(bytes, &templateBody)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "invalid_json", "invalid json")
+		return easyalert.Template{}, false
+	}
+
+	if templateBody.Name == "" || templateBody.Subject == "" || templateBody.Body == "" {
+		writeError(w, http.StatusUnprocessableEntity, "missing_field", "Name, subject and body must be given.")
+		return easyalert.Template{}, false
+	}
+
+	if !checkName(w, templateBody.Name) {
+		return easyalert.Template{}, false
+	}
+
+	if templateBody.Format == "" {
+		templateBody.Format = easyalert.MessageFormatText
+	}
+
+	if templateBody.Format != easyalert.MessageFormatText && templateBody.Format != easyalert.MessageFormatMarkdown {
+		writeAPIError(w, invalidField("format", "invalid_format", "Invalid format, expected text or markdown."))
+		return easyalert.Template{}, false
+	}
+
+	tmpl.Name = templateBody.Name
+	tmpl.Subject = templateBody.Subject
+	tmpl.Body = templateBody.Body
+	tmpl.Format = templateBody.Format
+
+	err = tmpl.Validate()
+	if err != nil {
+		field := "body"
+		if e, ok := err.(easyalert.InvalidTemplateError); ok {
+			field = e.Field
+		}
+
+		writeAPIError(w, invalidField(field, "invalid_template", err.Error()+"."))
+		return easyalert.Template{}, false
+	}
+
+	existing, err := repo.FindTemplate("WHERE user_id = $1 AND name = $2", user.ID, tmpl.Name)
+	if err == nil && existing.ID != tmpl.ID {
+		writeError(w, http.StatusConflict, "name_taken", "A template with this name already exists.")
+		return easyalert.Template{}, false
+	}
+
+	if err != nil && err != easyalert.ErrRecordDoesNotExist {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not fetch templates")
+		return easyalert.Template{}, false
+	}
+
+	return tmpl, true
+}
+
+// findUserTemplate returns the template given by the id route variable if
+// it belongs to the user. Otherwise an error is written and false is returned.
+func findUserTemplate(w http.ResponseWriter, r *http.Request, repo easyalert.TemplateRepository, user easyalert.User) (easyalert.Template, bool) {
+	id, ok := getURLID(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "Template not found.")
+		return easyalert.Template{}, false
+	}
+
+	tmpl, err := repo.FindTemplate("WHERE id = $1 AND user_id = $2", id, user.ID)
+	if err != nil {
+		if err == easyalert.ErrRecordDoesNotExist {
+			writeError(w, http.StatusNotFound, "not_found", "Template not found.")
+			return easyalert.Template{}, false
+		}
+
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not fetch template")
+		return easyalert.Template{}, false
+	}
+
+	return tmpl, true
+}
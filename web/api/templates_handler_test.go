@@ -0,0 +1,248 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/mocks"
+	"github.com/bakku/easyalert/web/api"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPOSTTemplates_ShouldReturnUnauthorizedIfAuthorizationHeaderIsNotPresent(t *testing.T) {
+	req, err := http.NewRequest("POST", "/api/templates", nil)
+	require.Nil(t, err)
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateTemplatesHandler{}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+	requireProblem(t, rr, "missing_token", "Missing or invalid Authorization header.")
+}
+
+func TestPOSTTemplates_ShouldReturnErrorIfNameIsInvalid(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	payload := `{"name": "my backup", "subject": "Backup failed", "body": "-"}`
+
+	req, err := http.NewRequest("POST", "/api/templates", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateTemplatesHandler{
+		UserRepo: userRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	requireProblem(t, rr, "invalid_name", "Name may only contain letters, digits, dots, dashes and underscores.")
+}
+
+func TestPOSTTemplates_ShouldReturnErrorIfTemplateIsInvalid(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	payload := `{"name": "backup", "subject": "Backup failed", "body": "{{range .lines}}{{.}}{{end}}"}`
+
+	req, err := http.NewRequest("POST", "/api/templates", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateTemplatesHandler{
+		UserRepo: userRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	requireProblem(t, rr, "invalid_template", "Invalid body template: range is not allowed.")
+}
+
+func TestPOSTTemplates_ShouldReturnConflictIfNameIsTaken(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	templateRepo := mocks.NewMockTemplateRepository(mockCtrl)
+	templateRepo.EXPECT().FindTemplate("WHERE user_id = $1 AND name = $2", uint(1), "backup").Return(easyalert.Template{ID: 2}, nil)
+
+	payload := `{"name": "backup", "subject": "Backup failed", "body": "-"}`
+
+	req, err := http.NewRequest("POST", "/api/templates", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateTemplatesHandler{
+		UserRepo:     userRepo,
+		TemplateRepo: templateRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusConflict, rr.Code)
+	requireProblem(t, rr, "name_taken", "A template with this name already exists.")
+}
+
+func TestPOSTTemplates_ShouldCreateTemplate(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	createdAt := time.Date(2019, 1, 24, 19, 0, 0, 0, time.UTC)
+
+	templateRepo := mocks.NewMockTemplateRepository(mockCtrl)
+	templateRepo.EXPECT().FindTemplate(gomock.Any(), gomock.Any(), gomock.Any()).Return(easyalert.Template{}, easyalert.ErrRecordDoesNotExist)
+	templateRepo.EXPECT().CreateTemplate(easyalert.Template{
+		Name:    "backup",
+		Subject: "Backup of {{.host}} failed",
+		Body:    "{{.log}}",
+		Format:  "text",
+		UserID:  1,
+	}).DoAndReturn(func(tmpl easyalert.Template) (easyalert.Template, error) {
+		tmpl.ID = 4
+		tmpl.CreatedAt = createdAt
+		tmpl.UpdatedAt = createdAt
+		return tmpl, nil
+	})
+
+	payload := `{"name": "backup", "subject": "Backup of {{.host}} failed", "body": "{{.log}}"}`
+
+	req, err := http.NewRequest("POST", "/api/templates", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateTemplatesHandler{
+		UserRepo:     userRepo,
+		TemplateRepo: templateRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusCreated, rr.Code)
+
+	expectedJsonResp := "{\n" +
+		"  \"id\": 4,\n" +
+		"  \"name\": \"backup\",\n" +
+		"  \"subject\": \"Backup of {{.host}} failed\",\n" +
+		"  \"body\": \"{{.log}}\",\n" +
+		"  \"format\": \"text\",\n" +
+		"  \"created_at\": \"2019-01-24T19:00:00Z\",\n" +
+		"  \"updated_at\": \"2019-01-24T19:00:00Z\"\n" +
+		"}"
+
+	require.Equal(t, expectedJsonResp, rr.Body.String())
+}
+
+func TestPUTTemplate_ShouldUpdateTemplate(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	existing := easyalert.Template{ID: 4, Name: "backup", Subject: "-", Body: "-", Format: "text", UserID: 1}
+
+	templateRepo := mocks.NewMockTemplateRepository(mockCtrl)
+	templateRepo.EXPECT().FindTemplate("WHERE id = $1 AND user_id = $2", uint64(4), uint(1)).Return(existing, nil)
+	templateRepo.EXPECT().FindTemplate("WHERE user_id = $1 AND name = $2", uint(1), "backup").Return(existing, nil)
+	templateRepo.EXPECT().UpdateTemplate(gomock.Any()).DoAndReturn(func(tmpl easyalert.Template) (easyalert.Template, error) {
+		require.Equal(t, uint(4), tmpl.ID)
+		require.Equal(t, "Backup failed", tmpl.Subject)
+		require.Equal(t, "markdown", tmpl.Format)
+		return tmpl, nil
+	})
+
+	payload := `{"name": "backup", "subject": "Backup failed", "body": "**{{.log}}**", "format": "markdown"}`
+
+	req, err := http.NewRequest("PUT", "/api/templates/4", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req = mux.SetURLVars(req, map[string]string{"id": "4"})
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.UpdateTemplateHandler{
+		UserRepo:     userRepo,
+		TemplateRepo: templateRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestGETTemplate_ShouldReturnNotFoundIfTemplateDoesNotBelongToUser(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	templateRepo := mocks.NewMockTemplateRepository(mockCtrl)
+	templateRepo.EXPECT().FindTemplate("WHERE id = $1 AND user_id = $2", uint64(4), uint(1)).Return(easyalert.Template{}, easyalert.ErrRecordDoesNotExist)
+
+	req, err := http.NewRequest("GET", "/api/templates/4", nil)
+	require.Nil(t, err)
+
+	req = mux.SetURLVars(req, map[string]string{"id": "4"})
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.GetTemplateHandler{
+		UserRepo:     userRepo,
+		TemplateRepo: templateRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusNotFound, rr.Code)
+	requireProblem(t, rr, "not_found", "Template not found.")
+}
+
+func TestDELETETemplate_ShouldDeleteTemplate(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	templateRepo := mocks.NewMockTemplateRepository(mockCtrl)
+	templateRepo.EXPECT().FindTemplate(gomock.Any(), gomock.Any(), gomock.Any()).Return(easyalert.Template{ID: 4}, nil)
+	templateRepo.EXPECT().DeleteTemplate(easyalert.Template{ID: 4}).Return(nil)
+
+	req, err := http.NewRequest("DELETE", "/api/templates/4", nil)
+	require.Nil(t, err)
+
+	req = mux.SetURLVars(req, map[string]string{"id": "4"})
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.DeleteTemplateHandler{
+		UserRepo:     userRepo,
+		TemplateRepo: templateRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusNoContent, rr.Code)
+}
@@ -2,7 +2,6 @@ package api
 
 import (
 	"encoding/json"
-	"fmt"
 	"io/ioutil"
 	"net/http"
 
@@ -28,7 +27,7 @@ type createUserResponseBody struct {
 func (h CreateUsersHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	bytes, err := ioutil.ReadAll(r.Body)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "could not read http body")
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not read http body")
 		return
 	}
 
@@ -36,55 +35,53 @@ func (h CreateUsersHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	err = json.Unmarshal(bytes, &userBody)
 	if err != nil {
-		writeError(w, http.StatusUnprocessableEntity, "invalid json")
+		writeError(w, http.StatusUnprocessableEntity, "invalid_json", "invalid json")
 		return
 	}
 
 	if userBody.Email == "" || userBody.Password == "" {
-		writeError(w, http.StatusBadRequest, "Empty email or password.")
+		writeError(w, http.StatusBadRequest, "missing_credentials", "Empty email or password.")
 		return
 	}
 
 	token, err := random.String(easyalert.UserTokenLength)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "could not generate token")
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not generate token")
+		return
+	}
+
+	inboundToken, err := generateInboundToken()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not generate token")
 		return
 	}
 
 	user := easyalert.User{
-		Email: userBody.Email,
-		Token: token,
+		Email:        userBody.Email,
+		Token:        token,
+		InboundToken: inboundToken,
 	}
 
 	err = user.HashPassword(userBody.Password)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "could not hash password")
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not hash password")
 		return
 	}
 
 	user, err = h.UserRepo.CreateUser(user)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, fmt.Sprintf("%v", err))
-		return
-	}
-
-	responseBody := createUserResponseBody{user.Token}
+		if err == easyalert.ErrEmailTaken {
+			writeError(w, http.StatusBadRequest, "email_taken", err.Error())
+			return
+		}
 
-	responseBodyBytes, err := json.Marshal(responseBody)
-	if err != nil {
-		writeError(w, http.StatusInternalServerError, "could not marshal response body")
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not create user")
 		return
 	}
 
-	body, err := prettifyJSON(string(responseBodyBytes))
-	if err != nil {
-		writeError(w, http.StatusInternalServerError, "could not prettify json response")
-		return
-	}
+	responseBody := createUserResponseBody{user.Token}
 
-	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
-	w.WriteHeader(http.StatusCreated)
-	w.Write([]byte(body))
+	writeJSON(w, http.StatusCreated, responseBody)
 }
 
 // UpdateUserHandler should accept a JSON object and update the user given by the auth header from it.
@@ -104,26 +101,14 @@ type updateUserResponseBody struct {
 
 // ServeHTTP handles the HTTP request.
 func (h UpdateUserHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	token, ok := getUserToken(r)
+	user, ok := authorizeUser(w, r, h.UserRepo)
 	if !ok {
-		writeError(w, http.StatusUnauthorized, "Missing or invalid Authorization header.")
-		return
-	}
-
-	user, err := h.UserRepo.FindUser("WHERE token = $1", token)
-	if err != nil {
-		if err == easyalert.ErrRecordDoesNotExist {
-			writeError(w, http.StatusUnauthorized, "Invalid token.")
-			return
-		}
-
-		writeError(w, http.StatusInternalServerError, "an unknown error occured")
 		return
 	}
 
 	bytes, err := ioutil.ReadAll(r.Body)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "could not read http body")
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not read http body")
 		return
 	}
 
@@ -131,7 +116,7 @@ func (h UpdateUserHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	err = json.Unmarshal(bytes, &userBody)
 	if err != nil {
-		writeError(w, http.StatusUnprocessableEntity, "invalid json")
+		writeError(w, http.StatusUnprocessableEntity, "invalid_json", "invalid json")
 		return
 	}
 
@@ -145,27 +130,13 @@ func (h UpdateUserHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	user, err = h.UserRepo.UpdateUser(user)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "could not update user")
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not update user")
 		return
 	}
 
 	responseBody := updateUserResponseBody{user.Email, user.Token}
 
-	responseBodyBytes, err := json.Marshal(responseBody)
-	if err != nil {
-		writeError(w, http.StatusInternalServerError, "could not marshal response body")
-		return
-	}
-
-	body, err := prettifyJSON(string(responseBodyBytes))
-	if err != nil {
-		writeError(w, http.StatusInternalServerError, "could not prettify json response")
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(body))
+	writeJSON(w, http.StatusOK, responseBody)
 }
 
 // DeleteUserHandler should delete the authorized user.
@@ -175,26 +146,14 @@ type DeleteUserHandler struct {
 
 // ServeHTTP handles the HTTP request.
 func (h DeleteUserHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	token, ok := getUserToken(r)
+	user, ok := authorizeUser(w, r, h.UserRepo)
 	if !ok {
-		writeError(w, http.StatusUnauthorized, "Missing or invalid Authorization header.")
-		return
-	}
-
-	user, err := h.UserRepo.FindUser("WHERE token = $1", token)
-	if err != nil {
-		if err == easyalert.ErrRecordDoesNotExist {
-			writeError(w, http.StatusUnauthorized, "Invalid token.")
-			return
-		}
-
-		writeError(w, http.StatusInternalServerError, "an unknown error occured")
 		return
 	}
 
-	err = h.UserRepo.DeleteUser(user)
+	err := h.UserRepo.DeleteUser(user)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "could not delete user")
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not delete user")
 		return
 	}
 
@@ -26,8 +26,7 @@ func TestPOSTUsers_ShouldReturnErrorIfUserWasGivenIncorrectly(t *testing.T) {
 	handler.ServeHTTP(rr, req)
 
 	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
-	require.Equal(t, "application/json; charset=UTF-8", rr.Header().Get("Content-Type"))
-	require.Equal(t, "{\n  \"error\": \"invalid json\"\n}", rr.Body.String())
+	requireProblem(t, rr, "invalid_json", "invalid json")
 }
 
 func TestPOSTUsers_ShouldReturnErrorFromDatabase(t *testing.T) {
@@ -35,7 +34,7 @@ func TestPOSTUsers_ShouldReturnErrorFromDatabase(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	userRepo := mocks.NewMockUserRepository(mockCtrl)
-	userRepo.EXPECT().CreateUser(gomock.Any()).Return(easyalert.User{}, errors.New("Email is already taken."))
+	userRepo.EXPECT().CreateUser(gomock.Any()).Return(easyalert.User{}, easyalert.ErrEmailTaken)
 
 	payload := `
 		{
@@ -54,8 +53,7 @@ func TestPOSTUsers_ShouldReturnErrorFromDatabase(t *testing.T) {
 	handler.ServeHTTP(rr, req)
 
 	require.Equal(t, http.StatusBadRequest, rr.Code)
-	require.Equal(t, "application/json; charset=UTF-8", rr.Header().Get("Content-Type"))
-	require.Equal(t, "{\n  \"error\": \"Email is already taken.\"\n}", rr.Body.String())
+	requireProblem(t, rr, "email_taken", "Email is already taken.")
 }
 
 func TestPOSTUsers_ShouldNotAcceptEmptyEmailOrPassword(t *testing.T) {
@@ -81,8 +79,7 @@ func TestPOSTUsers_ShouldNotAcceptEmptyEmailOrPassword(t *testing.T) {
 	handler.ServeHTTP(rr, req)
 
 	require.Equal(t, http.StatusBadRequest, rr.Code)
-	require.Equal(t, "application/json; charset=UTF-8", rr.Header().Get("Content-Type"))
-	require.Equal(t, "{\n  \"error\": \"Empty email or password.\"\n}", rr.Body.String())
+	requireProblem(t, rr, "missing_credentials", "Empty email or password.")
 }
 
 func TestPOSTUsers_ShouldCreateUser(t *testing.T) {
@@ -131,8 +128,7 @@ func TestPUTUsersMe_ShouldReturnUnauthorizedIfAuthorizationHeaderIsNotPresent(t
 	handler.ServeHTTP(rr, req)
 
 	require.Equal(t, http.StatusUnauthorized, rr.Code)
-	require.Equal(t, "application/json; charset=UTF-8", rr.Header().Get("Content-Type"))
-	require.Equal(t, "{\n  \"error\": \"Missing or invalid Authorization header.\"\n}", rr.Body.String())
+	requireProblem(t, rr, "missing_token", "Missing or invalid Authorization header.")
 }
 
 func TestPUTUsersMe_ShouldReturnUnauthorizedIfNoUserExistsForToken(t *testing.T) {
@@ -154,8 +150,7 @@ func TestPUTUsersMe_ShouldReturnUnauthorizedIfNoUserExistsForToken(t *testing.T)
 	handler.ServeHTTP(rr, req)
 
 	require.Equal(t, http.StatusUnauthorized, rr.Code)
-	require.Equal(t, "application/json; charset=UTF-8", rr.Header().Get("Content-Type"))
-	require.Equal(t, "{\n  \"error\": \"Invalid token.\"\n}", rr.Body.String())
+	requireProblem(t, rr, "invalid_token", "Invalid token.")
 }
 
 func TestPUTUsersMe_ShouldReturnErrorIfPayloadWasGivenIncorrectly(t *testing.T) {
@@ -179,8 +174,7 @@ func TestPUTUsersMe_ShouldReturnErrorIfPayloadWasGivenIncorrectly(t *testing.T)
 	handler.ServeHTTP(rr, req)
 
 	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
-	require.Equal(t, "application/json; charset=UTF-8", rr.Header().Get("Content-Type"))
-	require.Equal(t, "{\n  \"error\": \"invalid json\"\n}", rr.Body.String())
+	requireProblem(t, rr, "invalid_json", "invalid json")
 }
 
 func TestPUTUsersMe_ShouldReturnErrorOnUserUpdate(t *testing.T) {
@@ -209,8 +203,7 @@ func TestPUTUsersMe_ShouldReturnErrorOnUserUpdate(t *testing.T) {
 	handler.ServeHTTP(rr, req)
 
 	require.Equal(t, http.StatusInternalServerError, rr.Code)
-	require.Equal(t, "application/json; charset=UTF-8", rr.Header().Get("Content-Type"))
-	require.Equal(t, "{\n  \"error\": \"could not update user\"\n}", rr.Body.String())
+	requireProblem(t, rr, "internal_error", "could not update user")
 }
 
 func TestPUTUsersMe_ShouldUpdateUser(t *testing.T) {
@@ -252,8 +245,7 @@ func TestDELETEUsersMe_ShouldReturnUnauthorizedIfAuthorizationHeaderIsNotPresent
 	handler.ServeHTTP(rr, req)
 
 	require.Equal(t, http.StatusUnauthorized, rr.Code)
-	require.Equal(t, "application/json; charset=UTF-8", rr.Header().Get("Content-Type"))
-	require.Equal(t, "{\n  \"error\": \"Missing or invalid Authorization header.\"\n}", rr.Body.String())
+	requireProblem(t, rr, "missing_token", "Missing or invalid Authorization header.")
 }
 
 func TestDELETEUsersMe_ShouldReturnUnauthorizedIfNoUserExistsForToken(t *testing.T) {
@@ -275,8 +267,7 @@ func TestDELETEUsersMe_ShouldReturnUnauthorizedIfNoUserExistsForToken(t *testing
 	handler.ServeHTTP(rr, req)
 
 	require.Equal(t, http.StatusUnauthorized, rr.Code)
-	require.Equal(t, "application/json; charset=UTF-8", rr.Header().Get("Content-Type"))
-	require.Equal(t, "{\n  \"error\": \"Invalid token.\"\n}", rr.Body.String())
+	requireProblem(t, rr, "invalid_token", "Invalid token.")
 }
 
 func TestDELETEUsersMe_ShouldReturnErrorOnUserUpdate(t *testing.T) {
@@ -299,8 +290,7 @@ func TestDELETEUsersMe_ShouldReturnErrorOnUserUpdate(t *testing.T) {
 	handler.ServeHTTP(rr, req)
 
 	require.Equal(t, http.StatusInternalServerError, rr.Code)
-	require.Equal(t, "application/json; charset=UTF-8", rr.Header().Get("Content-Type"))
-	require.Equal(t, "{\n  \"error\": \"could not delete user\"\n}", rr.Body.String())
+	requireProblem(t, rr, "internal_error", "could not delete user")
 }
 
 func TestDELETEUsersMe_ShouldBeSuccessful(t *testing.T) {
@@ -3,10 +3,23 @@ package api
 import (
 	"bytes"
 	"encoding/json"
+	"io/ioutil"
 	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
 	"strings"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/netguard"
+	"github.com/gorilla/mux"
 )
 
+// namePattern restricts the names of templates, channels and other resources
+// of the user to characters which can be given in query parameters and form
+// fields without escaping.
+var namePattern = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+
 func prettifyJSON(in string) (string, error) {
 	var out bytes.Buffer
 
@@ -18,13 +31,6 @@ func prettifyJSON(in string) (string, error) {
 	return out.String(), nil
 }
 
-func writeError(w http.ResponseWriter, status int, message string) {
-	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
-	w.WriteHeader(status)
-	body, _ := prettifyJSON("{\"error\":\"" + message + "\"}")
-	w.Write([]byte(body))
-}
-
 func getUserToken(r *http.Request) (string, bool) {
 	rawToken := r.Header.Get("Authorization")
 	if rawToken == "" {
@@ -38,3 +44,145 @@ func getUserToken(r *http.Request) (string, bool) {
 
 	return splittedToken[1], true
 }
+
+// authorizeUser returns the user belonging to the token inside the Authorization
+// header. If no user could be found an error is written and false is returned.
+func authorizeUser(w http.ResponseWriter, r *http.Request, userRepo easyalert.UserRepository) (easyalert.User, bool) {
+	token, ok := getUserToken(r)
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "missing_token", "Missing or invalid Authorization header.")
+		return easyalert.User{}, false
+	}
+
+	user, err := userRepo.FindUser("WHERE token = $1", token)
+	if err != nil {
+		if err == easyalert.ErrRecordDoesNotExist {
+			writeError(w, http.StatusUnauthorized, "invalid_token", "Invalid token.")
+			return easyalert.User{}, false
+		}
+
+		writeError(w, http.StatusInternalServerError, "internal_error", "an unknown error occured")
+		return easyalert.User{}, false
+	}
+
+	return user, true
+}
+
+// getURLID returns the numeric id route variable.
+func getURLID(r *http.Request) (uint64, bool) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return id, true
+}
+
+// readJSONBody unmarshals the request body into v. If the body cannot be
+// read or is invalid an error is written and false is returned.
+func readJSONBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	bytes, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not read http body")
+		return false
+	}
+
+	err = json.Unmarshal(bytes, v)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "invalid_json", "invalid json")
+		return false
+	}
+
+	return true
+}
+
+// checkName validates the name of a resource against namePattern. If it is
+// invalid an error is written and false is returned.
+func checkName(w http.ResponseWriter, name string) bool {
+	if !namePattern.MatchString(name) {
+		writeAPIError(w, invalidField("name", "invalid_name", "Name may only contain letters, digits, dots, dashes and underscores."))
+		return false
+	}
+
+	return true
+}
+
+// checkUniqueName writes the conflict and returns false if another resource
+// of the user with the id has the name. find returns the id of the resource
+// with the name, or easyalert.ErrRecordDoesNotExist.
+func checkUniqueName(w http.ResponseWriter, id uint, conflict string, find func() (uint, error)) bool {
+	existing, err := find()
+	if err == nil && existing != id {
+		writeError(w, http.StatusConflict, "name_taken", conflict)
+		return false
+	}
+
+	if err != nil && err != easyalert.ErrRecordDoesNotExist {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not check name")
+		return false
+	}
+
+	return true
+}
+
+// findUserResource calls find with the id route variable, which looks up
+// the resource of the user. If the id is invalid or the resource does not
+// exist, e.g. because it belongs to another user, not found is written for
+// the noun and false is returned.
+func findUserResource(w http.ResponseWriter, r *http.Request, noun string, find func(id uint64) error) bool {
+	notFound := strings.ToUpper(noun[:1]) + noun[1:] + " not found."
+
+	id, ok := getURLID(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", notFound)
+		return false
+	}
+
+	err := find(id)
+	if err == easyalert.ErrRecordDoesNotExist {
+		writeError(w, http.StatusNotFound, "not_found", notFound)
+		return false
+	}
+
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not fetch "+noun)
+		return false
+	}
+
+	return true
+}
+
+// parseHTTPURL returns the normalized URL if s is an absolute http or https
+// URL whose host is not a loopback, private or link-local address. Host
+// names are checked again when connecting.
+func parseHTTPURL(s string) (string, bool) {
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", false
+	}
+
+	if netguard.CheckHost(u.Hostname()) != nil {
+		return "", false
+	}
+
+	return u.String(), true
+}
+
+// writeJSON writes the given value as prettified JSON.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	responseBodyBytes, err := json.Marshal(v)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not marshal response body")
+		return
+	}
+
+	body, err := prettifyJSON(string(responseBodyBytes))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not prettify json response")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(status)
+	w.Write([]byte(body))
+}
@@ -0,0 +1,379 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/random"
+)
+
+// Limits and defaults of the delivery policy of webhook endpoints.
+const (
+	defaultWebhookTimeout      = 10 * time.Second
+	maxWebhookTimeout          = 30 * time.Second
+	defaultWebhookMaxRetries   = 3
+	maxWebhookRetries          = 3
+	defaultWebhookRetryBackoff = 1 * time.Second
+	maxWebhookRetryBackoff     = 5 * time.Second
+)
+
+// webhookDeliveriesLimit is the number of the most recent deliveries which
+// are returned for an endpoint.
+const webhookDeliveriesLimit = 100
+
+type webhookResponseBody struct {
+	ID            uint   `json:"id"`
+	Name          string `json:"name"`
+	URL           string `json:"url"`
+	Secret        string `json:"secret"`
+	Timeout       string `json:"timeout"`
+	MaxRetries    uint   `json:"max_retries"`
+	RetryBackoff  string `json:"retry_backoff"`
+	DeliveriesURL string `json:"deliveries_url"`
+	CreatedAt     string `json:"created_at"`
+	UpdatedAt     string `json:"updated_at"`
+}
+
+func convertWebhookToResponseBody(endpoint easyalert.WebhookEndpoint) webhookResponseBody {
+	return webhookResponseBody{
+		ID:            endpoint.ID,
+		Name:          endpoint.Name,
+		URL:           endpoint.URL,
+		Secret:        endpoint.Secret,
+		Timeout:       endpoint.Timeout.String(),
+		MaxRetries:    endpoint.MaxRetries,
+		RetryBackoff:  endpoint.RetryBackoff.String(),
+		DeliveriesURL: fmt.Sprintf("/api/webhooks/%d/deliveries", endpoint.ID),
+		CreatedAt:     endpoint.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:     endpoint.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+type webhookRequestBody struct {
+	Name         string `json:"name"`
+	URL          string `json:"url"`
+	Timeout      string `json:"timeout"`
+	MaxRetries   *uint  `json:"max_retries"`
+	RetryBackoff string `json:"retry_backoff"`
+	RotateSecret bool   `json:"rotate_secret"`
+}
+
+type webhookDeliveryResponseBody struct {
+	ID         uint   `json:"id"`
+	AlertID    uint   `json:"alert_id"`
+	Attempt    uint   `json:"attempt"`
+	StatusCode int    `json:"status_code,omitempty"`
+	LatencyMS  int64  `json:"latency_ms"`
+	Response   string `json:"response,omitempty"`
+	Error      string `json:"error,omitempty"`
+	CreatedAt  string `json:"created_at"`
+}
+
+// GetWebhooksHandler should return all webhook endpoints of the user.
+type GetWebhooksHandler struct {
+	UserRepo     easyalert.UserRepository
+	EndpointRepo easyalert.WebhookEndpointRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h GetWebhooksHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	endpoints, err := h.EndpointRepo.FindWebhookEndpoints("WHERE user_id = $1 ORDER BY name", user.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not fetch webhooks")
+		return
+	}
+
+	responseBody := make([]webhookResponseBody, len(endpoints))
+	for i, endpoint := range endpoints {
+		responseBody[i] = convertWebhookToResponseBody(endpoint)
+	}
+
+	writeJSON(w, http.StatusOK, responseBody)
+}
+
+// CreateWebhooksHandler should accept a JSON object and create a webhook endpoint from it.
+type CreateWebhooksHandler struct {
+	UserRepo     easyalert.UserRepository
+	EndpointRepo easyalert.WebhookEndpointRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h CreateWebhooksHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	endpoint, ok := readWebhook(w, r, h.EndpointRepo, user, easyalert.WebhookEndpoint{UserID: user.ID})
+	if !ok {
+		return
+	}
+
+	endpoint, err := h.EndpointRepo.CreateWebhookEndpoint(endpoint)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not create webhook")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, convertWebhookToResponseBody(endpoint))
+}
+
+// GetWebhookHandler should return a single webhook endpoint of the user.
+type GetWebhookHandler struct {
+	UserRepo     easyalert.UserRepository
+	EndpointRepo easyalert.WebhookEndpointRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h GetWebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	endpoint, ok := findUserWebhook(w, r, h.EndpointRepo, user)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, convertWebhookToResponseBody(endpoint))
+}
+
+// UpdateWebhookHandler should accept a JSON object and replace a webhook endpoint of the user with it.
+type UpdateWebhookHandler struct {
+	UserRepo     easyalert.UserRepository
+	EndpointRepo easyalert.WebhookEndpointRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h UpdateWebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	endpoint, ok := findUserWebhook(w, r, h.EndpointRepo, user)
+	if !ok {
+		return
+	}
+
+	endpoint, ok = readWebhook(w, r, h.EndpointRepo, user, endpoint)
+	if !ok {
+		return
+	}
+
+	endpoint, err := h.EndpointRepo.UpdateWebhookEndpoint(endpoint)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not update webhook")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, convertWebhookToResponseBody(endpoint))
+}
+
+// DeleteWebhookHandler should delete a webhook endpoint of the user together with its deliveries.
+type DeleteWebhookHandler struct {
+	UserRepo     easyalert.UserRepository
+	EndpointRepo easyalert.WebhookEndpointRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h DeleteWebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	endpoint, ok := findUserWebhook(w, r, h.EndpointRepo, user)
+	if !ok {
+		return
+	}
+
+	err := h.EndpointRepo.DeleteWebhookEndpoint(endpoint)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not delete webhook")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetWebhookDeliveriesHandler should return the most recent delivery attempts of a webhook endpoint of the user.
+type GetWebhookDeliveriesHandler struct {
+	UserRepo     easyalert.UserRepository
+	EndpointRepo easyalert.WebhookEndpointRepository
+	DeliveryRepo easyalert.WebhookDeliveryRepository
+}
+
+// ServeHTTP handles the HTTP request.
+func (h GetWebhookDeliveriesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, ok := authorizeUser(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	endpoint, ok := findUserWebhook(w, r, h.EndpointRepo, user)
+	if !ok {
+		return
+	}
+
+	deliveries, err := h.DeliveryRepo.FindWebhookDeliveries("WHERE endpoint_id = $1 ORDER BY created_at DESC, id DESC LIMIT $2", endpoint.ID, webhookDeliveriesLimit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not fetch webhook deliveries")
+		return
+	}
+
+	responseBody := make([]webhookDeliveryResponseBody, len(deliveries))
+	for i, d := range deliveries {
+		responseBody[i] = webhookDeliveryResponseBody{
+			ID:         d.ID,
+			AlertID:    d.AlertID,
+			Attempt:    d.Attempt,
+			StatusCode: d.StatusCode,
+			LatencyMS:  int64(d.Latency / time.Millisecond),
+			Response:   d.Response,
+			Error:      d.Error,
+			CreatedAt:  d.CreatedAt.Format(time.RFC3339),
+		}
+	}
+
+	writeJSON(w, http.StatusOK, responseBody)
+}
+
+// readWebhook reads the webhook endpoint from the request body into endpoint
+// and validates it. Omitted policy fields are set to their defaults. A secret
+// is generated for new endpoints and on request. If the endpoint is invalid
+// an error is written and false is returned.
+func readWebhook(w http.ResponseWriter, r *http.Request, repo easyalert.WebhookEndpointRepository, user easyalert.User, endpoint easyalert.WebhookEndpoint) (easyalert.WebhookEndpoint, bool) {
+	bytes, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not read http body")
+		return easyalert.WebhookEndpoint{}, false
+	}
+
+	var body webhookRequestBody
+
+	err = json.Unmarshal(bytes, &body)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "invalid_json", "invalid json")
+		return easyalert.WebhookEndpoint{}, false
+	}
+
+	if body.Name == "" || body.URL == "" {
+		writeError(w, http.StatusUnprocessableEntity, "missing_field", "Name and URL must be given.")
+		return easyalert.WebhookEndpoint{}, false
+	}
+
+	if !checkName(w, body.Name) {
+		return easyalert.WebhookEndpoint{}, false
+	}
+
+	endpointURL, ok := parseHTTPURL(body.URL)
+	if !ok {
+		writeAPIError(w, invalidField("url", "invalid_url", "URL must be an absolute http or https URL of a public host."))
+		return easyalert.WebhookEndpoint{}, false
+	}
+
+	timeout, ok := parseWebhookDuration(body.Timeout, defaultWebhookTimeout, maxWebhookTimeout)
+	if !ok {
+		writeAPIError(w, invalidField("timeout", "invalid_timeout", "Timeout must be a duration between 1s and 30s."))
+		return easyalert.WebhookEndpoint{}, false
+	}
+
+	maxRetries := uint(defaultWebhookMaxRetries)
+	if body.MaxRetries != nil {
+		maxRetries = *body.MaxRetries
+	}
+
+	if maxRetries > maxWebhookRetries {
+		writeAPIError(w, invalidField("max_retries", "invalid_max_retries", "At most 3 retries may be given."))
+		return easyalert.WebhookEndpoint{}, false
+	}
+
+	backoff, ok := parseWebhookDuration(body.RetryBackoff, defaultWebhookRetryBackoff, maxWebhookRetryBackoff)
+	if !ok {
+		writeAPIError(w, invalidField("retry_backoff", "invalid_retry_backoff", "Retry backoff must be a duration between 1s and 5s."))
+		return easyalert.WebhookEndpoint{}, false
+	}
+
+	endpoint.Name = body.Name
+	endpoint.URL = endpointURL
+	endpoint.Timeout = timeout
+	endpoint.MaxRetries = maxRetries
+	endpoint.RetryBackoff = backoff
+
+	if wait := endpoint.RetryWait(); wait > easyalert.MaxWebhookRetryWait {
+		msg := fmt.Sprintf("Retries would wait %s in total, but at most %s are allowed. Lower max_retries or retry_backoff.", wait, easyalert.MaxWebhookRetryWait)
+		writeAPIError(w, invalidField("max_retries", "invalid_retry_policy", msg))
+		return easyalert.WebhookEndpoint{}, false
+	}
+
+	existing, err := repo.FindWebhookEndpoint("WHERE user_id = $1 AND name = $2", user.ID, endpoint.Name)
+	if err == nil && existing.ID != endpoint.ID {
+		writeError(w, http.StatusConflict, "name_taken", "A webhook with this name already exists.")
+		return easyalert.WebhookEndpoint{}, false
+	}
+
+	if err != nil && err != easyalert.ErrRecordDoesNotExist {
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not fetch webhooks")
+		return easyalert.WebhookEndpoint{}, false
+	}
+
+	if endpoint.Secret == "" || body.RotateSecret {
+		endpoint.Secret, err = random.String(easyalert.WebhookSecretLength)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal_error", "could not generate secret")
+			return easyalert.WebhookEndpoint{}, false
+		}
+	}
+
+	return endpoint, true
+}
+
+// parseWebhookDuration parses a duration of whole seconds between one second
+// and max. Empty strings result in the default.
+func parseWebhookDuration(s string, def, max time.Duration) (time.Duration, bool) {
+	if s == "" {
+		return def, true
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d < time.Second || d > max || d%time.Second != 0 {
+		return 0, false
+	}
+
+	return d, true
+}
+
+// findUserWebhook returns the webhook endpoint given by the id route variable
+// if it belongs to the user. Otherwise an error is written and false is
+// returned.
+func findUserWebhook(w http.ResponseWriter, r *http.Request, repo easyalert.WebhookEndpointRepository, user easyalert.User) (easyalert.WebhookEndpoint, bool) {
+	id, ok := getURLID(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "Webhook not found.")
+		return easyalert.WebhookEndpoint{}, false
+	}
+
+	endpoint, err := repo.FindWebhookEndpoint("WHERE id = $1 AND user_id = $2", id, user.ID)
+	if err != nil {
+		if err == easyalert.ErrRecordDoesNotExist {
+			writeError(w, http.StatusNotFound, "not_found", "Webhook not found.")
+			return easyalert.WebhookEndpoint{}, false
+		}
+
+		writeError(w, http.StatusInternalServerError, "internal_error", "could not fetch webhook")
+		return easyalert.WebhookEndpoint{}, false
+	}
+
+	return endpoint, true
+}
@@ -0,0 +1,262 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/mocks"
+	"github.com/bakku/easyalert/web/api"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPOSTWebhooks_ShouldReturnErrorIfURLIsInvalid(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	payload := `{"name": "ops", "url": "ftp://example.com/hook"}`
+
+	req, err := http.NewRequest("POST", "/api/webhooks", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateWebhooksHandler{UserRepo: userRepo}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+
+	p := requireProblem(t, rr, "invalid_url", "URL must be an absolute http or https URL of a public host.")
+	require.Equal(t, "url", p.Errors[0].Field)
+}
+
+func TestPOSTWebhooks_ShouldReturnErrorIfURLIsPrivate(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	payload := `{"name": "ops", "url": "http://169.254.169.254/latest/meta-data/"}`
+
+	req, err := http.NewRequest("POST", "/api/webhooks", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateWebhooksHandler{UserRepo: userRepo}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+
+	p := requireProblem(t, rr, "invalid_url", "URL must be an absolute http or https URL of a public host.")
+	require.Equal(t, "url", p.Errors[0].Field)
+}
+
+func TestPOSTWebhooks_ShouldReturnErrorIfTimeoutIsTooLong(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	payload := `{"name": "ops", "url": "https://example.com/hook", "timeout": "1m"}`
+
+	req, err := http.NewRequest("POST", "/api/webhooks", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateWebhooksHandler{UserRepo: userRepo}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	requireProblem(t, rr, "invalid_timeout", "Timeout must be a duration between 1s and 30s.")
+}
+
+func TestPOSTWebhooks_ShouldReturnErrorIfRetriesWaitTooLong(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	payload := `{"name": "ops", "url": "https://example.com/hook", "max_retries": 3, "retry_backoff": "2s"}`
+
+	req, err := http.NewRequest("POST", "/api/webhooks", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateWebhooksHandler{UserRepo: userRepo}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+
+	p := requireProblem(t, rr, "invalid_retry_policy", "Retries would wait 14s in total, but at most 10s are allowed. Lower max_retries or retry_backoff.")
+	require.Equal(t, "max_retries", p.Errors[0].Field)
+}
+
+func TestPOSTWebhooks_ShouldCreateWebhookWithDefaultsAndSecret(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	createdAt := time.Date(2019, 2, 4, 18, 0, 0, 0, time.UTC)
+
+	endpointRepo := mocks.NewMockWebhookEndpointRepository(mockCtrl)
+	endpointRepo.EXPECT().FindWebhookEndpoint("WHERE user_id = $1 AND name = $2", uint(1), "ops").Return(easyalert.WebhookEndpoint{}, easyalert.ErrRecordDoesNotExist)
+	endpointRepo.EXPECT().CreateWebhookEndpoint(gomock.Any()).DoAndReturn(func(endpoint easyalert.WebhookEndpoint) (easyalert.WebhookEndpoint, error) {
+		require.Equal(t, "https://example.com/hook", endpoint.URL)
+		require.Equal(t, 10*time.Second, endpoint.Timeout)
+		require.Equal(t, uint(0), endpoint.MaxRetries)
+		require.Equal(t, time.Second, endpoint.RetryBackoff)
+		require.Len(t, endpoint.Secret, easyalert.WebhookSecretLength)
+
+		endpoint.ID = 3
+		endpoint.CreatedAt = createdAt
+		endpoint.UpdatedAt = createdAt
+		return endpoint, nil
+	})
+
+	payload := `{"name": "ops", "url": "https://example.com/hook", "max_retries": 0}`
+
+	req, err := http.NewRequest("POST", "/api/webhooks", strings.NewReader(payload))
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+
+	rr := httptest.NewRecorder()
+	handler := api.CreateWebhooksHandler{
+		UserRepo:     userRepo,
+		EndpointRepo: endpointRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusCreated, rr.Code)
+	requireMatchesSpec(t, loadOpenAPI(t), "POST", "/api/webhooks", rr)
+
+	var body map[string]interface{}
+	require.Nil(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	require.Equal(t, "10s", body["timeout"])
+	require.Equal(t, "1s", body["retry_backoff"])
+	require.Equal(t, "/api/webhooks/3/deliveries", body["deliveries_url"])
+}
+
+func TestPUTWebhook_ShouldKeepSecretUnlessRotated(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil).Times(2)
+
+	endpoint := easyalert.WebhookEndpoint{ID: 3, Name: "ops", URL: "https://example.com/hook", Secret: "old", UserID: 1}
+
+	endpointRepo := mocks.NewMockWebhookEndpointRepository(mockCtrl)
+	endpointRepo.EXPECT().FindWebhookEndpoint("WHERE id = $1 AND user_id = $2", uint64(3), uint(1)).Return(endpoint, nil).Times(2)
+	endpointRepo.EXPECT().FindWebhookEndpoint("WHERE user_id = $1 AND name = $2", uint(1), "ops").Return(endpoint, nil).Times(2)
+
+	var secrets []string
+
+	endpointRepo.EXPECT().UpdateWebhookEndpoint(gomock.Any()).Times(2).DoAndReturn(func(e easyalert.WebhookEndpoint) (easyalert.WebhookEndpoint, error) {
+		secrets = append(secrets, e.Secret)
+		return e, nil
+	})
+
+	handler := api.UpdateWebhookHandler{
+		UserRepo:     userRepo,
+		EndpointRepo: endpointRepo,
+	}
+
+	for _, payload := range []string{
+		`{"name": "ops", "url": "https://example.com/hook"}`,
+		`{"name": "ops", "url": "https://example.com/hook", "rotate_secret": true}`,
+	} {
+		req, err := http.NewRequest("PUT", "/api/webhooks/3", strings.NewReader(payload))
+		require.Nil(t, err)
+
+		req.Header.Set("Authorization", "Bearer 12345")
+		req = mux.SetURLVars(req, map[string]string{"id": "3"})
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+	}
+
+	require.Equal(t, "old", secrets[0])
+	require.NotEqual(t, "old", secrets[1])
+}
+
+func TestGETWebhookDeliveries_ShouldReturnDeliveries(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	userRepo := mocks.NewMockUserRepository(mockCtrl)
+	userRepo.EXPECT().FindUser(gomock.Any(), gomock.Any()).Return(easyalert.User{ID: 1}, nil)
+
+	endpointRepo := mocks.NewMockWebhookEndpointRepository(mockCtrl)
+	endpointRepo.EXPECT().FindWebhookEndpoint("WHERE id = $1 AND user_id = $2", uint64(3), uint(1)).Return(easyalert.WebhookEndpoint{ID: 3, UserID: 1}, nil)
+
+	createdAt := time.Date(2019, 2, 4, 18, 0, 0, 0, time.UTC)
+
+	deliveryRepo := mocks.NewMockWebhookDeliveryRepository(mockCtrl)
+	deliveryRepo.EXPECT().FindWebhookDeliveries("WHERE endpoint_id = $1 ORDER BY created_at DESC, id DESC LIMIT $2", uint(3), 100).Return([]easyalert.WebhookDelivery{
+		{ID: 2, EndpointID: 3, AlertID: 5, Attempt: 2, StatusCode: 200, Latency: 120 * time.Millisecond, Response: "ok", CreatedAt: createdAt},
+		{ID: 1, EndpointID: 3, AlertID: 5, Attempt: 1, Latency: time.Second, Error: "connection refused", CreatedAt: createdAt},
+	}, nil)
+
+	req, err := http.NewRequest("GET", "/api/webhooks/3/deliveries", nil)
+	require.Nil(t, err)
+
+	req.Header.Set("Authorization", "Bearer 12345")
+	req = mux.SetURLVars(req, map[string]string{"id": "3"})
+
+	rr := httptest.NewRecorder()
+	handler := api.GetWebhookDeliveriesHandler{
+		UserRepo:     userRepo,
+		EndpointRepo: endpointRepo,
+		DeliveryRepo: deliveryRepo,
+	}
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	requireMatchesSpec(t, loadOpenAPI(t), "GET", "/api/webhooks/{id}/deliveries", rr)
+
+	expectedJsonResp := "[\n" +
+		"  {\n" +
+		"    \"id\": 2,\n" +
+		"    \"alert_id\": 5,\n" +
+		"    \"attempt\": 2,\n" +
+		"    \"status_code\": 200,\n" +
+		"    \"latency_ms\": 120,\n" +
+		"    \"response\": \"ok\",\n" +
+		"    \"created_at\": \"2019-02-04T18:00:00Z\"\n" +
+		"  },\n" +
+		"  {\n" +
+		"    \"id\": 1,\n" +
+		"    \"alert_id\": 5,\n" +
+		"    \"attempt\": 1,\n" +
+		"    \"latency_ms\": 1000,\n" +
+		"    \"error\": \"connection refused\",\n" +
+		"    \"created_at\": \"2019-02-04T18:00:00Z\"\n" +
+		"  }\n" +
+		"]"
+
+	require.Equal(t, expectedJsonResp, rr.Body.String())
+}
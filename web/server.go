@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/sms"
 	"github.com/bakku/easyalert/web/api"
 	"github.com/gorilla/mux"
 )
@@ -20,43 +21,187 @@ type Server struct {
 }
 
 // NewServer returns a new Server with all routes set up
-func NewServer(port string, userRepo easyalert.UserRepository, alertRepo easyalert.AlertRepository) *Server {
-	s := &Server{
+func NewServer(port string, userRepo easyalert.UserRepository, alertRepo easyalert.AlertRepository, heartbeatRepo easyalert.HeartbeatRepository, templateRepo easyalert.TemplateRepository, integrationRepo easyalert.IntegrationRepository, syslogSourceRepo easyalert.SyslogSourceRepository, webhookEndpointRepo easyalert.WebhookEndpointRepository, webhookDeliveryRepo easyalert.WebhookDeliveryRepository, chatChannelRepo easyalert.ChatChannelRepository, pushChannelRepo easyalert.PushChannelRepository, matrixRoomRepo easyalert.MatrixRoomRepository, routingRepo easyalert.RoutingRepository, idempotencyRepo easyalert.IdempotencyKeyRepository, notifier easyalert.Notifier, smsSender easyalert.SMSSender, alertLinks sms.LinkSigner) *Server {
+	router := newRouter(userRepo, alertRepo, heartbeatRepo, templateRepo, integrationRepo, syslogSourceRepo, webhookEndpointRepo, webhookDeliveryRepo, chatChannelRepo, pushChannelRepo, matrixRoomRepo, routingRepo, idempotencyRepo, notifier, smsSender, alertLinks)
+
+	return &Server{
 		server: http.Server{
-			Addr: ":" + port,
+			Addr:    ":" + port,
+			Handler: api.RequestID(router),
 		},
 	}
+}
 
+// newRouter returns a router with all routes of the API.
+func newRouter(userRepo easyalert.UserRepository, alertRepo easyalert.AlertRepository, heartbeatRepo easyalert.HeartbeatRepository, templateRepo easyalert.TemplateRepository, integrationRepo easyalert.IntegrationRepository, syslogSourceRepo easyalert.SyslogSourceRepository, webhookEndpointRepo easyalert.WebhookEndpointRepository, webhookDeliveryRepo easyalert.WebhookDeliveryRepository, chatChannelRepo easyalert.ChatChannelRepository, pushChannelRepo easyalert.PushChannelRepository, matrixRoomRepo easyalert.MatrixRoomRepository, routingRepo easyalert.RoutingRepository, idempotencyRepo easyalert.IdempotencyKeyRepository, notifier easyalert.Notifier, smsSender easyalert.SMSSender, alertLinks sms.LinkSigner) *mux.Router {
 	router := mux.NewRouter()
 
 	// api handler
 	home := api.HomeHandler{}
+	openAPI := api.OpenAPIHandler{}
 
 	createUsers := api.CreateUsersHandler{userRepo}
 	updateUser := api.UpdateUserHandler{userRepo}
 	deleteUser := api.DeleteUserHandler{userRepo}
+	getPhone := api.GetPhoneHandler{userRepo}
+	updatePhone := api.UpdatePhoneHandler{userRepo, smsSender}
+	verifyPhone := api.VerifyPhoneHandler{userRepo}
+	deletePhone := api.DeletePhoneHandler{userRepo}
+	getInboundToken := api.GetInboundTokenHandler{userRepo}
+	rotateInboundToken := api.RotateInboundTokenHandler{userRepo}
 
 	getAlerts := api.GetAlertsHandler{userRepo, alertRepo}
-	createAlerts := api.CreateAlertsHandler{userRepo, alertRepo}
+	createAlerts := api.CreateAlertsHandler{userRepo, alertRepo, templateRepo, idempotencyRepo, notifier}
+	createAlertsBatch := api.CreateAlertsBatchHandler{userRepo, alertRepo, templateRepo, idempotencyRepo, notifier}
+	previewAlert := api.PreviewAlertHandler{userRepo, templateRepo}
+	getAlert := api.GetAlertHandler{userRepo, alertRepo}
+	deleteAlert := api.DeleteAlertHandler{userRepo, alertRepo}
+	resendAlert := api.ResendAlertHandler{userRepo, alertRepo, notifier}
+	cancelAlert := api.CancelAlertHandler{userRepo, alertRepo}
+	alertLink := api.AlertLinkHandler{alertRepo, alertLinks}
+
+	createHeartbeats := api.CreateHeartbeatsHandler{userRepo, heartbeatRepo}
+	getHeartbeats := api.GetHeartbeatsHandler{userRepo, heartbeatRepo}
+	deleteHeartbeat := api.DeleteHeartbeatHandler{userRepo, heartbeatRepo}
+	pingHeartbeat := api.PingHeartbeatHandler{userRepo, heartbeatRepo, alertRepo, notifier}
+
+	getTemplates := api.GetTemplatesHandler{userRepo, templateRepo}
+	createTemplates := api.CreateTemplatesHandler{userRepo, templateRepo}
+	getTemplate := api.GetTemplateHandler{userRepo, templateRepo}
+	updateTemplate := api.UpdateTemplateHandler{userRepo, templateRepo}
+	deleteTemplate := api.DeleteTemplateHandler{userRepo, templateRepo}
+
+	getIntegrations := api.GetIntegrationsHandler{userRepo, integrationRepo}
+	createIntegrations := api.CreateIntegrationsHandler{userRepo, integrationRepo}
+	getIntegration := api.GetIntegrationHandler{userRepo, integrationRepo}
+	updateIntegration := api.UpdateIntegrationHandler{userRepo, integrationRepo}
+	deleteIntegration := api.DeleteIntegrationHandler{userRepo, integrationRepo}
+	integrationWebhook := api.IntegrationWebhookHandler{userRepo, integrationRepo, alertRepo, notifier}
+	alertmanager := api.AlertmanagerHandler{userRepo, alertRepo, notifier}
+
+	getSyslogSources := api.GetSyslogSourcesHandler{userRepo, syslogSourceRepo}
+	createSyslogSources := api.CreateSyslogSourcesHandler{userRepo, syslogSourceRepo}
+	getSyslogSource := api.GetSyslogSourceHandler{userRepo, syslogSourceRepo}
+	updateSyslogSource := api.UpdateSyslogSourceHandler{userRepo, syslogSourceRepo}
+	deleteSyslogSource := api.DeleteSyslogSourceHandler{userRepo, syslogSourceRepo}
+
+	getWebhooks := api.GetWebhooksHandler{userRepo, webhookEndpointRepo}
+	createWebhooks := api.CreateWebhooksHandler{userRepo, webhookEndpointRepo}
+	getWebhook := api.GetWebhookHandler{userRepo, webhookEndpointRepo}
+	updateWebhook := api.UpdateWebhookHandler{userRepo, webhookEndpointRepo}
+	deleteWebhook := api.DeleteWebhookHandler{userRepo, webhookEndpointRepo}
+	getWebhookDeliveries := api.GetWebhookDeliveriesHandler{userRepo, webhookEndpointRepo, webhookDeliveryRepo}
+
+	getChatChannels := api.GetChatChannelsHandler{userRepo, chatChannelRepo}
+	createChatChannels := api.CreateChatChannelsHandler{userRepo, chatChannelRepo}
+	getChatChannel := api.GetChatChannelHandler{userRepo, chatChannelRepo}
+	updateChatChannel := api.UpdateChatChannelHandler{userRepo, chatChannelRepo}
+	deleteChatChannel := api.DeleteChatChannelHandler{userRepo, chatChannelRepo}
+
+	getPushChannels := api.GetPushChannelsHandler{userRepo, pushChannelRepo}
+	createPushChannels := api.CreatePushChannelsHandler{userRepo, pushChannelRepo}
+	getPushChannel := api.GetPushChannelHandler{userRepo, pushChannelRepo}
+	updatePushChannel := api.UpdatePushChannelHandler{userRepo, pushChannelRepo}
+	deletePushChannel := api.DeletePushChannelHandler{userRepo, pushChannelRepo}
+
+	getMatrixRooms := api.GetMatrixRoomsHandler{userRepo, matrixRoomRepo}
+	createMatrixRooms := api.CreateMatrixRoomsHandler{userRepo, matrixRoomRepo}
+	getMatrixRoom := api.GetMatrixRoomHandler{userRepo, matrixRoomRepo}
+	updateMatrixRoom := api.UpdateMatrixRoomHandler{userRepo, matrixRoomRepo}
+	deleteMatrixRoom := api.DeleteMatrixRoomHandler{userRepo, matrixRoomRepo}
+
+	getRouting := api.GetRoutingHandler{userRepo, routingRepo}
+	updateRouting := api.UpdateRoutingHandler{userRepo, routingRepo}
+	deleteRouting := api.DeleteRoutingHandler{userRepo, routingRepo}
+	dryRunRouting := api.DryRunRoutingHandler{userRepo, routingRepo, webhookEndpointRepo, chatChannelRepo, pushChannelRepo, matrixRoomRepo}
 
 	auth := api.AuthHandler{userRepo}
 	authRefresh := api.AuthRefreshHandler{userRepo}
 
 	router.Methods("GET").Path("/api").Handler(home)
+	router.Methods("GET").Path("/api/openapi.json").Handler(openAPI)
 
 	router.Methods("POST").Path("/api/users").Handler(createUsers)
 	router.Methods("PUT").Path("/api/users/me").Handler(updateUser)
 	router.Methods("DELETE").Path("/api/users/me").Handler(deleteUser)
+	router.Methods("GET").Path("/api/users/me/phone").Handler(getPhone)
+	router.Methods("PUT").Path("/api/users/me/phone").Handler(updatePhone)
+	router.Methods("POST").Path("/api/users/me/phone/verify").Handler(verifyPhone)
+	router.Methods("DELETE").Path("/api/users/me/phone").Handler(deletePhone)
+	router.Methods("GET").Path("/api/users/me/inbound-token").Handler(getInboundToken)
+	router.Methods("POST").Path("/api/users/me/inbound-token").Handler(rotateInboundToken)
 
 	router.Methods("GET").Path("/api/alerts").Handler(getAlerts)
 	router.Methods("POST").Path("/api/alerts").Handler(createAlerts)
+	router.Methods("POST").Path("/api/alerts/batch").Handler(createAlertsBatch)
+	router.Methods("POST").Path("/api/alerts/preview").Handler(previewAlert)
+	router.Methods("GET").Path("/api/alerts/{id:[0-9]+}").Handler(getAlert)
+	router.Methods("DELETE").Path("/api/alerts/{id:[0-9]+}").Handler(deleteAlert)
+	router.Methods("POST").Path("/api/alerts/{id:[0-9]+}/resend").Handler(resendAlert)
+	router.Methods("POST").Path("/api/alerts/{id:[0-9]+}/cancel").Handler(cancelAlert)
+	router.Methods("GET").Path("/a/{token:[A-Za-z0-9_-]+}").Handler(alertLink)
+
+	router.Methods("GET").Path("/api/heartbeats").Handler(getHeartbeats)
+	router.Methods("POST").Path("/api/heartbeats").Handler(createHeartbeats)
+	router.Methods("DELETE").Path("/api/heartbeats/{id:[0-9]+}").Handler(deleteHeartbeat)
+	router.Methods("POST").Path("/api/heartbeats/{id:[0-9]+}/ping").Handler(pingHeartbeat)
+
+	router.Methods("GET").Path("/api/templates").Handler(getTemplates)
+	router.Methods("POST").Path("/api/templates").Handler(createTemplates)
+	router.Methods("GET").Path("/api/templates/{id:[0-9]+}").Handler(getTemplate)
+	router.Methods("PUT").Path("/api/templates/{id:[0-9]+}").Handler(updateTemplate)
+	router.Methods("DELETE").Path("/api/templates/{id:[0-9]+}").Handler(deleteTemplate)
+
+	router.Methods("GET").Path("/api/integrations").Handler(getIntegrations)
+	router.Methods("POST").Path("/api/integrations").Handler(createIntegrations)
+	router.Methods("GET").Path("/api/integrations/{id:[0-9]+}").Handler(getIntegration)
+	router.Methods("PUT").Path("/api/integrations/{id:[0-9]+}").Handler(updateIntegration)
+	router.Methods("DELETE").Path("/api/integrations/{id:[0-9]+}").Handler(deleteIntegration)
+	router.Methods("POST").Path("/api/integrations/{id:[0-9]+}/webhook").Handler(integrationWebhook)
+	router.Methods("POST").Path("/api/integrations/alertmanager").Handler(alertmanager)
+
+	router.Methods("GET").Path("/api/syslog-sources").Handler(getSyslogSources)
+	router.Methods("POST").Path("/api/syslog-sources").Handler(createSyslogSources)
+	router.Methods("GET").Path("/api/syslog-sources/{id:[0-9]+}").Handler(getSyslogSource)
+	router.Methods("PUT").Path("/api/syslog-sources/{id:[0-9]+}").Handler(updateSyslogSource)
+	router.Methods("DELETE").Path("/api/syslog-sources/{id:[0-9]+}").Handler(deleteSyslogSource)
+
+	router.Methods("GET").Path("/api/webhooks").Handler(getWebhooks)
+	router.Methods("POST").Path("/api/webhooks").Handler(createWebhooks)
+	router.Methods("GET").Path("/api/webhooks/{id:[0-9]+}").Handler(getWebhook)
+	router.Methods("PUT").Path("/api/webhooks/{id:[0-9]+}").Handler(updateWebhook)
+	router.Methods("DELETE").Path("/api/webhooks/{id:[0-9]+}").Handler(deleteWebhook)
+	router.Methods("GET").Path("/api/webhooks/{id:[0-9]+}/deliveries").Handler(getWebhookDeliveries)
+
+	router.Methods("GET").Path("/api/chat-channels").Handler(getChatChannels)
+	router.Methods("POST").Path("/api/chat-channels").Handler(createChatChannels)
+	router.Methods("GET").Path("/api/chat-channels/{id:[0-9]+}").Handler(getChatChannel)
+	router.Methods("PUT").Path("/api/chat-channels/{id:[0-9]+}").Handler(updateChatChannel)
+	router.Methods("DELETE").Path("/api/chat-channels/{id:[0-9]+}").Handler(deleteChatChannel)
+
+	router.Methods("GET").Path("/api/push-channels").Handler(getPushChannels)
+	router.Methods("POST").Path("/api/push-channels").Handler(createPushChannels)
+	router.Methods("GET").Path("/api/push-channels/{id:[0-9]+}").Handler(getPushChannel)
+	router.Methods("PUT").Path("/api/push-channels/{id:[0-9]+}").Handler(updatePushChannel)
+	router.Methods("DELETE").Path("/api/push-channels/{id:[0-9]+}").Handler(deletePushChannel)
+
+	router.Methods("GET").Path("/api/matrix-rooms").Handler(getMatrixRooms)
+	router.Methods("POST").Path("/api/matrix-rooms").Handler(createMatrixRooms)
+	router.Methods("GET").Path("/api/matrix-rooms/{id:[0-9]+}").Handler(getMatrixRoom)
+	router.Methods("PUT").Path("/api/matrix-rooms/{id:[0-9]+}").Handler(updateMatrixRoom)
+	router.Methods("DELETE").Path("/api/matrix-rooms/{id:[0-9]+}").Handler(deleteMatrixRoom)
+
+	router.Methods("GET").Path("/api/routing").Handler(getRouting)
+	router.Methods("PUT").Path("/api/routing").Handler(updateRouting)
+	router.Methods("DELETE").Path("/api/routing").Handler(deleteRouting)
+	router.Methods("POST").Path("/api/routing/dry-run").Handler(dryRunRouting)
 
 	router.Methods("POST").Path("/api/auth").Handler(auth)
 	router.Methods("PUT").Path("/api/auth/refresh").Handler(authRefresh)
 
-	s.server.Handler = router
+	router.NotFoundHandler = api.NotFoundHandler{}
 
-	return s
+	return router
 }
 
 // Start starts the HTTP server with graceful shutdown implemented
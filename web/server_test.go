@@ -0,0 +1,76 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/bakku/easyalert/sms"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+// routeVariablePattern matches the regular expression of a route variable,
+// e.g. {id:[0-9]+}, which is not part of the OpenAPI path.
+var routeVariablePattern = regexp.MustCompile(`\{(\w+):[^}]*\}`)
+
+func TestOpenAPI_ShouldDescribeEveryRoute(t *testing.T) {
+	router := newRouter(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, sms.LinkSigner{})
+
+	var routes []string
+
+	err := router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+
+		methods, err := route.GetMethods()
+		if err != nil {
+			return err
+		}
+
+		for _, method := range methods {
+			routes = append(routes, method+" "+routeVariablePattern.ReplaceAllString(path, "{$1}"))
+		}
+
+		return nil
+	})
+	require.Nil(t, err)
+
+	req, err := http.NewRequest("GET", "/api/openapi.json", nil)
+	require.Nil(t, err)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var spec struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+
+	err = json.Unmarshal(rr.Body.Bytes(), &spec)
+	require.Nil(t, err)
+
+	var documented []string
+
+	for path, operations := range spec.Paths {
+		for method := range operations {
+			if method == "parameters" {
+				continue
+			}
+
+			documented = append(documented, strings.ToUpper(method)+" "+path)
+		}
+	}
+
+	sort.Strings(routes)
+	sort.Strings(documented)
+
+	require.Equal(t, routes, documented)
+}
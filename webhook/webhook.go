@@ -0,0 +1,339 @@
+// Package webhook maps the fields of arbitrary JSON webhook payloads onto
+// alerts using JSONPath-like expressions.
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bakku/easyalert"
+)
+
+// PresetGrafana maps the payloads of Grafana unified alerting.
+const PresetGrafana = "grafana"
+
+// Presets are the mappings of well known tools.
+var Presets = map[string]easyalert.FieldMapping{
+	PresetGrafana: {
+		Subject:  "$.title",
+		Message:  "$.message",
+		Severity: "$.commonLabels.severity",
+		// the start of the first alert separates incidents of the group,
+		// so an alert firing again after it resolved is not folded
+		DedupKey: "grafana:{$.groupKey}:{$.alerts[0].startsAt}:{$.status}",
+	},
+}
+
+// ErrInvalidJSON is returned if the payload is not valid JSON.
+var ErrInvalidJSON = errors.New("invalid json")
+
+// ErrNoSubject is returned if the subject expression does not match anything inside the payload.
+var ErrNoSubject = errors.New("Subject expression did not match the payload.")
+
+// InvalidExpressionError is returned if an expression of a mapping can not be parsed.
+type InvalidExpressionError struct {
+	Field string
+	Err   error
+}
+
+func (e InvalidExpressionError) Error() string {
+	return fmt.Sprintf("Invalid %s expression: %v.", e.Field, e.Err)
+}
+
+// Validate checks that the subject is mapped and that all expressions of the
+// mapping can be parsed.
+func Validate(m easyalert.FieldMapping) error {
+	if m.Subject == "" {
+		return InvalidExpressionError{"subject", errors.New("subject has to be mapped")}
+	}
+
+	for _, field := range []struct {
+		name string
+		expr string
+	}{
+		{"subject", m.Subject},
+		{"message", m.Message},
+		{"severity", m.Severity},
+		{"dedup_key", m.DedupKey},
+	} {
+		_, err := parseExpression(field.expr)
+		if err != nil {
+			return InvalidExpressionError{field.name, err}
+		}
+	}
+
+	return nil
+}
+
+// Notification maps the payload onto an alert of the user. If the message is
+// not mapped the whole payload is sent as message.
+func Notification(m easyalert.FieldMapping, user easyalert.User, payload []byte) (easyalert.Notification, error) {
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+
+	var doc interface{}
+
+	err := decoder.Decode(&doc)
+	if err != nil || decoder.More() {
+		return easyalert.Notification{}, ErrInvalidJSON
+	}
+
+	eval := func(field, expr string) (string, error) {
+		e, err := parseExpression(expr)
+		if err != nil {
+			return "", InvalidExpressionError{field, err}
+		}
+
+		return e.eval(doc), nil
+	}
+
+	evalComplete := func(field, expr string) (string, error) {
+		e, err := parseExpression(expr)
+		if err != nil {
+			return "", InvalidExpressionError{field, err}
+		}
+
+		return e.evalComplete(doc), nil
+	}
+
+	subject, err := eval("subject", m.Subject)
+	if err != nil {
+		return easyalert.Notification{}, err
+	}
+
+	// a subject spanning multiple lines would break the mail header
+	subject = strings.Join(strings.Fields(subject), " ")
+
+	if subject == "" {
+		return easyalert.Notification{}, ErrNoSubject
+	}
+
+	message, err := eval("message", m.Message)
+	if err != nil {
+		return easyalert.Notification{}, err
+	}
+
+	if strings.TrimSpace(message) == "" {
+		var buf bytes.Buffer
+
+		json.Indent(&buf, payload, "", "  ")
+		message = buf.String()
+	}
+
+	severity, err := eval("severity", m.Severity)
+	if err != nil {
+		return easyalert.Notification{}, err
+	}
+
+	// a key with missing parts would fold unrelated alerts
+	dedupKey, err := evalComplete("dedup_key", m.DedupKey)
+	if err != nil {
+		return easyalert.Notification{}, err
+	}
+
+	return easyalert.Notification{
+		Alert: easyalert.Alert{
+			Subject:     subject,
+			Status:      easyalert.AlertStatusPending,
+			UserID:      user.ID,
+			Occurrences: 1,
+			Severity:    easyalert.SeverityFromName(severity),
+			DedupKey:    dedupKey,
+		},
+		Message: message,
+		Format:  easyalert.MessageFormatText,
+	}, nil
+}
+
+// expression is either a single path like "$.alerts[0].labels.severity" or
+// text containing paths in braces like "grafana:{$.groupKey}".
+type expression []part
+
+type part struct {
+	text string
+	path path
+}
+
+func parseExpression(s string) (expression, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	if strings.HasPrefix(s, "$") {
+		p, err := parsePath(s)
+		if err != nil {
+			return nil, err
+		}
+
+		return expression{{path: p}}, nil
+	}
+
+	var e expression
+
+	for {
+		start := strings.Index(s, "{$")
+		if start < 0 {
+			return append(e, part{text: s}), nil
+		}
+
+		end := strings.Index(s[start:], "}")
+		if end < 0 {
+			return nil, fmt.Errorf("missing } after %q", s[start:])
+		}
+
+		p, err := parsePath(s[start+1 : start+end])
+		if err != nil {
+			return nil, err
+		}
+
+		e = append(e, part{text: s[:start]}, part{path: p})
+		s = s[start+end+1:]
+	}
+}
+
+func (e expression) eval(doc interface{}) string {
+	var b strings.Builder
+
+	for _, p := range e {
+		if p.path == nil {
+			b.WriteString(p.text)
+			continue
+		}
+
+		value, ok := p.path.lookup(doc)
+		if ok {
+			b.WriteString(stringify(value))
+		}
+	}
+
+	return b.String()
+}
+
+// evalComplete is like eval, but returns an empty string if any path of
+// the expression does not exist or is empty.
+func (e expression) evalComplete(doc interface{}) string {
+	for _, p := range e {
+		if p.path == nil {
+			continue
+		}
+
+		value, ok := p.path.lookup(doc)
+		if !ok || stringify(value) == "" {
+			return ""
+		}
+	}
+
+	return e.eval(doc)
+}
+
+// path is a parsed path. Every segment is either a string key of an object
+// or an int index of an array.
+type path []interface{}
+
+// parsePath parses paths starting at the root $. Object keys are given as
+// .key or ["key"], array indexes as [0]. Negative indexes count from the end.
+func parsePath(s string) (path, error) {
+	if !strings.HasPrefix(s, "$") {
+		return nil, fmt.Errorf("path %q has to start with $", s)
+	}
+
+	p := path{}
+	rest := s[1:]
+
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+
+			key := rest[1 : end+1]
+			if key == "" {
+				return nil, fmt.Errorf("empty key in path %q", s)
+			}
+
+			p = append(p, key)
+			rest = rest[end+1:]
+		case '[':
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("missing ] in path %q", s)
+			}
+
+			inner := rest[1:end]
+
+			if len(inner) >= 2 && (inner[0] == '"' || inner[0] == '\'') && inner[len(inner)-1] == inner[0] {
+				p = append(p, inner[1:len(inner)-1])
+			} else {
+				index, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("invalid index %q in path %q", inner, s)
+				}
+
+				p = append(p, index)
+			}
+
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("unexpected %q in path %q", rest[0], s)
+		}
+	}
+
+	return p, nil
+}
+
+func (p path) lookup(doc interface{}) (interface{}, bool) {
+	current := doc
+
+	for _, segment := range p {
+		switch s := segment.(type) {
+		case string:
+			object, ok := current.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+
+			current, ok = object[s]
+			if !ok {
+				return nil, false
+			}
+		case int:
+			array, ok := current.([]interface{})
+			if !ok {
+				return nil, false
+			}
+
+			if s < 0 {
+				s += len(array)
+			}
+
+			if s < 0 || s >= len(array) {
+				return nil, false
+			}
+
+			current = array[s]
+		}
+	}
+
+	return current, true
+}
+
+// stringify returns strings and numbers as they are and everything else as JSON.
+func stringify(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	default:
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+}
@@ -0,0 +1,111 @@
+package webhook_test
+
+import (
+	"testing"
+
+	"github.com/bakku/easyalert"
+	"github.com/bakku/easyalert/webhook"
+	"github.com/stretchr/testify/require"
+)
+
+const grafanaPayload = `{
+	"receiver": "easyalert",
+	"status": "firing",
+	"orgId": 1,
+	"alerts": [
+		{
+			"status": "firing",
+			"labels": {"alertname": "HighCPU", "severity": "warning"},
+			"startsAt": "2019-02-18T19:05:07Z",
+			"values": {"B": 97.5}
+		}
+	],
+	"groupLabels": {"alertname": "HighCPU"},
+	"commonLabels": {"alertname": "HighCPU", "severity": "warning"},
+	"commonAnnotations": {},
+	"externalURL": "http://grafana:3000/",
+	"version": "1",
+	"groupKey": "{}:{alertname=\"HighCPU\"}",
+	"truncatedAlerts": 0,
+	"title": "[FIRING:1] HighCPU",
+	"state": "alerting",
+	"message": "CPU usage is at 97.5%"
+}`
+
+func TestNotification_GrafanaPreset(t *testing.T) {
+	n, err := webhook.Notification(webhook.Presets[webhook.PresetGrafana], easyalert.User{ID: 3}, []byte(grafanaPayload))
+	require.Nil(t, err)
+
+	require.Equal(t, "[FIRING:1] HighCPU", n.Alert.Subject)
+	require.Equal(t, "CPU usage is at 97.5%", n.Message)
+	require.Equal(t, uint(easyalert.AlertSeverityWarning), n.Alert.Severity)
+	require.Equal(t, `grafana:{}:{alertname="HighCPU"}:2019-02-18T19:05:07Z:firing`, n.Alert.DedupKey)
+	require.Equal(t, uint(3), n.Alert.UserID)
+	require.Equal(t, easyalert.MessageFormatText, n.Format)
+}
+
+func TestNotification_CustomMapping(t *testing.T) {
+	mapping := easyalert.FieldMapping{
+		Subject:  "{$.alerts[0].labels.alertname} at {$.alerts[-1].values.B}%",
+		Message:  `$.alerts[0]["labels"]`,
+		Severity: "critical",
+		DedupKey: "$.orgId",
+	}
+
+	n, err := webhook.Notification(mapping, easyalert.User{}, []byte(grafanaPayload))
+	require.Nil(t, err)
+
+	require.Equal(t, "HighCPU at 97.5%", n.Alert.Subject)
+	require.Equal(t, `{"alertname":"HighCPU","severity":"warning"}`, n.Message)
+	require.Equal(t, uint(easyalert.AlertSeverityCritical), n.Alert.Severity)
+	require.Equal(t, "1", n.Alert.DedupKey)
+}
+
+func TestNotification_SendsPayloadIfMessageIsNotMapped(t *testing.T) {
+	n, err := webhook.Notification(easyalert.FieldMapping{Subject: "$.title"}, easyalert.User{}, []byte(`{"title": "Deploy failed", "count": 2}`))
+	require.Nil(t, err)
+
+	require.Equal(t, "{\n  \"title\": \"Deploy failed\",\n  \"count\": 2\n}", n.Message)
+	require.Equal(t, uint(easyalert.AlertSeverityInfo), n.Alert.Severity)
+	require.Equal(t, "", n.Alert.DedupKey)
+}
+
+func TestNotification_LeavesDedupKeyEmptyIfPathIsMissing(t *testing.T) {
+	payload := `{"title": "[FIRING:1] HighCPU", "status": "firing", "alerts": [{"startsAt": "2019-02-18T19:05:07Z"}]}`
+
+	n, err := webhook.Notification(webhook.Presets[webhook.PresetGrafana], easyalert.User{}, []byte(payload))
+	require.Nil(t, err)
+
+	require.Equal(t, "", n.Alert.DedupKey)
+}
+
+func TestNotification_Errors(t *testing.T) {
+	mapping := easyalert.FieldMapping{Subject: "$.title"}
+
+	_, err := webhook.Notification(mapping, easyalert.User{}, []byte(`{"title": "x"} {}`))
+	require.Equal(t, webhook.ErrInvalidJSON, err)
+
+	_, err = webhook.Notification(mapping, easyalert.User{}, []byte(`{"name": "x"}`))
+	require.Equal(t, webhook.ErrNoSubject, err)
+}
+
+func TestValidate(t *testing.T) {
+	require.Nil(t, webhook.Validate(webhook.Presets[webhook.PresetGrafana]))
+
+	tests := []struct {
+		mapping easyalert.FieldMapping
+		field   string
+	}{
+		{easyalert.FieldMapping{}, "subject"},
+		{easyalert.FieldMapping{Subject: "$.title["}, "subject"},
+		{easyalert.FieldMapping{Subject: "$.title", Message: "$..message"}, "message"},
+		{easyalert.FieldMapping{Subject: "$.title", Severity: "$.labels[first]"}, "severity"},
+		{easyalert.FieldMapping{Subject: "$.title", DedupKey: "id-{$.id"}, "dedup_key"},
+	}
+
+	for _, test := range tests {
+		err := webhook.Validate(test.mapping)
+		require.NotNil(t, err)
+		require.Equal(t, test.field, err.(webhook.InvalidExpressionError).Field)
+	}
+}
@@ -0,0 +1,73 @@
+package easyalert
+
+import "time"
+
+// WebhookSecretLength is the length of the secret used to sign webhook requests.
+const WebhookSecretLength = 32
+
+// MaxWebhookRetryWait limits the time waited between the attempts of a
+// single endpoint, since the alert is only marked as sent or failed once
+// all channels finished.
+const MaxWebhookRetryWait = 10 * time.Second
+
+// WebhookEndpointRepository wraps all CRUD operations for webhook endpoints
+type WebhookEndpointRepository interface {
+	FindWebhookEndpoint(query string, params ...interface{}) (WebhookEndpoint, error)
+	FindWebhookEndpoints(query string, params ...interface{}) ([]WebhookEndpoint, error)
+	CreateWebhookEndpoint(endpoint WebhookEndpoint) (WebhookEndpoint, error)
+	UpdateWebhookEndpoint(endpoint WebhookEndpoint) (WebhookEndpoint, error)
+	DeleteWebhookEndpoint(endpoint WebhookEndpoint) error
+}
+
+// WebhookEndpoint is a URL of the user which receives every alert as signed
+// JSON request.
+type WebhookEndpoint struct {
+	ID     uint
+	Name   string
+	URL    string
+	Secret string
+	// Timeout limits a single attempt.
+	Timeout time.Duration
+	// MaxRetries is the number of attempts after the first failed one.
+	MaxRetries uint
+	// RetryBackoff is the delay before the first retry, it doubles on every
+	// further retry.
+	RetryBackoff time.Duration
+	UserID       uint
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// RetryWait returns the time waited between the attempts if all retries are
+// made.
+func (e WebhookEndpoint) RetryWait() time.Duration {
+	wait, backoff := time.Duration(0), e.RetryBackoff
+
+	for retry := uint(0); retry < e.MaxRetries; retry++ {
+		wait += backoff
+		backoff *= 2
+	}
+
+	return wait
+}
+
+// WebhookDeliveryRepository wraps the operations for the delivery log of webhook endpoints
+type WebhookDeliveryRepository interface {
+	FindWebhookDeliveries(query string, params ...interface{}) ([]WebhookDelivery, error)
+	CreateWebhookDelivery(delivery WebhookDelivery) (WebhookDelivery, error)
+}
+
+// WebhookDelivery records a single attempt to deliver an alert to a webhook
+// endpoint. StatusCode is zero if no response was received.
+type WebhookDelivery struct {
+	ID         uint
+	EndpointID uint
+	AlertID    uint
+	Attempt    uint
+	StatusCode int
+	Latency    time.Duration
+	// Response is the beginning of the response body.
+	Response  string
+	Error     string
+	CreatedAt time.Time
+}